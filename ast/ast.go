@@ -2,7 +2,10 @@ package ast
 
 import (
 	"fmt"
+	gotoken "go/token"
+	"math/big"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/MarcinKonowalczyk/goruby/ast/infix"
 )
@@ -14,6 +17,8 @@ type Node interface {
 	node() // marks this as a node
 	Coder
 	String() string
+	Pos() gotoken.Pos // position of the first character belonging to the node
+	End() gotoken.Pos // position of the first character immediately after the node
 }
 
 type Statement interface {
@@ -30,10 +35,29 @@ type Coder interface {
 	Code() string
 }
 
+// Span records the source range of a node. The positions are go/token
+// positions and resolve to file:line:column through the FileSet the program
+// was parsed with. Nodes built by hand (e.g. by the transformer) have a zero
+// Span, for which Pos().IsValid() reports false.
+type Span struct {
+	StartPos gotoken.Pos
+	EndPos   gotoken.Pos
+}
+
+func (s *Span) Pos() gotoken.Pos { return s.StartPos }
+func (s *Span) End() gotoken.Pos { return s.EndPos }
+
+// SetSpan sets the source range of the node
+func (s *Span) SetSpan(start, end gotoken.Pos) {
+	s.StartPos = start
+	s.EndPos = end
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 // A Program node is the root node within the AST.
 type Program struct {
+	Span
 	Statements []Statement
 }
 
@@ -55,8 +79,12 @@ var (
 )
 
 // A ReturnStatement represents a return node which yields another Expression.
+// The optional Condition works as for a BreakStatement.
 type ReturnStatement struct {
+	Span
 	ReturnValue Expression
+	Condition   Expression
+	Unless      bool
 }
 
 func (rs *ReturnStatement) node()          {}
@@ -64,12 +92,7 @@ func (rs *ReturnStatement) statementNode() {}
 func (rs *ReturnStatement) String() string { return "<<<ReturnStatement>>>" }
 
 func (rs *ReturnStatement) Code() string {
-	var out strings.Builder
-	out.WriteString("return ")
-	if rs.ReturnValue != nil {
-		out.WriteString(rs.ReturnValue.Code())
-	}
-	return out.String()
+	return jumpCode("return", rs.ReturnValue, rs.Condition, rs.Unless)
 }
 
 var (
@@ -79,6 +102,7 @@ var (
 
 // An ExpressionStatement is a Statement wrapping an Expression
 type ExpressionStatement struct {
+	Span
 	Expression Expression
 }
 
@@ -100,6 +124,7 @@ var (
 
 // BlockStatement represents a list of statements
 type BlockStatement struct {
+	Span
 	Statements []Statement
 }
 
@@ -132,8 +157,12 @@ var (
 	_ Statement = &BlockStatement{}
 )
 
-// A BreakStatement represents a break statement
+// A BreakStatement represents a break statement. The break only takes effect
+// if the optional Condition holds, as in `break if cond` or
+// `break unless cond`.
 type BreakStatement struct {
+	Span
+	Value     Expression
 	Condition Expression
 	Unless    bool
 }
@@ -143,17 +172,7 @@ func (bs *BreakStatement) statementNode() {}
 func (bs *BreakStatement) String() string { return "<<<Break Statement>>>" }
 
 func (bs *BreakStatement) Code() string {
-	var out strings.Builder
-	out.WriteString("break ")
-	if bs.Unless {
-		out.WriteString("unless ")
-	} else {
-		out.WriteString("if ")
-	}
-	if bs.Condition != nil {
-		out.WriteString(bs.Condition.Code())
-	}
-	return out.String()
+	return jumpCode("break", bs.Value, bs.Condition, bs.Unless)
 }
 
 var (
@@ -161,8 +180,51 @@ var (
 	_ Statement = &BreakStatement{}
 )
 
+// A NextStatement represents a next statement, which skips the rest of the
+// current loop iteration. The optional Condition works as for a
+// BreakStatement.
+type NextStatement struct {
+	Span
+	Value     Expression
+	Condition Expression
+	Unless    bool
+}
+
+func (ns *NextStatement) node()          {}
+func (ns *NextStatement) statementNode() {}
+func (ns *NextStatement) String() string { return "<<<Next Statement>>>" }
+
+func (ns *NextStatement) Code() string {
+	return jumpCode("next", ns.Value, ns.Condition, ns.Unless)
+}
+
+var (
+	_ Node      = &NextStatement{}
+	_ Statement = &NextStatement{}
+)
+
+// jumpCode returns the code of a return, break, next or redo statement
+func jumpCode(keyword string, value, condition Expression, unless bool) string {
+	var out strings.Builder
+	out.WriteString(keyword)
+	if value != nil {
+		out.WriteString(" ")
+		out.WriteString(value.Code())
+	}
+	if condition != nil {
+		if unless {
+			out.WriteString(" unless ")
+		} else {
+			out.WriteString(" if ")
+		}
+		out.WriteString(condition.Code())
+	}
+	return out.String()
+}
+
 // Assignment represents a generic assignment
 type Assignment struct {
+	Span
 	Left  Expression
 	Right Expression
 }
@@ -184,8 +246,38 @@ var (
 	_ Expression = &Assignment{}
 )
 
+// LogicalAssignment represents `x ||= y` and `x &&= y`, which assign Right to
+// Left only if Left is falsy or truthy respectively. Operator is either
+// LOGICALOR or LOGICALAND.
+type LogicalAssignment struct {
+	Span
+	Left     Expression
+	Operator infix.Infix
+	Right    Expression
+}
+
+func (la *LogicalAssignment) node()           {}
+func (la *LogicalAssignment) expressionNode() {}
+func (la *LogicalAssignment) String() string  { return "<<<LogicalAssignment>>>" }
+
+func (la *LogicalAssignment) Code() string {
+	var out strings.Builder
+	out.WriteString(maybeParenthesize(la.Left.Code(), needsParens(la.Left)))
+	out.WriteString(" ")
+	out.WriteString(la.Operator.String())
+	out.WriteString("= ")
+	out.WriteString(maybeParenthesize(la.Right.Code(), needsParens(la.Right)))
+	return out.String()
+}
+
+var (
+	_ Node       = &LogicalAssignment{}
+	_ Expression = &LogicalAssignment{}
+)
+
 // MultiAssignment represents multiple variables on the left-hand side
 type MultiAssignment struct {
+	Span
 	Variables []*Identifier
 	Values    []Expression
 }
@@ -216,6 +308,7 @@ var (
 )
 
 type Identifier struct {
+	Span
 	Value string
 }
 
@@ -243,15 +336,27 @@ func (i *Identifier) IsGlobal() bool {
 	return i.Value[0] == '$'
 }
 
+func (i *Identifier) IsInstanceVariable() bool {
+	if len(i.Value) == 0 {
+		return false
+	}
+	return i.Value[0] == '@'
+}
+
 // IntegerLiteral represents an integer in the AST
 type IntegerLiteral struct {
+	Span
 	Value int64
+	Big   *big.Int // the value, if it does not fit in 64 bits
 }
 
 func (il *IntegerLiteral) node()           {}
 func (il *IntegerLiteral) expressionNode() {}
 func (il *IntegerLiteral) String() string  { return "<<<IntegerLiteral>>>" }
 func (il *IntegerLiteral) Code() string {
+	if il.Big != nil {
+		return il.Big.String()
+	}
 	var out strings.Builder
 	out.WriteString(fmt.Sprintf("%d", il.Value))
 	return out.String()
@@ -264,6 +369,7 @@ var (
 
 // FloatLiteral represents a float in the AST
 type FloatLiteral struct {
+	Span
 	Value float64
 }
 
@@ -277,8 +383,56 @@ var (
 	_ Expression = &FloatLiteral{}
 )
 
+// RationalLiteral represents a rational number in the AST, e.g. `3r` or
+// `1.5r`
+type RationalLiteral struct {
+	Span
+	Value *big.Rat
+}
+
+func (rl *RationalLiteral) node()           {}
+func (rl *RationalLiteral) expressionNode() {}
+func (rl *RationalLiteral) String() string  { return "<<<RationalLiteral>>>" }
+func (rl *RationalLiteral) Code() string {
+	if rl.Value.IsInt() {
+		return rl.Value.Num().String() + "r"
+	}
+	// the literals of rationals are decimal fractions, which can be written
+	// exactly
+	for prec := 1; prec <= 64; prec++ {
+		decimal := rl.Value.FloatString(prec)
+		if r, ok := new(big.Rat).SetString(decimal); ok && r.Cmp(rl.Value) == 0 {
+			return decimal + "r"
+		}
+	}
+	return fmt.Sprintf("(%s/%sr)", rl.Value.Num(), rl.Value.Denom())
+}
+
+var (
+	_ Node       = &RationalLiteral{}
+	_ Expression = &RationalLiteral{}
+)
+
+// ImaginaryLiteral represents an imaginary number in the AST, e.g. `2i`,
+// `1.5i` or `3ri`
+type ImaginaryLiteral struct {
+	Span
+	Value Expression // the IntegerLiteral, FloatLiteral or RationalLiteral multiplied by i
+}
+
+func (il *ImaginaryLiteral) node()           {}
+func (il *ImaginaryLiteral) expressionNode() {}
+func (il *ImaginaryLiteral) String() string  { return "<<<ImaginaryLiteral>>>" }
+func (il *ImaginaryLiteral) Code() string    { return il.Value.Code() + "i" }
+
+var (
+	_ Node       = &ImaginaryLiteral{}
+	_ Expression = &ImaginaryLiteral{}
+)
+
 // StringLiteral represents a double quoted string in the AST
 type StringLiteral struct {
+	Span
 	Value string
 }
 
@@ -288,18 +442,119 @@ func (sl *StringLiteral) String() string  { return "<<<StringLiteral>>>" }
 func (sl *StringLiteral) Code() string {
 	var out strings.Builder
 	out.WriteString("\"")
-	out.WriteString(sl.Value)
+	out.WriteString(escapeString(sl.Value))
 	out.WriteString("\"")
 	return out.String()
 }
 
+// stringEscapes maps the characters escaped in the code of a double quoted
+// string to their escape sequences
+var stringEscapes = map[rune]string{
+	'\\': `\\`,
+	'"':  `\"`,
+	'\n': `\n`,
+	'\t': `\t`,
+	'\r': `\r`,
+	'\v': `\v`,
+	'\f': `\f`,
+	'\a': `\a`,
+	'\b': `\b`,
+	0x1b: `\e`,
+}
+
+// escapeString returns value with the escape sequences required to write it
+// within double quotes, the inverse of the lexer decoding them
+func escapeString(value string) string {
+	var out strings.Builder
+	for i, r := range value {
+		switch escaped, ok := stringEscapes[r]; {
+		case ok:
+			out.WriteString(escaped)
+		case r == '#' && strings.HasPrefix(value[i+1:], "{"):
+			out.WriteString(`\#`)
+		case r == utf8.RuneError && !strings.HasPrefix(value[i:], string(utf8.RuneError)):
+			fmt.Fprintf(&out, `\x%02X`, value[i])
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&out, `\x%02X`, r)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
 var (
 	_ Node       = &StringLiteral{}
 	_ Expression = &StringLiteral{}
 )
 
+// InterpolatedString represents a double quoted string with embedded code,
+// e.g. `"a #{b} c"`. Literals holds the text around the embedded code, hence
+// there is always one more literal than there are embedded blocks.
+type InterpolatedString struct {
+	Span
+	Literals []*StringLiteral
+	Embedded []*BlockStatement
+	Symbol   bool // an interpolated symbol, e.g. `:"a#{b}"`
+}
+
+func (is *InterpolatedString) node()           {}
+func (is *InterpolatedString) expressionNode() {}
+func (is *InterpolatedString) String() string  { return "<<<InterpolatedString>>>" }
+func (is *InterpolatedString) Code() string {
+	var out strings.Builder
+	if is.Symbol {
+		out.WriteString(":")
+	}
+	out.WriteString("\"")
+	out.WriteString(is.content(escapeString))
+	out.WriteString("\"")
+	return out.String()
+}
+
+// content returns the code of the string without its quotes, writing its
+// literals with escape
+func (is *InterpolatedString) content(escape func(string) string) string {
+	var out strings.Builder
+	for i, literal := range is.Literals {
+		out.WriteString(escape(literal.Value))
+		if i < len(is.Embedded) {
+			out.WriteString("#{")
+			out.WriteString(is.Embedded[i].Code())
+			out.WriteString("}")
+		}
+	}
+	return out.String()
+}
+
+var (
+	_ Node       = &InterpolatedString{}
+	_ Expression = &InterpolatedString{}
+)
+
+// RegexLiteral represents a regular expression literal, e.g. `/a+#{b}/i`
+type RegexLiteral struct {
+	Span
+	Pattern *InterpolatedString // the source, possibly embedding code
+	Options string              // the option letters, e.g. `i` or `mx`
+}
+
+func (rl *RegexLiteral) node()           {}
+func (rl *RegexLiteral) expressionNode() {}
+func (rl *RegexLiteral) String() string  { return "<<<RegexLiteral>>>" }
+func (rl *RegexLiteral) Code() string {
+	// the source of a regex keeps its escape sequences
+	return "/" + rl.Pattern.content(func(s string) string { return s }) + "/" + rl.Options
+}
+
+var (
+	_ Node       = &RegexLiteral{}
+	_ Expression = &RegexLiteral{}
+)
+
 // Comment represents a double quoted string in the AST
 type Comment struct {
+	Span
 	Value string
 }
 
@@ -320,6 +575,7 @@ var (
 
 // SymbolLiteral represents a symbol within the AST
 type SymbolLiteral struct {
+	Span
 	Value string
 }
 
@@ -335,6 +591,7 @@ var (
 
 // ConditionalExpression represents an if expression within the AST
 type ConditionalExpression struct {
+	Span
 	Unless      bool // true = unless, false = if
 	Condition   Expression
 	Consequence *BlockStatement
@@ -368,9 +625,19 @@ var (
 	_ Expression = &ConditionalExpression{}
 )
 
-// A LoopExpression represents an infinite loop (with breaks)
+// A LoopExpression represents a loop. Without Condition and Iterable it is
+// an infinite `loop { }` which only ends with a break. With a Condition it
+// is a while loop, or an until loop if Until is set. With an Iterable it is
+// a for loop binding each element to Variable.
 type LoopExpression struct {
-	Block *BlockStatement
+	Span
+	Condition Expression
+	Until     bool       // true = until, false = while
+	Modifier  bool       // the body precedes the condition, e.g. `i += 1 while i < 10`
+	DoWhile   bool       // the body runs once before the condition is checked, i.e. `begin ... end while cond`
+	Variable  Expression // an Identifier or an ExpressionList
+	Iterable  Expression
+	Block     *BlockStatement
 }
 
 func (ce *LoopExpression) node()           {}
@@ -379,9 +646,36 @@ func (ce *LoopExpression) String() string  { return "<<<LoopExpression>>>" }
 
 func (ce *LoopExpression) Code() string {
 	var out strings.Builder
-	out.WriteString("loop {")
-	out.WriteString(ce.Block.Code())
-	out.WriteString("}")
+	switch {
+	case ce.Iterable != nil:
+		out.WriteString("for ")
+		out.WriteString(ce.Variable.Code())
+		out.WriteString(" in ")
+		out.WriteString(ce.Iterable.Code())
+		out.WriteString("; ")
+		out.WriteString(ce.Block.Code())
+		out.WriteString("; end")
+	case ce.Condition != nil:
+		keyword := "while"
+		if ce.Until {
+			keyword = "until"
+		}
+		if ce.Modifier {
+			out.WriteString(ce.Block.Code())
+			out.WriteString(" " + keyword + " ")
+			out.WriteString(ce.Condition.Code())
+			break
+		}
+		out.WriteString(keyword + " ")
+		out.WriteString(ce.Condition.Code())
+		out.WriteString("; ")
+		out.WriteString(ce.Block.Code())
+		out.WriteString("; end")
+	default:
+		out.WriteString("loop {")
+		out.WriteString(ce.Block.Code())
+		out.WriteString("}")
+	}
 	return out.String()
 }
 
@@ -390,12 +684,720 @@ var (
 	_ Expression = &LoopExpression{}
 )
 
+// A BeginExpression represents a begin/end block with its rescue, else and
+// ensure clauses. Method bodies with rescue clauses and rescue modifiers are
+// represented by a BeginExpression as well.
+type BeginExpression struct {
+	Span
+	Body    *BlockStatement
+	Rescues []*RescueClause
+	Else    *BlockStatement
+	Ensure  *BlockStatement
+}
+
+func (be *BeginExpression) node()           {}
+func (be *BeginExpression) expressionNode() {}
+func (be *BeginExpression) String() string  { return "<<<BeginExpression>>>" }
+
+func (be *BeginExpression) Code() string {
+	var out strings.Builder
+	out.WriteString("begin; ")
+	out.WriteString(be.Body.Code())
+	for _, rescue := range be.Rescues {
+		out.WriteString("; ")
+		out.WriteString(rescue.Code())
+	}
+	if be.Else != nil {
+		out.WriteString("; else; ")
+		out.WriteString(be.Else.Code())
+	}
+	if be.Ensure != nil {
+		out.WriteString("; ensure; ")
+		out.WriteString(be.Ensure.Code())
+	}
+	out.WriteString("; end")
+	return out.String()
+}
+
+var (
+	_ Node       = &BeginExpression{}
+	_ Expression = &BeginExpression{}
+)
+
+// A RescueClause represents a single rescue clause of a BeginExpression. An
+// empty list of ExceptionClasses rescues StandardError.
+type RescueClause struct {
+	Span
+	ExceptionClasses []Expression
+	Exception        *Identifier // the variable the exception is assigned to, if any
+	Body             *BlockStatement
+}
+
+func (rc *RescueClause) node()          {}
+func (rc *RescueClause) String() string { return "<<<RescueClause>>>" }
+
+func (rc *RescueClause) Code() string {
+	var out strings.Builder
+	out.WriteString("rescue")
+	classes := make([]string, len(rc.ExceptionClasses))
+	for i, class := range rc.ExceptionClasses {
+		classes[i] = class.Code()
+	}
+	if len(classes) > 0 {
+		out.WriteString(" ")
+		out.WriteString(strings.Join(classes, ", "))
+	}
+	if rc.Exception != nil {
+		out.WriteString(" => ")
+		out.WriteString(rc.Exception.Code())
+	}
+	out.WriteString("; ")
+	out.WriteString(rc.Body.Code())
+	return out.String()
+}
+
+var (
+	_ Node = &RescueClause{}
+)
+
+// A CaseExpression represents a case expression. Each when clause is matched
+// by sending === to its values with the Subject as argument. Without a
+// Subject the first clause with a truthy value is taken.
+type CaseExpression struct {
+	Span
+	Subject Expression // nil for the subject-less form
+	Whens   []*WhenClause
+	Else    *BlockStatement
+}
+
+func (ce *CaseExpression) node()           {}
+func (ce *CaseExpression) expressionNode() {}
+func (ce *CaseExpression) String() string  { return "<<<CaseExpression>>>" }
+
+func (ce *CaseExpression) Code() string {
+	var out strings.Builder
+	out.WriteString("case")
+	if ce.Subject != nil {
+		out.WriteString(" ")
+		out.WriteString(ce.Subject.Code())
+	}
+	for _, when := range ce.Whens {
+		out.WriteString("; ")
+		out.WriteString(when.Code())
+	}
+	if ce.Else != nil {
+		out.WriteString("; else; ")
+		out.WriteString(ce.Else.Code())
+	}
+	out.WriteString("; end")
+	return out.String()
+}
+
+var (
+	_ Node       = &CaseExpression{}
+	_ Expression = &CaseExpression{}
+)
+
+// A WhenClause represents a single when clause of a CaseExpression
+type WhenClause struct {
+	Span
+	Values []Expression
+	Body   *BlockStatement
+}
+
+func (wc *WhenClause) node()          {}
+func (wc *WhenClause) String() string { return "<<<WhenClause>>>" }
+
+func (wc *WhenClause) Code() string {
+	var out strings.Builder
+	out.WriteString("when ")
+	values := make([]string, len(wc.Values))
+	for i, value := range wc.Values {
+		values[i] = value.Code()
+	}
+	out.WriteString(strings.Join(values, ", "))
+	out.WriteString("; ")
+	out.WriteString(wc.Body.Code())
+	return out.String()
+}
+
+var (
+	_ Node = &WhenClause{}
+)
+
+// A CaseMatchExpression represents a case expression with in clauses. The
+// Subject is matched against the pattern of each clause in turn.
+type CaseMatchExpression struct {
+	Span
+	Subject Expression
+	Ins     []*InClause
+	Else    *BlockStatement
+}
+
+func (ce *CaseMatchExpression) node()           {}
+func (ce *CaseMatchExpression) expressionNode() {}
+func (ce *CaseMatchExpression) String() string  { return "<<<CaseMatchExpression>>>" }
+
+func (ce *CaseMatchExpression) Code() string {
+	var out strings.Builder
+	out.WriteString("case ")
+	out.WriteString(ce.Subject.Code())
+	for _, in := range ce.Ins {
+		out.WriteString("; ")
+		out.WriteString(in.Code())
+	}
+	if ce.Else != nil {
+		out.WriteString("; else; ")
+		out.WriteString(ce.Else.Code())
+	}
+	out.WriteString("; end")
+	return out.String()
+}
+
+var (
+	_ Node       = &CaseMatchExpression{}
+	_ Expression = &CaseMatchExpression{}
+)
+
+// An InClause represents a single in clause of a CaseMatchExpression. The
+// clause is only taken if its Guard, if any, is truthy (falsy for Unless).
+type InClause struct {
+	Span
+	Pattern Pattern
+	Guard   Expression
+	Unless  bool
+	Body    *BlockStatement
+}
+
+func (ic *InClause) node()          {}
+func (ic *InClause) String() string { return "<<<InClause>>>" }
+
+func (ic *InClause) Code() string {
+	var out strings.Builder
+	out.WriteString("in ")
+	out.WriteString(ic.Pattern.Code())
+	if ic.Guard != nil {
+		if ic.Unless {
+			out.WriteString(" unless ")
+		} else {
+			out.WriteString(" if ")
+		}
+		out.WriteString(ic.Guard.Code())
+	}
+	out.WriteString("; ")
+	out.WriteString(ic.Body.Code())
+	return out.String()
+}
+
+var (
+	_ Node = &InClause{}
+)
+
+// A MatchExpression represents one of the standalone pattern matching forms.
+// `value => pattern` is Required and raises NoMatchingPatternError if the
+// value does not match, `value in pattern` returns whether it does.
+type MatchExpression struct {
+	Span
+	Value    Expression
+	Pattern  Pattern
+	Required bool
+}
+
+func (me *MatchExpression) node()           {}
+func (me *MatchExpression) expressionNode() {}
+func (me *MatchExpression) String() string  { return "<<<MatchExpression>>>" }
+
+func (me *MatchExpression) Code() string {
+	var out strings.Builder
+	out.WriteString(maybeParenthesize(me.Value.Code(), needsParens(me.Value)))
+	if me.Required {
+		out.WriteString(" => ")
+	} else {
+		out.WriteString(" in ")
+	}
+	out.WriteString(me.Pattern.Code())
+	return out.String()
+}
+
+var (
+	_ Node       = &MatchExpression{}
+	_ Expression = &MatchExpression{}
+)
+
+// A Pattern is a node which can be matched against a value
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// A ValuePattern matches if Value === the matched value is truthy
+type ValuePattern struct {
+	Span
+	Value Expression
+}
+
+func (vp *ValuePattern) node()          {}
+func (vp *ValuePattern) patternNode()   {}
+func (vp *ValuePattern) String() string { return "<<<ValuePattern>>>" }
+func (vp *ValuePattern) Code() string   { return vp.Value.Code() }
+
+var (
+	_ Node    = &ValuePattern{}
+	_ Pattern = &ValuePattern{}
+)
+
+// A VariablePattern matches any value and binds it to the local variable Name
+type VariablePattern struct {
+	Span
+	Name *Identifier
+}
+
+func (vp *VariablePattern) node()          {}
+func (vp *VariablePattern) patternNode()   {}
+func (vp *VariablePattern) String() string { return "<<<VariablePattern>>>" }
+func (vp *VariablePattern) Code() string   { return vp.Name.Code() }
+
+var (
+	_ Node    = &VariablePattern{}
+	_ Pattern = &VariablePattern{}
+)
+
+// A PinPattern matches if the value of an existing variable or expression,
+// e.g. `^x` or `^(x + 1)`, is === to the matched value
+type PinPattern struct {
+	Span
+	Value Expression
+}
+
+func (pp *PinPattern) node()          {}
+func (pp *PinPattern) patternNode()   {}
+func (pp *PinPattern) String() string { return "<<<PinPattern>>>" }
+
+func (pp *PinPattern) Code() string {
+	if _, ok := pp.Value.(*Identifier); ok {
+		return "^" + pp.Value.Code()
+	}
+	return "^(" + pp.Value.Code() + ")"
+}
+
+var (
+	_ Node    = &PinPattern{}
+	_ Pattern = &PinPattern{}
+)
+
+// A SplatPattern collects the remaining elements of an array or hash pattern
+// into Name. An anonymous splat has a nil Name.
+type SplatPattern struct {
+	Span
+	Name *Identifier
+}
+
+func (sp *SplatPattern) node()          {}
+func (sp *SplatPattern) patternNode()   {}
+func (sp *SplatPattern) String() string { return "<<<SplatPattern>>>" }
+
+func (sp *SplatPattern) Code() string {
+	if sp.Name == nil {
+		return "*"
+	}
+	return "*" + sp.Name.Code()
+}
+
+var (
+	_ Node    = &SplatPattern{}
+	_ Pattern = &SplatPattern{}
+)
+
+// An ArrayPattern matches arrays whose elements match Pre, followed by any
+// number of elements collected by Rest and elements matching Post. Post is
+// always empty without a Rest. A Constant, as in `Point[x, y]`, must be ===
+// to the matched value as well.
+type ArrayPattern struct {
+	Span
+	Constant Expression
+	Pre      []Pattern
+	Rest     *SplatPattern
+	Post     []Pattern
+}
+
+func (ap *ArrayPattern) node()          {}
+func (ap *ArrayPattern) patternNode()   {}
+func (ap *ArrayPattern) String() string { return "<<<ArrayPattern>>>" }
+
+func (ap *ArrayPattern) Code() string {
+	elements := patternCodes(ap.Pre)
+	if ap.Rest != nil {
+		elements = append(elements, ap.Rest.Code())
+	}
+	elements = append(elements, patternCodes(ap.Post)...)
+	return wrapPatternElements(ap.Constant, "[", strings.Join(elements, ", "), "]")
+}
+
+var (
+	_ Node    = &ArrayPattern{}
+	_ Pattern = &ArrayPattern{}
+)
+
+// A FindPattern, e.g. `[*, 1, x, *]`, matches arrays containing a run of
+// elements matching Middle anywhere
+type FindPattern struct {
+	Span
+	Constant Expression
+	Pre      *SplatPattern
+	Middle   []Pattern
+	Post     *SplatPattern
+}
+
+func (fp *FindPattern) node()          {}
+func (fp *FindPattern) patternNode()   {}
+func (fp *FindPattern) String() string { return "<<<FindPattern>>>" }
+
+func (fp *FindPattern) Code() string {
+	elements := append([]string{fp.Pre.Code()}, patternCodes(fp.Middle)...)
+	elements = append(elements, fp.Post.Code())
+	return wrapPatternElements(fp.Constant, "[", strings.Join(elements, ", "), "]")
+}
+
+var (
+	_ Node    = &FindPattern{}
+	_ Pattern = &FindPattern{}
+)
+
+// A HashPattern matches hashes holding all of the symbol keys of Pairs. Rest
+// collects the remaining pairs, while NoRest (`**nil`) rejects hashes with
+// any other key.
+type HashPattern struct {
+	Span
+	Constant Expression
+	Pairs    []*HashPatternPair
+	Rest     *SplatPattern
+	NoRest   bool
+}
+
+func (hp *HashPattern) node()          {}
+func (hp *HashPattern) patternNode()   {}
+func (hp *HashPattern) String() string { return "<<<HashPattern>>>" }
+
+func (hp *HashPattern) Code() string {
+	elements := make([]string, 0, len(hp.Pairs)+1)
+	for _, pair := range hp.Pairs {
+		elements = append(elements, pair.Code())
+	}
+	if hp.Rest != nil {
+		elements = append(elements, "*"+hp.Rest.Code())
+	}
+	if hp.NoRest {
+		elements = append(elements, "**nil")
+	}
+	return wrapPatternElements(hp.Constant, "{", strings.Join(elements, ", "), "}")
+}
+
+var (
+	_ Node    = &HashPattern{}
+	_ Pattern = &HashPattern{}
+)
+
+// A HashPatternPair matches the value of the symbol Key against Value. A nil
+// Value binds the value to a local variable named after the key.
+type HashPatternPair struct {
+	Span
+	Key   *Identifier
+	Value Pattern
+}
+
+func (hp *HashPatternPair) node()          {}
+func (hp *HashPatternPair) String() string { return "<<<HashPatternPair>>>" }
+
+func (hp *HashPatternPair) Code() string {
+	if hp.Value == nil {
+		return hp.Key.Code() + ":"
+	}
+	return hp.Key.Code() + ": " + hp.Value.Code()
+}
+
+var (
+	_ Node = &HashPatternPair{}
+)
+
+// An AlternativePattern, e.g. `Integer | Float`, matches if any of its
+// Alternatives does
+type AlternativePattern struct {
+	Span
+	Alternatives []Pattern
+}
+
+func (ap *AlternativePattern) node()          {}
+func (ap *AlternativePattern) patternNode()   {}
+func (ap *AlternativePattern) String() string { return "<<<AlternativePattern>>>" }
+func (ap *AlternativePattern) Code() string {
+	return strings.Join(patternCodes(ap.Alternatives), " | ")
+}
+
+var (
+	_ Node    = &AlternativePattern{}
+	_ Pattern = &AlternativePattern{}
+)
+
+// A CapturePattern, e.g. `Integer => n`, binds the matched value to Name if
+// Pattern matches
+type CapturePattern struct {
+	Span
+	Pattern Pattern
+	Name    *Identifier
+}
+
+func (cp *CapturePattern) node()          {}
+func (cp *CapturePattern) patternNode()   {}
+func (cp *CapturePattern) String() string { return "<<<CapturePattern>>>" }
+func (cp *CapturePattern) Code() string   { return cp.Pattern.Code() + " => " + cp.Name.Code() }
+
+var (
+	_ Node    = &CapturePattern{}
+	_ Pattern = &CapturePattern{}
+)
+
+func patternCodes(patterns []Pattern) []string {
+	codes := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		codes[i] = pattern.Code()
+	}
+	return codes
+}
+
+// wrapPatternElements encloses the elements of an array or hash pattern in
+// brackets, or in parentheses following the constant if there is one
+func wrapPatternElements(constant Expression, open, elements, close string) string {
+	if constant != nil {
+		return constant.Code() + "(" + elements + ")"
+	}
+	return open + elements + close
+}
+
+// A RetryStatement represents a retry statement within a rescue clause
+type RetryStatement struct {
+	Span
+}
+
+func (rs *RetryStatement) node()          {}
+func (rs *RetryStatement) statementNode() {}
+func (rs *RetryStatement) String() string { return "<<<RetryStatement>>>" }
+func (rs *RetryStatement) Code() string   { return "retry" }
+
+var (
+	_ Node      = &RetryStatement{}
+	_ Statement = &RetryStatement{}
+)
+
+// A RedoStatement represents a redo statement, which restarts the current
+// loop iteration or block invocation. The optional Condition works as for a
+// BreakStatement.
+type RedoStatement struct {
+	Span
+	Condition Expression
+	Unless    bool
+}
+
+func (rs *RedoStatement) node()          {}
+func (rs *RedoStatement) statementNode() {}
+func (rs *RedoStatement) String() string { return "<<<RedoStatement>>>" }
+func (rs *RedoStatement) Code() string {
+	return jumpCode("redo", nil, rs.Condition, rs.Unless)
+}
+
+var (
+	_ Node      = &RedoStatement{}
+	_ Statement = &RedoStatement{}
+)
+
+// A ClassExpression represents a class definition. SuperClass is nil if the
+// class does not name its parent.
+type ClassExpression struct {
+	Span
+	Name       *Identifier
+	SuperClass Expression
+	Body       *BlockStatement
+}
+
+func (ce *ClassExpression) node()           {}
+func (ce *ClassExpression) expressionNode() {}
+func (ce *ClassExpression) String() string  { return "<<<ClassExpression>>>" }
+
+func (ce *ClassExpression) Code() string {
+	var out strings.Builder
+	out.WriteString("class ")
+	out.WriteString(ce.Name.Code())
+	if ce.SuperClass != nil {
+		out.WriteString(" < ")
+		out.WriteString(ce.SuperClass.Code())
+	}
+	out.WriteString("\n")
+	body_string := ce.Body.Code()
+	for _, line := range strings.Split(body_string, "\n") {
+		out.WriteString("    ")
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	out.WriteString("end")
+	return out.String()
+}
+
+var (
+	_ Node       = &ClassExpression{}
+	_ Expression = &ClassExpression{}
+)
+
+// A ModuleExpression represents a module definition
+type ModuleExpression struct {
+	Span
+	Name *Identifier
+	Body *BlockStatement
+}
+
+func (me *ModuleExpression) node()           {}
+func (me *ModuleExpression) expressionNode() {}
+func (me *ModuleExpression) String() string  { return "<<<ModuleExpression>>>" }
+
+func (me *ModuleExpression) Code() string {
+	var out strings.Builder
+	out.WriteString("module ")
+	out.WriteString(me.Name.Code())
+	out.WriteString("\n")
+	body_string := me.Body.Code()
+	for _, line := range strings.Split(body_string, "\n") {
+		out.WriteString("    ")
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	out.WriteString("end")
+	return out.String()
+}
+
+var (
+	_ Node       = &ModuleExpression{}
+	_ Expression = &ModuleExpression{}
+)
+
+// A ScopedConstant represents a constant looked up within a module through the
+// scope operator, e.g. Foo::Bar. Scope is nil for constants looked up at the
+// top level, as in ::Bar.
+type ScopedConstant struct {
+	Span
+	Scope Expression
+	Name  *Identifier
+}
+
+func (sc *ScopedConstant) node()           {}
+func (sc *ScopedConstant) expressionNode() {}
+func (sc *ScopedConstant) String() string  { return "<<<ScopedConstant>>>" }
+
+func (sc *ScopedConstant) Code() string {
+	if sc.Scope == nil {
+		return "::" + sc.Name.Code()
+	}
+	return sc.Scope.Code() + "::" + sc.Name.Code()
+}
+
+var (
+	_ Node       = &ScopedConstant{}
+	_ Expression = &ScopedConstant{}
+)
+
+// Self represents the self keyword
+type Self struct {
+	Span
+}
+
+func (s *Self) node()           {}
+func (s *Self) expressionNode() {}
+func (s *Self) String() string  { return "<<<Self>>>" }
+func (s *Self) Code() string    { return "self" }
+
+var (
+	_ Node       = &Self{}
+	_ Expression = &Self{}
+)
+
+// A Super represents a call of the method overridden by the current method.
+// A bare super passes on the arguments of the current method, in which case
+// ExplicitArguments is false.
+type Super struct {
+	Span
+	Arguments         []Expression
+	ExplicitArguments bool
+}
+
+func (s *Super) node()           {}
+func (s *Super) expressionNode() {}
+func (s *Super) String() string  { return "<<<Super>>>" }
+
+func (s *Super) Code() string {
+	if !s.ExplicitArguments {
+		return "super"
+	}
+	args := make([]string, len(s.Arguments))
+	for i, a := range s.Arguments {
+		args[i] = a.Code()
+	}
+	return "super(" + strings.Join(args, ", ") + ")"
+}
+
+var (
+	_ Node       = &Super{}
+	_ Expression = &Super{}
+)
+
+// A Yield represents a call of the block passed to the current method
+type Yield struct {
+	Span
+	Arguments []Expression
+}
+
+func (y *Yield) node()           {}
+func (y *Yield) expressionNode() {}
+func (y *Yield) String() string  { return "<<<Yield>>>" }
+
+func (y *Yield) Code() string {
+	if len(y.Arguments) == 0 {
+		return "yield"
+	}
+	args := make([]string, len(y.Arguments))
+	for i, a := range y.Arguments {
+		args[i] = a.Code()
+	}
+	return "yield(" + strings.Join(args, ", ") + ")"
+}
+
+var (
+	_ Node       = &Yield{}
+	_ Expression = &Yield{}
+)
+
 // ExpressionList represents a list of expressions within the AST divided by commas
 type ExpressionList []Expression
 
 func (el ExpressionList) node()           {}
 func (el ExpressionList) expressionNode() {}
 
+// Pos returns the start of the first element of the list
+func (el ExpressionList) Pos() gotoken.Pos {
+	for _, e := range el {
+		if e != nil {
+			return e.Pos()
+		}
+	}
+	return gotoken.NoPos
+}
+
+// End returns the end of the last element of the list
+func (el ExpressionList) End() gotoken.Pos {
+	for i := len(el) - 1; i >= 0; i-- {
+		if el[i] != nil {
+			return el[i].End()
+		}
+	}
+	return gotoken.NoPos
+}
+
 func (el ExpressionList) String() string {
 	var out strings.Builder
 	elements := []string{}
@@ -425,6 +1427,7 @@ var (
 
 // ArrayLiteral represents an Array literal within the AST
 type ArrayLiteral struct {
+	Span
 	Elements []Expression
 }
 
@@ -450,7 +1453,10 @@ var (
 
 // HashLiteral represents an Hash literal within the AST
 type HashLiteral struct {
-	Map map[Expression]Expression
+	Span
+	Map       map[Expression]Expression
+	Splats    []Expression // hashes merged into the literal, e.g. `**opts`
+	Braceless bool         // the trailing hash of call arguments, e.g. `foo(x, opt: 1)`
 }
 
 func (hl *HashLiteral) node()           {}
@@ -459,9 +1465,15 @@ func (hl *HashLiteral) String() string  { return "<<<HashLiteral>>>" }
 func (hl *HashLiteral) Code() string {
 	var out strings.Builder
 	elements := []string{}
+	for _, splat := range hl.Splats {
+		elements = append(elements, "**"+splat.Code())
+	}
 	for key, val := range hl.Map {
 		elements = append(elements, fmt.Sprintf("%q => %q", key.Code(), val.Code()))
 	}
+	if hl.Braceless {
+		return strings.Join(elements, ", ")
+	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(elements, ", "))
 	out.WriteString("}")
@@ -475,6 +1487,7 @@ var (
 
 // RangeLiteral represents a range literal within the AST
 type RangeLiteral struct {
+	Span
 	Left      Expression
 	Right     Expression
 	Inclusive bool
@@ -505,6 +1518,8 @@ var (
 
 // A FunctionLiteral represents a function definition in the AST
 type FunctionLiteral struct {
+	Span
+	Receiver   Expression // set for singleton methods, e.g. self in `def self.foo`
 	Name       string
 	Parameters []*FunctionParameter
 	Body       *BlockStatement
@@ -556,6 +1571,10 @@ func (fl *FunctionLiteral) Code() string {
 		out.WriteString("}")
 	} else {
 		out.WriteString("def ")
+		if fl.Receiver != nil {
+			out.WriteString(fl.Receiver.Code())
+			out.WriteString(".")
+		}
 		out.WriteString(fl.Name)
 		out.WriteString("(")
 		args := []string{}
@@ -583,9 +1602,14 @@ var (
 
 // A FunctionParameter represents a parameter in a function literal
 type FunctionParameter struct {
-	Name    string
-	Default Expression
-	Splat   bool
+	Span
+	Name        string
+	Default     Expression
+	Splat       bool
+	Block       bool                 // an explicit block parameter, e.g. `&blk`
+	Keyword     bool                 // a keyword parameter, e.g. `opt: 1` or the required `opt:`
+	DoubleSplat bool                 // collects the remaining keywords, e.g. `**opts`
+	Destructure []*FunctionParameter // nested parameters of a destructuring parameter, e.g. `(k, v)`
 }
 
 func (f *FunctionParameter) node()           {}
@@ -599,7 +1623,28 @@ func (f *FunctionParameter) Code() string {
 	if f.Splat {
 		out.WriteString("*")
 	}
+	if f.DoubleSplat {
+		out.WriteString("**")
+	}
+	if f.Block {
+		out.WriteString("&")
+	}
 	out.WriteString(f.Name)
+	if len(f.Destructure) != 0 {
+		nested := make([]string, len(f.Destructure))
+		for i, param := range f.Destructure {
+			nested[i] = param.Code()
+		}
+		out.WriteString("(" + strings.Join(nested, ", ") + ")")
+	}
+	if f.Keyword {
+		out.WriteString(":")
+		if f.Default != nil {
+			out.WriteString(" ")
+			out.WriteString(f.Default.Code())
+		}
+		return out.String()
+	}
 	if f.Default != nil {
 		out.WriteString(" = ")
 		out.WriteString(f.Default.Code())
@@ -614,6 +1659,7 @@ var (
 
 // A Splat represents a splat operator in the AST
 type Splat struct {
+	Span
 	Value Expression
 }
 
@@ -632,8 +1678,31 @@ var (
 	_ Expression = &Splat{}
 )
 
+// A BlockPass represents an argument passed as the block of a call, e.g.
+// `&blk` or `&:to_s`
+type BlockPass struct {
+	Span
+	Value Expression
+}
+
+func (b *BlockPass) node()           {}
+func (b *BlockPass) expressionNode() {}
+func (b *BlockPass) String() string  { return "<<<BlockPass>>>" }
+func (b *BlockPass) Code() string {
+	var out strings.Builder
+	out.WriteString("&")
+	out.WriteString(b.Value.Code())
+	return out.String()
+}
+
+var (
+	_ Node       = &BlockPass{}
+	_ Expression = &BlockPass{}
+)
+
 // An IndexExpression represents an array or hash access in the AST
 type IndexExpression struct {
+	Span
 	Left  Expression
 	Index Expression
 }
@@ -663,6 +1732,7 @@ var (
 
 // A ContextCallExpression represents a method call on a given Context
 type ContextCallExpression struct {
+	Span
 	Context   Expression   // The left-hand side expression
 	Function  string       // The function to call
 	Arguments []Expression // Normal arguments
@@ -705,6 +1775,7 @@ var (
 
 // PrefixExpression represents a prefix operator
 type PrefixExpression struct {
+	Span
 	Operator string
 	Right    Expression
 }
@@ -726,6 +1797,7 @@ var (
 
 // An InfixExpression represents an infix operator in the AST
 type InfixExpression struct {
+	Span
 	Left     Expression
 	Operator infix.Infix
 	Right    Expression
@@ -764,6 +1836,8 @@ func needsParens(e Expression) bool {
 	case *IntegerLiteral:
 	case *SymbolLiteral:
 	case *FloatLiteral:
+	case *Self:
+	case *ScopedConstant:
 	case *ContextCallExpression:
 		if e.Context == nil {
 			// we're calling a function without a context, aka just calling a function
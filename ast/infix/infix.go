@@ -14,7 +14,9 @@ const (
 	MODULO           = Infix(token.MODULO)
 	AND              = Infix(token.AND)
 	PIPE             = Infix(token.PIPE)
+	CARET            = Infix(token.CARET)
 	EQ               = Infix(token.EQ)
+	CASEEQ           = Infix(token.CASEEQ)
 	NOTEQ            = Infix(token.NOTEQ)
 	LT               = Infix(token.LT)
 	GT               = Infix(token.GT)
@@ -24,6 +26,9 @@ const (
 	LOGICALAND       = Infix(token.LOGICALAND)
 	SPACESHIP        = Infix(token.SPACESHIP)
 	LSHIFT           = Infix(token.LSHIFT)
+	RSHIFT           = Infix(token.RSHIFT)
+	MATCH            = Infix(token.MATCH)
+	NOTMATCH         = Infix(token.NOTMATCH)
 )
 
 // var infix_strings = map[Infix]string{
@@ -37,6 +42,7 @@ const (
 // 	AND:        "AND",
 // 	PIPE:       "PIPE",
 // 	EQ:         "EQ",
+// 	CASEEQ:     "CASEEQ",
 // 	NOTEQ:      "NOTEQ",
 // 	LT:         "LT",
 // 	GT:         "GT",
@@ -56,9 +62,11 @@ var infix_repr = map[Infix]string{
 	ASTERISK:   "*",
 	POW:        "**",
 	MODULO:     "%",
-	AND:        "&&",
-	PIPE:       "||",
+	AND:        "&",
+	PIPE:       "|",
+	CARET:      "^",
 	EQ:         "==",
+	CASEEQ:     "===",
 	NOTEQ:      "!=",
 	LT:         "<",
 	GT:         ">",
@@ -68,6 +76,9 @@ var infix_repr = map[Infix]string{
 	LOGICALAND: "&&",
 	SPACESHIP:  "<=>",
 	LSHIFT:     "<<",
+	RSHIFT:     ">>",
+	MATCH:      "=~",
+	NOTMATCH:   "!~",
 }
 
 func (i Infix) String() string {
@@ -95,8 +106,12 @@ func InfixFromTokenType(t token.Type) Infix {
 		return AND
 	case token.PIPE:
 		return PIPE
+	case token.CARET:
+		return CARET
 	case token.EQ:
 		return EQ
+	case token.CASEEQ:
+		return CASEEQ
 	case token.NOTEQ:
 		return NOTEQ
 	case token.LT:
@@ -115,6 +130,12 @@ func InfixFromTokenType(t token.Type) Infix {
 		return SPACESHIP
 	case token.LSHIFT:
 		return LSHIFT
+	case token.RSHIFT:
+		return RSHIFT
+	case token.MATCH:
+		return MATCH
+	case token.NOTMATCH:
+		return NOTMATCH
 	default:
 		return ILLEGAL
 	}
@@ -142,6 +163,22 @@ func InfixFromAssignmentOperatorType(t token.Type) Infix {
 		return SLASH
 	case token.MODASSIGN:
 		return MODULO
+	case token.POWASSIGN:
+		return POW
+	case token.ANDASSIGN:
+		return AND
+	case token.ORASSIGN:
+		return PIPE
+	case token.XORASSIGN:
+		return CARET
+	case token.LSHIFTASSIGN:
+		return LSHIFT
+	case token.RSHIFTASSIGN:
+		return RSHIFT
+	case token.LOGICALANDASSIGN:
+		return LOGICALAND
+	case token.LOGICALORASSIGN:
+		return LOGICALOR
 	default:
 		return ILLEGAL
 	}
@@ -213,8 +213,55 @@ func Walk(
 		*Comment:
 		// nothing to do
 
+	case *RegexLiteral:
+		if mutating {
+			new_node = Walk(n.Pattern, transformer, v)
+			if new_pattern, ok := new_node.(*InterpolatedString); ok {
+				n.Pattern = new_pattern
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a regex literal pattern from %T to %T", n.Pattern, new_node))
+			}
+		} else {
+			_ = Walk(n.Pattern, transformer, v)
+		}
+
+	case *InterpolatedString:
+		if mutating {
+			for i, x := range n.Literals {
+				new_node = Walk(x, transformer, v)
+				if new_literal, ok := new_node.(*StringLiteral); ok {
+					n.Literals[i] = new_literal
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an interpolated string literal to %T", new_node))
+				}
+			}
+			for i, x := range n.Embedded {
+				new_node = Walk(x, transformer, v)
+				if new_block, ok := new_node.(*BlockStatement); ok {
+					n.Embedded[i] = new_block
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an interpolated string block to %T", new_node))
+				}
+			}
+		} else {
+			for _, x := range n.Literals {
+				_ = Walk(x, transformer, v)
+			}
+			for _, x := range n.Embedded {
+				_ = Walk(x, transformer, v)
+			}
+		}
+
 	case *FunctionLiteral:
 		if mutating {
+			if n.Receiver != nil {
+				new_node = Walk(n.Receiver, transformer, v)
+				if new_receiver, ok := new_node.(Expression); ok {
+					n.Receiver = new_receiver
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a function receiver from %T to %T", n.Receiver, new_node))
+				}
+			}
 			new_params := make([]*FunctionParameter, len(n.Parameters))
 			for i, x := range n.Parameters {
 				new_node = Walk(x, transformer, v)
@@ -232,6 +279,9 @@ func Walk(
 				panic(fmt.Sprintf("ast.Walk mutated a function body to %T", new_body))
 			}
 		} else {
+			if n.Receiver != nil {
+				_ = Walk(n.Receiver, transformer, v)
+			}
 			for _, x := range n.Parameters {
 				_ = Walk(x, transformer, v)
 			}
@@ -250,8 +300,19 @@ func Walk(
 					panic(fmt.Sprintf("ast.Walk mutated a function parameter default to %T. Previously it was %T", new_default, n.Default))
 				}
 			}
+			for i, x := range n.Destructure {
+				new_node = Walk(x, transformer, v)
+				if new_param, ok := new_node.(*FunctionParameter); ok {
+					n.Destructure[i] = new_param
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a nested function parameter to %T", new_param))
+				}
+			}
 		} else {
 			_ = Walk(n.Default, transformer, v)
+			for _, x := range n.Destructure {
+				_ = Walk(x, transformer, v)
+			}
 		}
 
 	case *IndexExpression:
@@ -431,11 +492,22 @@ func Walk(
 				}
 			}
 			n.Map = new_map
+			for i, splat := range n.Splats {
+				new_node = Walk(splat, transformer, v)
+				if new_splat, ok := new_node.(Expression); ok {
+					n.Splats[i] = new_splat
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a hash literal splat to %T", new_splat))
+				}
+			}
 		} else {
 			for k, val := range n.Map {
 				_ = Walk(k, transformer, v)
 				_ = Walk(val, transformer, v)
 			}
+			for _, splat := range n.Splats {
+				_ = Walk(splat, transformer, v)
+			}
 		}
 
 	case *ExpressionStatement:
@@ -487,34 +559,65 @@ func Walk(
 			_ = Walk(n.Right, transformer, v)
 		}
 
-	case *ReturnStatement:
+	case *LogicalAssignment:
 		if mutating {
-			new_node = Walk(n.ReturnValue, transformer, v)
-			if new_return_value, ok := new_node.(Expression); ok {
-				if new_return_value != n.ReturnValue {
-					fmt.Printf("*ReturnStatement::ReturnValue: ast.Walk mutated %T to %T\n", n.ReturnValue, new_node)
-				}
-				n.ReturnValue = new_return_value
+			new_node = Walk(n.Left, transformer, v)
+			if new_left, ok := new_node.(Expression); ok {
+				n.Left = new_left
 			} else {
-				panic(fmt.Sprintf("ast.Walk mutated a return statement return value from %T to %T", n.ReturnValue, new_return_value))
+				panic(fmt.Sprintf("ast.Walk mutated a logical assignment left to %T", new_node))
+			}
+			new_node = Walk(n.Right, transformer, v)
+			if new_right, ok := new_node.(Expression); ok {
+				n.Right = new_right
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a logical assignment right from %T to %T", n.Right, new_node))
 			}
-
 		} else {
-			_ = Walk(n.ReturnValue, transformer, v)
+			_ = Walk(n.Left, transformer, v)
+			_ = Walk(n.Right, transformer, v)
 		}
 
-	case *BreakStatement:
+	case *ReturnStatement:
 		if mutating {
-			new_node = Walk(n.Condition, transformer, v)
-			if new_node == nil {
-				n.Condition = nil
-			} else {
-				if new_condition, ok := new_node.(Expression); ok {
-					n.Condition = new_condition
+			if n.ReturnValue != nil {
+				new_node = Walk(n.ReturnValue, transformer, v)
+				if new_return_value, ok := new_node.(Expression); ok {
+					if new_return_value != n.ReturnValue {
+						fmt.Printf("*ReturnStatement::ReturnValue: ast.Walk mutated %T to %T\n", n.ReturnValue, new_node)
+					}
+					n.ReturnValue = new_return_value
 				} else {
-					panic(fmt.Sprintf("ast.Walk mutated a break statement condition from %T to %T", n.Condition, new_condition))
+					panic(fmt.Sprintf("ast.Walk mutated a return statement return value from %T to %T", n.ReturnValue, new_return_value))
 				}
 			}
+			n.Condition = walkOptionalExpression(n.Condition, transformer, v, "return statement condition")
+		} else {
+			_ = Walk(n.ReturnValue, transformer, v)
+			_ = Walk(n.Condition, transformer, v)
+		}
+
+	case *BreakStatement:
+		if mutating {
+			n.Value = walkOptionalExpression(n.Value, transformer, v, "break statement value")
+			n.Condition = walkOptionalExpression(n.Condition, transformer, v, "break statement condition")
+		} else {
+			_ = Walk(n.Value, transformer, v)
+			_ = Walk(n.Condition, transformer, v)
+		}
+
+	case *NextStatement:
+		if mutating {
+			n.Value = walkOptionalExpression(n.Value, transformer, v, "next statement value")
+			n.Condition = walkOptionalExpression(n.Condition, transformer, v, "next statement condition")
+		} else {
+			_ = Walk(n.Value, transformer, v)
+			_ = Walk(n.Condition, transformer, v)
+		}
+
+	case *RedoStatement:
+		if mutating {
+			n.Condition = walkOptionalExpression(n.Condition, transformer, v, "redo statement condition")
 		} else {
 			_ = Walk(n.Condition, transformer, v)
 		}
@@ -569,12 +672,9 @@ func Walk(
 
 	case *LoopExpression:
 		if mutating {
-			// new_node = Walk(n.Condition, transformer, v)
-			// if new_condition, ok := new_node.(Expression); ok {
-			// n.Condition = new_condition
-			// } else {
-			// panic(fmt.Sprintf("ast.Walk mutated a loop expression condition to %T", new_condition))
-			// }
+			n.Condition = walkOptionalExpression(n.Condition, transformer, v, "loop expression condition")
+			n.Variable = walkOptionalExpression(n.Variable, transformer, v, "loop expression variable")
+			n.Iterable = walkOptionalExpression(n.Iterable, transformer, v, "loop expression iterable")
 			new_node = Walk(n.Block, transformer, v)
 			if new_block, ok := new_node.(*BlockStatement); ok {
 				n.Block = new_block
@@ -582,10 +682,605 @@ func Walk(
 				panic(fmt.Sprintf("ast.Walk mutated a loop expression block from %T to %T", n.Block, new_block))
 			}
 		} else {
-			// _ = Walk(n.Condition, transformer, v)
+			_ = Walk(n.Condition, transformer, v)
+			_ = Walk(n.Variable, transformer, v)
+			_ = Walk(n.Iterable, transformer, v)
 			_ = Walk(n.Block, transformer, v)
 		}
 
+	case *BeginExpression:
+		if mutating {
+			new_node = Walk(n.Body, transformer, v)
+			if new_body, ok := new_node.(*BlockStatement); ok {
+				n.Body = new_body
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a begin expression body from %T to %T", n.Body, new_node))
+			}
+			new_rescues := make([]*RescueClause, len(n.Rescues))
+			for i, x := range n.Rescues {
+				new_node = Walk(x, transformer, v)
+				if new_rescue, ok := new_node.(*RescueClause); ok {
+					new_rescues[i] = new_rescue
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a begin expression rescue clause to %T", new_node))
+				}
+			}
+			n.Rescues = new_rescues
+			if n.Else != nil {
+				new_node = Walk(n.Else, transformer, v)
+				if new_else, ok := new_node.(*BlockStatement); ok {
+					n.Else = new_else
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a begin expression else from %T to %T", n.Else, new_node))
+				}
+			}
+			if n.Ensure != nil {
+				new_node = Walk(n.Ensure, transformer, v)
+				if new_ensure, ok := new_node.(*BlockStatement); ok {
+					n.Ensure = new_ensure
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a begin expression ensure from %T to %T", n.Ensure, new_node))
+				}
+			}
+		} else {
+			_ = Walk(n.Body, transformer, v)
+			for _, x := range n.Rescues {
+				_ = Walk(x, transformer, v)
+			}
+			if n.Else != nil {
+				_ = Walk(n.Else, transformer, v)
+			}
+			if n.Ensure != nil {
+				_ = Walk(n.Ensure, transformer, v)
+			}
+		}
+
+	case *RescueClause:
+		if mutating {
+			new_classes := make([]Expression, len(n.ExceptionClasses))
+			for i, x := range n.ExceptionClasses {
+				new_node = Walk(x, transformer, v)
+				if new_class, ok := new_node.(Expression); ok {
+					new_classes[i] = new_class
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a rescue clause exception class to %T", new_node))
+				}
+			}
+			n.ExceptionClasses = new_classes
+			if n.Exception != nil {
+				new_node = Walk(n.Exception, transformer, v)
+				if new_exception, ok := new_node.(*Identifier); ok {
+					n.Exception = new_exception
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a rescue clause exception variable to %T", new_node))
+				}
+			}
+			new_node = Walk(n.Body, transformer, v)
+			if new_body, ok := new_node.(*BlockStatement); ok {
+				n.Body = new_body
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a rescue clause body from %T to %T", n.Body, new_node))
+			}
+		} else {
+			for _, x := range n.ExceptionClasses {
+				_ = Walk(x, transformer, v)
+			}
+			if n.Exception != nil {
+				_ = Walk(n.Exception, transformer, v)
+			}
+			_ = Walk(n.Body, transformer, v)
+		}
+
+	case *CaseExpression:
+		if mutating {
+			if n.Subject != nil {
+				new_node = Walk(n.Subject, transformer, v)
+				if new_subject, ok := new_node.(Expression); ok {
+					n.Subject = new_subject
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a case expression subject from %T to %T", n.Subject, new_node))
+				}
+			}
+			new_whens := make([]*WhenClause, len(n.Whens))
+			for i, x := range n.Whens {
+				new_node = Walk(x, transformer, v)
+				if new_when, ok := new_node.(*WhenClause); ok {
+					new_whens[i] = new_when
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a case expression when clause to %T", new_node))
+				}
+			}
+			n.Whens = new_whens
+			if n.Else != nil {
+				new_node = Walk(n.Else, transformer, v)
+				if new_else, ok := new_node.(*BlockStatement); ok {
+					n.Else = new_else
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a case expression else from %T to %T", n.Else, new_node))
+				}
+			}
+		} else {
+			if n.Subject != nil {
+				_ = Walk(n.Subject, transformer, v)
+			}
+			for _, x := range n.Whens {
+				_ = Walk(x, transformer, v)
+			}
+			if n.Else != nil {
+				_ = Walk(n.Else, transformer, v)
+			}
+		}
+
+	case *WhenClause:
+		if mutating {
+			new_values := make([]Expression, len(n.Values))
+			for i, x := range n.Values {
+				new_node = Walk(x, transformer, v)
+				if new_value, ok := new_node.(Expression); ok {
+					new_values[i] = new_value
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a when clause value to %T", new_node))
+				}
+			}
+			n.Values = new_values
+			new_node = Walk(n.Body, transformer, v)
+			if new_body, ok := new_node.(*BlockStatement); ok {
+				n.Body = new_body
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a when clause body from %T to %T", n.Body, new_node))
+			}
+		} else {
+			for _, x := range n.Values {
+				_ = Walk(x, transformer, v)
+			}
+			_ = Walk(n.Body, transformer, v)
+		}
+
+	case *CaseMatchExpression:
+		if mutating {
+			new_node = Walk(n.Subject, transformer, v)
+			if new_subject, ok := new_node.(Expression); ok {
+				n.Subject = new_subject
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a case expression subject from %T to %T", n.Subject, new_node))
+			}
+			new_ins := make([]*InClause, len(n.Ins))
+			for i, x := range n.Ins {
+				new_node = Walk(x, transformer, v)
+				if new_in, ok := new_node.(*InClause); ok {
+					new_ins[i] = new_in
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a case expression in clause to %T", new_node))
+				}
+			}
+			n.Ins = new_ins
+			if n.Else != nil {
+				new_node = Walk(n.Else, transformer, v)
+				if new_else, ok := new_node.(*BlockStatement); ok {
+					n.Else = new_else
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a case expression else from %T to %T", n.Else, new_node))
+				}
+			}
+		} else {
+			_ = Walk(n.Subject, transformer, v)
+			for _, x := range n.Ins {
+				_ = Walk(x, transformer, v)
+			}
+			if n.Else != nil {
+				_ = Walk(n.Else, transformer, v)
+			}
+		}
+
+	case *InClause:
+		if mutating {
+			new_node = Walk(n.Pattern, transformer, v)
+			if new_pattern, ok := new_node.(Pattern); ok {
+				n.Pattern = new_pattern
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated an in clause pattern from %T to %T", n.Pattern, new_node))
+			}
+			if n.Guard != nil {
+				new_node = Walk(n.Guard, transformer, v)
+				if new_guard, ok := new_node.(Expression); ok {
+					n.Guard = new_guard
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an in clause guard from %T to %T", n.Guard, new_node))
+				}
+			}
+			new_node = Walk(n.Body, transformer, v)
+			if new_body, ok := new_node.(*BlockStatement); ok {
+				n.Body = new_body
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated an in clause body from %T to %T", n.Body, new_node))
+			}
+		} else {
+			_ = Walk(n.Pattern, transformer, v)
+			if n.Guard != nil {
+				_ = Walk(n.Guard, transformer, v)
+			}
+			_ = Walk(n.Body, transformer, v)
+		}
+
+	case *MatchExpression:
+		if mutating {
+			new_node = Walk(n.Value, transformer, v)
+			if new_value, ok := new_node.(Expression); ok {
+				n.Value = new_value
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a match expression value from %T to %T", n.Value, new_node))
+			}
+			new_node = Walk(n.Pattern, transformer, v)
+			if new_pattern, ok := new_node.(Pattern); ok {
+				n.Pattern = new_pattern
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a match expression pattern from %T to %T", n.Pattern, new_node))
+			}
+		} else {
+			_ = Walk(n.Value, transformer, v)
+			_ = Walk(n.Pattern, transformer, v)
+		}
+
+	case *ValuePattern:
+		if mutating {
+			new_node = Walk(n.Value, transformer, v)
+			if new_value, ok := new_node.(Expression); ok {
+				n.Value = new_value
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a value pattern from %T to %T", n.Value, new_node))
+			}
+		} else {
+			_ = Walk(n.Value, transformer, v)
+		}
+
+	case *VariablePattern:
+		if mutating {
+			new_node = Walk(n.Name, transformer, v)
+			if new_name, ok := new_node.(*Identifier); ok {
+				n.Name = new_name
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a variable pattern name from %T to %T", n.Name, new_node))
+			}
+		} else {
+			_ = Walk(n.Name, transformer, v)
+		}
+
+	case *PinPattern:
+		if mutating {
+			new_node = Walk(n.Value, transformer, v)
+			if new_value, ok := new_node.(Expression); ok {
+				n.Value = new_value
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a pin pattern from %T to %T", n.Value, new_node))
+			}
+		} else {
+			_ = Walk(n.Value, transformer, v)
+		}
+
+	case *SplatPattern:
+		if mutating {
+			if n.Name != nil {
+				new_node = Walk(n.Name, transformer, v)
+				if new_name, ok := new_node.(*Identifier); ok {
+					n.Name = new_name
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a splat pattern name from %T to %T", n.Name, new_node))
+				}
+			}
+		} else {
+			if n.Name != nil {
+				_ = Walk(n.Name, transformer, v)
+			}
+		}
+
+	case *ArrayPattern:
+		if mutating {
+			if n.Constant != nil {
+				new_node = Walk(n.Constant, transformer, v)
+				if new_constant, ok := new_node.(Expression); ok {
+					n.Constant = new_constant
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an array pattern constant from %T to %T", n.Constant, new_node))
+				}
+			}
+			new_pre := make([]Pattern, len(n.Pre))
+			for i, x := range n.Pre {
+				new_node = Walk(x, transformer, v)
+				if new_element, ok := new_node.(Pattern); ok {
+					new_pre[i] = new_element
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an array pattern element to %T", new_node))
+				}
+			}
+			n.Pre = new_pre
+			if n.Rest != nil {
+				new_node = Walk(n.Rest, transformer, v)
+				if new_rest, ok := new_node.(*SplatPattern); ok {
+					n.Rest = new_rest
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an array pattern rest from %T to %T", n.Rest, new_node))
+				}
+			}
+			new_post := make([]Pattern, len(n.Post))
+			for i, x := range n.Post {
+				new_node = Walk(x, transformer, v)
+				if new_element, ok := new_node.(Pattern); ok {
+					new_post[i] = new_element
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an array pattern element to %T", new_node))
+				}
+			}
+			n.Post = new_post
+		} else {
+			if n.Constant != nil {
+				_ = Walk(n.Constant, transformer, v)
+			}
+			for _, x := range n.Pre {
+				_ = Walk(x, transformer, v)
+			}
+			if n.Rest != nil {
+				_ = Walk(n.Rest, transformer, v)
+			}
+			for _, x := range n.Post {
+				_ = Walk(x, transformer, v)
+			}
+		}
+
+	case *FindPattern:
+		if mutating {
+			if n.Constant != nil {
+				new_node = Walk(n.Constant, transformer, v)
+				if new_constant, ok := new_node.(Expression); ok {
+					n.Constant = new_constant
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a find pattern constant from %T to %T", n.Constant, new_node))
+				}
+			}
+			new_node = Walk(n.Pre, transformer, v)
+			if new_pre, ok := new_node.(*SplatPattern); ok {
+				n.Pre = new_pre
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a find pattern splat from %T to %T", n.Pre, new_node))
+			}
+			new_middle := make([]Pattern, len(n.Middle))
+			for i, x := range n.Middle {
+				new_node = Walk(x, transformer, v)
+				if new_element, ok := new_node.(Pattern); ok {
+					new_middle[i] = new_element
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a find pattern element to %T", new_node))
+				}
+			}
+			n.Middle = new_middle
+			new_node = Walk(n.Post, transformer, v)
+			if new_post, ok := new_node.(*SplatPattern); ok {
+				n.Post = new_post
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a find pattern splat from %T to %T", n.Post, new_node))
+			}
+		} else {
+			if n.Constant != nil {
+				_ = Walk(n.Constant, transformer, v)
+			}
+			_ = Walk(n.Pre, transformer, v)
+			for _, x := range n.Middle {
+				_ = Walk(x, transformer, v)
+			}
+			_ = Walk(n.Post, transformer, v)
+		}
+
+	case *HashPattern:
+		if mutating {
+			if n.Constant != nil {
+				new_node = Walk(n.Constant, transformer, v)
+				if new_constant, ok := new_node.(Expression); ok {
+					n.Constant = new_constant
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a hash pattern constant from %T to %T", n.Constant, new_node))
+				}
+			}
+			new_pairs := make([]*HashPatternPair, len(n.Pairs))
+			for i, x := range n.Pairs {
+				new_node = Walk(x, transformer, v)
+				if new_pair, ok := new_node.(*HashPatternPair); ok {
+					new_pairs[i] = new_pair
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a hash pattern pair to %T", new_node))
+				}
+			}
+			n.Pairs = new_pairs
+			if n.Rest != nil {
+				new_node = Walk(n.Rest, transformer, v)
+				if new_rest, ok := new_node.(*SplatPattern); ok {
+					n.Rest = new_rest
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a hash pattern rest from %T to %T", n.Rest, new_node))
+				}
+			}
+		} else {
+			if n.Constant != nil {
+				_ = Walk(n.Constant, transformer, v)
+			}
+			for _, x := range n.Pairs {
+				_ = Walk(x, transformer, v)
+			}
+			if n.Rest != nil {
+				_ = Walk(n.Rest, transformer, v)
+			}
+		}
+
+	case *HashPatternPair:
+		if mutating {
+			new_node = Walk(n.Key, transformer, v)
+			if new_key, ok := new_node.(*Identifier); ok {
+				n.Key = new_key
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a hash pattern key from %T to %T", n.Key, new_node))
+			}
+			if n.Value != nil {
+				new_node = Walk(n.Value, transformer, v)
+				if new_value, ok := new_node.(Pattern); ok {
+					n.Value = new_value
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a hash pattern value from %T to %T", n.Value, new_node))
+				}
+			}
+		} else {
+			_ = Walk(n.Key, transformer, v)
+			if n.Value != nil {
+				_ = Walk(n.Value, transformer, v)
+			}
+		}
+
+	case *AlternativePattern:
+		if mutating {
+			new_alternatives := make([]Pattern, len(n.Alternatives))
+			for i, x := range n.Alternatives {
+				new_node = Walk(x, transformer, v)
+				if new_alternative, ok := new_node.(Pattern); ok {
+					new_alternatives[i] = new_alternative
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated an alternative pattern to %T", new_node))
+				}
+			}
+			n.Alternatives = new_alternatives
+		} else {
+			for _, x := range n.Alternatives {
+				_ = Walk(x, transformer, v)
+			}
+		}
+
+	case *CapturePattern:
+		if mutating {
+			new_node = Walk(n.Pattern, transformer, v)
+			if new_pattern, ok := new_node.(Pattern); ok {
+				n.Pattern = new_pattern
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a capture pattern from %T to %T", n.Pattern, new_node))
+			}
+			new_node = Walk(n.Name, transformer, v)
+			if new_name, ok := new_node.(*Identifier); ok {
+				n.Name = new_name
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a capture pattern name from %T to %T", n.Name, new_node))
+			}
+		} else {
+			_ = Walk(n.Pattern, transformer, v)
+			_ = Walk(n.Name, transformer, v)
+		}
+
+	case *RetryStatement,
+		*Self:
+		// nothing to do
+
+	case *ClassExpression:
+		if mutating {
+			new_node = Walk(n.Name, transformer, v)
+			if new_name, ok := new_node.(*Identifier); ok {
+				n.Name = new_name
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a class name to %T", new_node))
+			}
+			if n.SuperClass != nil {
+				new_node = Walk(n.SuperClass, transformer, v)
+				if new_super, ok := new_node.(Expression); ok {
+					n.SuperClass = new_super
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a class superclass from %T to %T", n.SuperClass, new_node))
+				}
+			}
+			new_node = Walk(n.Body, transformer, v)
+			if new_body, ok := new_node.(*BlockStatement); ok {
+				n.Body = new_body
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a class body from %T to %T", n.Body, new_node))
+			}
+		} else {
+			_ = Walk(n.Name, transformer, v)
+			if n.SuperClass != nil {
+				_ = Walk(n.SuperClass, transformer, v)
+			}
+			_ = Walk(n.Body, transformer, v)
+		}
+
+	case *ModuleExpression:
+		if mutating {
+			new_node = Walk(n.Name, transformer, v)
+			if new_name, ok := new_node.(*Identifier); ok {
+				n.Name = new_name
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a module name to %T", new_node))
+			}
+			new_node = Walk(n.Body, transformer, v)
+			if new_body, ok := new_node.(*BlockStatement); ok {
+				n.Body = new_body
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a module body from %T to %T", n.Body, new_node))
+			}
+		} else {
+			_ = Walk(n.Name, transformer, v)
+			_ = Walk(n.Body, transformer, v)
+		}
+
+	case *ScopedConstant:
+		if mutating {
+			if n.Scope != nil {
+				new_node = Walk(n.Scope, transformer, v)
+				if new_scope, ok := new_node.(Expression); ok {
+					n.Scope = new_scope
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a constant scope from %T to %T", n.Scope, new_node))
+				}
+			}
+			new_node = Walk(n.Name, transformer, v)
+			if new_name, ok := new_node.(*Identifier); ok {
+				n.Name = new_name
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a scoped constant name to %T", new_node))
+			}
+		} else {
+			if n.Scope != nil {
+				_ = Walk(n.Scope, transformer, v)
+			}
+			_ = Walk(n.Name, transformer, v)
+		}
+
+	case *Super:
+		if mutating {
+			new_args := make([]Expression, len(n.Arguments))
+			for i, x := range n.Arguments {
+				new_node = Walk(x, transformer, v)
+				if new_arg, ok := new_node.(Expression); ok {
+					new_args[i] = new_arg
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a super argument to %T", new_node))
+				}
+			}
+			n.Arguments = new_args
+		} else {
+			for _, x := range n.Arguments {
+				_ = Walk(x, transformer, v)
+			}
+		}
+
+	case *Yield:
+		if mutating {
+			new_args := make([]Expression, len(n.Arguments))
+			for i, x := range n.Arguments {
+				new_node = Walk(x, transformer, v)
+				if new_arg, ok := new_node.(Expression); ok {
+					new_args[i] = new_arg
+				} else {
+					panic(fmt.Sprintf("ast.Walk mutated a yield argument to %T", new_node))
+				}
+			}
+			n.Arguments = new_args
+		} else {
+			for _, x := range n.Arguments {
+				_ = Walk(x, transformer, v)
+			}
+		}
+
 	// Program
 	case *Program:
 		if mutating {
@@ -643,9 +1338,34 @@ func Walk(
 			_ = Walk(n.Value, transformer, v)
 		}
 
-	case *FloatLiteral:
+	case *BlockPass:
+		if mutating {
+			new_node = Walk(n.Value, transformer, v)
+			if new_value, ok := new_node.(Expression); ok {
+				n.Value = new_value
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated a block pass value from %T to %T", n.Value, new_value))
+			}
+		} else {
+			_ = Walk(n.Value, transformer, v)
+		}
+
+	case *FloatLiteral,
+		*RationalLiteral:
 		// nothing to do
 
+	case *ImaginaryLiteral:
+		if mutating {
+			new_node = Walk(n.Value, transformer, v)
+			if new_value, ok := new_node.(Expression); ok {
+				n.Value = new_value
+			} else {
+				panic(fmt.Sprintf("ast.Walk mutated an imaginary literal value from %T to %T", n.Value, new_value))
+			}
+		} else {
+			_ = Walk(n.Value, transformer, v)
+		}
+
 	default:
 		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
 	}
@@ -662,3 +1382,20 @@ func Walk(
 
 	return node
 }
+
+// walkOptionalExpression walks expression, which may be nil, and returns the
+// expression it has been transformed into
+func walkOptionalExpression(expression Expression, transformer Transformer, v Visitor, what string) Expression {
+	if expression == nil {
+		return nil
+	}
+	new_node := Walk(expression, transformer, v)
+	if new_node == nil {
+		return nil
+	}
+	new_expression, ok := new_node.(Expression)
+	if !ok {
+		panic(fmt.Sprintf("ast.Walk mutated a %s from %T to %T", what, expression, new_node))
+	}
+	return new_expression
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/MarcinKonowalczyk/goruby/interpreter"
+	"github.com/MarcinKonowalczyk/goruby/object"
 	"github.com/pkg/errors"
 )
 
@@ -33,7 +34,13 @@ var cpuprofile string = ""
 
 func printError(err error) {
 	// fmt.Printf("%v\n", errors.Cause(err))
-	fmt.Printf("%T : %v\n", errors.Cause(err), err)
+	cause := errors.Cause(err)
+	if exc, ok := cause.(object.RubyObject); ok && object.IsError(exc) {
+		// uncaught Ruby exception. report it like MRI does
+		fmt.Fprintln(os.Stderr, object.FullMessage(exc))
+		return
+	}
+	fmt.Printf("%T : %v\n", cause, err)
 }
 
 func main() {
@@ -67,7 +74,11 @@ func main() {
 		log.Println("No program files specified")
 		os.Exit(1)
 	}
-	interpreter := interpreter.NewInterpreterEx(args[1:])
+	var argv []string
+	if len(args) > 1 {
+		argv = args[1:]
+	}
+	interpreter := interpreter.NewInterpreterEx(argv)
 	if trace_parse {
 		interpreter.SetTraceParse(true)
 	}
@@ -76,9 +87,9 @@ func main() {
 	}
 	if len(onelineScripts) != 0 {
 		input := strings.Join(onelineScripts, "\n")
-		_, err := interpreter.Interpret("", input)
+		_, err := interpreter.Interpret("-e", input)
 		if err != nil {
-			fmt.Printf("%v\n", errors.Cause(err))
+			printError(err)
 			os.Exit(1)
 		}
 		return
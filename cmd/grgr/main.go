@@ -94,6 +94,10 @@ func (g *grgrOutput) PrintNode(node ast.Node) {
 		g.PrintFakeComment(fmt.Sprintf(" %T", node))
 		g.Println(node.Code())
 		g.Println()
+	case *ast.LogicalAssignment:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
 
 	case *ast.IndexExpression:
 		g.PrintFakeComment(fmt.Sprintf(" %T", node))
@@ -111,6 +115,38 @@ func (g *grgrOutput) PrintNode(node ast.Node) {
 		g.PrintFakeComment(fmt.Sprintf(" %T", node))
 		g.Println(node.Code())
 		g.Println()
+	case *ast.LoopExpression:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
+	case *ast.BeginExpression:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
+	case *ast.CaseExpression:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
+	case *ast.CaseMatchExpression:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
+	case *ast.MatchExpression:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
+	case *ast.ClassExpression:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
+	case *ast.ModuleExpression:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
+	case *ast.ScopedConstant:
+		g.PrintFakeComment(fmt.Sprintf(" %T", node))
+		g.Println(node.Code())
+		g.Println()
 
 	default:
 		panic(fmt.Sprintf("GRGR print does not yet know how to print %T", node))
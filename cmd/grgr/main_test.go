@@ -0,0 +1,44 @@
+package main
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/interpreter"
+	"github.com/MarcinKonowalczyk/goruby/parser"
+	"github.com/MarcinKonowalczyk/goruby/transformer"
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+// roundTrip returns the grgr output of input
+func roundTrip(t *testing.T, input string) string {
+	t.Helper()
+	program, err := parser.ParseFile(token.NewFileSet(), "", input)
+	utils.AssertNoError(t, err)
+	program, err = transformer.Transform(program, false)
+	utils.AssertNoError(t, err)
+	var g grgrOutput
+	g.PrintNode(program)
+	return g.out.String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"logical assignment of a local", "y ||= 4\ny &&= y + 1\nr = y"},
+		{"logical assignment of a global", "$g ||= 1\n$g ||= 2\nr = $g"},
+		{"logical assignment of an index", "h = {}\nh[:a] ||= 1\nh[:a] ||= 2\nr = h[:a]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := interpreter.NewInterpreterEx(nil).Interpret("", tt.input)
+			utils.AssertNoError(t, err)
+			actual, err := interpreter.NewInterpreterEx(nil).Interpret("", roundTrip(t, tt.input))
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, actual.Inspect(), expected.Inspect())
+		})
+	}
+}
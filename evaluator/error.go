@@ -0,0 +1,38 @@
+package evaluator
+
+import (
+	gotoken "go/token"
+
+	"github.com/pkg/errors"
+)
+
+// Make sure PositionError implements error interface
+var _ error = &PositionError{}
+
+// PositionError annotates an evaluation error with the source location of the
+// expression which failed.
+//
+// Only the innermost failing expression is recorded; the enclosing expressions
+// pass the error on unchanged. The wrapped error is available via Cause and
+// Unwrap, so errors.Cause still returns the underlying Ruby exception.
+type PositionError struct {
+	Pos gotoken.Position
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	return e.Pos.String() + ": " + e.Err.Error()
+}
+
+func (e *PositionError) Cause() error  { return e.Err }
+func (e *PositionError) Unwrap() error { return e.Err }
+
+// ErrorPosition returns the location of the expression which caused err, if
+// it is known.
+func ErrorPosition(err error) (gotoken.Position, bool) {
+	var posErr *PositionError
+	if !errors.As(err, &posErr) {
+		return gotoken.Position{}, false
+	}
+	return posErr.Pos, true
+}
@@ -2,7 +2,9 @@ package evaluator
 
 import (
 	"fmt"
-	"regexp"
+	gotoken "go/token"
+	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/MarcinKonowalczyk/goruby/ast"
@@ -21,6 +23,21 @@ func (c *callContext) Eval(node ast.Node, env object.Environment) (object.RubyOb
 	return c.evaluator.Eval(node, env)
 }
 
+func (c *callContext) CallStack() *object.CallStack {
+	if e, ok := c.evaluator.(*evaluator); ok {
+		return e.stack
+	}
+	return nil
+}
+
+// A blockContext is a callContext carrying the block attached to the call
+type blockContext struct {
+	*callContext
+	block *object.Proc
+}
+
+func (c *blockContext) Block() *object.Proc { return c.block }
+
 type rubyObjects []object.RubyObject
 
 func (r rubyObjects) Inspect() string {
@@ -41,7 +58,10 @@ func expandToArrayIfNeeded(obj object.RubyObject) object.RubyObject {
 	return object.NewArray(arr...)
 }
 
-func EvalEx(node ast.Node, env object.Environment, trace_eval bool) (object.RubyObject, trace.Tracer, error) {
+// EvalEx evaluates the given node. Positions recorded in the AST are resolved
+// through fset, so that errors report the location of the failing expression.
+// fset may be nil, in which case errors carry no location.
+func EvalEx(node ast.Node, env object.Environment, fset *gotoken.FileSet, trace_eval bool) (object.RubyObject, trace.Tracer, error) {
 	if node == nil {
 		return nil, nil, nil
 	}
@@ -52,6 +72,8 @@ func EvalEx(node ast.Node, env object.Environment, trace_eval bool) (object.Ruby
 	}
 
 	e := NewEvaluator().(*evaluator)
+	e.fset = fset
+	e.stack = object.NewCallStack(fset)
 	if trace_eval {
 		e.tracer = trace.NewTracer()
 	}
@@ -65,7 +87,7 @@ func EvalEx(node ast.Node, env object.Environment, trace_eval bool) (object.Ruby
 
 // Eval evaluates the given node and traverses recursive over its children
 func Eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
-	res, _, err := EvalEx(node, env, false)
+	res, _, err := EvalEx(node, env, nil, false)
 	return res, err
 }
 
@@ -74,17 +96,80 @@ type Evaluator interface {
 }
 
 func NewEvaluator() Evaluator {
-	return &evaluator{}
+	return &evaluator{stack: object.NewCallStack(nil)}
 }
 
 type evaluator struct {
 	tracer trace.Tracer
+	fset   *gotoken.FileSet
+	stack  *object.CallStack
+	blocks []*object.Function // blocks attached to the method calls in progress
 }
 
 func (e *evaluator) Eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace("evaluator.Eval"))
 	}
+	// Keep track of the expression being evaluated in the current frame. On
+	// error the position is left pointing at the failing expression.
+	frame := e.stack.Top()
+	outer := frame.Pos
+	if node != nil && node.Pos().IsValid() {
+		frame.Pos = node.Pos()
+	}
+	res, err := e.eval(node, env)
+	if err != nil {
+		err = e.checkLocalJump(err)
+		e.recordBacktrace(err)
+		return res, e.withPosition(err, node)
+	}
+	frame.Pos = outer
+	return res, nil
+}
+
+// recordBacktrace stores the current call stack in the exception err is
+// caused by, unless it has been raised before.
+func (e *evaluator) recordBacktrace(err error) {
+	exc, ok := errors.Cause(err).(object.Backtracer)
+	if !ok || exc.Backtrace() != nil {
+		return
+	}
+	exc.SetBacktrace(e.stack.Backtrace())
+}
+
+// checkLocalJump turns a break or return unwinding from a block into a
+// LocalJumpError if its target is not running anymore, e.g. the method call
+// the block was attached to has already returned.
+func (e *evaluator) checkLocalJump(err error) error {
+	switch jump := errors.Cause(err).(type) {
+	case *object.BreakError:
+		for _, block := range e.blocks {
+			if block == jump.Function {
+				return err
+			}
+		}
+		return errors.WithStack(object.NewLocalJumpError("break from proc-closure"))
+	case *object.ReturnError:
+		if jump.Frame == nil || !e.stack.Contains(jump.Frame) {
+			return errors.WithStack(object.NewLocalJumpError("unexpected return"))
+		}
+	}
+	return err
+}
+
+// withPosition annotates err with the location of node, unless the error
+// already carries the location of a nested expression.
+func (e *evaluator) withPosition(err error, node ast.Node) error {
+	if e.fset == nil || node == nil || !node.Pos().IsValid() {
+		return err
+	}
+	if _, ok := ErrorPosition(err); ok {
+		return err
+	}
+	return &PositionError{Pos: e.fset.Position(node.Pos()), Err: err}
+}
+
+func (e *evaluator) eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
 	switch node := node.(type) {
 	// Statements
 	case *ast.Program:
@@ -95,6 +180,19 @@ func (e *evaluator) Eval(node ast.Node, env object.Environment) (object.RubyObje
 		return e.evalReturnStatement(node, env)
 	case *ast.BreakStatement:
 		return e.evalBreakStatement(node, env)
+	case *ast.NextStatement:
+		return e.evalNextStatement(node, env)
+	case *ast.RetryStatement:
+		return &object.RetryValue{}, nil
+	case *ast.RedoStatement:
+		_, jump, err := e.evalJump(nil, node.Condition, node.Unless, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval of redo statement")
+		}
+		if !jump {
+			return object.NIL, nil
+		}
+		return &object.RedoValue{}, nil
 	case *ast.BlockStatement:
 		return e.evalBlockStatement(node, env)
 	// Literals
@@ -102,10 +200,22 @@ func (e *evaluator) Eval(node ast.Node, env object.Environment) (object.RubyObje
 		return e.evalIntegerLiteral(node, env)
 	case *ast.FloatLiteral:
 		return e.evalFloatLiteral(node, env)
+	case *ast.RationalLiteral:
+		return object.NewRational(new(big.Rat).Set(node.Value)), nil
+	case *ast.ImaginaryLiteral:
+		value, err := e.Eval(node.Value, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval imaginary literal")
+		}
+		return object.NewComplex(object.NewInteger(0), value), nil
 	case *ast.Identifier:
 		return e.evalIdentifier(node, env)
 	case *ast.StringLiteral:
 		return e.evalStringLiteral(node, env)
+	case *ast.InterpolatedString:
+		return e.evalInterpolatedString(node, env)
+	case *ast.RegexLiteral:
+		return e.evalRegexLiteral(node, env)
 	case *ast.SymbolLiteral:
 		return e.evalSymbolLiteral(node, env)
 	case *ast.FunctionLiteral:
@@ -119,6 +229,8 @@ func (e *evaluator) Eval(node ast.Node, env object.Environment) (object.RubyObje
 	// Expressions
 	case *ast.Assignment:
 		return e.evalAssignment(node, env)
+	case *ast.LogicalAssignment:
+		return e.evalLogicalAssignment(node, env)
 	case *ast.ContextCallExpression:
 		return e.evalContextCallExpression(node, env)
 	case *ast.IndexExpression:
@@ -128,7 +240,7 @@ func (e *evaluator) Eval(node ast.Node, env object.Environment) (object.RubyObje
 		if err != nil {
 			return nil, errors.WithMessage(err, "eval prefix right side")
 		}
-		return e.evalPrefixExpression(node.Operator, right)
+		return e.evalPrefixExpression(node.Operator, right, env)
 	case *ast.InfixExpression:
 		return e.evalInfixExpression(node, env)
 	case *ast.ConditionalExpression:
@@ -150,6 +262,26 @@ func (e *evaluator) Eval(node ast.Node, env object.Environment) (object.RubyObje
 		return e.evalSplat(node, env)
 	case *ast.LoopExpression:
 		return e.evalLoopExpression(node, env)
+	case *ast.BeginExpression:
+		return e.evalBeginExpression(node, env)
+	case *ast.CaseExpression:
+		return e.evalCaseExpression(node, env)
+	case *ast.CaseMatchExpression:
+		return e.evalCaseMatchExpression(node, env)
+	case *ast.MatchExpression:
+		return e.evalMatchExpression(node, env)
+	case *ast.ClassExpression:
+		return e.evalClassExpression(node, env)
+	case *ast.ModuleExpression:
+		return e.evalModuleExpression(node, env)
+	case *ast.ScopedConstant:
+		return e.evalScopedConstant(node, env)
+	case *ast.Self:
+		return selfOf(env), nil
+	case *ast.Super:
+		return e.evalSuper(node, env)
+	case *ast.Yield:
+		return e.evalYield(node, env)
 	default:
 		err := object.NewException("Unknown AST: %T", node)
 		return nil, errors.WithStack(err)
@@ -157,102 +289,727 @@ func (e *evaluator) Eval(node ast.Node, env object.Environment) (object.RubyObje
 
 }
 
-func unescapeStringLiteral(node *ast.StringLiteral) string {
-	rep := map[string]string{
-		"\\n":  "\n",
-		"\\t":  "\t",
-		"\\r":  "\r",
-		"\\b":  "\b",
-		"\\\\": "\\",
+func (e *evaluator) evalLoopExpression(node *ast.LoopExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-	// NOTE: we support only double-quoted strings
-	rep["\""] = "\""
-	value := node.Value
-	for k, v := range rep {
-		value = strings.ReplaceAll(value, k, v)
+	if node.Iterable != nil {
+		return e.evalForLoop(node, env)
+	}
+	// the body of `begin ... end while cond` runs before the first check
+	check := !node.DoWhile
+	for {
+		if node.Condition != nil && check {
+			condition, err := e.Eval(node.Condition, env)
+			if err != nil {
+				return nil, errors.WithMessage(err, "eval loop condition")
+			}
+			if isTruthy(condition) == node.Until {
+				return object.NIL, nil
+			}
+		}
+		check = true
+		value, done, err := e.evalLoopBody(node.Block, env)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return value, nil
+		}
 	}
-	return value
 }
 
-var FORMAT_DIRECTIVE_RE = regexp.MustCompile(`\x60#\{(?P<content>[^}]*)\}\x60`)
+// evalForLoop evaluates `for x in iterable`, binding every element of
+// iterable to the loop variable in the enclosing scope. Arrays and ranges are
+// iterated directly, any other object through its each method. The loop
+// returns iterable unless left by a break.
+func (e *evaluator) evalForLoop(node *ast.LoopExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	iterable, err := e.Eval(node.Iterable, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval for loop iterable")
+	}
+	var array *object.Array
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		array = iterable
+	case *object.Range:
+		array = iterable.ToArray()
+	default:
+		return e.evalForLoopEach(node, iterable, env)
+	}
+	// the body may modify the array, so its length is checked on every
+	// iteration
+	for i := 0; i < len(array.Elements); i++ {
+		if _, err := e.assign(node.Variable, array.Elements[i], env); err != nil {
+			return nil, errors.WithMessage(err, "eval for loop variable")
+		}
+		value, done, err := e.evalLoopBody(node.Block, env)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return value, nil
+		}
+	}
+	return iterable, nil
+}
 
-func (e *evaluator) evalFormatDirectives(env object.Environment, value string) (string, error) {
+// evalForLoopEach evaluates a for loop over iterable by sending it each with a
+// block running the loop body. A break or return within the body unwinds the
+// call to each as a forLoopExit.
+func (e *evaluator) evalForLoopEach(node *ast.LoopExpression, iterable object.RubyObject, env object.Environment) (object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-	if !strings.Contains(value, "`") {
-		return value, nil
+	exit := &forLoopExit{}
+	block := object.NewBuiltinProc(func(context object.CallContext, tracer trace.Tracer, args ...object.RubyObject) (object.RubyObject, error) {
+		var value object.RubyObject = object.NIL
+		switch len(args) {
+		case 0:
+		case 1:
+			value = args[0]
+		default:
+			value = object.NewArray(args...)
+		}
+		if _, err := e.assign(node.Variable, value, env); err != nil {
+			return nil, errors.WithMessage(err, "eval for loop variable")
+		}
+		value, done, err := e.evalLoopBody(node.Block, env)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			exit.Value = value
+			return nil, exit
+		}
+		return object.NIL, nil
+	})
+	callContext := &callContext{object.NewCallContext(env, iterable), e}
+	result, err := object.Send(&blockContext{callContext, block}, "each", e.tracer, block)
+	if errors.Cause(err) == exit {
+		return exit.Value, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
+
+// A forLoopExit unwinds the call to each of a for loop from a break or return
+// within the loop body. Value is the value the loop is left with.
+type forLoopExit struct {
+	Value object.RubyObject
+}
 
-	// example sting "hello `#{place}`"
-	// search for `#{...}` pattern
+func (e *forLoopExit) Error() string { return "exit from for loop" }
 
-	matches := FORMAT_DIRECTIVE_RE.FindAllStringSubmatchIndex(value, -1)
-	if len(matches) == 0 {
-		return value, nil
+// evalLoopBody evaluates a single iteration of a loop, which starts over on a
+// redo. It reports whether the loop is left, either by a break, whose value is
+// the value of the loop, or by a return, which is passed on to the enclosing
+// method.
+func (e *evaluator) evalLoopBody(block *ast.BlockStatement, env object.Environment) (object.RubyObject, bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	for {
+		value, err := e.evalBlockStatement(block, env)
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "eval loop body")
+		}
+		switch value := value.(type) {
+		case *object.RedoValue:
+			continue
+		case *object.BreakValue:
+			return value.Value, true, nil
+		case *object.ReturnValue:
+			return value, true, nil
+		}
+		return nil, false, nil
 	}
+}
 
-	// for each match, evaluate the expression
-	// note: in ruby this can be any expression but to save on parser evals
-	// we only manually parse out the identifier name, and allow only that
-	// hence `puts("hello `#{1+1}`")` *does* work in ruby but not here
-	for _, match := range matches {
-		start, end := match[0], match[1]
-		start += 3 // skip the `#{`
-		end -= 2   // skip the }`
-		// loop up identifier
-		val, err := e.Eval(&ast.Identifier{Value: value[start:end]}, env)
+func (e *evaluator) evalBeginExpression(node *ast.BeginExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	result, err := e.evalRescue(node, env)
+	if node.Ensure == nil {
+		return result, err
+	}
+	ensured, ensureErr := e.evalBlockStatement(node.Ensure, env)
+	if ensureErr != nil {
+		return nil, errors.WithMessage(ensureErr, "eval ensure clause")
+	}
+	// an explicit return or break within ensure discards the result as well
+	// as any exception
+	switch ensured.(type) {
+	case *object.ReturnValue, *object.BreakValue, *object.NextValue, *object.RedoValue:
+		return ensured, nil
+	}
+	return result, err
+}
+
+// evalRescue evaluates the body of node, handing exceptions to the first
+// matching rescue clause. The body is evaluated again whenever the rescue
+// clause ends with retry.
+func (e *evaluator) evalRescue(node *ast.BeginExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	for {
+		result, err := e.evalBlockStatement(node.Body, env)
+		if err == nil {
+			switch result.(type) {
+			case *object.ReturnValue, *object.BreakValue, *object.NextValue, *object.RedoValue:
+				return result, nil
+			}
+			if node.Else != nil {
+				return e.evalBlockStatement(node.Else, env)
+			}
+			return result, nil
+		}
+
+		exc, ok := errors.Cause(err).(object.RubyObject)
+		if !ok || !object.IsError(exc) {
+			return nil, err
+		}
+		var rescue *ast.RescueClause
+		for _, clause := range node.Rescues {
+			matches, matchErr := e.rescueMatches(clause, exc, env)
+			if matchErr != nil {
+				return nil, matchErr
+			}
+			if matches {
+				rescue = clause
+				break
+			}
+		}
+		if rescue == nil {
+			return nil, err
+		}
+
+		result, err = e.evalRescueClause(rescue, exc, env)
 		if err != nil {
-			return "", errors.WithMessage(err, "eval format directive")
+			return nil, err
+		}
+		if _, ok := result.(*object.RetryValue); ok {
+			continue
 		}
+		return result, nil
+	}
+}
 
-		var val_str string
-		switch val := val.(type) {
-		case *object.String:
-			val_str = val.Value
-		case *object.Array:
-			val_str = val.Inspect()
-		default:
-			fmt.Printf("val %T\n", val)
+// rescueMatches returns true if exc is an instance of one of the exception
+// classes listed by rescue
+func (e *evaluator) rescueMatches(rescue *ast.RescueClause, exc object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	if len(rescue.ExceptionClasses) == 0 {
+		return object.IsStandardError(exc), nil
+	}
+	for _, expr := range rescue.ExceptionClasses {
+		class, err := e.Eval(expr, env)
+		if err != nil {
+			return false, errors.WithMessage(err, "eval rescue clause exception class")
+		}
+		classes := []object.RubyObject{class}
+		if arr, ok := class.(*object.Array); ok {
+			classes = arr.Elements
+		}
+		for _, class := range classes {
+			class, ok := class.(object.RubyClassObject)
+			if !ok {
+				return false, errors.WithStack(
+					object.NewTypeError("class or module required for rescue clause"),
+				)
+			}
+			if object.IsKindOf(exc, class) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// evalRescueClause evaluates the body of rescue while exc is the exception
+// being handled, i.e. the value of $!
+func (e *evaluator) evalRescueClause(rescue *ast.RescueClause, exc object.RubyObject, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	handled, ok := env.Get("$!")
+	if !ok {
+		handled = object.NIL
+	}
+	env.SetGlobal("$!", exc)
+	defer env.SetGlobal("$!", handled)
+
+	if rescue.Exception != nil {
+		env.Set(rescue.Exception.Value, exc)
+	}
+	return e.evalBlockStatement(rescue.Body, env)
+}
+
+// evalCaseExpression evaluates the body of the first when clause matching the
+// subject of node, or its else clause if none matches
+func (e *evaluator) evalCaseExpression(node *ast.CaseExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	var subject object.RubyObject
+	if node.Subject != nil {
+		var err error
+		subject, err = e.Eval(node.Subject, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval case subject")
+		}
+	}
+	for _, when := range node.Whens {
+		matches, err := e.whenMatches(when, subject, env)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			return e.evalBlockStatement(when.Body, env)
+		}
+	}
+	if node.Else != nil {
+		return e.evalBlockStatement(node.Else, env)
+	}
+	return object.NIL, nil
+}
+
+// whenMatches returns true if one of the values of when matches subject, i.e.
+// value === subject is truthy. Without a subject the values are tested for
+// truthiness themselves.
+func (e *evaluator) whenMatches(when *ast.WhenClause, subject object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	for _, expr := range when.Values {
+		value, err := e.Eval(expr, env)
+		if err != nil {
+			return false, errors.WithMessage(err, "eval when clause value")
+		}
+		if subject == nil {
+			if isTruthy(value) {
+				return true, nil
+			}
+			continue
+		}
+		matches, err := e.caseEqual(value, subject, env)
+		if err != nil {
+			return false, errors.WithMessage(err, "eval when clause ===")
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// caseEqual returns true if pattern === value is truthy
+func (e *evaluator) caseEqual(pattern, value object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	context := &callContext{object.NewCallContext(env, pattern), e}
+	result, err := object.Send(context, "===", e.tracer, value)
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(result), nil
+}
+
+// evalCaseMatchExpression evaluates the body of the first in clause whose
+// pattern matches the subject of node and whose guard holds. Without a
+// matching clause the else clause is evaluated, if there is none a
+// NoMatchingPatternError is raised.
+func (e *evaluator) evalCaseMatchExpression(node *ast.CaseMatchExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	subject, err := e.Eval(node.Subject, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval case subject")
+	}
+	for _, in := range node.Ins {
+		matches, err := e.matchPattern(in.Pattern, subject, env)
+		if err != nil {
+			return nil, err
+		}
+		if matches && in.Guard != nil {
+			guard, err := e.Eval(in.Guard, env)
+			if err != nil {
+				return nil, errors.WithMessage(err, "eval in clause guard")
+			}
+			matches = isTruthy(guard) != in.Unless
+		}
+		if matches {
+			return e.evalBlockStatement(in.Body, env)
+		}
+	}
+	if node.Else != nil {
+		return e.evalBlockStatement(node.Else, env)
+	}
+	return nil, errors.WithStack(object.NewNoMatchingPatternError(subject))
+}
+
+// evalMatchExpression evaluates `value => pattern`, which raises a
+// NoMatchingPatternError unless value matches, and `value in pattern`, which
+// returns whether it does
+func (e *evaluator) evalMatchExpression(node *ast.MatchExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	value, err := e.Eval(node.Value, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval pattern match value")
+	}
+	matches, err := e.matchPattern(node.Pattern, value, env)
+	if err != nil {
+		return nil, err
+	}
+	if node.Required {
+		if !matches {
+			return nil, errors.WithStack(object.NewNoMatchingPatternError(value))
 		}
+		return object.NIL, nil
+	}
+	if matches {
+		return object.TRUE, nil
+	}
+	return object.FALSE, nil
+}
 
-		// replace the match with the value
-		value = strings.Replace(value, value[match[0]:match[1]], val_str, 1)
+// matchPattern returns true if value matches pattern. Variables bound by the
+// pattern are assigned within env as matching proceeds, so a failed match may
+// leave some of them assigned, as it does in MRI.
+func (e *evaluator) matchPattern(pattern ast.Pattern, value object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	switch pattern := pattern.(type) {
+	case *ast.ValuePattern:
+		expected, err := e.Eval(pattern.Value, env)
+		if err != nil {
+			return false, errors.WithMessage(err, "eval value pattern")
+		}
+		return e.caseEqual(expected, value, env)
+	case *ast.PinPattern:
+		expected, err := e.Eval(pattern.Value, env)
+		if err != nil {
+			return false, errors.WithMessage(err, "eval pin pattern")
+		}
+		return e.caseEqual(expected, value, env)
+	case *ast.VariablePattern:
+		env.Set(pattern.Name.Value, value)
+		return true, nil
+	case *ast.CapturePattern:
+		matches, err := e.matchPattern(pattern.Pattern, value, env)
+		if err != nil || !matches {
+			return false, err
+		}
+		env.Set(pattern.Name.Value, value)
+		return true, nil
+	case *ast.AlternativePattern:
+		for _, alternative := range pattern.Alternatives {
+			matches, err := e.matchPattern(alternative, value, env)
+			if err != nil || matches {
+				return matches, err
+			}
+		}
+		return false, nil
+	case *ast.ArrayPattern:
+		return e.matchArrayPattern(pattern, value, env)
+	case *ast.FindPattern:
+		return e.matchFindPattern(pattern, value, env)
+	case *ast.HashPattern:
+		return e.matchHashPattern(pattern, value, env)
+	default:
+		return false, errors.WithStack(
+			object.NewSyntaxError(fmt.Errorf("unexpected pattern %s", pattern.Code())),
+		)
+	}
+}
+
+// matchConstant returns true if the constant of an array, find or hash
+// pattern is === to value. Patterns without a constant match any value.
+func (e *evaluator) matchConstant(constant ast.Expression, value object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	if constant == nil {
+		return true, nil
+	}
+	expected, err := e.Eval(constant, env)
+	if err != nil {
+		return false, errors.WithMessage(err, "eval pattern constant")
+	}
+	return e.caseEqual(expected, value, env)
+}
+
+// deconstruct returns the elements an array or find pattern is matched
+// against. Objects other than arrays take part through their deconstruct
+// method, and do not match if they have none.
+func (e *evaluator) deconstruct(value object.RubyObject, env object.Environment) ([]object.RubyObject, bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	if arr, ok := value.(*object.Array); ok {
+		return arr.Elements, true, nil
+	}
+	if !object.RespondTo(value, "deconstruct") {
+		return nil, false, nil
+	}
+	context := &callContext{object.NewCallContext(env, value), e}
+	result, err := object.Send(context, "deconstruct", e.tracer)
+	if err != nil {
+		return nil, false, err
+	}
+	arr, ok := result.(*object.Array)
+	if !ok {
+		return nil, false, errors.WithStack(object.NewTypeError("deconstruct must return Array"))
+	}
+	return arr.Elements, true, nil
+}
+
+func (e *evaluator) matchArrayPattern(pattern *ast.ArrayPattern, value object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	matches, err := e.matchConstant(pattern.Constant, value, env)
+	if err != nil || !matches {
+		return false, err
+	}
+	elements, ok, err := e.deconstruct(value, env)
+	if err != nil || !ok {
+		return false, err
+	}
+	fixed := len(pattern.Pre) + len(pattern.Post)
+	if len(elements) < fixed || (pattern.Rest == nil && len(elements) != fixed) {
+		return false, nil
+	}
+	rest := elements[len(pattern.Pre) : len(elements)-len(pattern.Post)]
+	if matches, err := e.matchPatterns(pattern.Pre, elements[:len(pattern.Pre)], env); err != nil || !matches {
+		return false, err
+	}
+	if pattern.Rest != nil && pattern.Rest.Name != nil {
+		env.Set(pattern.Rest.Name.Value, object.NewArray(rest...))
+	}
+	return e.matchPatterns(pattern.Post, elements[len(elements)-len(pattern.Post):], env)
+}
+
+func (e *evaluator) matchFindPattern(pattern *ast.FindPattern, value object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	matches, err := e.matchConstant(pattern.Constant, value, env)
+	if err != nil || !matches {
+		return false, err
+	}
+	elements, ok, err := e.deconstruct(value, env)
+	if err != nil || !ok {
+		return false, err
+	}
+	for i := 0; i+len(pattern.Middle) <= len(elements); i++ {
+		end := i + len(pattern.Middle)
+		matches, err := e.matchPatterns(pattern.Middle, elements[i:end], env)
+		if err != nil {
+			return false, err
+		}
+		if !matches {
+			continue
+		}
+		if pattern.Pre.Name != nil {
+			env.Set(pattern.Pre.Name.Value, object.NewArray(elements[:i]...))
+		}
+		if pattern.Post.Name != nil {
+			env.Set(pattern.Post.Name.Value, object.NewArray(elements[end:]...))
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// matchPatterns matches each of values against the pattern at the same index
+func (e *evaluator) matchPatterns(patterns []ast.Pattern, values []object.RubyObject, env object.Environment) (bool, error) {
+	for i, pattern := range patterns {
+		matches, err := e.matchPattern(pattern, values[i], env)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (e *evaluator) matchHashPattern(pattern *ast.HashPattern, value object.RubyObject, env object.Environment) (bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	matches, err := e.matchConstant(pattern.Constant, value, env)
+	if err != nil || !matches {
+		return false, err
+	}
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		if !object.RespondTo(value, "deconstruct_keys") {
+			return false, nil
+		}
+		context := &callContext{object.NewCallContext(env, value), e}
+		result, err := object.Send(context, "deconstruct_keys", e.tracer, object.NIL)
+		if err != nil {
+			return false, err
+		}
+		if hash, ok = result.(*object.Hash); !ok {
+			return false, errors.WithStack(object.NewTypeError("deconstruct_keys must return Hash"))
+		}
+	}
+	// `in {}` only matches empty hashes
+	if len(pattern.Pairs) == 0 && pattern.Rest == nil && len(hash.Map) > 0 {
+		return false, nil
+	}
+	rest := &object.Hash{}
+	for _, pair := range hash.Map {
+		rest.Set(pair.Key, pair.Value)
+	}
+	for _, pair := range pattern.Pairs {
+		key := object.NewSymbol(pair.Key.Value)
+		element, ok := hash.Get(key)
+		if !ok {
+			return false, nil
+		}
+		delete(rest.Map, key.HashKey())
+		if pair.Value == nil {
+			env.Set(pair.Key.Value, element)
+			continue
+		}
+		matches, err := e.matchPattern(pair.Value, element, env)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+	if pattern.NoRest && len(rest.Map) > 0 {
+		return false, nil
+	}
+	if pattern.Rest != nil && pattern.Rest.Name != nil {
+		env.Set(pattern.Rest.Name.Value, rest)
+	}
+	return true, nil
+}
+
+func (e *evaluator) evalClassExpression(node *ast.ClassExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	var superClass object.RubyObject
+	if node.SuperClass != nil {
+		var err error
+		superClass, err = e.Eval(node.SuperClass, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval class superclass")
+		}
+	}
+	class, err := object.OpenClass(constantScope(env), node.Name.Value, superClass)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	e.stack.Push("<class:"+node.Name.Value+">", "")
+	defer e.stack.Pop()
+	classEnv := object.NewEnclosedEnvironment(env)
+	classEnv.Set("self", class)
+	result, err := e.Eval(node.Body, classEnv)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval class body")
+	}
+	return result, nil
+}
+
+func (e *evaluator) evalModuleExpression(node *ast.ModuleExpression, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	module, err := object.OpenModule(constantScope(env), node.Name.Value)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	e.stack.Push("<module:"+node.Name.Value+">", "")
+	defer e.stack.Pop()
+	moduleEnv := object.NewEnclosedEnvironment(env)
+	moduleEnv.Set("self", module)
+	result, err := e.Eval(node.Body, moduleEnv)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval module body")
+	}
+	return result, nil
+}
+
+func (e *evaluator) evalScopedConstant(node *ast.ScopedConstant, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+		e.tracer.Message(node.Name.Value)
+	}
+	if node.Scope == nil {
+		top := env
+		for top.Outer() != nil {
+			top = top.Outer()
+		}
+		if value, ok := top.Get(node.Name.Value); ok {
+			return value, nil
+		}
+		return nil, errors.Wrap(
+			object.NewUninitializedConstantNameError(node.Name.Value),
+			"eval top level constant",
+		)
+	}
+	scope, err := e.Eval(node.Scope, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval constant scope")
+	}
+	value, err := object.ScopedConstant(scope, node.Name.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "eval scoped constant")
+	}
+	return value, nil
+}
+
+func (e *evaluator) evalSuper(node *ast.Super, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-
-	return value, nil
+	args, err := e.evalArguments(node.Arguments, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval super arguments")
+	}
+	context := &callContext{object.NewCallContext(env, selfOf(env)), e}
+	// super passes on the block of the current method
+	if block := e.stack.Top().Block; block != nil {
+		args = append(args, block)
+		return object.Super(&blockContext{context, block}, e.tracer, !node.ExplicitArguments, args...)
+	}
+	return object.Super(context, e.tracer, !node.ExplicitArguments, args...)
 }
 
-func (e *evaluator) evalLoopExpression(node *ast.LoopExpression, env object.Environment) (object.RubyObject, error) {
+func (e *evaluator) evalYield(node *ast.Yield, env object.Environment) (object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-	// condition, err := e.Eval(node.Condition, env)
-	// if err != nil {
-	// return nil, errors.WithMessage(err, "eval loop condition")
-	// }
-	for {
-		value, err := e.evalBlockStatement(node.Block, env)
-		if err != nil {
-			return nil, errors.WithMessage(err, "eval loop body")
-		}
-		if value != nil {
-			switch value := value.(type) {
-			case *object.BreakValue:
-				if isTruthy(value.Value) {
-					return value.Value, nil
-				}
-			case *object.ReturnValue:
-				return value.Value, nil
-			default:
-				// <shrug> do nothing
-			}
-		}
-		// condition, err = e.Eval(node.Condition, env)
-		// if err != nil {
-		// 	return nil, errors.WithMessage(err, "eval loop condition")
-		// }
+	block := e.stack.Top().Block
+	if block == nil {
+		return nil, errors.WithStack(object.NewLocalJumpError("no block given (yield)"))
+	}
+	args, err := e.evalArguments(node.Arguments, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval yield arguments")
 	}
+	context := &callContext{object.NewCallContext(env, object.FUNCS_STORE), e}
+	return block.Call(context, e.tracer, args...)
 }
 
 func (e *evaluator) evalProgram(statements []ast.Statement, env object.Environment) (object.RubyObject, error) {
@@ -296,6 +1053,27 @@ func (e *evaluator) evalExpressions(expressions []ast.Expression, env object.Env
 	return result, nil
 }
 
+// evalArguments evaluates the arguments of a call. A trailing hash written
+// without braces holds the keyword arguments.
+func (e *evaluator) evalArguments(arguments []ast.Expression, env object.Environment) ([]object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	args, err := e.evalExpressions(arguments, env)
+	if err != nil {
+		return nil, err
+	}
+	if len(arguments) == 0 {
+		return args, nil
+	}
+	if literal, ok := arguments[len(arguments)-1].(*ast.HashLiteral); ok && literal.Braceless {
+		if hash, ok := args[len(args)-1].(*object.Hash); ok {
+			args[len(args)-1] = object.KeywordArguments(hash)
+		}
+	}
+	return args, nil
+}
+
 func (e *evaluator) evalArrayElements(elements []ast.Expression, env object.Environment) ([]object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
@@ -332,7 +1110,7 @@ func (e *evaluator) evalArrayElements(elements []ast.Expression, env object.Envi
 	return result, nil
 }
 
-func (e *evaluator) evalPrefixExpression(operator string, right object.RubyObject) (object.RubyObject, error) {
+func (e *evaluator) evalPrefixExpression(operator string, right object.RubyObject, env object.Environment) (object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
@@ -340,7 +1118,10 @@ func (e *evaluator) evalPrefixExpression(operator string, right object.RubyObjec
 	case "!":
 		return e.evalBangOperatorExpression(right), nil
 	case "-":
-		return e.evalMinusPrefixOperatorExpression(right)
+		return e.evalMinusPrefixOperatorExpression(right, env)
+	case "~":
+		context := &callContext{object.NewCallContext(env, right), e}
+		return object.Send(context, operator, e.tracer)
 	default:
 		return nil, errors.WithStack(object.NewException("unknown operator: %s%s", operator, object.RubyObjectToTypeString(right)))
 	}
@@ -362,14 +1143,20 @@ func (e *evaluator) evalBangOperatorExpression(right object.RubyObject) object.R
 	}
 }
 
-func (e *evaluator) evalMinusPrefixOperatorExpression(right object.RubyObject) (object.RubyObject, error) {
+func (e *evaluator) evalMinusPrefixOperatorExpression(right object.RubyObject, env object.Environment) (object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
 	switch right := right.(type) {
 	case *object.Integer:
-		return object.NewInteger(-right.Value), nil
+		return right.Negate(), nil
+	case *object.Float:
+		return object.NewFloat(-right.Value), nil
 	default:
+		if object.RespondTo(right, "-@") {
+			context := &callContext{object.NewCallContext(env, right), e}
+			return object.Send(context, "-@", e.tracer)
+		}
 		return nil, errors.WithStack(object.NewException("unknown operator: -%s", object.RubyObjectToTypeString(right)))
 	}
 }
@@ -445,6 +1232,20 @@ func (e *evaluator) evalDefaultIndexExpression(left, index object.RubyObject) (o
 }
 
 func (e *evaluator) evalSymbolIndexExpression(env object.Environment, target *object.Symbol, index ast.Expression) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	args, err := e.evalIndexArguments(env, index)
+	if err != nil {
+		return nil, err
+	}
+	callContext := &callContext{object.NewCallContext(env, object.FUNCS_STORE), e}
+	return object.Send(callContext, target.Value, e.tracer, args...)
+}
+
+// evalIndexArguments evaluates the index of a function, proc or method being
+// called through `[]` into the arguments of the call
+func (e *evaluator) evalIndexArguments(env object.Environment, index ast.Expression) ([]object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
@@ -468,7 +1269,7 @@ func (e *evaluator) evalSymbolIndexExpression(env object.Environment, target *ob
 			)
 		}
 
-		// call the proc with the splat arguments
+		// the elements of the splat are the arguments
 		args := make([]object.RubyObject, len(arrObj.Elements))
 		for i, e := range arrObj.Elements {
 			if e == nil {
@@ -477,17 +1278,7 @@ func (e *evaluator) evalSymbolIndexExpression(env object.Environment, target *ob
 				args[i] = e
 			}
 		}
-		// printable_args := make([]string, len(args))
-		// for i, e := range args {
-		// 	if e == nil {
-		// 		printable_args[i] = "nil"
-		// 	} else {
-		// 		printable_args[i] = e.Inspect()
-		// 	}
-		// }
-		callContext := &callContext{object.NewCallContext(env, object.FUNCS_STORE), e}
-		value, err := object.Send(callContext, target.Value, e.tracer, args...)
-		return value, err
+		return args, nil
 
 	case ast.ExpressionList:
 		// evaluate the expression list
@@ -512,10 +1303,7 @@ func (e *evaluator) evalSymbolIndexExpression(env object.Environment, target *ob
 				args[i] = e
 			}
 		}
-		// printable_args := make([]string, len(args))
-		callContext := &callContext{object.NewCallContext(env, object.FUNCS_STORE), e}
-		value, err := object.Send(callContext, target.Value, e.tracer, args...)
-		return value, err
+		return args, nil
 
 	default:
 		evaluated, err := e.Eval(index, env)
@@ -529,17 +1317,7 @@ func (e *evaluator) evalSymbolIndexExpression(env object.Environment, target *ob
 			)
 		}
 
-		// call the proc with the arguments
-		args := make([]object.RubyObject, 1)
-		if evaluated == nil {
-			args[0] = object.NIL
-		} else {
-			args[0] = evaluated
-		}
-
-		callContext := &callContext{object.NewCallContext(env, object.FUNCS_STORE), e}
-		value, err := object.Send(callContext, target.Value, e.tracer, args...)
-		return value, err
+		return []object.RubyObject{evaluated}, nil
 	}
 }
 
@@ -728,10 +1506,10 @@ func (e *evaluator) evalBlockStatement(block *ast.BlockStatement, env object.Env
 			switch result := result.(type) {
 			case *object.ReturnValue:
 				return result, nil
-			case *object.BreakValue:
-				if isTruthy(result.Value) {
-					return result, nil
-				}
+			case *object.BreakValue, *object.NextValue, *object.RedoValue:
+				return result, nil
+			case *object.RetryValue:
+				return result, nil
 			}
 		}
 	}
@@ -747,6 +1525,16 @@ func (e *evaluator) evalIdentifier(node *ast.Identifier, env object.Environment)
 		e.tracer.Message(node.Value)
 	}
 
+	if node.IsInstanceVariable() {
+		return object.InstanceVariableGet(selfOf(env), node.Value), nil
+	}
+
+	if node.IsConstant() {
+		if val, ok := object.LookupConstant(lexicalModules(env), node.Value); ok {
+			return val, nil
+		}
+	}
+
 	val, ok := env.Get(node.Value)
 	if ok {
 		return val, nil
@@ -760,20 +1548,74 @@ func (e *evaluator) evalIdentifier(node *ast.Identifier, env object.Environment)
 	}
 
 	if node.IsGlobal() {
+		if n, err := strconv.Atoi(node.Value[1:]); err == nil {
+			return nthMatch(env, n), nil
+		}
 		return object.NIL, nil
 	}
 
 	// maybe a function
-	// fmt.Println("ident", node)
-	context := &callContext{object.NewCallContext(env, object.FUNCS_STORE), e}
-	val, err := object.Send(context, node.Value, e.tracer)
-	if err != nil {
+	receiver := implicitReceiver(env, node.Value)
+	if !object.RespondTo(receiver, node.Value) {
 		return nil, errors.Wrap(
-			object.NewNoMethodError(object.FUNCS_STORE, node.Value),
+			object.NewNoMethodError(receiver, node.Value),
 			"eval ident as method call",
 		)
 	}
-	return val, nil
+	context := &callContext{object.NewCallContext(env, receiver), e}
+	return object.Send(context, node.Value, e.tracer)
+}
+
+// selfOf returns the object self refers to within env. At the top level this
+// is the object holding the top level functions.
+func selfOf(env object.Environment) object.RubyObject {
+	if self, ok := env.Get("self"); ok {
+		return self
+	}
+	return object.FUNCS_STORE
+}
+
+// lexicalModules returns the classes and modules whose bodies lexically
+// enclose env, innermost first
+func lexicalModules(env object.Environment) []object.RubyObject {
+	var modules []object.RubyObject
+	for ; env != nil; env = env.Outer() {
+		self, ok := env.Get("self")
+		if !ok {
+			break
+		}
+		if _, ok := self.(object.RubyClassObject); !ok {
+			continue
+		}
+		// enclosed environments share the self of their outer one
+		if len(modules) > 0 && modules[len(modules)-1] == self {
+			continue
+		}
+		modules = append(modules, self)
+	}
+	return modules
+}
+
+// constantScope returns the environment constants defined within env are
+// stored in. This is the innermost class or module enclosing env, if any.
+func constantScope(env object.Environment) object.Environment {
+	for _, module := range lexicalModules(env) {
+		if scope, ok := module.(object.Environment); ok {
+			return scope
+		}
+	}
+	return env
+}
+
+// implicitReceiver returns the receiver of a call of method without an
+// explicit one. This is self, unless self does not respond to method but a
+// top level function of that name exists.
+func implicitReceiver(env object.Environment, method string) object.RubyObject {
+	self := selfOf(env)
+	if !object.RespondTo(self, method) && object.RespondTo(object.FUNCS_STORE, method) {
+		return object.FUNCS_STORE
+	}
+	return self
 }
 
 func isTruthy(obj object.RubyObject) bool {
@@ -819,10 +1661,13 @@ func (e *evaluator) evalReturnStatement(node *ast.ReturnStatement, env object.En
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-	val, err := e.Eval(node.ReturnValue, env)
+	val, jump, err := e.evalJump(node.ReturnValue, node.Condition, node.Unless, env)
 	if err != nil {
 		return nil, errors.WithMessage(err, "eval of return statement")
 	}
+	if !jump {
+		return object.NIL, nil
+	}
 	return &object.ReturnValue{Value: val}, nil
 }
 
@@ -830,52 +1675,228 @@ func (e *evaluator) evalBreakStatement(node *ast.BreakStatement, env object.Envi
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-	var val object.RubyObject
-	val, err := e.Eval(node.Condition, env)
+	value, jump, err := e.evalJump(node.Value, node.Condition, node.Unless, env)
 	if err != nil {
 		return nil, errors.WithMessage(err, "eval of break statement")
 	}
-	if node.Unless {
-		if isTruthy(val) {
-			val = object.FALSE
-		} else {
-			val = object.TRUE
+	if !jump {
+		return object.NIL, nil
+	}
+	return &object.BreakValue{Value: value}, nil
+}
+
+func (e *evaluator) evalNextStatement(node *ast.NextStatement, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	value, jump, err := e.evalJump(node.Value, node.Condition, node.Unless, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval of next statement")
+	}
+	if !jump {
+		return object.NIL, nil
+	}
+	return &object.NextValue{Value: value}, nil
+}
+
+// evalJump evaluates the modifier condition of a return, break, next or redo
+// statement and, if the jump is taken, its value. A missing value is nil.
+func (e *evaluator) evalJump(valueNode, conditionNode ast.Expression, unless bool, env object.Environment) (object.RubyObject, bool, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	if conditionNode != nil {
+		condition, err := e.Eval(conditionNode, env)
+		if err != nil {
+			return nil, false, err
 		}
+		if isTruthy(condition) == unless {
+			return nil, false, nil
+		}
+	}
+	if valueNode == nil {
+		return object.NIL, true, nil
 	}
-	return &object.BreakValue{Value: val}, nil
+	value, err := e.Eval(valueNode, env)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
 }
 
 func (e *evaluator) evalStringLiteral(node *ast.StringLiteral, env object.Environment) (object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-	value := unescapeStringLiteral(node)
-	value, err := e.evalFormatDirectives(env, value)
+	return object.NewString(node.Value), nil
+}
+
+// evalInterpolatedString joins the literals of node with the results of its
+// embedded code, which are converted with to_s
+func (e *evaluator) evalInterpolatedString(node *ast.InterpolatedString, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	var out strings.Builder
+	for i, literal := range node.Literals {
+		out.WriteString(literal.Value)
+		if i == len(node.Embedded) {
+			break
+		}
+		value, err := e.Eval(node.Embedded[i], env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval interpolated string")
+		}
+		str, err := e.evalToS(value, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval interpolated string")
+		}
+		out.WriteString(str)
+	}
+	if node.Symbol {
+		return object.NewSymbol(out.String()), nil
+	}
+	return object.NewString(out.String()), nil
+}
+
+// evalRegexLiteral compiles the regex literal node. Unlike in strings, escape
+// sequences are left to the regular expression, and embedded regexps keep
+// their options.
+func (e *evaluator) evalRegexLiteral(node *ast.RegexLiteral, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	var source strings.Builder
+	for i, literal := range node.Pattern.Literals {
+		source.WriteString(literal.Value)
+		if i == len(node.Pattern.Embedded) {
+			break
+		}
+		value, err := e.Eval(node.Pattern.Embedded[i], env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval interpolated regex")
+		}
+		if re, ok := value.(*object.Regexp); ok {
+			source.WriteString(re.String())
+			continue
+		}
+		str, err := e.evalToS(value, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval interpolated regex")
+		}
+		source.WriteString(str)
+	}
+	re, err := object.NewRegexp(source.String(), object.RegexpOptions(node.Options))
 	if err != nil {
-		return nil, errors.WithMessage(err, "eval string literal")
+		return nil, errors.WithStack(err)
 	}
-	return object.NewString(value), nil
+	return re, nil
 }
 
-func (e *evaluator) evalFunctionLiteral(node *ast.FunctionLiteral, env object.Environment) (object.RubyObject, error) {
+// nthMatch returns the group n of the last match, as referenced by `$1` to
+// `$9`, or nil if there is none
+func nthMatch(env object.Environment, n int) object.RubyObject {
+	last, _ := env.Get("$~")
+	match, ok := last.(*object.MatchData)
+	if !ok || !match.Matched(n) {
+		return object.NIL
+	}
+	return object.NewString(match.Group(n))
+}
+
+// evalToS converts value with its to_s method. If to_s does not return a
+// String the default representation of value is used instead, as Ruby does.
+func (e *evaluator) evalToS(value object.RubyObject, env object.Environment) (string, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
-	// context, _ := env.Get("bottom")
-	// construct a function object and stick it onto self
-	params := make([]*object.FunctionParameter, len(node.Parameters))
-	for i, param := range node.Parameters {
+	if value == nil {
+		// empty embedded code, e.g. `"#{}"`
+		return "", nil
+	}
+	if str, ok := value.(*object.String); ok {
+		return str.Value, nil
+	}
+	result, err := object.Send(&callContext{object.NewCallContext(env, value), e}, "to_s", e.tracer)
+	if err != nil {
+		return "", err
+	}
+	if str, ok := result.(*object.String); ok {
+		return str.Value, nil
+	}
+	return fmt.Sprintf("#<%s>", value.Class().Name()), nil
+}
+
+// evalFunctionParameters evaluates the defaults of params, including those of
+// the nested parameters of destructuring ones
+func (e *evaluator) evalFunctionParameters(params []*ast.FunctionParameter, env object.Environment) ([]*object.FunctionParameter, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	evaluated := make([]*object.FunctionParameter, len(params))
+	for i, param := range params {
 		def, err := e.Eval(param.Default, env)
 		if err != nil {
 			return nil, errors.WithMessage(err, "eval function literal param")
 		}
-		params[i] = &object.FunctionParameter{Name: param.Name, Default: def, Splat: param.Splat}
+		var nested []*object.FunctionParameter
+		if len(param.Destructure) != 0 {
+			nested, err = e.evalFunctionParameters(param.Destructure, env)
+			if err != nil {
+				return nil, err
+			}
+		}
+		evaluated[i] = &object.FunctionParameter{
+			Name:        param.Name,
+			Default:     def,
+			Splat:       param.Splat,
+			Block:       param.Block,
+			Keyword:     param.Keyword,
+			DoubleSplat: param.DoubleSplat,
+			Destructure: nested,
+		}
+	}
+	return evaluated, nil
+}
+
+func (e *evaluator) evalFunctionLiteral(node *ast.FunctionLiteral, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	// context, _ := env.Get("bottom")
+	// construct a function object and stick it onto self
+	params, err := e.evalFunctionParameters(node.Parameters, env)
+	if err != nil {
+		return nil, err
 	}
 	function := &object.Function{
 		Name:       node.Name,
 		Parameters: params,
 		Env:        env,
 		Body:       node.Body,
+		Scope:      e.stack.Top().Label(),
+		Home:       e.stack.MethodFrame(),
+	}
+	if node.Receiver != nil {
+		receiver, err := e.Eval(node.Receiver, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval function literal receiver")
+		}
+		if err := object.AddSingletonMethod(receiver, node.Name, function); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return object.NewSymbol(node.Name), nil
+	}
+	// lambdas are objects of their own, whereas blocks are looked up by name
+	// by the call they are attached to
+	if function.IsLambda() {
+		return object.NewProc(function), nil
+	}
+	// methods defined within a class body belong to the class. Blocks are
+	// always stored with the top level functions.
+	if class, ok := selfOf(env).(object.RubyClassObject); ok && !function.IsAnonymous() {
+		object.AddMethod(class, node.Name, function)
+		return object.NewSymbol(node.Name), nil
 	}
 	_, extended := object.AddMethod(object.FUNCS_STORE, node.Name, function)
 	if extended {
@@ -905,6 +1926,19 @@ func (e *evaluator) evalHashLiteral(node *ast.HashLiteral, env object.Environmen
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
 	var hash object.Hash
+	for _, splat := range node.Splats {
+		value, err := e.Eval(splat, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval hash splat")
+		}
+		merged, ok := value.(*object.Hash)
+		if !ok {
+			return nil, errors.WithStack(object.NewImplicitConversionTypeError(&hash, value))
+		}
+		for key, value := range merged.ObjectMap() {
+			hash.Set(key, value)
+		}
+	}
 	for k, v := range node.Map {
 		key, err := e.Eval(k, env)
 		if err != nil {
@@ -943,8 +1977,16 @@ func (e *evaluator) evalAssignment(node *ast.Assignment, env object.Environment)
 	if err != nil {
 		return nil, errors.WithMessage(err, "eval right hand Assignment side")
 	}
+	return e.assign(node.Left, right, env)
+}
 
-	switch left := node.Left.(type) {
+// assign binds right to the assignment target left, e.g. a variable, an
+// attribute, an index or a list of those
+func (e *evaluator) assign(target ast.Expression, right object.RubyObject, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	switch left := target.(type) {
 	case *ast.IndexExpression:
 		indexLeft, err := e.Eval(left.Left, env)
 		if err != nil {
@@ -955,73 +1997,72 @@ func (e *evaluator) evalAssignment(node *ast.Assignment, env object.Environment)
 			return nil, errors.WithMessage(err, "eval left hand Assignment side: eval right side of IndexExpression")
 		}
 		return e.evalIndexExpressionAssignment(indexLeft, index, expandToArrayIfNeeded(right))
+	case *ast.ContextCallExpression:
+		right = expandToArrayIfNeeded(right)
+		receiver, err := e.Eval(left.Context, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval left hand Assignment side: eval attribute receiver")
+		}
+		context := &callContext{object.NewCallContext(env, receiver), e}
+		if _, err := object.Send(context, left.Function+"=", e.tracer, right); err != nil {
+			return nil, err
+		}
+		return right, nil
 	case *ast.Identifier:
 		right = expandToArrayIfNeeded(right)
-		if left.IsGlobal() {
+		if left.IsConstant() {
+			object.SetClassName(right, left.Value)
+			constantScope(env).Set(left.Value, right)
+			return right, nil
+		}
+		if left.IsInstanceVariable() {
+			if err := object.InstanceVariableSet(selfOf(env), left.Value, right); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		} else if left.IsGlobal() {
 			env.SetGlobal(left.Value, right)
 		} else {
 			env.Set(left.Value, right)
 		}
 		return right, nil
 	case ast.ExpressionList:
-
-		// // make sure all the left hand side expressions are identifiers
-		// // this might not end up being true int he future. -MK
-
-		// left_ids := make([]*ast.Identifier, len(left))
-		// for i, exp := range left {
-		// 	if id, ok := exp.(*ast.Identifier); !ok {
-		// 		return nil, errors.WithStack(
-		// 			object.NewSyntaxError(fmt.Errorf("assignment not supported to %T", exp)),
-		// 		)
-		// 	} else {
-		// 		left_ids[i] = id
-		// 	}
-		// }
-
-		var values rubyObjects
-		switch right := right.(type) {
-		case rubyObjects:
-			values = right
-		case *object.Array:
-			values = right.Elements
-		default:
-			values = []object.RubyObject{right}
-		}
-		if len(left) > len(values) {
-			// enlarge slice
-			for len(values) <= len(left) {
-				values = append(values, object.NIL)
+		// multiple assignment, e.g. `a, (b, c), *d = 1, [2, 3], 4, 5`
+		var values []object.RubyObject
+		if list, ok := right.(rubyObjects); ok {
+			values = list
+		} else {
+			context := &callContext{object.NewCallContext(env, right), e}
+			spread, err := object.ImplicitArray(context, e.tracer, right)
+			if err != nil {
+				return nil, errors.WithMessage(err, "eval multiple assignment")
 			}
+			values = spread
 		}
+		splat := -1
 		for i, exp := range left {
-			switch exp := exp.(type) {
-			case *ast.Identifier:
-				env.Set(exp.Value, values[i])
-			case *ast.Splat:
-				panic("splat in assignment not implemented yet")
-			case *ast.IndexExpression:
-				indexLeft, err := e.Eval(exp.Left, env)
-				if err != nil {
-					return nil, errors.WithMessage(err, "eval left hand Assignment side: eval left side of IndexExpression")
-				}
-				index, err := e.Eval(exp.Index, env)
-				if err != nil {
-					return nil, errors.WithMessage(err, "eval left hand Assignment side: eval right side of IndexExpression")
-				}
-				_, err = e.evalIndexExpressionAssignment(indexLeft, index, values[i])
-				if err != nil {
-					return nil, errors.WithMessage(err, "eval left hand Assignment side: eval right side of IndexExpression")
-				}
+			if _, ok := exp.(*ast.Splat); !ok {
 				continue
-			default:
-				panic("unexpected expression in assignment: " + fmt.Sprintf("%T", exp))
+			}
+			if splat >= 0 {
+				return nil, errors.WithStack(
+					object.NewSyntaxError(fmt.Errorf("multiple splats in assignment")),
+				)
+			}
+			splat = i
+		}
+		for i, value := range object.Distribute(values, len(left), splat) {
+			target := left[i]
+			if rest, ok := target.(*ast.Splat); ok {
+				target = rest.Value
+			}
+			if _, err := e.assign(target, value, env); err != nil {
+				return nil, errors.WithMessage(err, "eval multiple assignment")
 			}
 		}
 		return expandToArrayIfNeeded(right), nil
 	default:
 		return nil, errors.WithStack(
-			object.NewSyntaxError(fmt.Errorf("assignment not supported to %T", node.Left)),
+			object.NewSyntaxError(fmt.Errorf("assignment not supported to %T", target)),
 		)
 	}
 }
@@ -1036,26 +2077,91 @@ func (e *evaluator) evalContextCallExpression(node *ast.ContextCallExpression, e
 		return nil, errors.WithMessage(err, "eval method call receiver")
 	}
 	if context == nil {
-		// var ok bool
-		// context, ok = env.Get("bottom")
-		// if !ok {
-		// 	panic("no bottom class in the env")
-		// }
-		context = object.FUNCS_STORE
-	}
-	args, err := e.evalExpressions(node.Arguments, env)
+		context = implicitReceiver(env, node.Function)
+	}
+	arguments := node.Arguments
+	var blockPass *ast.BlockPass
+	if len(arguments) != 0 {
+		if pass, ok := arguments[len(arguments)-1].(*ast.BlockPass); ok {
+			arguments, blockPass = arguments[:len(arguments)-1], pass
+		}
+	}
+	args, err := e.evalArguments(arguments, env)
 	if err != nil {
 		return nil, errors.WithMessage(err, "eval method call arguments")
 	}
+	var block *object.Proc
+	var literal *object.Function
 	if node.Block != nil {
-		block, err := e.Eval(node.Block, env)
+		symbol, err := e.Eval(node.Block, env)
 		if err != nil {
 			return nil, errors.WithMessage(err, "eval method call block")
 		}
-		args = append(args, block)
+		args = append(args, symbol)
+		if name, ok := symbol.(*object.Symbol); ok {
+			if method, ok := object.FUNCS_STORE.GetMethod(name.Value); ok {
+				literal, _ = method.(*object.Function)
+			}
+		}
+		if literal != nil {
+			block = object.NewProc(literal)
+		}
+	}
+	if blockPass != nil {
+		block, err = e.evalBlockPass(blockPass, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval method call block argument")
+		}
+		if block != nil {
+			args = append(args, block)
+		}
 	}
 	callContext := &callContext{object.NewCallContext(env, context), e}
-	return object.Send(callContext, node.Function, e.tracer, args...)
+	if block == nil {
+		return object.Send(callContext, node.Function, e.tracer, args...)
+	}
+	if literal == nil {
+		return object.Send(&blockContext{callContext, block}, node.Function, e.tracer, args...)
+	}
+	// a break within the block ends the call
+	e.blocks = append(e.blocks, literal)
+	defer func() { e.blocks = e.blocks[:len(e.blocks)-1] }()
+	result, err := object.Send(&blockContext{callContext, block}, node.Function, e.tracer, args...)
+	if brk, ok := errors.Cause(err).(*object.BreakError); ok && brk.Function == literal {
+		return brk.Value, nil
+	}
+	return result, err
+}
+
+// evalBlockPass evaluates the argument passed as the block of a call and
+// converts it into a proc through to_proc. It returns nil for nil, i.e. no
+// block.
+func (e *evaluator) evalBlockPass(node *ast.BlockPass, env object.Environment) (*object.Proc, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	value, err := e.Eval(node.Value, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval block argument")
+	}
+	if value == object.NIL {
+		return nil, nil
+	}
+	if proc, ok := value.(*object.Proc); ok {
+		return proc, nil
+	}
+	if object.RespondTo(value, "to_proc") {
+		converted, err := object.Send(&callContext{object.NewCallContext(env, value), e}, "to_proc", e.tracer)
+		if err != nil {
+			return nil, err
+		}
+		if proc, ok := converted.(*object.Proc); ok {
+			return proc, nil
+		}
+	}
+	return nil, errors.WithStack(
+		object.NewTypeError(fmt.Sprintf("wrong argument type %s (expected Proc)", value.Class().Name())),
+	)
 }
 
 func (e *evaluator) evalIndexExpression(node *ast.IndexExpression, env object.Environment) (object.RubyObject, error) {
@@ -1073,6 +2179,29 @@ func (e *evaluator) evalIndexExpression(node *ast.IndexExpression, env object.En
 		// indexing them should call them
 		// NOTE: we pass unevaluated index to proc
 		return e.evalSymbolIndexExpression(env, left, node.Index)
+	case *object.Proc, *object.Method:
+		// indexing procs and methods calls them, just like #call
+		args, err := e.evalIndexArguments(env, node.Index)
+		if err != nil {
+			return nil, err
+		}
+		callContext := &callContext{object.NewCallContext(env, left), e}
+		return object.Send(callContext, "call", e.tracer, args...)
+	case *object.MatchData:
+		index, err := e.Eval(node.Index, env)
+		if err != nil {
+			return nil, errors.WithMessage(err, "eval IndexExpression index")
+		}
+		callContext := &callContext{object.NewCallContext(env, left), e}
+		return object.Send(callContext, "[]", e.tracer, index)
+	case *object.Integer:
+		// bit reference, e.g. `5[0]` or `5[1, 2]`
+		args, err := e.evalIndexArguments(env, node.Index)
+		if err != nil {
+			return nil, err
+		}
+		callContext := &callContext{object.NewCallContext(env, left), e}
+		return object.Send(callContext, "[]", e.tracer, args...)
 	default:
 		index, err := e.Eval(node.Index, env)
 		if err != nil {
@@ -1121,6 +2250,48 @@ func (e *evaluator) evalInfixExpression(node *ast.InfixExpression, env object.En
 	return object.Send(context, node.Operator.String(), e.tracer, right)
 }
 
+// evalLogicalAssignment evaluates `x ||= y` and `x &&= y`, which are
+// `x || x = y` and `x && x = y`
+func (e *evaluator) evalLogicalAssignment(node *ast.LogicalAssignment, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	left, err := e.evalLogicalAssignmentTarget(node, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval logical assignment target")
+	}
+	if isTruthy(left) == (node.Operator == infix.LOGICALOR) {
+		return left, nil
+	}
+	right, err := e.Eval(node.Right, env)
+	if err != nil {
+		return nil, errors.WithMessage(err, "eval right hand Assignment side")
+	}
+	return e.assign(node.Left, right, env)
+}
+
+// evalLogicalAssignmentTarget evaluates the left side of node. A constant
+// reads as nil until it is assigned, and a local variable is defined as nil,
+// as in Ruby.
+func (e *evaluator) evalLogicalAssignmentTarget(node *ast.LogicalAssignment, env object.Environment) (object.RubyObject, error) {
+	if e.tracer != nil {
+		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
+	}
+	ident, ok := node.Left.(*ast.Identifier)
+	if !ok || ident.IsInstanceVariable() || ident.IsGlobal() {
+		return e.Eval(node.Left, env)
+	}
+	if _, ok := env.Get(ident.Value); ok {
+		return e.Eval(node.Left, env)
+	}
+	if !ident.IsConstant() {
+		env.Set(ident.Value, object.NIL)
+	} else if val, ok := object.LookupConstant(lexicalModules(env), ident.Value); ok {
+		return val, nil
+	}
+	return object.NIL, nil
+}
+
 func (e *evaluator) evalRangeLiteral(node *ast.RangeLiteral, env object.Environment) (object.RubyObject, error) {
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
@@ -1183,6 +2354,9 @@ func (e *evaluator) evalIntegerLiteral(node *ast.IntegerLiteral, _ object.Enviro
 	if e.tracer != nil {
 		defer e.tracer.Un(e.tracer.Trace(trace.Here()))
 	}
+	if node.Big != nil {
+		return object.NewBigInteger(new(big.Int).Set(node.Big)), nil
+	}
 	return object.NewInteger(node.Value), nil
 }
 
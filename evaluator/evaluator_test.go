@@ -43,6 +43,30 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
 		{"5 % 2", 1},
+		{"0x1f + 0b11 + 0o7 + 010 + 0d10", 31 + 3 + 7 + 8 + 10},
+		{"1_000_000", 1000000},
+		{"-2.to_s.size", 2},
+		{"-1.5.to_s.size", 4},
+		{"-2 ** 2", -4},
+		{"(-2) ** 2", 4},
+		{"x = 3; x -1", 2},
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"12 ^ 10", 6},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"(-16) >> 2", -4},
+		{"16 << -2", 4},
+		{"1 | 2 ^ 3 & 6", 1},
+		{"5[0] + 5[1]", 1},
+		{"0b110110[1, 3]", 0b011},
+		{"255.bit_length", 8},
+		{"-7 / 2", -4},
+		{"-7 % 2", 1},
+		{"7 % -2", -1},
+		{"3.pow(4, 5)", 1},
+		{"2 ** 64 - 2 ** 64 + 1", 1},
+		{"18446744073709551616 / 4294967296", 4294967296},
 	}
 
 	for _, tt := range tests {
@@ -52,6 +76,218 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestBignums(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 ** 64", "18446744073709551616"},
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775808 - 1", "-9223372036854775809"},
+		{"-9223372036854775808", "-9223372036854775808"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"0xffff_ffff_ffff_ffff_ff", "4722366482869645213695"},
+		{"f = 1; i = 1; while i <= 25; f *= i; i += 1; end; f", "15511210043330985984000000"},
+		{"(2 ** 100).to_s(16)", "10000000000000000000000000"},
+		{"(2 ** 64).divmod(-7)", "[-2635249153387078803, -5]"},
+		{"h = {2 ** 70 => :big}; h[2 ** 70]", ":big"},
+		{"2 ** 64 == 18446744073709551616", ":true"},
+		{"(2 ** 64).class", "Integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, evaluated.Inspect(), tt.expected)
+		})
+	}
+}
+
+func TestNumericTower(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"3r", "(3/1)"},
+		{"1.5r", "(3/2)"},
+		{"2i", "(0+2i)"},
+		{"3ri", "(0+(3/1)*i)"},
+		{"def half(x); x / 2; end; half 3r", "(3/2)"},
+		{"1 + 2i", "(1+2i)"},
+		{"(1 + 2.5).to_s", "3.5"},
+		{"1 + Rational(1, 2)", "(3/2)"},
+		{"Rational(1, 3) * 3", "(1/1)"},
+		{"(Rational(1, 2) + 0.25).to_s", "0.75"},
+		{"Rational(6, -4)", "(-3/2)"},
+		{"-Rational(1, 2)", "(-1/2)"},
+		{"2 ** -2", "(1/4)"},
+		{"1.quo(3)", "(1/3)"},
+		{"0.75.to_r", "(3/4)"},
+		{"0.333.rationalize(Rational(1, 100))", "(1/3)"},
+		{"0.1.rationalize", "(1/10)"},
+		{"Rational(7, 2).divmod(Rational(1, 3))", "[10, (1/6)]"},
+		{"Complex(1, 2) * Complex(3, 4)", "(-5+10i)"},
+		{"Complex(1, 2) / Complex(3, 4)", "((11/25)+(2/25)*i)"},
+		{"Complex(4, 2) / Complex(2, 1)", "(2+0i)"},
+		{"Complex(1, 2) ** 2", "(-3+4i)"},
+		{"Complex(1, 2) ** -1", "((1/5)-(2/5)*i)"},
+		{"2i * 2i", "(-4+0i)"},
+		{"Complex(3, 4).abs.to_s", "5.0"},
+		{"Complex(3, 0).abs", "3"},
+		{"Complex(1, 2).conj", "(1-2i)"},
+		{"Complex(1.5, -2).to_s", "1.5-2i"},
+		{"Complex(1, 2).rectangular", "[1, 2]"},
+		{"1 < 2.5", ":true"},
+		{"Rational(1, 2) <=> 1", "-1"},
+		{"Rational(1, 2) == 0.5", ":true"},
+		{"1 == Complex(1, 0)", ":true"},
+		{"1.is_a?(Numeric)", ":true"},
+		{"Complex(1, 2).real?", ":false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, evaluated.Inspect(), tt.expected)
+		})
+	}
+
+	t.Run("user defined numbers", func(t *testing.T) {
+		input := `
+		class Meters < Numeric
+			def initialize(value)
+				@value = value
+			end
+			def value
+				@value
+			end
+			def coerce(other)
+				[Meters.new(other), self]
+			end
+			def +(other)
+				Meters.new(@value + other.value)
+			end
+			def <(other)
+				@value < other.value
+			end
+		end
+		[(5 + Meters.new(3)).value, 5 < Meters.new(6), Meters.new(1).is_a?(Numeric)]
+		`
+		evaluated, err := testEval(input, object.NewMainEnvironment())
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, evaluated.Inspect(), "[8, :true, :true]")
+	})
+	t.Run("objects without coerce", func(t *testing.T) {
+		_, err := testEval(`1 + "1"`, object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewCoercionTypeError(object.NewString("1"), object.NewInteger(1)))
+
+		_, err = testEval(`1 < "1"`, object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewArgumentError("comparison of Integer with String failed"))
+	})
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[Random.new(42).rand, Random.new(42).rand(100), Random.new(42).seed]", "[0.3745401188473625, 51, 42]"},
+		{"a = Random.new(7); b = Random.new(7); [a.rand(1000), a.rand] == [b.rand(1000), b.rand]", ":true"},
+		{"srand(1234); a = [rand, rand(10), rand(1..6)]; srand(1234) == 1234 && a == [rand, rand(10), rand(1..6)]", ":true"},
+		{"def reseed; srand(99); end; reseed; a = rand(1000); [1].each { srand(99) }; a == rand(1000)", ":true"},
+		{"[rand(5..1), rand(1.9), rand(-1)]", "[:nil, 0, 0]"},
+		{"[1, 2, 3, 4, 5].shuffle(random: Random.new(3)) == [1, 2, 3, 4, 5].shuffle(random: Random.new(3))", ":true"},
+		{"a = [1, 2, 3, 4, 5]; a.shuffle!(random: Random.new(3)); a.size", "5"},
+		{"[1, 2, 3, 4, 5, 6, 7, 8, 9, 10].sample(random: Random.new(42))", "7"},
+		{"[1, 2, 3, 4, 5, 6, 7, 8, 9, 10].sample(3, random: Random.new(42))", "[7, 4, 6]"},
+		{"[1, 2, 3].sample(5).size", "3"},
+		{"[].sample", ":nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, evaluated.Inspect(), tt.expected)
+		})
+	}
+
+	t.Run("srand is per interpreter", func(t *testing.T) {
+		seeded := object.NewMainEnvironment()
+		_, err := testEval("srand(1234)", seeded)
+		utils.AssertNoError(t, err)
+		evaluated, err := testEval("srand", object.NewMainEnvironment())
+		utils.AssertNoError(t, err)
+		utils.Assert(t, evaluated.Inspect() != "1234", "expected a fresh seed, got 1234")
+		evaluated, err = testEval("srand", seeded)
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, evaluated.Inspect(), "1234")
+	})
+	t.Run("unknown keyword", func(t *testing.T) {
+		_, err := testEval("[1, 2].shuffle(seed: 1)", object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewUnknownKeywordError(object.NewSymbol("seed")))
+	})
+	t.Run("custom generator out of range", func(t *testing.T) {
+		_, err := testEval("class Bad; def rand(n); n; end; end; [1, 2].shuffle(random: Bad.new)", object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewRangeError("random number too big 2"))
+	})
+}
+
+func TestNumericAPI(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a = []; 3.times { |i| a << i }; a", "[0, 1, 2]"},
+		{"a = []; 1.upto(3) { |i| a << i }; 3.downto(1) { |i| a << i }; a", "[1, 2, 3, 3, 2, 1]"},
+		{"a = []; 1.step(10, 3) { |i| a << i }; a", "[1, 4, 7, 10]"},
+		{"a = []; 1.0.step(2.0, 0.5) { |f| a << f }; a", "[1.0, 1.5, 2.0]"},
+		{"a = []; 1.step(0, -0.25) { |f| a << f }; a", "[1.0, 0.75, 0.5, 0.25, 0.0]"},
+		{"a = []; 1.step { |i| break if i > 3; a << i }; a", "[1, 2, 3]"},
+		{"a = []; 1.times { |i| a << i }", "1"},
+		{"1234.digits", "[4, 3, 2, 1]"},
+		{"[12.gcd(18), 4.lcm(6), -5.abs, 4.even?, 3.odd?, 0.zero?, 1.succ, 1.pred]", "[6, 12, 5, :true, :true, :true, 2, 0]"},
+		{"[2.pow(10), 3.pow(4, 5)]", "[1024, 1]"},
+		{"[3.14159.round(2), 2.5.round, 1234.5678.round(-2), 1.23456.floor(2), 1.23456.ceil(2), -1.5.truncate]", "[3.14, 3, 1200, 1.23, 1.24, -1]"},
+		{"[Float::INFINITY, -Float::INFINITY, Float::NAN.nan?, (1.0 / 0).infinite?, 1.0.infinite?, Float::EPSILON]", "[Infinity, -Infinity, :true, 1, :nil, 2.220446049250313e-16]"},
+		{"[1e20, 1.0e-5, 100.0, 1e15, 0.1 + 0.2]", "[1.0e+20, 1.0e-05, 100.0, 1000000000000000.0, 0.30000000000000004]"},
+		{"[1e20.to_s, (0.0 / 0).to_s]", `["1.0e+20", "NaN"]`},
+		{"[15.clamp(1, 10), 0.clamp(1, 10), 5.clamp(1..3), 2.5.clamp(1, 2), 2.5.between?(1, 3), 5.between?(1, 3)]", "[10, 1, 3, 2, :true, :false]"},
+		{"[Math.sqrt(16), Math.cbrt(27), Math.log(8, 2), Math.log2(8), Math.hypot(3, 4)]", "[4.0, 3.0, 3.0, 3.0, 5.0]"},
+		{"[Math::PI, Math::E, Math.sin(0), Math.cos(0), Math.exp(0), Math.atan2(1, 1) * 4]", "[3.141592653589793, 2.718281828459045, 0.0, 1.0, 1.0, 3.141592653589793]"},
+		{"begin; Math.sqrt(-1); rescue Math::DomainError => e; e.message; end", `Numerical argument is out of domain - "sqrt"`},
+		{"class Circle; include Math; def area(r); PI * r ** 2; end; def side(a); sqrt(a); end; end; [Circle.new.side(9)]", "[3.0]"},
+		{"1.5 <=> 2", "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, evaluated.Inspect(), tt.expected)
+		})
+	}
+
+	t.Run("clamp with reversed bounds", func(t *testing.T) {
+		_, err := testEval("5.clamp(3, 1)", object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewArgumentError("min argument must be less than or equal to max argument"))
+	})
+	t.Run("step of zero", func(t *testing.T) {
+		_, err := testEval("1.step(3, 0) { }", object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewArgumentError("step can't be 0"))
+	})
+	t.Run("round of NaN", func(t *testing.T) {
+		_, err := testEval("Float::NAN.round", object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewFloatDomainError("NaN"))
+	})
+	t.Run("infinity to an Integer", func(t *testing.T) {
+		_, err := testEval("Float::INFINITY.to_i", object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewFloatDomainError("Infinity"))
+	})
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -151,6 +387,8 @@ func TestReturnStatements(t *testing.T) {
 		{"return 10; 9;", 10},
 		{"return 2 * 5; 9;", 10},
 		{"9; return 2 * 5; 9;", 10},
+		{"return 10 if 1 > 2; 9;", 9},
+		{"return 10 unless 1 > 2; 9;", 10},
 		{`if 10 > 1
 			if 10 > 1
 				return 10
@@ -172,80 +410,1475 @@ func TestErrorHandling(t *testing.T) {
 		expectedMessage string
 	}{
 		{
-			"5 + true;",
-			"TypeError: Integer can't be coerced into Symbol",
+			"5 + true;",
+			"TypeError: Integer can't be coerced into Symbol",
+		},
+		{
+			"5 + true; 5;",
+			"TypeError: Integer can't be coerced into Symbol",
+		},
+		{
+			"-true",
+			"Exception: unknown operator: -Symbol",
+		},
+		{
+			"true + false;",
+			"NoMethodError: undefined method `+' for :true:Symbol",
+		},
+		{
+			"true + false + true + false;",
+			"NoMethodError: undefined method `+' for :true:Symbol",
+		},
+		{
+			"5; true + false; 5",
+			"NoMethodError: undefined method `+' for :true:Symbol",
+		},
+		{
+			`"Hello" - "World"`,
+			"NoMethodError: undefined method `-' for Hello:String",
+		},
+		{
+			"if (10 > 1); true + false; end",
+			"NoMethodError: undefined method `+' for :true:Symbol",
+		},
+		{
+			"if (10 > 1); true + false; end",
+			"NoMethodError: undefined method `+' for :true:Symbol",
+		},
+		{
+			`
+if (10 > 1)
+	if (10 > 1)
+		return true + false;
+	end
+	return 1;
+end
+`,
+			"NoMethodError: undefined method `+' for :true:Symbol",
+		},
+		{
+			"foobar",
+			"NoMethodError: undefined method `foobar' for :funcs:Symbol",
+		},
+		{
+			"Foobar",
+			"NameError: uninitialized constant Foobar",
+		},
+		{
+			`
+			def foo x, y
+			end
+
+			foo 1
+			`,
+			"ArgumentError: wrong number of arguments (given 1, expected 2)",
+		},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		_, err := testEval(tt.input, env)
+		utils.AssertNotEqual(t, err, nil)
+
+		actual, ok := errors.Cause(err).(object.RubyObject)
+		utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+		utils.Assert(t, object.IsError(actual), "Expected error or exception, got %T", actual)
+		utils.AssertEqual(t, actual.Inspect(), tt.expectedMessage)
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + true", "test.rb:1:1"},
+		{"x = 3\ny = [1, foobar]", "test.rb:2:9"},
+		{"def foo(x)\n  x.bar\nend\nfoo(2)", "test.rb:2:3"},
+		{"[1, 2].map { |x|\n  x + nil\n}", "test.rb:2:3"},
+	}
+
+	for _, tt := range tests {
+		fset := token.NewFileSet()
+		program, err := parser.ParseFile(fset, "test.rb", tt.input)
+		utils.AssertNoError(t, err)
+
+		_, _, err = evaluator.EvalEx(program, object.NewMainEnvironment(), fset, false)
+		utils.AssertNotEqual(t, err, nil)
+
+		pos, ok := evaluator.ErrorPosition(err)
+		utils.Assert(t, ok, "Expected error to carry a position, got %v", err)
+		utils.AssertEqual(t, pos.String(), tt.expected)
+
+		_, ok = errors.Cause(err).(object.RubyObject)
+		utils.Assert(t, ok, "Error cause is not a RubyObject. got=%T (%+v)", errors.Cause(err), err)
+	}
+}
+
+func TestBacktrace(t *testing.T) {
+	input := `def inner(x)
+  x + bar
+end
+def outer()
+  [1, 2].map { |y| inner(y) }
+end
+outer()`
+
+	fset := token.NewFileSet()
+	program, err := parser.ParseFile(fset, "test.rb", input)
+	utils.AssertNoError(t, err)
+
+	_, _, err = evaluator.EvalEx(program, object.NewMainEnvironment(), fset, false)
+	utils.AssertNotEqual(t, err, nil)
+
+	exc, ok := errors.Cause(err).(object.Backtracer)
+	utils.Assert(t, ok, "Error cause does not carry a backtrace. got=%T (%+v)", errors.Cause(err), err)
+	utils.AssertEqualCmp(t, exc.Backtrace(), []string{
+		"test.rb:2:in 'inner'",
+		"test.rb:5:in 'block in outer'",
+		"test.rb:5:in 'outer'",
+		"test.rb:7:in '<main>'",
+	}, utils.CompareArrays)
+}
+
+func TestCaller(t *testing.T) {
+	input := `def foo()
+  caller(0)
+end
+x = foo()
+x + caller`
+
+	fset := token.NewFileSet()
+	program, err := parser.ParseFile(fset, "test.rb", input)
+	utils.AssertNoError(t, err)
+
+	evaluated, _, err := evaluator.EvalEx(program, object.NewMainEnvironment(), fset, false)
+	utils.AssertNoError(t, err)
+
+	testObject(t, evaluated, []string{"test.rb:2:in 'foo'", "test.rb:4:in '<main>'"})
+}
+
+func TestExceptionHierarchy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`ArgumentError.new("x").is_a?(StandardError)`, true},
+		{`NoMethodError.new.is_a?(NameError)`, true},
+		{`SyntaxError.new.is_a?(StandardError)`, false},
+		{`NotImplementedError.new.is_a?(ScriptError)`, true},
+		{`NoMatchingPatternError.new.is_a?(StandardError)`, true},
+		{`LocalJumpError.new.is_a?(StandardError)`, true},
+		{`TypeError.new("x").is_a?(ArgumentError)`, false},
+		{`ZeroDivisionError.new.message`, "ZeroDivisionError"},
+		{`ZeroDivisionError.new.is_a?(ArithmeticError)`, true},
+		{`ArithmeticError.new.is_a?(StandardError)`, true},
+		{`KeyError.new.is_a?(IndexError)`, true},
+		{`StopIteration.new.is_a?(IndexError)`, true},
+		{`FloatDomainError.new.is_a?(RangeError)`, true},
+		{`begin; 1 / 0; rescue ArithmeticError; 2; end`, 2},
+		{`begin; Float::NAN.round; rescue RangeError => e; e.class.to_s; end`, "FloatDomainError"},
+		{`RuntimeError.new("boom").message`, "boom"},
+		{`RuntimeError.new("boom").full_message`, "boom (RuntimeError)"},
+		{`MyError = Class.new(ArgumentError); MyError.new("x").is_a?(ArgumentError)`, true},
+		{`MyError = Class.new(ArgumentError); MyError.new.message`, "MyError"},
+		{`MyError = Class.new(ArgumentError); MyError.new("x").full_message`, "x (MyError)"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		utils.AssertNoError(t, err)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestRaise(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`raise "boom"`, "RuntimeError: boom"},
+		{`raise ArgumentError`, "ArgumentError: ArgumentError"},
+		{`raise ArgumentError, "bad"`, "ArgumentError: bad"},
+		{`raise TypeError.new("bad")`, "TypeError: bad"},
+		{`MyError = Class.new(StandardError); raise MyError, "custom"`, "MyError: custom"},
+		{`raise 1, "bad"`, "TypeError: exception class/object expected"},
+	}
+
+	for _, tt := range tests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		utils.AssertNotEqual(t, err, nil)
+
+		actual, ok := errors.Cause(err).(object.RubyObject)
+		utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+		utils.AssertEqual(t, actual.Inspect(), tt.expectedMessage)
+	}
+
+	t.Run("cause", func(t *testing.T) {
+		env := object.NewMainEnvironment()
+		original := object.NewArgumentError("original")
+		env.SetGlobal("$!", original)
+
+		_, err := testEval(`raise "wrapped"`, env)
+
+		raised, ok := errors.Cause(err).(object.Causer)
+		utils.Assert(t, ok, "Expected exception, got %T", errors.Cause(err))
+		utils.AssertEqualCmpAny(t, raised.Cause(), original, object.CompareRubyObjectsForTests)
+	})
+}
+
+func TestBeginExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"no exception",
+			`begin; 1; rescue; 2; end`,
+			1,
+		},
+		{
+			"bare rescue",
+			`begin; raise "x"; rescue; 2; end`,
+			2,
+		},
+		{
+			"rescue by class",
+			`begin; 1 / 0; rescue TypeError; 2; rescue ZeroDivisionError; 3; end`,
+			3,
+		},
+		{
+			"rescue by superclass",
+			`begin; raise ArgumentError; rescue StandardError => e; e.message; end`,
+			"ArgumentError",
+		},
+		{
+			"else",
+			`begin; 1; rescue; 2; else; 3; end`,
+			3,
+		},
+		{
+			"ensure does not change the result",
+			`x = 0; y = begin; 1; ensure; x = 5; end; x + y`,
+			6,
+		},
+		{
+			"retry",
+			`x = 0; begin; x = x + 1; raise "x" if x < 3; x; rescue; retry; end`,
+			3,
+		},
+		{
+			"rescue modifier",
+			`x = raise("x") rescue 5; x`,
+			5,
+		},
+		{
+			"implicit begin in method body",
+			`
+def foo()
+  raise ArgumentError, "bad"
+rescue ArgumentError => e
+  e.message
+end
+foo()`,
+			"bad",
+		},
+		{
+			"cause of exception raised in rescue",
+			`begin; begin; raise "inner"; rescue; raise "outer"; end; rescue => e; e.cause.message; end`,
+			"inner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	t.Run("unmatched exception", func(t *testing.T) {
+		env := object.NewMainEnvironment()
+		_, err := testEval(`begin; raise Exception, "x"; rescue; 1; ensure; y = 2; end`, env)
+
+		actual, ok := errors.Cause(err).(object.RubyObject)
+		utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+		utils.AssertEqual(t, actual.Inspect(), "Exception: x")
+
+		y, ok := env.Get("y")
+		utils.Assert(t, ok, "Expected ensure clause to run")
+		testObject(t, y, 2)
+	})
+}
+
+func TestClassDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"instance variables and initialize",
+			`
+class Point
+  def initialize(x, y)
+    @x = x
+    @y = y
+  end
+  def sum
+    @x + @y
+  end
+end
+Point.new(1, 2).sum
+`,
+			3,
+		},
+		{
+			"unset instance variable",
+			`class Foo; def bar; @bar; end; end; Foo.new.bar.nil?`,
+			true,
+		},
+		{
+			"inheritance",
+			`class Foo; def bar; 1; end; end; class Baz < Foo; end; Baz.new.bar`,
+			1,
+		},
+		{
+			"method override",
+			`class Foo; def bar; 1; end; end; class Baz < Foo; def bar; 2; end; end; Baz.new.bar`,
+			2,
+		},
+		{
+			"implicit super",
+			`
+class Foo
+  def initialize(x)
+    @x = x
+  end
+  def x
+    @x
+  end
+end
+class Baz < Foo
+  def initialize(x)
+    super
+    @x = @x * 2
+  end
+end
+Baz.new(3).x
+`,
+			6,
+		},
+		{
+			"explicit super",
+			`class Foo; def bar(x); x; end; end; class Baz < Foo; def bar(x); super(x + 1); end; end; Baz.new.bar(1)`,
+			2,
+		},
+		{
+			"attr_accessor",
+			`class Foo; attr_accessor :bar; end; foo = Foo.new; foo.bar = 5; foo.bar += 1; foo.bar`,
+			6,
+		},
+		{
+			"class method",
+			`class Foo; def self.create; new; end; def bar; 7; end; end; Foo.create.bar`,
+			7,
+		},
+		{
+			"self in method",
+			`class Foo; def me; self; end; end; foo = Foo.new; foo.me == foo`,
+			true,
+		},
+		{
+			"self in class body",
+			`class Foo; self; end.name`,
+			"Foo",
+		},
+		{
+			"reopen class",
+			`class Foo; def bar; 1; end; end; class Foo; def baz; 2; end; end; foo = Foo.new; [foo.bar, foo.baz]`,
+			[]string{"1", "2"},
+		},
+		{
+			"superclass",
+			`class Foo; end; class Baz < Foo; end; Baz.superclass.name`,
+			"Foo",
+		},
+		{
+			"is_a? superclass",
+			`class Foo; end; class Baz < Foo; end; Baz.new.is_a?(Foo)`,
+			true,
+		},
+		{
+			"top level function in method",
+			`def helper; 4; end; class Foo; def bar; helper; end; end; Foo.new.bar`,
+			4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	errorTests := []struct {
+		name            string
+		input           string
+		expectedMessage string
+	}{
+		{
+			"superclass mismatch",
+			`class Foo; end; class Baz < Foo; end; class Baz < String; end`,
+			"TypeError: superclass mismatch for class Baz",
+		},
+		{
+			"no superclass method",
+			`class Foo; def bar; super; end; end; Foo.new.bar`,
+			"NoMethodError: super: no superclass method `bar'",
+		},
+		{
+			"wrong number of arguments to initialize",
+			`class Foo; def initialize(x); end; end; Foo.new`,
+			"ArgumentError: wrong number of arguments (given 0, expected 1)",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testEval(tt.input, object.NewMainEnvironment())
+
+			actual, ok := errors.Cause(err).(object.RubyObject)
+			utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+			utils.Assert(t, strings.HasPrefix(actual.Inspect(), tt.expectedMessage), "Expected message to start with %q, got %q", tt.expectedMessage, actual.Inspect())
+		})
+	}
+}
+
+func TestModules(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"include",
+			`module Greet; def greet; "hi " + name; end; end; class Foo; include Greet; def name; "foo"; end; end; Foo.new.greet`,
+			"hi foo",
+		},
+		{
+			"prepend and super",
+			`module Loud; def greet; super + "!"; end; end; class Foo; prepend Loud; def greet; "hi"; end; end; Foo.new.greet`,
+			"hi!",
+		},
+		{
+			"super from included module",
+			`module Twice; def value; super * 2; end; end; class Foo; def value; 2; end; end; class Bar < Foo; include Twice; end; Bar.new.value`,
+			4,
+		},
+		{
+			"ancestors",
+			`module A; end; module B; include A; end; class Foo; include B; end; Foo.ancestors`,
+			[]string{"Foo", "B", "A", "Object"},
+		},
+		{
+			"include?",
+			`module A; end; class Foo; include A; end; class Bar < Foo; end; Bar.include?(A)`,
+			true,
+		},
+		{
+			"instance_methods",
+			`module A; def b; end; def a; end; end; A.instance_methods`,
+			[]string{":a", ":b"},
+		},
+		{
+			"is_a? module",
+			`module A; end; class Foo; include A; end; Foo.new.is_a?(A)`,
+			true,
+		},
+		{
+			"extend class",
+			`module A; def make; new; end; end; class Foo; extend A; end; Foo.make.class.name`,
+			"Foo",
+		},
+		{
+			"extend self",
+			`module Util; extend self; def double(x); x * 2; end; end; Util.double(3)`,
+			6,
+		},
+		{
+			"module function",
+			`module Util; def self.double(x); x * 2; end; end; Util::double(4)`,
+			8,
+		},
+		{
+			"scoped constant",
+			`module Outer; VALUE = 3; class Inner; end; end; [Outer::VALUE, Outer::Inner.name]`,
+			[]string{"3", "Outer::Inner"},
+		},
+		{
+			"lexical constant lookup",
+			`module Outer; VALUE = 3; class Inner; def value; VALUE; end; end; end; Outer::Inner.new.value`,
+			3,
+		},
+		{
+			"constant lookup through ancestors",
+			`module A; VALUE = 5; end; class Foo; include A; def value; VALUE; end; end; Foo.new.value`,
+			5,
+		},
+		{
+			"nested constants shadow top level ones",
+			`VALUE = 1; module Outer; VALUE = 2; def self.value; VALUE; end; end; [VALUE, Outer.value]`,
+			[]string{"1", "2"},
+		},
+		{
+			"top level constant",
+			`VALUE = 1; module Outer; VALUE = 2; def self.value; ::VALUE; end; end; Outer.value`,
+			1,
+		},
+		{
+			"reopen module",
+			`module A; def a; 1; end; end; module A; def b; 2; end; end; class Foo; include A; end; foo = Foo.new; [foo.a, foo.b]`,
+			[]string{"1", "2"},
+		},
+		{
+			"class of module",
+			`module A; end; A.class.name`,
+			"Module",
+		},
+		{
+			"class interpolation",
+			`"#{1.class}"`,
+			"Integer",
+		},
+		{
+			"module to_s",
+			`module A; end; class Foo; end; [A.to_s, Foo.inspect, "#{A}"]`,
+			[]string{"A", "Foo", "A"},
+		},
+		{
+			"class of self interpolation",
+			`class Foo; def describe; "#{self.class}"; end; end; Foo.new.describe`,
+			"Foo",
+		},
+		{
+			"class of rescued exception",
+			`begin; 1 / 0; rescue => e; "#{e.class}"; end`,
+			"ZeroDivisionError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	errorTests := []struct {
+		name            string
+		input           string
+		expectedMessage string
+	}{
+		{
+			"uninitialized scoped constant",
+			`module A; end; A::B`,
+			"NameError: uninitialized constant A::B",
+		},
+		{
+			"include a class",
+			`class A; end; class B; include A; end`,
+			"TypeError: wrong argument type Class (expected Module)",
+		},
+		{
+			"module is not a class",
+			`module A; end; class A; end`,
+			"TypeError: A is not a class",
+		},
+		{
+			"modules have no instances",
+			`module A; end; A.new`,
+			"NoMethodError: undefined method `new' for A:Module",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testEval(tt.input, object.NewMainEnvironment())
+
+			actual, ok := errors.Cause(err).(object.RubyObject)
+			utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+			utils.AssertEqual(t, actual.Inspect(), tt.expectedMessage)
+		})
+	}
+}
+
+func TestCaseExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"value list",
+			`case 2; when 1, 2 then "small"; else "big"; end`,
+			"small",
+		},
+		{
+			"else",
+			`case 7; when 1, 2 then "small"; else "big"; end`,
+			"big",
+		},
+		{
+			"no match without else",
+			`case 7; when 1 then "one"; end.nil?`,
+			true,
+		},
+		{
+			"class",
+			`case "foo"; when Integer then "int"; when String then "str"; end`,
+			"str",
+		},
+		{
+			"module",
+			`module A; end; class Foo; include A; end; case Foo.new; when A then "a"; end`,
+			"a",
+		},
+		{
+			"inclusive range",
+			`case 5; when 1..5 then "in"; else "out"; end`,
+			"in",
+		},
+		{
+			"exclusive range",
+			`case 5; when 1...5 then "in"; else "out"; end`,
+			"out",
+		},
+		{
+			"float in range",
+			`case 2.5; when 1..3 then "in"; else "out"; end`,
+			"in",
+		},
+		{
+			"without subject",
+			`x = 4; case; when x > 5 then "big"; when x > 2 then "mid"; end`,
+			"mid",
+		},
+		{
+			"user defined ===",
+			`class Even; def ===(x); x % 2 == 0; end; end; case 4; when Even.new then "even"; end`,
+			"even",
+		},
+		{
+			"user defined ==",
+			`class Answer; def ==(x); x == 42; end; end; case 42; when Answer.new then "answer"; end`,
+			"answer",
+		},
+		{
+			"first match wins",
+			`case 1; when Integer then "int"; when 1 then "one"; end`,
+			"int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+}
+
+func TestLoopExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"while",
+			`x = 0; while x < 5; x += 1; end; x`,
+			5,
+		},
+		{
+			"while returns nil",
+			`x = 0; (while x < 5; x += 1; end).nil?`,
+			true,
+		},
+		{
+			"until",
+			`x = 0; until x == 3; x += 1; end; x`,
+			3,
+		},
+		{
+			"modifier while",
+			`x = 0; x += 2 while x < 7; x`,
+			8,
+		},
+		{
+			"modifier until",
+			`x = 10; x -= 1 until x < 4; x`,
+			3,
+		},
+		{
+			"modifier does not run if the condition fails",
+			`x = 0; x += 1 while x > 0; x`,
+			0,
+		},
+		{
+			"begin end while runs once",
+			`x = 0; begin; x += 1; end while x > 5; x`,
+			1,
+		},
+		{
+			"begin end until",
+			`x = 0; begin; x += 1; end until x >= 3; x`,
+			3,
+		},
+		{
+			"break with value",
+			`x = 0; while true; x += 1; break x * 10 if x == 4; end`,
+			40,
+		},
+		{
+			"break with falsy value",
+			`loop { break false }`,
+			false,
+		},
+		{
+			"plain break",
+			`x = 0; loop { x += 1; break }; x`,
+			1,
+		},
+		{
+			"break unless",
+			`x = 0; loop { x += 1; break unless x < 3 }; x`,
+			3,
+		},
+		{
+			"next",
+			`x = 0; sum = 0; while x < 5; x += 1; next if x == 2; sum += x; end; sum`,
+			13,
+		},
+		{
+			"next unless",
+			`sum = 0; for x in 1..6; next unless x % 2 == 0; sum += x; end; sum`,
+			12,
+		},
+		{
+			"for over array",
+			`sum = 0; for x in [1, 2, 3]; sum += x; end; sum`,
+			6,
+		},
+		{
+			"for variable outlives the loop",
+			`for x in [1, 2, 3]; end; x`,
+			3,
+		},
+		{
+			"for returns the iterable",
+			`for x in [4, 5]; end`,
+			[]string{"4", "5"},
+		},
+		{
+			"for with multiple variables",
+			`sum = 0; for a, b in [[1, 2], [3, 4]]; sum += a * b; end; sum`,
+			14,
+		},
+		{
+			"break out of for",
+			`for x in 1..10; break x if x * x > 20; end`,
+			5,
+		},
+		{
+			"return from within a loop",
+			`def foo; while true; return 7; end; 9; end; foo`,
+			7,
+		},
+		{
+			"nested loops",
+			`n = 0; for i in 1..3; for j in 1..3; break if j > i; n += 1; end; end; n`,
+			6,
+		},
+		{
+			"for over a hash",
+			`r = nil; for k, v in {a: 1}; r = [k, v]; end; r`,
+			[]string{":a", "1"},
+		},
+		{
+			"for over an object with each",
+			`class Coll; def each; yield 1; yield 2; yield 3; end; end; sum = 0; for x in Coll.new; next if x == 2; sum += x; end; [sum, x]`,
+			[]string{"4", "3"},
+		},
+		{
+			"break out of for over an object with each",
+			`class Coll; def each; yield 1; yield 2; yield 3; end; end; for x in Coll.new; break x * 10 if x == 2; end`,
+			20,
+		},
+		{
+			"return from within for over an object with each",
+			`class Coll; def each; yield 1; yield 2; end; end; def foo; for x in Coll.new; return x; end; 9; end; foo`,
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	t.Run("for over a non enumerable", func(t *testing.T) {
+		_, err := testEval(`for x in 5; end`, object.NewMainEnvironment())
+
+		actual, ok := errors.Cause(err).(object.RubyObject)
+		utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+		utils.AssertEqual(t, actual.Inspect(), "NoMethodError: undefined method `each' for 5:Integer")
+	})
+}
+
+func TestNonLocalControlFlow(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"break out of map",
+			`[1, 2, 3, 4].map { |x| break x * 100 if x == 3; x }`,
+			300,
+		},
+		{
+			"break without value",
+			`[1, 2].each { |x| break }.nil?`,
+			true,
+		},
+		{
+			"break returns from the method call only",
+			`def foo; [1, 2].each { |x| break x * 10 }; 5; end; foo`,
+			5,
+		},
+		{
+			"next in map",
+			`[1, 2, 3].map { |x| next 0 if x == 2; x }`,
+			[]string{"1", "0", "3"},
+		},
+		{
+			"next in find_all",
+			`[1, 2, 3, 4].find_all { |x| next false if x == 2; x > 1 }`,
+			[]string{"3", "4"},
+		},
+		{
+			"redo in a block",
+			`out = []; [1, 2].each { |x| out.push(x); redo if out.size == 1 }; out`,
+			[]string{"1", "1", "2"},
+		},
+		{
+			"redo in a while loop",
+			`i = 0; n = 0; while i < 3; i += 1; n += 1; redo if n == 2; end; n`,
+			3,
+		},
+		{
+			"return from a block returns from the method",
+			`def foo(arr); arr.each { |x| return x if x > 2 }; nil; end; foo([1, 5, 7])`,
+			5,
+		},
+		{
+			"return from nested blocks",
+			`def foo; [1].each { |x| [2].each { |y| return x + y } }; 0; end; foo`,
+			3,
+		},
+		{
+			"return in a lambda",
+			`l = -> (x) { return x * 2; 0 }; l[3]`,
+			6,
+		},
+		{
+			"break in a lambda",
+			`l = -> (x) { break x + 1; 0 }; l[1]`,
+			2,
+		},
+		{
+			"next in a lambda",
+			`l = -> (x) { next x + 2; 0 }; l[1]`,
+			3,
+		},
+		{
+			"ensure runs on break",
+			`out = []; [1, 2].each { |x| begin; break; ensure; out.push(x); end }; out`,
+			[]string{"1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	t.Run("return from a top level block", func(t *testing.T) {
+		_, err := testEval(`[1].each { |x| return x }`, object.NewMainEnvironment())
+
+		actual, ok := errors.Cause(err).(object.RubyObject)
+		utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+		utils.AssertEqual(t, actual.Inspect(), "LocalJumpError: unexpected return")
+	})
+	t.Run("rescue LocalJumpError", func(t *testing.T) {
+		evaluated, err := testEval(`
+		begin
+			[1].each { |x| return x }
+		rescue LocalJumpError => e
+			e.message
+		end`, object.NewMainEnvironment())
+		utils.AssertNoError(t, err)
+		testObject(t, evaluated, "unexpected return")
+	})
+}
+
+func TestYield(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"do block",
+			`def foo; yield 2; end; foo do |x| x * 3 end`,
+			6,
+		},
+		{
+			"brace block",
+			`def foo; yield; end; foo { 5 }`,
+			5,
+		},
+		{
+			"several arguments",
+			`def foo; yield 3, 4; end; foo { |a, b| a * b }`,
+			12,
+		},
+		{
+			"yield with parens",
+			`def foo; yield(1, 2) + 1; end; foo { |a, b| a + b }`,
+			4,
+		},
+		{
+			"array spread over block parameters",
+			`def foo; yield [5, 6]; end; foo { |a, b| b }`,
+			6,
+		},
+		{
+			"missing block arguments are nil",
+			`def foo; yield 1; end; foo { |a, b| b.nil? }`,
+			true,
+		},
+		{
+			"surplus block arguments are dropped",
+			`def foo; yield 1, 2; end; foo { |a| a }`,
+			1,
+		},
+		{
+			"yield within a block",
+			`def foo; [1, 2].map { |x| yield x }; end; foo { |x| x * 10 }`,
+			[]string{"10", "20"},
+		},
+		{
+			"block_given? without block",
+			`def foo; block_given?; end; foo`,
+			false,
+		},
+		{
+			"block_given? with block",
+			`def foo; block_given?; end; foo { }`,
+			true,
+		},
+		{
+			"block_given? with do block",
+			`def foo(x); block_given?; end; foo 1 do end`,
+			true,
+		},
+		{
+			"do binds to the outermost call",
+			`def foo(x); block_given? ? x : 0; end; def bar; block_given? ? 2 : 1; end; foo bar do end`,
+			1,
+		},
+		{
+			"braces bind to the closest call",
+			`def foo(x); block_given? ? 0 : x; end; def bar; block_given? ? 2 : 1; end; foo bar { }`,
+			2,
+		},
+		{
+			"explicit block parameter",
+			`def foo(x, &blk); blk.call(x) + 1; end; foo(2) { |y| y * 5 }`,
+			11,
+		},
+		{
+			"explicit block parameter without block",
+			`def foo(&blk); blk; end; foo.nil?`,
+			true,
+		},
+		{
+			"explicit block parameter is a Proc",
+			`def foo(&blk); blk; end; foo { }.is_a?(Proc)`,
+			true,
+		},
+		{
+			"super passes on the block",
+			`class A; def each; yield 1; end; end; class B < A; def each; super; end; end; B.new.each { |x| x + 41 }`,
+			42,
+		},
+		{
+			"break out of a yielding method",
+			`def foo; yield; 1; end; foo { break 2 }`,
+			2,
+		},
+		{
+			"do block for a builtin",
+			`[1, 2].map do |x| x * 2 end`,
+			[]string{"2", "4"},
+		},
+		{
+			"loop do",
+			`x = 0; loop do x += 1; break if x == 3; end; x`,
+			3,
+		},
+		{
+			"while do",
+			`x = 0; while x < 3 do x += 1 end; x`,
+			3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	t.Run("yield without block", func(t *testing.T) {
+		_, err := testEval(`def foo; yield; end; foo`, object.NewMainEnvironment())
+
+		actual, ok := errors.Cause(err).(object.RubyObject)
+		utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+		utils.AssertEqual(t, actual.Inspect(), "LocalJumpError: no block given (yield)")
+	})
+}
+
+func TestProcs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"lambda literal", `sq = ->(x) { x * x }; sq.call(3)`, 9},
+		{"call shorthand", `sq = ->(x) { x * x }; sq.(4)`, 16},
+		{"index call", `sq = ->(x) { x * x }; sq[5]`, 25},
+		{"lambda literal is a lambda", `->(x) { x }.lambda?`, true},
+		{"lambda", `l = lambda { |a, b| a + b }; l.call(1, 2)`, 3},
+		{"lambda is a lambda", `lambda { }.lambda?`, true},
+		{"proc", `pr = proc { |a, b| b }; pr.call(1, 2)`, 2},
+		{"proc is no lambda", `proc { }.lambda?`, false},
+		{"Proc.new", `pr = Proc.new { |x| x + 1 }; pr.call(1)`, 2},
+		{"proc spreads an array", `pr = proc { |a, b| b }; pr.call([3, 4])`, 4},
+		{"proc pads missing arguments", `pr = proc { |a, b| b.nil? }; pr.call(1)`, true},
+		{"proc drops surplus arguments", `pr = proc { |a| a }; pr.call(1, 2)`, 1},
+		{"arity", `proc { |x, y| }.arity`, 2},
+		{"arity with optional parameters", `lambda { |x, y = 1| }.arity`, -2},
+		{"curry", `add = ->(a, b, c) { a + b + c }; c = add.curry; c[1][2][3]`, 6},
+		{"curry with several arguments", `add = ->(a, b, c) { a + b + c }; c = add.curry; c.(1, 2).(3)`, 6},
+		{">>", `inc = ->(x) { x + 1 }; dbl = ->(x) { x * 2 }; f = inc >> dbl; f.call(3)`, 8},
+		{"<<", `inc = ->(x) { x + 1 }; dbl = ->(x) { x * 2 }; f = inc << dbl; f.call(3)`, 7},
+		{"return from a proc returns from the method", `def foo; pr = proc { return 10 }; pr.call; 20; end; foo`, 10},
+		{"return from a lambda returns from the lambda", `def foo; l = -> { return 10 }; l.call; 20; end; foo`, 20},
+		{"break from a lambda", `l = ->(x) { break x * 3 }; l.call(2)`, 6},
+		{"lambda as argument", `def foo(l); l.call(2); end; foo ->(x) { x * 4 }`, 8},
+		{"method", `def hello(x); x + 1; end; m = method(:hello); m.call(1)`, 2},
+		{"method index call", `def hello(x); x + 1; end; m = method(:hello); m[2]`, 3},
+		{"method arity", `def hello(x, y = 1); end; method(:hello).arity`, -2},
+		{"method name", `def hello; end; method(:hello).name == :hello`, true},
+		{"method of a builtin", `m = 2.method("+"); m.call(3)`, 5},
+		{"method of an instance", `class A; def initialize(x); @x = x; end; def x; @x; end; end; m = A.new(7).method(:x); m.call`, 7},
+		{"method composition", `def inc(x); x + 1; end; f = method(:inc) >> ->(x) { x * 10 }; f.call(1)`, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	errorTests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"lambda arity", `l = lambda { |a, b| a + b }; l.call(1)`, "ArgumentError: wrong number of arguments (given 1, expected 2)"},
+		{"proc without block", `proc`, "ArgumentError: tried to create Proc object without a block"},
+		{"return from an orphaned proc", `def foo; proc { return 1 }; end; foo.call`, "LocalJumpError: unexpected return"},
+		{"break from a proc", `proc { break 3 }.call`, "LocalJumpError: break from proc-closure"},
+		{"undefined method", `method(:nope)`, "NameError: undefined method `nope' for :funcs:Symbol"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testEval(tt.input, object.NewMainEnvironment())
+
+			actual, ok := errors.Cause(err).(object.RubyObject)
+			utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+			utils.AssertEqual(t, actual.Inspect(), tt.err)
+		})
+	}
+}
+
+func TestKeywordArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"label keys", `h = {a: 1, "b": 2}; h[:a] + h[:b]`, 3},
+		{"double splat in a hash", `h = {a: 1}; {**h, b: 2}[:a]`, 1},
+		{"optional keyword", `def foo(x, opt: 1); x + opt; end; foo(1)`, 2},
+		{"optional keyword given", `def foo(x, opt: 1); x + opt; end; foo(1, opt: 10)`, 11},
+		{"without parens", `def foo(x, opt: 1); x + opt; end; foo 1, opt: 5`, 6},
+		{"hash rocket", `def foo(x, opt: 1); x + opt; end; foo(1, :opt => 3)`, 4},
+		{"required keyword", `def foo(a:); a; end; foo(a: 7)`, 7},
+		{"double splat parameter", `def foo(a: 1, **rest); rest[:b]; end; foo(b: 2)`, 2},
+		{"double splat parameter without keywords", `def foo(**rest); rest; end; foo.has_key?(:a)`, false},
+		{"double splat argument", `def foo(a:, b:); a - b; end; opts = {b: 1}; foo(a: 3, **opts)`, 2},
+		{"hash for a positional parameter", `def foo(h); h[:k]; end; foo(k: 9)`, 9},
+		{"hash for a positional parameter before keywords", `def foo(h, k: 1); h[:k] + k; end; foo({k: 9})`, 10},
+		{"braced hash for an optional parameter", `def foo(h = {}, **kw); [h[:a], kw.has_key?(:a)]; end; foo({a: 1})`, []string{"1", ":false"}},
+		{"braced hash after a splat", `def foo(*a, **kw); [a.size, kw.has_key?(:a)]; end; foo(1, {a: 3})`, []string{"2", ":false"}},
+		{"braceless hash after a splat", `def foo(*a, **kw); [a.size, kw[:a]]; end; foo(1, a: 3)`, []string{"1", "3"}},
+		{"keywords passed on as a hash", `def inner(h = {}, k: 1); [h[:k], k]; end; def outer(h); inner(h); end; outer(k: 2)`, []string{"2", "1"}},
+		{"double splat of a braced hash", `def foo(a: 1); a; end; h = {a: 4}; foo(**h)`, 4},
+		{"splat after a positional parameter", `def foo(a, *r, k: 1); [a, r, k]; end; foo(1, k: 3)`, []string{"1", "[]", "3"}},
+		{"required keyword after a splat", `def foo(a, *r, k:); [a, r, k]; end; foo(1, 2, k: 3)`, []string{"1", "[2]", "3"}},
+		{"optional and splat before keywords", `def foo(a, b = 1, *r, k: 1); [a, b, r, k]; end; foo(1, 2, 3, k: 4)`, []string{"1", "2", "[3]", "4"}},
+		{"optional and splat without keywords", `def foo(a, b = 1, *r, k: 1); [a, b, r, k]; end; foo(1)`, []string{"1", "1", "[]", "1"}},
+		{"lambda", `l = ->(x, y: 2) { x * y }; l.call(3, y: 4)`, 12},
+		{"block", `def foo; yield 1, k: 2; end; foo { |x, k: 0| x + k }`, 3},
+		{"initialize", `class A; def initialize(x:); @x = x; end; def x; @x; end; end; A.new(x: 5).x`, 5},
+		{"bare super", `class A; def go(k: 1); k; end; end; class B < A; def go(k: 2); super; end; end; B.new.go(k: 3)`, 3},
+		{"arity with optional keywords", `def foo(a, b: 1); end; method(:foo).arity`, -2},
+		{"arity with required keywords", `def foo(a, b:); end; method(:foo).arity`, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	errorTests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"missing keyword", `def foo(a:); end; foo`, "ArgumentError: missing keyword: :a"},
+		{"missing keywords", `def foo(a:, b:); end; foo(c: 1)`, "ArgumentError: missing keywords: :a, :b"},
+		{"unknown keyword", `def foo(a: 1); end; foo(b: 2)`, "ArgumentError: unknown keyword: :b"},
+		{"unknown keywords", `def foo(a: 1); end; foo(c: 3, b: 2)`, "ArgumentError: unknown keywords: :b, :c"},
+		{"double splat of no hash", `def foo(**k); end; foo(**1)`, "TypeError: no implicit conversion of Integer into Hash"},
+		{"braced hash for keywords", `def foo(a: 1); a; end; foo({a: 2})`, "ArgumentError: wrong number of arguments (given 1, expected 0)"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testEval(tt.input, object.NewMainEnvironment())
+
+			actual, ok := errors.Cause(err).(object.RubyObject)
+			utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+			utils.AssertEqual(t, actual.Inspect(), tt.err)
+		})
+	}
+}
+
+func TestBlockPass(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"symbol", `[[1, 2], [3, 4]].map(&:first).first`, 1},
+		{"lambda", `l = ->(x) { x * 3 }; [1, 2].map(&l).first`, 3},
+		{"proc", `pr = proc { |x| x + 1 }; [1, 2].map(&pr).first`, 2},
+		{"method", `def double(x); x * 2; end; [1, 2, 3].map(&method(:double)).first`, 2},
+		{"to a def with yield", `def twice; yield(yield(1)); end; l = ->(x) { x + 3 }; twice(&l)`, 7},
+		{"to an explicit block parameter", `def same(&blk); blk; end; l = ->(x) { x }; same(&l) == l`, true},
+		{"nil", `def given?; block_given?; end; given?(&nil)`, false},
+		{"block_given?", `def given?; block_given?; end; given?(&:to_s)`, true},
+		{"passing on a block", `def inner; yield 5; end; def outer(&blk); inner(&blk); end; outer { |x| x * 3 }`, 15},
+		{"Symbol#to_proc", `:size.to_proc.call("abc")`, 3},
+		{"Method#to_proc is a lambda", `def foo; end; method(:foo).to_proc.lambda?`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	errorTests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"not a proc", `[1].map(&1)`, "TypeError: wrong argument type Integer (expected Proc)"},
+		{"Symbol#to_proc without receiver", `:size.to_proc.call`, "ArgumentError: no receiver given"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testEval(tt.input, object.NewMainEnvironment())
+
+			actual, ok := errors.Cause(err).(object.RubyObject)
+			utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+			utils.AssertEqual(t, actual.Inspect(), tt.err)
+		})
+	}
+}
+
+func TestDestructuringParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"block", `[[1, [2, 3]]].map { |a, (b, c)| a + b + c }.first`, 6},
+		{"block with splat", `[[1, [2, 3, 4]]].map { |a, (b, *c)| c.size }.first`, 2},
+		{"missing values", `[[1, [2]]].map { |a, (b, c)| c }.first`, nil},
+		{"Array#each_with_index", `r = []; [[1, 2]].each_with_index { |(a, b), i| r.push(a + b + i) }; r.first`, 3},
+		{"Hash#each_with_index", `r = []; {a: 5}.each_with_index { |(k, v), i| r.push(v + i) }; r.first`, 5},
+		{"Hash#each", `r = []; {a: 5}.each { |k, v| r.push(v) }; r.first`, 5},
+		{"lambda", `l = ->((a, b), c) { a + b + c }; l.call([1, 2], 3)`, 6},
+		{"lambda with a non array", `l = ->((a, b), c) { a }; l.call(1, 3)`, 1},
+		{"to_ary", `class P; def to_ary; [1, 2]; end; end; [P.new].map { |(a, b)| b }.first`, 2},
+		{"def", `def foo((a, b)); a + b; end; foo([3, 4])`, 7},
+		{"parameters", `->((a, b), c) {}.parameters.size`, 2},
+		{"block with a trailing splat", `r = []; [1, 2].each { |a, *r2| r.push([a, r2]) }; r`, []string{"[1, []]", "[2, []]"}},
+		{"block spreading over a trailing splat", `[[1, 2, 3]].map { |a, *r| [a, r] }.first`, []string{"1", "[2, 3]"}},
+		{"block with a middle splat", `[[1, 2]].map { |a, *r, b| [a, r, b] }.first`, []string{"1", "[]", "2"}},
+		{"lambda with a trailing splat", `->(a, *r) { [a, r] }.call(1, 2, 3)`, []string{"1", "[2, 3]"}},
+		{"lambda with a middle splat", `->(a, *r, b) { [a, r, b] }.call(1, 2, 3, 4)`, []string{"1", "[2, 3]", "4"}},
+		{"def with a trailing splat", `def f(a, *r); [a, r]; end; f(1)`, []string{"1", "[]"}},
+		{"def with optional and splat", `def f(a, b = 5, *r, c); [a, b, r, c]; end; f(1, 2, 3, 4, 5)`, []string{"1", "2", "[3, 4]", "5"}},
+		{"def with optional before a mandatory", `def f(a, b = 5, c); [a, b, c]; end; f(1, 2)`, []string{"1", "5", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+
+	t.Run("lambda with a middle splat lacking arguments", func(t *testing.T) {
+		_, err := testEval(`->(a, *r, b) {}.call(1)`, object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewWrongNumberOfArgumentsError(2, 1))
+	})
+}
+
+func TestPatternMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			"value",
+			`case 2; in 1 then "one"; in 2 then "two"; end`,
+			"two",
 		},
 		{
-			"5 + true; 5;",
-			"TypeError: Integer can't be coerced into Symbol",
+			"class and capture",
+			`case 5; in String then "str"; in Integer => n then n; end`,
+			5,
 		},
 		{
-			"-true",
-			"Exception: unknown operator: -Symbol",
+			"alternatives",
+			`case :b; in :a | :b then "ab"; else "other"; end`,
+			"ab",
 		},
 		{
-			"true + false;",
-			"NoMethodError: undefined method `+' for :true:Symbol",
+			"range",
+			`case 3; in 1..5 then "in"; end`,
+			"in",
 		},
 		{
-			"true + false + true + false;",
-			"NoMethodError: undefined method `+' for :true:Symbol",
+			"array",
+			`case [1, 2]; in [a, b] then a + b; end`,
+			3,
 		},
 		{
-			"5; true + false; 5",
-			"NoMethodError: undefined method `+' for :true:Symbol",
+			"array length mismatch",
+			`case [1, 2, 3]; in [a, b] then "two"; in [a, b, c] then "three"; end`,
+			"three",
 		},
 		{
-			`"Hello" - "World"`,
-			"NoMethodError: undefined method `-' for Hello:String",
+			"array splat",
+			`case [1, 2, 3, 4]; in [first, *middle, last] then middle; end`,
+			[]string{"2", "3"},
 		},
 		{
-			"if (10 > 1); true + false; end",
-			"NoMethodError: undefined method `+' for :true:Symbol",
+			"top level array",
+			`case [1, 2, 3]; in Integer => first, *rest then rest; end`,
+			[]string{"2", "3"},
 		},
 		{
-			"if (10 > 1); true + false; end",
-			"NoMethodError: undefined method `+' for :true:Symbol",
+			"find",
+			`case [1, 5, 2, 7, 3]; in [*pre, Integer => x, 7, *post] then [pre, x, post]; end`,
+			[]string{"[1, 5]", "2", "[3]"},
 		},
 		{
-			`
-if (10 > 1)
-	if (10 > 1)
-		return true + false;
-	end
-	return 1;
-end
-`,
-			"NoMethodError: undefined method `+' for :true:Symbol",
+			"nested",
+			`case [1, [2, [3]]]; in [_, [_, [x]]] then x; end`,
+			3,
 		},
 		{
-			"foobar",
-			"NoMethodError: undefined method `foobar' for :funcs:Symbol",
+			"hash",
+			`case {:name => "bob", :age => 3}; in {name: String => name, age:} then name; end`,
+			"bob",
 		},
 		{
-			"Foobar",
-			"NameError: uninitialized constant Foobar",
+			"hash binds key",
+			`case {:name => "bob", :age => 3}; in {name: String, age:} then age; end`,
+			3,
 		},
 		{
-			`
-			def foo x, y
-			end
-
-			foo 1
-			`,
-			"ArgumentError: wrong number of arguments (given 1, expected 2)",
+			"top level hash",
+			`case {:name => "bob"}; in name: then name; end`,
+			"bob",
+		},
+		{
+			"hash missing key",
+			`case {:name => "bob"}; in {age:} then "age"; else "no age"; end`,
+			"no age",
+		},
+		{
+			"hash no rest",
+			`case {:a => 1, :b => 2}; in {a: 1, **nil} then "exact"; in {a: 1} then "subset"; end`,
+			"subset",
+		},
+		{
+			"empty hash",
+			`case {:a => 1}; in {} then "empty"; else "not empty"; end`,
+			"not empty",
+		},
+		{
+			"guard",
+			`case 5; in Integer => n if n > 10 then "big"; in Integer unless false then "small"; end`,
+			"small",
+		},
+		{
+			"pin",
+			`expected = 5; case 5; in ^expected then "pinned"; end`,
+			"pinned",
+		},
+		{
+			"pin expression",
+			`base = 4; case 5; in ^(base + 1) then "pinned"; end`,
+			"pinned",
+		},
+		{
+			"else",
+			`case 5; in String then "str"; else "other"; end`,
+			"other",
+		},
+		{
+			"deconstruct",
+			`class Point; def deconstruct; [1, 2]; end; end; case Point.new; in Point(x, y) then y; end`,
+			2,
+		},
+		{
+			"deconstruct_keys",
+			`class Point; def deconstruct_keys(keys); {:x => 1}; end; end; case Point.new; in Point(x:) then x; end`,
+			1,
+		},
+		{
+			"constant mismatch",
+			`case [1]; in String(x) then "str"; in Array(x) then x; end`,
+			1,
+		},
+		{
+			"in",
+			`[1, "a"] in [Integer, String]`,
+			true,
+		},
+		{
+			"in mismatch",
+			`1 in String`,
+			false,
+		},
+		{
+			"rightward assignment",
+			`[1, [2, 3]] => [a, [*, b]]; a + b`,
+			4,
+		},
+		{
+			"rightward assignment to hash",
+			`{:a => 1} => {a:}; a`,
+			1,
 		},
 	}
 
 	for _, tt := range tests {
-		env := object.NewEnvironment()
-		_, err := testEval(tt.input, env)
-		utils.AssertNotEqual(t, err, nil)
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
 
-		actual, ok := errors.Cause(err).(object.RubyObject)
-		utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
-		utils.Assert(t, object.IsError(actual), "Expected error or exception, got %T", actual)
-		utils.AssertEqual(t, actual.Inspect(), tt.expectedMessage)
+	errorTests := []struct {
+		name            string
+		input           string
+		expectedMessage string
+	}{
+		{
+			"no matching in clause",
+			`case 5; in String then 1; end`,
+			"NoMatchingPatternError: 5",
+		},
+		{
+			"rightward assignment",
+			`[1, 2] => [a]`,
+			"NoMatchingPatternError: [1, 2]",
+		},
+		{
+			"deconstruct returning no array",
+			`class Point; def deconstruct; 1; end; end; case Point.new; in [x] then x; end`,
+			"TypeError: deconstruct must return Array",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testEval(tt.input, object.NewMainEnvironment())
+
+			actual, ok := errors.Cause(err).(object.RubyObject)
+			utils.Assert(t, ok, "Error is not a RubyObject. got=%T (%+v)", err, err)
+			utils.AssertEqual(t, actual.Inspect(), tt.expectedMessage)
+		})
 	}
 }
 
@@ -367,6 +2000,12 @@ func TestAssignment(t *testing.T) {
 				`foo = 5; foo -= 3; foo`,
 				2,
 			},
+			{`foo = 3; foo **= 2`, 9},
+			{`foo = 12; foo &= 10`, 8},
+			{`foo = 12; foo |= 10`, 14},
+			{`foo = 12; foo ^= 10`, 6},
+			{`foo = 1; foo <<= 3; foo`, 8},
+			{`foo = 8; foo >>= 3; foo`, 1},
 		}
 
 		for _, tt := range tests {
@@ -377,6 +2016,44 @@ func TestAssignment(t *testing.T) {
 	})
 }
 
+func TestLogicalAssignment(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"||= on an undefined local", `x ||= 5; x`, 5},
+		{"||= on a truthy local", `x = 3; x ||= 5; x`, 3},
+		{"||= on a falsy local", `x = false; x ||= 5; x`, 5},
+		{"&&= on a truthy local", `x = 3; x &&= x + 1; x`, 4},
+		{"&&= on an undefined local", `x &&= 5; x == nil`, true},
+		{"||= on a method name", `def m; 42; end; m ||= 1; m`, 1},
+		{"||= on a global", `$x ||= 5; $x ||= 6; $x`, 5},
+		{"||= on an instance variable", `@x ||= 5; @x ||= 6; @x`, 5},
+		{"||= on a constant", `X ||= 5; X`, 5},
+		{"||= on an index", `h = {}; h[:a] ||= []; h[:a] << 1; h[:a] ||= 2; h[:a]`, []string{"1"}},
+		{"&&= on an index", `a = [1, nil]; a[0] &&= 2; a[1] &&= 3; a`, []string{"2", ":nil"}},
+		{
+			"||= on an attribute",
+			`class Foo; attr_accessor :bar; end; f = Foo.new; f.bar ||= 4; f.bar ||= 5; f.bar`,
+			4,
+		},
+		{
+			"||= does not call the setter",
+			`class Foo; attr_reader :bar; def initialize; @bar = 1; end; end; f = Foo.new; f.bar ||= 5`,
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testObject(t, evaluated, tt.expected)
+		})
+	}
+}
+
 func TestMultiAssignment(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -418,6 +2095,67 @@ func TestMultiAssignment(t *testing.T) {
 				object.NewInteger(2),
 			),
 		},
+		{
+			name:  "nested and splat targets",
+			input: "a, (b, c), *d, e = 1, [2, 3], 4, 5, 6; [a, b, c, d, e]",
+			output: object.NewArray(
+				object.NewInteger(1),
+				object.NewInteger(2),
+				object.NewInteger(3),
+				object.NewArray(object.NewInteger(4), object.NewInteger(5)),
+				object.NewInteger(6),
+			),
+		},
+		{
+			name:  "leading splat",
+			input: "*a, b = 1, 2, 3; [a, b]",
+			output: object.NewArray(
+				object.NewArray(object.NewInteger(1), object.NewInteger(2)),
+				object.NewInteger(3),
+			),
+		},
+		{
+			name:  "splat without values",
+			input: "a, *b = 1; [a, b]",
+			output: object.NewArray(
+				object.NewInteger(1),
+				object.NewArray(),
+			),
+		},
+		{
+			name:  "swap",
+			input: "a = 1; b = 2; a, b = b, a; [a, b]",
+			output: object.NewArray(
+				object.NewInteger(2),
+				object.NewInteger(1),
+			),
+		},
+		{
+			name:  "array value",
+			input: "a, b = [1, 2]; [a, b]",
+			output: object.NewArray(
+				object.NewInteger(1),
+				object.NewInteger(2),
+			),
+		},
+		{
+			name: "to_ary conversion",
+			input: `
+			class Pair
+				def to_ary
+					[1, 2]
+				end
+			end
+			a, b = Pair.new
+			c, (d, e) = 0, Pair.new
+			[a, b, d, e]`,
+			output: object.NewArray(
+				object.NewInteger(1),
+				object.NewInteger(2),
+				object.NewInteger(1),
+				object.NewInteger(2),
+			),
+		},
 	}
 
 	for _, tt := range tests {
@@ -558,6 +2296,27 @@ func TestGlobalLiteral(t *testing.T) {
 	utils.AssertEqual(t, str.Value, "bar")
 }
 
+func TestFloatLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5", 1.5},
+		{"1_000.25", 1000.25},
+		{"1e3", 1000},
+		{"1.5E-2", 0.015},
+		{"-2.5e+1", -25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testFloatObject(t, evaluated, tt.expected)
+		})
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 
@@ -568,6 +2327,50 @@ func TestStringLiteral(t *testing.T) {
 	utils.AssertEqual(t, str.Value, "Hello World!")
 }
 
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\tb\n"`, "a\tb\n"},
+		{`"\s\e\a\v\f\0"`, " \x1b\a\v\f\x00"},
+		{`"\101\x42\u0043\u{44 1F600}"`, "ABCD😀"},
+		{`"\cA\C-b\c?"`, "\x01\x02\x7f"},
+		{`"\M-a\M-\C-a"`, "\xe1\x81"},
+		{`"\\n\"\#{1}"`, `\n"#{1}`},
+		{`"\\#{1}"`, `\1`},
+		{`'\\n\'\"'`, `\n'\"`},
+		{`'#{1}\t'`, `#{1}\t`},
+		{`%q(\(\)\n)`, `()\n`},
+		{`%Q(\(\)\n)`, "()\n"},
+		{`:"a\tb"`, "a\tb"},
+		{`?\t`, "\t"},
+		{`?\u{41}`, "A"},
+		{"<<~EOS\n  \\ta\n  b\\n\nEOS\n", "\ta\nb\n\n"},
+		{"<<~'EOS'\n  \\ta\\\\\nEOS\n", `\ta\\` + "\n"},
+		{`"a
+b"`, "a\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			switch obj := evaluated.(type) {
+			case *object.Symbol:
+				utils.AssertEqual(t, obj.Value, tt.expected)
+			default:
+				testStringObject(t, evaluated, tt.expected)
+			}
+		})
+	}
+
+	t.Run("invalid escape", func(t *testing.T) {
+		_, err := testEval(`"\xZZ"`, object.NewMainEnvironment())
+		utils.AssertError(t, err, "invalid hex escape")
+	})
+}
+
 func TestStringConcatenation(t *testing.T) {
 	input := `"Hello" + " " + "World!"`
 
@@ -578,6 +2381,176 @@ func TestStringConcatenation(t *testing.T) {
 	utils.AssertEqual(t, str.Value, "Hello World!")
 }
 
+func TestAppendOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`a = [1]; a << 2 << 3; a`, []string{"1", "2", "3"}},
+		{`a = []; b = a; a << 1; b`, []string{"1"}},
+		{`s = "ab"; t = s; s << "c" << 100; t`, "abcd"},
+		{`s = "a"; s <<= "b"; s`, "ab"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		utils.AssertNoError(t, err)
+		testObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`name = "World"; "Hello #{name}!"`, "Hello World!"},
+		{`"1 + 1 = #{1 + 1}"`, "1 + 1 = 2"},
+		{`"#{1}#{2}"`, "12"},
+		{`"a #{"b #{"c"}"}"`, "a b c"},
+		{`"#{x = 2; x * 3}"`, "6"},
+		{`"#{[1, 2].map { |x| x * 2 }.first}"`, "2"},
+		{`"#{}"`, ""},
+		{`"#{nil}"`, ""},
+		{`"#{:sym}"`, "sym"},
+		{`"#{1.5}"`, "1.5"},
+		{`"#{[1, 2]}"`, "[1, 2]"},
+		{`"\#{a}"`, "#{a}"},
+		{`'#{a}'`, "#{a}"},
+		{`class Foo; def to_s; "foo"; end; end; "#{Foo.new}"`, "foo"},
+		{`class Foo; def to_s; 1; end; end; "#{Foo.new}"`, "#<Foo>"},
+		{"`echo #{1 + 1}`", "2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			testStringObject(t, evaluated, tt.expected)
+		})
+	}
+
+	t.Run("symbol", func(t *testing.T) {
+		evaluated, err := testEval(`a = 1; :"a#{a}"`, object.NewMainEnvironment())
+		utils.AssertNoError(t, err)
+		testSymbolObject(t, evaluated, "a1")
+	})
+}
+
+func TestRegexLiteral(t *testing.T) {
+	tests := []struct {
+		input   string
+		source  string
+		options int
+	}{
+		{`/a+b/`, "a+b", 0},
+		{`/a\/b/im`, `a\/b`, object.REGEXP_IGNORECASE | object.REGEXP_MULTILINE},
+		{`%r{a/b}x`, "a/b", object.REGEXP_EXTENDED},
+		{`x = "b"; /a#{x}\d/`, `ab\d`, 0},
+		{`x = /b/i; /a#{x}/`, "a(?i-mx:b)", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			re, ok := evaluated.(*object.Regexp)
+			utils.Assert(t, ok, "object is not Regexp. got=%T (%+v)", evaluated, evaluated)
+			utils.AssertEqual(t, re.Source, tt.source)
+			utils.AssertEqual(t, re.Options, tt.options)
+		})
+	}
+
+	t.Run("unsupported construct", func(t *testing.T) {
+		_, err := testEval(`/(a)\1/`, object.NewMainEnvironment())
+		utils.AssertError(t, errors.Cause(err), object.NewRegexpError("backreferences are not supported: /(a)\\1/"))
+	})
+}
+
+func TestRegexMatching(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"hello world" =~ /wor/`, 6},
+		{`/wor/ =~ "hello world"`, 6},
+		{`"hello" =~ /z/`, nil},
+		{`"hello" !~ /z/`, true},
+		{`"hello" !~ /l/`, false},
+		{`"FOO".match?(/foo/i)`, true},
+		{`/x+/ === "xxx"`, true},
+		{`"a\nb" =~ /^b/`, 2},
+		{`"a\nb" =~ /a.b/`, nil},
+		{`"a\nb" =~ /a.b/m`, 0},
+		{`"ab12" =~ /(\d+)/; $1`, "12"},
+		{`"ab12" =~ /(\d+)/; $2`, nil},
+		{`"ab" =~ /(\d+)/; $1`, nil},
+		{`"x-42" =~ /\d+/; $~[0]`, "42"},
+		{`m = /(\d+)-(?<word>\w+)/.match("abc 42-foo"); m[:word]`, "foo"},
+		{`m = /(\d+)-(?<word>\w+)/.match("abc 42-foo"); m["word"]`, "foo"},
+		{`m = /(\d+)-(\w+)/.match("abc 42-foo"); m[-1]`, "foo"},
+		{`/(\d+)/.match("abc 42").pre_match`, "abc "},
+		{`/(\d+)/.match("abc") { |m| m[0] }`, nil},
+		{`case "hello"; when /^h(.)/; $1; end`, "e"},
+		{`"abc".match(/b/); Regexp.last_match(0)`, "b"},
+		{`"ab" =~ /(a)/; def inner; "zz" =~ /(z)/; end; inner; $1`, "a"},
+		{`"ab" =~ /(a)/; def inner; $1; end; inner`, nil},
+		{`def outer; "ab" =~ /(b)/; [1].each { "q" =~ /(q)/ }; $1; end; outer`, "q"},
+		{`def outer; "ab" =~ /(b)/; [1].each { $1 }.first; $~[1]; end; outer`, "b"},
+		{`/a b # comment
+		  c/x.match?("abc")`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			switch expected := tt.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				testStringObject(t, evaluated, expected)
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			default:
+				testNilObject(t, evaluated)
+			}
+		})
+	}
+}
+
+func TestHeredocAndPercentLiterals(t *testing.T) {
+	tests := []struct {
+		input   string
+		inspect string
+	}{
+		{"x = <<EOS\n  a\nEOS\nx", "  a\n"},
+		{"x = <<-EOS\n  a\n  EOS\nx", "  a\n"},
+		{"x = <<~EOS\n    a\n      b\n  EOS\nx", "a\n  b\n"},
+		{"b = 1; <<~EOS\n  a#{b}\nEOS\n", "a1\n"},
+		{"b = 1; <<~'EOS'\n  a#{b}\nEOS\n", "a#{b}\n"},
+		{"[<<A, <<B]\na\nA\nb\nB\n", "[\"a\n\", \"b\n\"]"},
+		{"<<~EOS.size\n  ab\nEOS\n", `3`},
+		{"<<`EOS`\necho hi\nEOS\n", "hi\n"},
+		{`%w[a b\ c]`, `["a", "b c"]`},
+		{`%i(a b)`, `[:a, :b]`},
+		{`b = 1; %q(a#{b})`, `a#{b}`},
+		{`b = 1; %Q(a#{b})`, `a1`},
+		{`b = 1; %(a (#{b}))`, `a (1)`},
+		{`%s{a b}`, `:a b`},
+		{`%x(echo hi)`, "hi\n"},
+		{`10 % 3`, `1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, evaluated.Inspect(), tt.inspect)
+		})
+	}
+}
+
 func TestSymbolLiteral(t *testing.T) {
 	input := `:foobar;`
 
@@ -867,6 +2840,13 @@ func testIntegerObject(t *testing.T, obj object.RubyObject, expected int64) {
 	utils.AssertEqual(t, result.Value, expected)
 }
 
+func testFloatObject(t *testing.T, obj object.RubyObject, expected float64) {
+	t.Helper()
+	result, ok := obj.(*object.Float)
+	utils.Assert(t, ok, "object is not Float. got=%T (%+v)", obj, obj)
+	utils.AssertEqual(t, result.Value, expected)
+}
+
 func testSymbolObject(t *testing.T, obj object.RubyObject, expected string) {
 	t.Helper()
 	result, ok := obj.(*object.Symbol)
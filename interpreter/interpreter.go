@@ -28,7 +28,7 @@ func NewInterpreterEx(argv []string) Interpreter {
 		argvArr.Elements = append(argvArr.Elements, object.NewString(arg))
 	}
 	env.SetGlobal("ARGV", argvArr)
-	return &interpreter{environment: env}
+	return &interpreter{environment: env, fset: token.NewFileSet()}
 }
 
 // NewInterpreter returns an Interpreter ready to use and with the environment set to
@@ -39,13 +39,14 @@ func NewInterpreter() Interpreter {
 
 type interpreter struct {
 	environment     object.Environment
+	fset            *token.FileSet // shared by all inputs, so positions stay valid across calls
 	trace_parse     bool
 	trace_transform bool
 	trace_eval      bool
 }
 
 func (i *interpreter) Interpret(filename string, input interface{}) (object.RubyObject, error) {
-	program, tracer, err := parser.ParseFileEx(token.NewFileSet(), filename, input, i.trace_parse)
+	program, tracer, err := parser.ParseFileEx(i.fset, filename, input, i.trace_parse)
 	if tracer != nil {
 		walkable, err := tracer.ToWalkable()
 		if err != nil {
@@ -66,7 +67,7 @@ func (i *interpreter) Interpret(filename string, input interface{}) (object.Ruby
 		}
 	}
 
-	res, tracer, err := evaluator.EvalEx(program, i.environment, i.trace_eval)
+	res, tracer, err := evaluator.EvalEx(program, i.environment, i.fset, i.trace_eval)
 	if tracer != nil {
 		walkable, err := tracer.ToWalkable()
 		if err != nil {
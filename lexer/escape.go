@@ -0,0 +1,191 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// simpleEscapes maps the escape sequences of double quoted strings which
+// stand for a single character
+var simpleEscapes = map[byte]byte{
+	'n': '\n',
+	't': '\t',
+	's': ' ',
+	'r': '\r',
+	'v': '\v',
+	'f': '\f',
+	'a': '\a',
+	'b': '\b',
+	'e': 0x1b,
+}
+
+// unescape decodes the escape sequences of the content of a double quoted
+// string, e.g. `\n`, `\x41`, `\u{1F600}` or `\C-a`. An escaped newline joins
+// the lines, and any other escaped character stands for itself.
+func unescape(content string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\\' {
+			out.WriteByte(content[i])
+			continue
+		}
+		value, n, err := decodeEscape(content[i+1:])
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(value)
+		i += n
+	}
+	return out.String(), nil
+}
+
+// unescapeQuoted decodes the content of a single quoted string, e.g. `'a'` or
+// `%q(a)`, in which only a backslash and the delimiters can be escaped
+func unescapeQuoted(content string, d delimiter) string {
+	var out strings.Builder
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\\' && i+1 < len(content) {
+			next := rune(content[i+1])
+			if next == '\\' || next == d.close || (d.open != 0 && next == d.open) {
+				i++
+			}
+		}
+		out.WriteByte(content[i])
+	}
+	return out.String()
+}
+
+// decodeEscape decodes the escape sequence at the start of s, which follows a
+// backslash. It returns the decoded text and the number of bytes consumed.
+func decodeEscape(s string) (string, int, error) {
+	if s == "" {
+		return "\\", 0, nil
+	}
+	c := s[0]
+	if value, ok := simpleEscapes[c]; ok {
+		return string(value), 1, nil
+	}
+	switch {
+	case c == '\n':
+		return "", 1, nil
+	case c >= '0' && c <= '7':
+		n := 1
+		for n < 3 && n < len(s) && s[n] >= '0' && s[n] <= '7' {
+			n++
+		}
+		value, _ := strconv.ParseUint(s[:n], 8, 8)
+		return string([]byte{byte(value)}), n, nil
+	case c == 'x':
+		n := 1
+		for n < 3 && n < len(s) && isHexDigit(s[n]) {
+			n++
+		}
+		if n == 1 {
+			return "", 0, fmt.Errorf("invalid hex escape")
+		}
+		value, _ := strconv.ParseUint(s[1:n], 16, 8)
+		return string([]byte{byte(value)}), n, nil
+	case c == 'u':
+		return decodeUnicodeEscape(s)
+	case c == 'c' || c == 'C' || c == 'M':
+		value, n, err := decodeControlEscape(s)
+		if err != nil {
+			return "", 0, err
+		}
+		return string([]byte{value}), n, nil
+	default:
+		_, size := utf8.DecodeRuneInString(s)
+		return s[:size], size, nil
+	}
+}
+
+// decodeUnicodeEscape decodes `unnnn` or `u{nnnn ...}` at the start of s,
+// where the braces hold one or more codepoints separated by spaces
+func decodeUnicodeEscape(s string) (string, int, error) {
+	if !strings.HasPrefix(s, "u{") {
+		if len(s) < 5 || !isHexDigits(s[1:5]) {
+			return "", 0, fmt.Errorf("invalid Unicode escape")
+		}
+		value, _ := strconv.ParseUint(s[1:5], 16, 32)
+		return string(rune(value)), 5, nil
+	}
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated Unicode escape")
+	}
+	codepoints := strings.Fields(s[2:end])
+	if len(codepoints) == 0 {
+		return "", 0, fmt.Errorf("invalid Unicode escape")
+	}
+	var out strings.Builder
+	for _, codepoint := range codepoints {
+		if len(codepoint) > 6 || !isHexDigits(codepoint) {
+			return "", 0, fmt.Errorf("invalid Unicode escape")
+		}
+		value, _ := strconv.ParseUint(codepoint, 16, 32)
+		if value > utf8.MaxRune || (value >= 0xd800 && value <= 0xdfff) {
+			return "", 0, fmt.Errorf("invalid Unicode codepoint")
+		}
+		out.WriteRune(rune(value))
+	}
+	return out.String(), end + 1, nil
+}
+
+// decodeControlEscape decodes a control or meta character at the start of s,
+// i.e. `cx`, `C-x` or `M-x`, where x may itself be an escaped control or meta
+// character, e.g. `M-\C-x`
+func decodeControlEscape(s string) (byte, int, error) {
+	var n int
+	meta := false
+	switch {
+	case s[0] == 'c':
+		n = 1
+	case strings.HasPrefix(s, "C-"):
+		n = 2
+	case strings.HasPrefix(s, "M-"):
+		n, meta = 2, true
+	default:
+		return 0, 0, fmt.Errorf("invalid control escape")
+	}
+	if n >= len(s) {
+		return 0, 0, fmt.Errorf("invalid control escape")
+	}
+	value := s[n]
+	size := 1
+	if value == '\\' {
+		decoded, m, err := decodeEscape(s[n+1:])
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(decoded) != 1 {
+			return 0, 0, fmt.Errorf("invalid control escape")
+		}
+		value, size = decoded[0], m+1
+	} else if value >= utf8.RuneSelf {
+		return 0, 0, fmt.Errorf("invalid control escape")
+	}
+	switch {
+	case meta:
+		value |= 0x80
+	case value == '?':
+		value = 0x7f
+	default:
+		value &= 0x9f
+	}
+	return value, n + size, nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isHexDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isHexDigit(s[i]) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,88 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{`a\nb`, "a\nb"},
+		{`\t\s\r\v\f\a\b\e`, "\t \r\v\f\a\b\x1b"},
+		{`\\ \" \# \q`, `\ " # q`},
+		{`\0`, "\x00"},
+		{`\101\1012`, "AA2"},
+		{`\x41\x4\x4g`, "A\x04\x04g"},
+		{`éé`, "éé"},
+		{`\u{41}\u{1F600}`, "A😀"},
+		{`\u{41 42  43}`, "ABC"},
+		{`\cA\ca\C-a`, "\x01\x01\x01"},
+		{`\c?\C-?`, "\x7f\x7f"},
+		{`\M-a`, "\xe1"},
+		{`\M-\C-a\M-\ca\c\M-a`, "\x81\x81\x81"},
+		{`\C-\x41`, "\x01"},
+		{"a\\\nb", "ab"},
+		{`é\é`, "éé"},
+		{`a\`, `a\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			result, err := unescape(tt.content)
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, result, tt.expected)
+		})
+	}
+}
+
+func TestUnescapeInvalid(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{`\xg`, "invalid hex escape"},
+		{`\u12`, "invalid Unicode escape"},
+		{`\u{}`, "invalid Unicode escape"},
+		{`\u{1234567}`, "invalid Unicode escape"},
+		{`\u{41`, "unterminated Unicode escape"},
+		{`\u{110000}`, "invalid Unicode codepoint"},
+		{`\u{d800}`, "invalid Unicode codepoint"},
+		{`\C`, "invalid control escape"},
+		{`\M-`, "invalid control escape"},
+		{`\cé`, "invalid control escape"},
+		{`\C-\u{41 42}`, "invalid control escape"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			_, err := unescape(tt.content)
+
+			utils.AssertError(t, err, "^"+tt.err+"$")
+		})
+	}
+}
+
+func TestUnescapeQuoted(t *testing.T) {
+	tests := []struct {
+		content  string
+		d        delimiter
+		expected string
+	}{
+		{`it\'s`, delimiter{close: '\''}, `it's`},
+		{`a\\b\nc\"`, delimiter{close: '\''}, `a\b\nc\"`},
+		{`\(a\) \\`, delimiter{open: '(', close: ')'}, `(a) \`},
+		{`a\|b\'`, delimiter{close: '|'}, `a|b\'`},
+		{`a\`, delimiter{close: '\''}, `a\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			utils.AssertEqual(t, unescapeQuoted(tt.content, tt.d), tt.expected)
+		})
+	}
+}
@@ -35,13 +35,66 @@ func New(input string) *Lexer {
 
 // Lexer is the engine to process input and emit Tokens
 type Lexer struct {
-	input     string           // the string being scanned.
-	state     StateFn          // the next lexing function to enter
-	pos       int              // current position in the input.
-	start     int              // start position of this item.
-	width     int              // width of last rune read from input.
-	tokens    chan token.Token // channel of scanned tokens.
-	lastToken token.Token      // lastToken stores the last token emitted by the lexer
+	input          string           // the string being scanned.
+	state          StateFn          // the next lexing function to enter
+	pos            int              // current position in the input.
+	start          int              // start position of this item.
+	width          int              // width of last rune read from input.
+	tokens         chan token.Token // channel of scanned tokens.
+	lastToken      token.Token      // lastToken stores the last token emitted by the lexer
+	interpolations []interpolation  // the strings enclosing the code being lexed
+	heredocEnd     int              // the end of the heredoc bodies following the current line, if any
+}
+
+// delimiter describes where a string literal ends
+type delimiter struct {
+	open    rune     // the opening bracket of a percent literal, e.g. `%r{...}`, which may nest
+	close   rune     // the rune ending the string
+	regex   bool     // a regex literal, whose options follow its end
+	raw     bool     // a literal without interpolation, e.g. `%q(...)`
+	heredoc *heredoc // a heredoc body, which ends at the line holding its identifier
+}
+
+// heredoc describes a here document, e.g. `<<~EOS`, whose body follows the
+// line it starts on
+type heredoc struct {
+	id       string // the identifier on the line ending the body
+	indented bool   // the identifier may be indented, as with `<<-` and `<<~`
+	dedent   int    // the indentation stripped from the lines of a `<<~` body
+	resume   int    // the position the line holding the heredoc continues at
+}
+
+// terminates reports whether line ends the body of h
+func (h *heredoc) terminates(line string) bool {
+	line = strings.TrimSuffix(line, "\r")
+	if h.indented {
+		line = strings.TrimLeft(line, " \t")
+	}
+	return line == h.id
+}
+
+// percentDelimiter returns the delimiter of a percent literal opened with
+// open, e.g. `%r{...}` or `%r|...|`
+func percentDelimiter(open rune) delimiter {
+	switch open {
+	case '(':
+		return delimiter{open: open, close: ')'}
+	case '[':
+		return delimiter{open: open, close: ']'}
+	case '{':
+		return delimiter{open: open, close: '}'}
+	case '<':
+		return delimiter{open: open, close: '>'}
+	default:
+		return delimiter{close: open}
+	}
+}
+
+// interpolation tracks the string the code embedded with `#{` belongs to
+type interpolation struct {
+	delimiter delimiter // the delimiter of the enclosing string
+	nesting   int       // the brackets the string opened before the embedded code
+	braces    int       // the number of braces opened within the embedded code
 }
 
 // NextToken will return the next token processed from the lexer.
@@ -72,7 +125,13 @@ func (l *Lexer) HasNext() bool {
 
 // emit passes a token back to the client.
 func (l *Lexer) emit(t token.Type) {
-	token := token.NewToken(t, l.input[l.start:l.pos], l.start)
+	l.emitLiteral(t, l.input[l.start:l.pos])
+}
+
+// emitLiteral passes a token with the given literal back to the client, for
+// tokens whose literal differs from the input, e.g. a dedented heredoc.
+func (l *Lexer) emitLiteral(t token.Type, literal string) {
+	token := token.NewToken(t, literal, l.start)
 	l.lastToken = token
 	l.tokens <- token
 	l.start = l.pos
@@ -142,6 +201,25 @@ func startLexer(l *Lexer) StateFn {
 		case '[':
 			l.next() // consume the whitespace
 			l.emit(token.SLBRACKET)
+		case '&':
+			// a space-disambiguated '&' passes a block argument, as in
+			// `list.each &handler`
+			if l.pos+1 < len(l.input) && !strings.ContainsRune(" \t\n&=", rune(l.input[l.pos+1])) {
+				l.next() // consume the ampersand
+				l.emit(token.SAND)
+			} else {
+				l.ignore()
+			}
+		case ':':
+			// a space-disambiguated '::' refers to a top level constant, as
+			// in `puts ::Foo`
+			if l.peek_string_match("::") && l.pos+2 < len(l.input) && !isWhitespace(rune(l.input[l.pos+2])) {
+				l.next() // consume the first colon
+				l.next() // consume the second colon
+				l.emit(token.SSCOPE)
+			} else {
+				l.ignore()
+			}
 		case 'o':
 			// hack to handle space-disambiguated 'or'
 			if l.peek_string_match("or ") {
@@ -174,16 +252,30 @@ func startLexer(l *Lexer) StateFn {
 	switch r {
 	case '$':
 		return lexGlobal
+	case '@':
+		return lexInstanceVariable
 	case '\n':
 		l.emit(token.NEWLINE)
+		if l.heredocEnd > 0 {
+			// skip the heredoc bodies already lexed
+			l.pos, l.start, l.heredocEnd = l.heredocEnd, l.heredocEnd, 0
+		}
 		return startLexer
 	case '\'':
-		return lexSingleQuoteString
+		return lexDelimitedString(delimiter{close: '\'', raw: true})
 	case '"':
 		return lexString('"')
+	case '`':
+		l.emit(token.BACKTICK)
+		return lexString('`')
 	case ':':
 		p := l.peek()
-		if isWhitespace(p) {
+		if p == ':' {
+			l.next()
+			l.emit(token.SCOPE)
+			return startLexer
+		}
+		if isWhitespace(p) || isLabelDelimiter(p) {
 			l.emit(token.COLON)
 			return startLexer
 		}
@@ -204,9 +296,17 @@ func startLexer(l *Lexer) StateFn {
 		l.emit(token.DOT)
 		return startLexer
 	case '=':
-		if l.peek() == '=' {
+		if l.peek() == '~' {
 			l.next()
-			l.emit(token.EQ)
+			l.emit(token.MATCH)
+		} else if l.peek() == '=' {
+			l.next()
+			if l.peek() == '=' {
+				l.next()
+				l.emit(token.CASEEQ)
+			} else {
+				l.emit(token.EQ)
+			}
 		} else if l.peek() == '>' {
 			l.next()
 			l.emit(token.HASHROCKET)
@@ -239,6 +339,9 @@ func startLexer(l *Lexer) StateFn {
 		if l.peek() == '=' {
 			l.next()
 			l.emit(token.NOTEQ)
+		} else if l.peek() == '~' {
+			l.next()
+			l.emit(token.NOTMATCH)
 		} else {
 			l.emit(token.BANG)
 		}
@@ -261,11 +364,12 @@ func startLexer(l *Lexer) StateFn {
 			l.next()
 			l.emit(token.DIVASSIGN)
 			return startLexer
-		} else if isWhitespace(p) || isExpressionDelimiter(p) {
+		} else if isWhitespace(p) || isExpressionDelimiter(p) || !l.literalAllowed() {
 			l.emit(token.SLASH)
 			return startLexer
 		} else {
-			return lexString('/')
+			l.emit(token.REGEX)
+			return lexDelimitedString(delimiter{close: '/', regex: true})
 		}
 	case '*':
 		if l.peek() == '=' {
@@ -274,12 +378,22 @@ func startLexer(l *Lexer) StateFn {
 			return startLexer
 		} else if l.peek() == '*' {
 			l.next()
+			if l.peek() == '=' {
+				l.next()
+				l.emit(token.POWASSIGN)
+				return startLexer
+			}
 			l.emit(token.POW)
 			return startLexer
 		}
 		l.emit(token.ASTERISK)
 		return startLexer
 	case '%':
+		if l.literalAllowed() {
+			if literal := l.lexPercentLiteral(); literal != nil {
+				return literal
+			}
+		}
 		if l.peek() == '=' {
 			l.next()
 			l.emit(token.MODASSIGN)
@@ -290,8 +404,17 @@ func startLexer(l *Lexer) StateFn {
 	case '&':
 		if p := l.peek(); p == '&' {
 			l.next()
+			if l.peek() == '=' {
+				l.next()
+				l.emit(token.LOGICALANDASSIGN)
+				return startLexer
+			}
 			l.emit(token.LOGICALAND)
 			return startLexer
+		} else if p == '=' {
+			l.next()
+			l.emit(token.ANDASSIGN)
+			return startLexer
 		}
 		l.emit(token.AND)
 		return startLexer
@@ -307,7 +430,17 @@ func startLexer(l *Lexer) StateFn {
 			return startLexer
 		}
 		if l.peek() == '<' {
+			if l.literalAllowed() {
+				if heredoc := l.lexHeredoc(); heredoc != nil {
+					return heredoc
+				}
+			}
 			l.next()
+			if l.peek() == '=' {
+				l.next()
+				l.emit(token.LSHIFTASSIGN)
+				return startLexer
+			}
 			l.emit(token.LSHIFT)
 			return startLexer
 		}
@@ -319,6 +452,16 @@ func startLexer(l *Lexer) StateFn {
 			l.emit(token.GTE)
 			return startLexer
 		}
+		if l.peek() == '>' {
+			l.next()
+			if l.peek() == '=' {
+				l.next()
+				l.emit(token.RSHIFTASSIGN)
+				return startLexer
+			}
+			l.emit(token.RSHIFT)
+			return startLexer
+		}
 		l.emit(token.GT)
 		return startLexer
 	case '(':
@@ -328,9 +471,22 @@ func startLexer(l *Lexer) StateFn {
 		l.emit(token.RPAREN)
 		return startLexer
 	case '{':
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1].braces++
+		}
 		l.emit(token.LBRACE)
 		return startLexer
 	case '}':
+		if n := len(l.interpolations); n > 0 {
+			if l.interpolations[n-1].braces == 0 {
+				// the end of the embedded code, back to the string
+				enclosing := l.interpolations[n-1]
+				l.interpolations = l.interpolations[:n-1]
+				l.emit(token.RBRACE)
+				return lexStringContent(enclosing.delimiter, enclosing.nesting)
+			}
+			l.interpolations[n-1].braces--
+		}
 		l.emit(token.RBRACE)
 		return startLexer
 	case '[':
@@ -350,6 +506,17 @@ func startLexer(l *Lexer) StateFn {
 		return startLexer
 	case '#':
 		return commentLexer
+	case '^':
+		if l.peek() == '=' {
+			l.next()
+			l.emit(token.XORASSIGN)
+			return startLexer
+		}
+		l.emit(token.CARET)
+		return startLexer
+	case '~':
+		l.emit(token.TILDE)
+		return startLexer
 	case '|':
 		if l.lastToken.Type == token.LBRACE {
 			l.emit(token.PIPE)
@@ -357,8 +524,17 @@ func startLexer(l *Lexer) StateFn {
 		}
 		if p := l.peek(); p == '|' {
 			l.next()
+			if l.peek() == '=' {
+				l.next()
+				l.emit(token.LOGICALORASSIGN)
+				return startLexer
+			}
 			l.emit(token.LOGICALOR)
 			return startLexer
+		} else if p == '=' {
+			l.next()
+			l.emit(token.ORASSIGN)
+			return startLexer
 		}
 		l.emit(token.PIPE)
 		return startLexer
@@ -378,6 +554,7 @@ func startLexer(l *Lexer) StateFn {
 const OPERATOR_CHARS = "+-*/%&<>=,;#.:(){}[]|@$?!"
 const IDENT_CHARS = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_?!"
 const LEGAL_IDENT_CHARS = "?!"
+const REGEX_OPTION_CHARS = "imxonesu"
 
 func lexIdentifierOrKeywordCore(l *Lexer) rune {
 	r := l.next()
@@ -393,66 +570,138 @@ func lexIdentifierOrKeywordCore(l *Lexer) rune {
 }
 
 func lexIdentifierOrKeyword(l *Lexer) StateFn {
-	r := lexIdentifierOrKeywordCore(l)
+	_ = lexIdentifierOrKeywordCore(l)
 	literal := l.input[l.start:l.pos]
-	t := token.LookupIdent(literal)
-	if t == token.COMMENT {
-		// TODO: i think this branch can be removed?
-		for r != '\n' && r != eof {
-			r = l.next()
-		}
-		l.backup()
-		l.emit(token.COMMENT)
-	} else {
-		l.emit(t)
-	}
+	l.emit(token.LookupIdent(literal))
 	return startLexer
 }
 
+// numberPrefixes maps the prefixes of integer literals, e.g. the `x` of
+// `0x1F`, to their base
+var numberPrefixes = map[byte]int{
+	'x': 16, 'X': 16,
+	'b': 2, 'B': 2,
+	'o': 8, 'O': 8,
+	'd': 10, 'D': 10,
+	'_': 8,
+}
+
+// baseNames names the bases of integer literals in errors
+var baseNames = map[int]string{
+	16: "hexadecimal",
+	10: "decimal",
+	8:  "octal",
+	2:  "binary",
+}
+
+// lexNumber lexes an integer, e.g. `1_000`, `0x1F`, `0b101`, `0o17` or `017`,
+// or a float, e.g. `1.5` or `1.2e-3`. Integers and floats without an exponent
+// may be rational, e.g. `3r`, and any number may be imaginary, e.g. `2i` or
+// `1.5ri`.
 func lexNumber(l *Lexer) StateFn {
-	// walk until we find a non digit
-	r := l.next()
-	for isDigitOrUnderscore(r) {
-		r = l.next()
+	l.backup()
+	if l.peek() == '0' && l.pos+1 < len(l.input) {
+		next := l.input[l.pos+1]
+		if base, ok := numberPrefixes[next]; ok || isDigit(rune(next)) {
+			if ok && next != '_' {
+				l.pos += 2 // consume the prefix
+			} else {
+				base = 8 // a leading zero, e.g. `017`
+			}
+			digits, err := l.lexDigits(base)
+			if err != nil {
+				return l.errorf("%s", err)
+			}
+			if digits == 0 {
+				return l.errorf("numeric literal without digits")
+			}
+			if r := l.peek(); isDigit(r) {
+				return l.errorf("invalid digit '%c' in %s literal", r, baseNames[base])
+			}
+			return lexNumberSuffix(token.INT)
+		}
 	}
-	if r == '.' {
-		// 123. ..
-		r = l.next()
-		if isDigit(r) {
-			// 123.4 ..
-			// walk until we find a non digit
-			for isDigitOrUnderscore(r) {
-				r = l.next()
+	if _, err := l.lexDigits(10); err != nil {
+		return l.errorf("%s", err)
+	}
+	t := token.INT
+	if l.peek() == '.' && l.pos+1 < len(l.input) && isDigit(rune(l.input[l.pos+1])) {
+		// 123.4, whereas `123.to_s` calls a method
+		l.next()
+		if _, err := l.lexDigits(10); err != nil {
+			return l.errorf("%s", err)
+		}
+		t = token.FLOAT
+	}
+	if r := l.peek(); r == 'e' || r == 'E' {
+		exponent := l.input[l.pos+1:]
+		if exponent != "" && (exponent[0] == '+' || exponent[0] == '-') {
+			exponent = exponent[1:]
+		}
+		if exponent != "" && isDigit(rune(exponent[0])) {
+			l.pos = len(l.input) - len(exponent)
+			if _, err := l.lexDigits(10); err != nil {
+				return l.errorf("%s", err)
+			}
+			// an exponent rules out a rational suffix
+			t = token.FLOAT
+			if strings.HasPrefix(l.input[l.pos:], "r") {
+				return l.errorf("rational suffix after an exponent")
 			}
-			l.backup()
-			l.emit(token.FLOAT)
-		} else {
-			// 123. ..
-			// maybe a method call. back up twice
-			l.backup()
-			l.backup()
-			l.emit(token.INT)
 		}
-	} else {
-		// we have an int
-		l.backup()
-		l.emit(token.INT)
 	}
-	return startLexer
+	return lexNumberSuffix(t)
 }
 
-func lexSingleQuoteString(l *Lexer) StateFn {
-	l.ignore()
-	r := l.next()
+// lexNumberSuffix emits the number lexed so far as t, or as a RATIONAL or an
+// IMAGINARY if it is followed by an `r`, an `i` or `ri`. A suffix followed by
+// an identifier character is not a suffix, e.g. the `if` of `1if x`.
+func lexNumberSuffix(t token.Type) StateFn {
+	return func(l *Lexer) StateFn {
+		for _, suffix := range []struct {
+			literal string
+			t       token.Type
+		}{{"ri", token.IMAGINARY}, {"r", token.RATIONAL}, {"i", token.IMAGINARY}} {
+			rest := l.input[l.pos:]
+			if !strings.HasPrefix(rest, suffix.literal) {
+				continue
+			}
+			if end := len(suffix.literal); end < len(rest) && strings.ContainsRune(IDENT_CHARS, rune(rest[end])) {
+				continue
+			}
+			l.pos += len(suffix.literal)
+			t = suffix.t
+			break
+		}
+		l.emit(t)
+		return startLexer
+	}
+}
 
-	for r != '\'' {
-		r = l.next()
+// lexDigits consumes the digits of a number in base, which may be separated
+// by single underscores, and returns their count
+func (l *Lexer) lexDigits(base int) (int, error) {
+	digits := 0
+	underscore := false
+	for {
+		r := l.peek()
+		switch {
+		case r == '_' && digits == 0:
+			return 0, fmt.Errorf("numeric literal without digits")
+		case r == '_' && underscore:
+			return 0, fmt.Errorf("trailing '_' in number")
+		case r == '_':
+			underscore = true
+		case isDigitIn(r, base):
+			underscore = false
+			digits++
+		case underscore:
+			return 0, fmt.Errorf("trailing '_' in number")
+		default:
+			return digits, nil
+		}
+		l.next()
 	}
-	l.backup()
-	l.emit(token.STRING)
-	l.next()
-	l.ignore()
-	return startLexer
 }
 
 func lexCharacterLiteral(l *Lexer) StateFn {
@@ -461,13 +710,19 @@ func lexCharacterLiteral(l *Lexer) StateFn {
 	if isWhitespace(r) && r != '\t' && r != '\v' && r != '\f' && r != '\r' {
 		return l.errorf("invalid character syntax; use ?\\s")
 	}
+	literal := string(r)
 	if r == '\\' {
-		l.next()
+		value, n, err := decodeEscape(l.input[l.pos:])
+		if err != nil {
+			return l.errorf("%s", err)
+		}
+		literal = value
+		l.pos += n
 	}
 	if p := l.peek(); !isWhitespace(p) && !isExpressionDelimiter(p) {
 		return l.errorf("unexpected '?'")
 	}
-	l.emit(token.STRING)
+	l.emitLiteral(token.STRING, literal)
 	return startLexer
 }
 
@@ -478,25 +733,316 @@ func lexSymbol(l *Lexer) StateFn {
 }
 
 func lexString(end rune) StateFn {
+	return lexDelimitedString(delimiter{close: end})
+}
+
+func lexDelimitedString(d delimiter) StateFn {
 	return func(l *Lexer) StateFn {
 		l.ignore()
-		r := l.next()
+		return lexStringContent(d, 0)
+	}
+}
 
-		for r != end {
-			if r == '\\' {
+// lexStringContent lexes a string up to its end delimiter. Code embedded with
+// `#{...}` ends the string token so far and is lexed as usual up to its
+// closing brace, after which the string continues. A string with embedded
+// code hence consists of STRING, INTERPOLATION, the tokens of the code,
+// RBRACE and another STRING.
+func lexStringContent(d delimiter, nesting int) StateFn {
+	return func(l *Lexer) StateFn {
+		for {
+			if d.heredoc != nil && l.input[l.pos-1] == '\n' {
+				if end, ok := l.heredocTerminator(d.heredoc); ok {
+					if err := l.emitString(d); err != nil {
+						return l.errorf("%s", err)
+					}
+					l.heredocEnd = end
+					l.pos, l.start = d.heredoc.resume, d.heredoc.resume
+					return startLexer
+				}
+			}
+			r := l.next()
+			switch {
+			case r == eof:
+				return l.errorf("unterminated string meets end of file")
+			case r == '\\':
 				l.next()
+			case r == '#' && l.peek_string_match("{") && !d.raw:
+				l.backup()
+				if err := l.emitString(d); err != nil {
+					return l.errorf("%s", err)
+				}
+				l.next() // consume the '#'
+				l.next() // consume the '{'
+				l.emit(token.INTERPOLATION)
+				l.interpolations = append(l.interpolations, interpolation{delimiter: d, nesting: nesting})
+				return startLexer
+			case d.heredoc != nil:
+				// heredocs end at a line, not at a delimiter
+			case d.open != 0 && r == d.open:
+				nesting++
+			case r == d.close && nesting > 0:
+				nesting--
+			case r == d.close:
+				l.backup()
+				if err := l.emitString(d); err != nil {
+					return l.errorf("%s", err)
+				}
+				l.next()
+				l.ignore()
+				if d.regex {
+					return lexRegexOptions
+				}
+				return startLexer
 			}
-			r = l.next()
+		}
+	}
+}
+
+// emitString emits the string content lexed so far with its escape sequences
+// decoded, stripping the indentation from the lines of a `<<~` heredoc first.
+// Regex literals and single quoted heredocs are emitted verbatim.
+func (l *Lexer) emitString(d delimiter) error {
+	content := l.input[l.start:l.pos]
+	if d.heredoc != nil && d.heredoc.dedent > 0 {
+		lines := strings.SplitAfter(content, "\n")
+		for i, line := range lines {
+			// the content after embedded code does not start a line
+			if i > 0 || l.input[l.start-1] == '\n' {
+				lines[i] = dedent(line, d.heredoc.dedent)
+			}
+		}
+		content = strings.Join(lines, "")
+	}
+	switch {
+	case d.regex || (d.raw && d.heredoc != nil):
+	case d.raw:
+		content = unescapeQuoted(content, d)
+	default:
+		var err error
+		content, err = unescape(content)
+		if err != nil {
+			return err
+		}
+	}
+	l.emitLiteral(token.STRING, content)
+	return nil
+}
+
+// dedent removes up to width spaces or tabs from the start of line
+func dedent(line string, width int) string {
+	i := 0
+	for i < width && i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	return line[i:]
+}
+
+// heredocTerminator reports whether the line at the current position ends the
+// body of h, and returns the position following that line
+func (l *Lexer) heredocTerminator(h *heredoc) (int, bool) {
+	line, end := l.input[l.pos:], len(l.input)
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line, end = line[:i], l.pos+i+1
+	}
+	return end, h.terminates(line)
+}
+
+// lexHeredoc lexes the start of a here document, e.g. `<<~EOS` or
+// `<<-'EOS'`, and continues with its body on the next line. The rest of the
+// line holding the heredoc is lexed after the body. It returns nil if the
+// `<<` at the current position does not start a heredoc.
+func (l *Lexer) lexHeredoc() StateFn {
+	rest := l.input[l.pos+1:] // after the second '<'
+	h := &heredoc{}
+	squiggly := false
+	if rest != "" && (rest[0] == '-' || rest[0] == '~') {
+		h.indented, squiggly = true, rest[0] == '~'
+		rest = rest[1:]
+	}
+	var quote byte
+	if rest != "" && strings.ContainsRune("'\"`", rune(rest[0])) {
+		quote = rest[0]
+		end := strings.IndexByte(rest[1:], quote)
+		if end <= 0 || strings.ContainsRune(rest[1:end+1], '\n') {
+			return nil
+		}
+		h.id, rest = rest[1:end+1], rest[end+2:]
+	} else {
+		n := 0
+		for n < len(rest) && (isLetter(rune(rest[n])) || isDigit(rune(rest[n]))) {
+			n++
+		}
+		if n == 0 || isDigit(rune(rest[0])) {
+			return nil
+		}
+		h.id, rest = rest[:n], rest[n:]
+	}
+	l.pos = len(l.input) - len(rest)
+	h.resume = l.pos
+	if quote == '`' {
+		l.emit(token.BACKTICK)
+	}
+	l.ignore()
+
+	body := l.heredocEnd
+	if body == 0 {
+		newline := strings.IndexByte(rest, '\n')
+		if newline < 0 {
+			return func(l *Lexer) StateFn {
+				return l.errorf("unterminated here document meets end of file")
+			}
+		}
+		body = l.pos + newline + 1
+	}
+	if squiggly {
+		h.dedent = heredocIndentation(h, l.input[body:])
+	}
+	l.pos, l.start = body, body
+	return lexStringContent(delimiter{raw: quote == '\'', heredoc: h}, 0)
+}
+
+// heredocIndentation returns the indentation of the least indented line of
+// the body of h, ignoring blank lines. A tab counts as a single character.
+func heredocIndentation(h *heredoc, body string) int {
+	indentation := -1
+	for _, line := range strings.Split(body, "\n") {
+		if h.terminates(line) {
+			break
+		}
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+		if width := len(line) - len(trimmed); indentation < 0 || width < indentation {
+			indentation = width
+		}
+	}
+	if indentation < 0 {
+		return 0
+	}
+	return indentation
+}
+
+// lexPercentLiteral lexes a percent literal, e.g. `%w[a b]` or `%q(a)`, with
+// any pair of delimiters. It returns nil if the '%' at the current position is
+// the modulo operator.
+func (l *Lexer) lexPercentLiteral() StateFn {
+	kind := l.peek()
+	if !isLetter(kind) {
+		// a double quoted string, e.g. `%(a "b")`
+		if !strings.ContainsRune("([{<|!", kind) {
+			return nil
+		}
+		return lexDelimitedString(percentDelimiter(l.next()))
+	}
+	if !strings.ContainsRune("wiqQsrx", kind) || l.pos+1 >= len(l.input) || !isPercentDelimiter(rune(l.input[l.pos+1])) {
+		return nil
+	}
+	l.next() // consume the kind
+	d := percentDelimiter(l.next())
+	switch kind {
+	case 'w':
+		l.emit(token.WORDS)
+		return lexWords(d)
+	case 'i':
+		l.emit(token.SYMBOLS)
+		return lexWords(d)
+	case 'q':
+		d.raw = true
+	case 's':
+		d.raw = true
+		l.emit(token.SYMBOL)
+	case 'r':
+		d.regex = true
+		l.emit(token.REGEX)
+	case 'x':
+		l.emit(token.BACKTICK)
+	}
+	return lexDelimitedString(d)
+}
+
+// lexWords lexes the next whitespace separated element of a `%w` or `%i`
+// literal into a STRING. A backslash escapes whitespace, the delimiters and
+// itself.
+func lexWords(d delimiter) StateFn {
+	return func(l *Lexer) StateFn {
+		for r := l.next(); unicode.IsSpace(r); r = l.next() {
 		}
 		l.backup()
-		l.emit(token.STRING)
-		l.next()
 		l.ignore()
-		return startLexer
+		var word strings.Builder
+		nesting := 0
+		for {
+			r := l.next()
+			switch {
+			case r == eof:
+				return l.errorf("unterminated list meets end of file")
+			case r == '\\' && (unicode.IsSpace(l.peek()) || l.peek() == d.close || l.peek() == d.open || l.peek() == '\\'):
+				word.WriteRune(l.next())
+			case r == d.close && nesting == 0 && l.pos-l.width == l.start:
+				// the end of the list
+				l.ignore()
+				return startLexer
+			case unicode.IsSpace(r) || r == d.close && nesting == 0:
+				l.backup()
+				l.emitLiteral(token.STRING, word.String())
+				return lexWords(d)
+			default:
+				if d.open != 0 && r == d.open {
+					nesting++
+				} else if r == d.close {
+					nesting--
+				}
+				word.WriteRune(r)
+			}
+		}
+	}
+}
+
+// lexRegexOptions lexes the options following a regex literal, e.g. the `i`
+// of `/foo/i`
+func lexRegexOptions(l *Lexer) StateFn {
+	for strings.ContainsRune(REGEX_OPTION_CHARS, l.peek()) {
+		l.next()
+	}
+	l.emit(token.REGEXEND)
+	return startLexer
+}
+
+// literalAllowed reports whether a '/' or '%' starts a literal rather than
+// being an operator. This is the case unless it follows a value or a name
+// without a space in between, so that `puts /a/` passes a regex whereas
+// `a/b` divides.
+func (l *Lexer) literalAllowed() bool {
+	switch l.lastToken.Type {
+	case token.INT, token.FLOAT, token.RATIONAL, token.IMAGINARY, token.STRING, token.REGEXEND, token.SYMBOL,
+		token.RPAREN, token.RBRACKET, token.RBRACE,
+		token.SELF, token.NIL, token.TRUE, token.FALSE, token.END:
+		return false
+	case token.IDENT:
+		return l.start > 0 && isWhitespace(rune(l.input[l.start-1]))
+	default:
+		return true
 	}
 }
 
 func lexGlobal(l *Lexer) StateFn {
+	if l.peek() == '~' {
+		// the last match, `$~`
+		l.next()
+		l.emit(token.IDENT)
+		return startLexer
+	}
+	_ = lexIdentifierOrKeywordCore(l)
+	l.emit(token.IDENT)
+	return startLexer
+}
+
+func lexInstanceVariable(l *Lexer) StateFn {
+	if !isLetter(l.peek()) {
+		return l.errorf("'@' without identifiers is not allowed as an instance variable name")
+	}
 	_ = lexIdentifierOrKeywordCore(l)
 	l.emit(token.IDENT)
 	return startLexer
@@ -525,10 +1071,30 @@ func isDigit(r rune) bool {
 	return '0' <= r && r <= '9'
 }
 
-func isDigitOrUnderscore(r rune) bool {
-	return isDigit(r) || r == '_'
+// isDigitIn reports whether r is a digit of a number in base, which is at
+// most 16
+func isDigitIn(r rune, base int) bool {
+	switch {
+	case isDigit(r):
+		return int(r-'0') < base
+	case base == 16:
+		return 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F'
+	}
+	return false
 }
 
 func isExpressionDelimiter(r rune) bool {
 	return r == '\n' || r == ';' || r == eof
 }
+
+// isPercentDelimiter reports whether r can delimit a percent literal, as the
+// braces of `%r{...}`
+func isPercentDelimiter(r rune) bool {
+	return r < utf8.RuneSelf && unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// isLabelDelimiter reports whether r can follow a label without a value, as
+// in the hash pattern `{name:}`
+func isLabelDelimiter(r rune) bool {
+	return r == ',' || r == '}' || r == ')' || r == ']' || isExpressionDelimiter(r)
+}
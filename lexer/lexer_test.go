@@ -71,6 +71,10 @@ func TestLex(t *testing.T) {
 				1.0
 				123.456
 				123.to_s
+				0x1F 0b1_0 0o17 017 0d9
+				1e3 1.5E-3 1_0.0_1e+1_0
+				3r 1.5r 2i 1.5ri 1if
+				1..2
 			`,
 			exp: []expected{
 				expect(t)("INT", "5"),
@@ -84,6 +88,27 @@ func TestLex(t *testing.T) {
 				expect(t)("INT", "123"),
 				expect(t)("DOT", "."),
 				expect(t)("IDENT", "to_s"),
+				NL,
+				expect(t)("INT", "0x1F"),
+				expect(t)("INT", "0b1_0"),
+				expect(t)("INT", "0o17"),
+				expect(t)("INT", "017"),
+				expect(t)("INT", "0d9"),
+				NL,
+				expect(t)("FLOAT", "1e3"),
+				expect(t)("FLOAT", "1.5E-3"),
+				expect(t)("FLOAT", "1_0.0_1e+1_0"),
+				NL,
+				expect(t)("RATIONAL", "3r"),
+				expect(t)("RATIONAL", "1.5r"),
+				expect(t)("IMAGINARY", "2i"),
+				expect(t)("IMAGINARY", "1.5ri"),
+				expect(t)("INT", "1"),
+				expect(t)("IF", "if"),
+				NL,
+				expect(t)("INT", "1"),
+				expect(t)("DDOT", ".."),
+				expect(t)("INT", "2"),
 			},
 		},
 		{
@@ -111,8 +136,8 @@ func TestLex(t *testing.T) {
 				"foo bar"
 				'foo bar'
 				"\\"
-				/\//
-				/a/
+				"a\tb\x41\u00e9\C-a"
+				'it\'s a\n\\'
 			`,
 			exp: []expected{
 				expect(t)("STRING", ""),
@@ -125,11 +150,69 @@ func TestLex(t *testing.T) {
 				NL,
 				expect(t)("STRING", "foo bar"),
 				NL,
-				expect(t)("STRING", "\\\\"),
+				expect(t)("STRING", "\\"),
+				NL,
+				expect(t)("STRING", "a\tbAé\x01"),
 				NL,
+				expect(t)("STRING", "it's a\\n\\"),
+			},
+		},
+		{
+			desc: "regex literals",
+			lines: `
+				/\//
+				/a/i
+				%r{a{2}}m
+				x =~ /a#{b}/
+				a !~ %r|b|
+				a / b
+				a/b
+				puts /b/
+				$~
+			`,
+			exp: []expected{
+				expect(t)("REGEX", "/"),
 				expect(t)("STRING", "\\/"),
+				expect(t)("REGEXEND", ""),
 				NL,
+				expect(t)("REGEX", "/"),
 				expect(t)("STRING", "a"),
+				expect(t)("REGEXEND", "i"),
+				NL,
+				expect(t)("REGEX", "%r{"),
+				expect(t)("STRING", "a{2}"),
+				expect(t)("REGEXEND", "m"),
+				NL,
+				expect(t)("IDENT", "x"),
+				expect(t)("MATCH", "=~"),
+				expect(t)("REGEX", "/"),
+				expect(t)("STRING", "a"),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "b"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", ""),
+				expect(t)("REGEXEND", ""),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("NOTMATCH", "!~"),
+				expect(t)("REGEX", "%r|"),
+				expect(t)("STRING", "b"),
+				expect(t)("REGEXEND", ""),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("SLASH", "/"),
+				expect(t)("IDENT", "b"),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("SLASH", "/"),
+				expect(t)("IDENT", "b"),
+				NL,
+				expect(t)("IDENT", "puts"),
+				expect(t)("REGEX", "/"),
+				expect(t)("STRING", "b"),
+				expect(t)("REGEXEND", ""),
+				NL,
+				expect(t)("IDENT", "$~"),
 			},
 		},
 		{
@@ -176,6 +259,8 @@ func TestLex(t *testing.T) {
 			lines: `
 				!-/ *%5;
 				+= -= *= /= %=
+				**= &= |= ^= <<= >>= &&= ||=
+				~5 ^ 3 | 1
 				5 < 10 > 5
 				10 == 10
 				10 != 9
@@ -183,6 +268,8 @@ func TestLex(t *testing.T) {
 				10 >= 9
 				10 <=> 9
 				10 << 9
+				10 >> 9
+				10 === 9
 			`,
 			exp: []expected{
 				expect(t)("BANG", "!"),
@@ -199,6 +286,22 @@ func TestLex(t *testing.T) {
 				expect(t)("DIVASSIGN", "/="),
 				expect(t)("MODASSIGN", "%="),
 				NL,
+				expect(t)("POWASSIGN", "**="),
+				expect(t)("ANDASSIGN", "&="),
+				expect(t)("ORASSIGN", "|="),
+				expect(t)("XORASSIGN", "^="),
+				expect(t)("LSHIFTASSIGN", "<<="),
+				expect(t)("RSHIFTASSIGN", ">>="),
+				expect(t)("LOGICALANDASSIGN", "&&="),
+				expect(t)("LOGICALORASSIGN", "||="),
+				NL,
+				expect(t)("TILDE", "~"),
+				expect(t)("INT", "5"),
+				expect(t)("CARET", "^"),
+				expect(t)("INT", "3"),
+				expect(t)("PIPE", "|"),
+				expect(t)("INT", "1"),
+				NL,
 				expect(t)("INT", "5"),
 				expect(t)("LT", "<"),
 				expect(t)("INT", "10"),
@@ -228,6 +331,14 @@ func TestLex(t *testing.T) {
 				expect(t)("INT", "10"),
 				expect(t)("LSHIFT", "<<"),
 				expect(t)("INT", "9"),
+				NL,
+				expect(t)("INT", "10"),
+				expect(t)("RSHIFT", ">>"),
+				expect(t)("INT", "9"),
+				NL,
+				expect(t)("INT", "10"),
+				expect(t)("CASEEQ", "==="),
+				expect(t)("INT", "9"),
 			},
 		},
 		{
@@ -238,11 +349,11 @@ func TestLex(t *testing.T) {
 				end
 			`,
 			exp: []expected{
-				expect(t)("IDENT", "while"),
+				expect(t)("WHILE", "while"),
 				expect(t)("IDENT", "x"),
 				expect(t)("LT", "<"),
 				expect(t)("IDENT", "y"),
-				expect(t)("IDENT", "do"),
+				expect(t)("DO", "do"),
 				NL,
 				expect(t)("IDENT", "x"),
 				expect(t)("ADDASSIGN", "+="),
@@ -251,6 +362,37 @@ func TestLex(t *testing.T) {
 				expect(t)("END", "end"),
 			},
 		},
+		{
+			desc: "until and for",
+			lines: `
+				x += 1 until x > 3
+				for a, b in pairs
+					next if a
+				end
+			`,
+			exp: []expected{
+				expect(t)("IDENT", "x"),
+				expect(t)("ADDASSIGN", "+="),
+				expect(t)("INT", "1"),
+				expect(t)("UNTIL", "until"),
+				expect(t)("IDENT", "x"),
+				expect(t)("GT", ">"),
+				expect(t)("INT", "3"),
+				NL,
+				expect(t)("FOR", "for"),
+				expect(t)("IDENT", "a"),
+				expect(t)("COMMA", ","),
+				expect(t)("IDENT", "b"),
+				expect(t)("IN", "in"),
+				expect(t)("IDENT", "pairs"),
+				NL,
+				expect(t)("NEXT", "next"),
+				expect(t)("IF", "if"),
+				expect(t)("IDENT", "a"),
+				NL,
+				expect(t)("END", "end"),
+			},
+		},
 		{
 			desc: "loop",
 			lines: `
@@ -320,7 +462,7 @@ func TestLex(t *testing.T) {
 				NL,
 				expect(t)("STRING", "-"),
 				NL,
-				expect(t)("STRING", "\\n"),
+				expect(t)("STRING", "\n"),
 				NL,
 				expect(t)("QMARK", "?"),
 				expect(t)("IDENT", "foo"),
@@ -335,6 +477,7 @@ func TestLex(t *testing.T) {
 				$foo;
 				$Foo
 				$dotAfter.
+				$1
 			`,
 			exp: []expected{
 				expect(t)("IDENT", "$foo"),
@@ -347,6 +490,90 @@ func TestLex(t *testing.T) {
 				NL,
 				expect(t)("IDENT", "$dotAfter"),
 				expect(t)("DOT", "."),
+				NL,
+				expect(t)("IDENT", "$1"),
+			},
+		},
+		{
+			desc: "instance variables",
+			lines: `
+				@foo = @bar
+				self.x
+			`,
+			exp: []expected{
+				expect(t)("IDENT", "@foo"),
+				expect(t)("ASSIGN", "="),
+				expect(t)("IDENT", "@bar"),
+				NL,
+				expect(t)("SELF", "self"),
+				expect(t)("DOT", "."),
+				expect(t)("IDENT", "x"),
+			},
+		},
+		{
+			desc: "scope operator",
+			lines: `
+				Foo::Bar
+				puts ::Baz
+				a ? b : c
+			`,
+			exp: []expected{
+				expect(t)("IDENT", "Foo"),
+				expect(t)("SCOPE", "::"),
+				expect(t)("IDENT", "Bar"),
+				NL,
+				expect(t)("IDENT", "puts"),
+				expect(t)("SSCOPE", " ::"),
+				expect(t)("IDENT", "Baz"),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("QMARK", " ?"),
+				expect(t)("IDENT", "b"),
+				expect(t)("COLON", ":"),
+				expect(t)("IDENT", "c"),
+			},
+		},
+		{
+			desc: "case",
+			lines: `
+				case x
+				when 1, 2 then y
+				else z
+				end
+			`,
+			exp: []expected{
+				expect(t)("CASE", "case"),
+				expect(t)("IDENT", "x"),
+				NL,
+				expect(t)("WHEN", "when"),
+				expect(t)("INT", "1"),
+				expect(t)("COMMA", ","),
+				expect(t)("INT", "2"),
+				expect(t)("THEN", "then"),
+				expect(t)("IDENT", "y"),
+				NL,
+				expect(t)("ELSE", "else"),
+				expect(t)("IDENT", "z"),
+				NL,
+				expect(t)("END", "end"),
+			},
+		},
+		{
+			desc: "pattern matching",
+			lines: `
+				in {name:, age: ^x}
+			`,
+			exp: []expected{
+				expect(t)("IN", "in"),
+				expect(t)("LBRACE", "{"),
+				expect(t)("IDENT", "name"),
+				expect(t)("COLON", ":"),
+				expect(t)("COMMA", ","),
+				expect(t)("IDENT", "age"),
+				expect(t)("COLON", ":"),
+				expect(t)("CARET", "^"),
+				expect(t)("IDENT", "x"),
+				expect(t)("RBRACE", "}"),
 			},
 		},
 		{
@@ -390,12 +617,13 @@ func TestLex(t *testing.T) {
 				NL,
 				expect(t)("END", "end"),
 				NL,
-				expect(t)("IDENT", "module"),
+				expect(t)("MODULE", "module"),
 				expect(t)("IDENT", "Abc"),
 				NL,
 				expect(t)("END", "end"),
 				NL,
-				expect(t)("COMMENT", "class Abc"),
+				expect(t)("CLASS", "class"),
+				expect(t)("IDENT", "Abc"),
 				NL,
 				expect(t)("END", "end"),
 			},
@@ -411,9 +639,9 @@ func TestLex(t *testing.T) {
 				end
 			`,
 			exp: []expected{
-				expect(t)("IDENT", "begin"), // NOTE: ident, not keyword
+				expect(t)("BEGIN", "begin"),
 				NL,
-				expect(t)("IDENT", "rescue"), // NOTE: ident, not keyword
+				expect(t)("RESCUE", "rescue"),
 				NL,
 				expect(t)("END", "end"),
 				NL,
@@ -426,7 +654,7 @@ func TestLex(t *testing.T) {
 				expect(t)("RBRACE", "}"),
 				NL,
 				expect(t)("IDENT", "add"),
-				expect(t)("IDENT", "do"),
+				expect(t)("DO", "do"),
 				expect(t)("PIPE", "|"),
 				expect(t)("IDENT", "x"),
 				expect(t)("PIPE", "|"),
@@ -501,11 +729,11 @@ func TestLex(t *testing.T) {
 				NL,
 				expect(t)("IDENT", "__FILE__"),
 				NL,
-				expect(t)("IDENT", "self"),
+				expect(t)("SELF", "self"),
 				NL,
 				expect(t)("NIL", "nil"),
 				NL,
-				expect(t)("IDENT", "yield"),
+				expect(t)("YIELD", "yield"),
 			},
 		},
 		{
@@ -555,13 +783,147 @@ func TestLex(t *testing.T) {
 				expect(t)("RBRACKET", "]"),
 			},
 		},
+		{
+			desc: "block_pass",
+			lines: `
+				a(&b)
+				a &b
+				a & b
+			`,
+			exp: []expected{
+				expect(t)("IDENT", "a"),
+				expect(t)("LPAREN", "("),
+				expect(t)("AND", "&"),
+				expect(t)("IDENT", "b"),
+				expect(t)("RPAREN", ")"),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("SAND", " &"),
+				expect(t)("IDENT", "b"),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("AND", "&"),
+				expect(t)("IDENT", "b"),
+			},
+		},
+		{
+			desc: "interpolation",
+			lines: `
+				"a #{b} c"
+				"#{x + "}"}"
+				"#{ {a: 1} }"
+				"\#{a}"
+				'#{a}'
+				:"s#{a}"
+				` + "`ls #{dir}`" + `
+			`,
+			exp: []expected{
+				expect(t)("STRING", "a "),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "b"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", " c"),
+				NL,
+				expect(t)("STRING", ""),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "x"),
+				expect(t)("PLUS", "+"),
+				expect(t)("STRING", "}"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", ""),
+				NL,
+				expect(t)("STRING", ""),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("LBRACE", "{"),
+				expect(t)("IDENT", "a"),
+				expect(t)("COLON", ":"),
+				expect(t)("INT", "1"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", ""),
+				NL,
+				expect(t)("STRING", "#{a}"),
+				NL,
+				expect(t)("STRING", "#{a}"),
+				NL,
+				expect(t)("SYMBOL", ":"),
+				expect(t)("STRING", "s"),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "a"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", ""),
+				NL,
+				expect(t)("BACKTICK", "`"),
+				expect(t)("STRING", "ls "),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "dir"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", ""),
+			},
+		},
+		{
+			desc: "percent literals",
+			lines: `
+				%w[a b\ c [d]]
+				%i(x y)
+				%w{}
+				%q(a (b) #{c})
+				%Q|a #{b}|
+				%(a)
+				%s<sym>
+				%x(ls)
+				a % b
+				a %w
+				x %= 2
+			`,
+			exp: []expected{
+				expect(t)("WORDS", "%w["),
+				expect(t)("STRING", "a"),
+				expect(t)("STRING", "b c"),
+				expect(t)("STRING", "[d]"),
+				NL,
+				expect(t)("SYMBOLS", "%i("),
+				expect(t)("STRING", "x"),
+				expect(t)("STRING", "y"),
+				NL,
+				expect(t)("WORDS", "%w{"),
+				NL,
+				expect(t)("STRING", "a (b) #{c}"),
+				NL,
+				expect(t)("STRING", "a "),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "b"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", ""),
+				NL,
+				expect(t)("STRING", "a"),
+				NL,
+				expect(t)("SYMBOL", "%s<"),
+				expect(t)("STRING", "sym"),
+				NL,
+				expect(t)("BACKTICK", "%x("),
+				expect(t)("STRING", "ls"),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("MODULO", "%"),
+				expect(t)("IDENT", "b"),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("MODULO", "%"),
+				expect(t)("IDENT", "w"),
+				NL,
+				expect(t)("IDENT", "x"),
+				expect(t)("MODASSIGN", "%="),
+				expect(t)("INT", "2"),
+			},
+		},
 		{
 			desc: "hash_of_lambdas",
 			lines: `
 				"\"" => -> (a) { val_to_str a }
 			`,
 			exp: []expected{
-				expect(t)("STRING", "\\\""),
+				expect(t)("STRING", "\""),
 				expect(t)("HASHROCKET", "=>"),
 				expect(t)("LAMBDAROCKET", "->"),
 				expect(t)("LPAREN", "("),
@@ -588,6 +950,174 @@ func TestLex(t *testing.T) {
 	}
 }
 
+func TestLexUnterminatedString(t *testing.T) {
+	lexer := New(`"foo #{bar}`)
+
+	var last token.Token
+	for lexer.HasNext() {
+		last = lexer.NextToken()
+	}
+
+	utils.AssertEqual(t, last.Type, token.ILLEGAL)
+	utils.AssertEqual(t, last.Literal, "unterminated string meets end of file")
+}
+
+func TestLexMalformedNumbers(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		err   string
+	}{
+		{"0b102", 0, "invalid digit '2' in binary literal"},
+		{"x = 0o78", 4, "invalid digit '8' in octal literal"},
+		{"09", 0, "invalid digit '9' in octal literal"},
+		{"1__0", 0, "trailing '_' in number"},
+		{"1_", 0, "trailing '_' in number"},
+		{"1.5_", 0, "trailing '_' in number"},
+		{"0x", 0, "numeric literal without digits"},
+		{"0b_1", 0, "numeric literal without digits"},
+		{"1e5r", 0, "rational suffix after an exponent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input)
+
+			var last token.Token
+			for lexer.HasNext() {
+				last = lexer.NextToken()
+			}
+
+			utils.AssertEqual(t, last.Type, token.ILLEGAL)
+			utils.AssertEqual(t, last.Pos, tt.pos)
+			utils.AssertEqual(t, last.Literal, tt.err)
+		})
+	}
+}
+
+func TestLexHeredoc(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input string
+		exp   []expected
+	}{
+		{
+			desc:  "plain",
+			input: "x = <<EOS\n  a #{b}\n  c\nEOS\ny",
+			exp: []expected{
+				expect(t)("IDENT", "x"),
+				expect(t)("ASSIGN", "="),
+				expect(t)("STRING", "  a "),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "b"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", "\n  c\n"),
+				NL,
+				expect(t)("IDENT", "y"),
+			},
+		},
+		{
+			desc:  "indented terminator",
+			input: "<<-EOS\n  a\n  EOS\n",
+			exp: []expected{
+				expect(t)("STRING", "  a\n"),
+				NL,
+			},
+		},
+		{
+			desc:  "squiggly",
+			input: "<<~EOS\n    a\n\n      b #{c} d\n    e\n  EOS\n",
+			exp: []expected{
+				expect(t)("STRING", "a\n\n  b "),
+				expect(t)("INTERPOLATION", "#{"),
+				expect(t)("IDENT", "c"),
+				expect(t)("RBRACE", "}"),
+				expect(t)("STRING", " d\ne\n"),
+				NL,
+			},
+		},
+		{
+			desc:  "quoted",
+			input: "<<'EOS'\na #{b}\nEOS\n<<\"EOS\"\nc\nEOS\n",
+			exp: []expected{
+				expect(t)("STRING", "a #{b}\n"),
+				NL,
+				expect(t)("STRING", "c\n"),
+				NL,
+			},
+		},
+		{
+			desc:  "command",
+			input: "<<`EOS`\nls\nEOS\n",
+			exp: []expected{
+				expect(t)("BACKTICK", "<<`EOS`"),
+				expect(t)("STRING", "ls\n"),
+				NL,
+			},
+		},
+		{
+			desc:  "rest of the line after the body",
+			input: "foo(<<A, <<B).bar\na\nA\nb\nB\nbaz",
+			exp: []expected{
+				expect(t)("IDENT", "foo"),
+				expect(t)("LPAREN", "("),
+				expect(t)("STRING", "a\n"),
+				expect(t)("COMMA", ","),
+				expect(t)("STRING", "b\n"),
+				expect(t)("RPAREN", ")"),
+				expect(t)("DOT", "."),
+				expect(t)("IDENT", "bar"),
+				NL,
+				expect(t)("IDENT", "baz"),
+			},
+		},
+		{
+			desc:  "shift operators",
+			input: "a << b\na<<B\n1 <<2\nclass << self",
+			exp: []expected{
+				expect(t)("IDENT", "a"),
+				expect(t)("LSHIFT", "<<"),
+				expect(t)("IDENT", "b"),
+				NL,
+				expect(t)("IDENT", "a"),
+				expect(t)("LSHIFT", "<<"),
+				expect(t)("IDENT", "B"),
+				NL,
+				expect(t)("INT", "1"),
+				expect(t)("LSHIFT", "<<"),
+				expect(t)("INT", "2"),
+				NL,
+				expect(t)("CLASS", "class"),
+				expect(t)("LSHIFT", "<<"),
+				expect(t)("SELF", "self"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tokens := allTokens(New(tt.input))
+			utils.AssertEqual(t, len(tokens), len(tt.exp))
+			for i, exp := range tt.exp {
+				utils.AssertEqual(t, tokens[i].Type, exp.typ)
+				utils.AssertEqual(t, tokens[i].Literal, exp.lit)
+			}
+		})
+	}
+
+	t.Run("unterminated", func(t *testing.T) {
+		lexer := New("<<EOS\nfoo\n")
+
+		var last token.Token
+		for lexer.HasNext() {
+			last = lexer.NextToken()
+		}
+
+		utils.AssertEqual(t, last.Type, token.ILLEGAL)
+		utils.AssertEqual(t, last.Literal, "unterminated string meets end of file")
+	})
+}
+
 // Tests that the lexer can handle the source of pyra.rb
 // https://github.com/ConorOBrien-Foxx/Pyramid-Scheme/blob/master/pyra.rb
 func TestLexPyraRb(t *testing.T) {
@@ -1,6 +1,7 @@
 package object
 
 import (
+	"fmt"
 	"hash/fnv"
 	"strings"
 
@@ -56,23 +57,85 @@ func (a *Array) HashKey() HashKey {
 	return HashKey(h.Sum64())
 }
 
+// ImplicitArray returns the values obj is spread into by a multiple
+// assignment or a destructuring parameter: the elements of an Array, those of
+// the Array returned by to_ary if obj responds to it, or obj itself
+func ImplicitArray(context CallContext, tracer trace.Tracer, obj RubyObject) ([]RubyObject, error) {
+	if arr, ok := obj.(*Array); ok {
+		return arr.Elements, nil
+	}
+	if !RespondTo(obj, "to_ary") {
+		return []RubyObject{obj}, nil
+	}
+	converted, err := Send(withReceiver(context, obj), "to_ary", tracer)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := converted.(*Array)
+	if !ok {
+		return nil, NewTypeError(
+			fmt.Sprintf("can't convert %s to Array (%s#to_ary gives %s)", obj.Class().Name(), obj.Class().Name(), converted.Class().Name()),
+		)
+	}
+	return arr.Elements, nil
+}
+
+// Distribute spreads values over count targets. The target at index splat
+// collects the values left over by the others into an Array, a splat of -1
+// means there is none. Targets without a value get nil.
+func Distribute(values []RubyObject, count, splat int) []RubyObject {
+	distributed := make([]RubyObject, count)
+	if splat < 0 {
+		for i := range distributed {
+			distributed[i] = NIL
+			if i < len(values) {
+				distributed[i] = values[i]
+			}
+		}
+		return distributed
+	}
+	after := count - splat - 1
+	for i := 0; i < splat; i++ {
+		distributed[i] = NIL
+		if i < len(values) {
+			distributed[i] = values[i]
+		}
+	}
+	rest := values[min(splat, len(values)):]
+	tail := max(len(rest)-after, 0)
+	distributed[splat] = NewArray(rest[:tail]...)
+	for i := 0; i < after; i++ {
+		distributed[splat+1+i] = NIL
+		if tail+i < len(rest) {
+			distributed[splat+1+i] = rest[tail+i]
+		}
+	}
+	return distributed
+}
+
 var arrayMethods = map[string]RubyMethod{
-	"push":     newMethod(arrayPush),
-	"unshift":  newMethod(arrayUnshift),
-	"size":     newMethod(arraySize),
-	"length":   newMethod(arraySize),
-	"find_all": newMethod(arrayFindAll),
-	"first":    newMethod(arrayFirst),
-	"map":      newMethod(arrayMap),
-	"all?":     newMethod(arrayAll),
-	"join":     newMethod(arrayJoin),
-	"include?": newMethod(arrayInclude),
-	"each":     newMethod(arrayEach),
-	"reject":   newMethod(arrayReject),
-	"pop":      newMethod(arrayPop),
-	"-":        newMethod(arrayMinus),
-	"+":        newMethod(arrayPlus),
-	"*":        newMethod(arrayAst),
+	"push":            newMethod(arrayPush),
+	"<<":              withArity(1, newMethod(arrayPush)),
+	"unshift":         newMethod(arrayUnshift),
+	"size":            newMethod(arraySize),
+	"length":          newMethod(arraySize),
+	"find_all":        newMethod(arrayFindAll),
+	"first":           newMethod(arrayFirst),
+	"map":             newMethod(arrayMap),
+	"all?":            newMethod(arrayAll),
+	"join":            newMethod(arrayJoin),
+	"include?":        newMethod(arrayInclude),
+	"each":            newMethod(arrayEach),
+	"each_with_index": newMethod(arrayEachWithIndex),
+	"to_s":            withArity(0, newMethod(arrayToS)),
+	"reject":          newMethod(arrayReject),
+	"pop":             newMethod(arrayPop),
+	"-":               newMethod(arrayMinus),
+	"+":               newMethod(arrayPlus),
+	"*":               newMethod(arrayAst),
+	"shuffle":         newMethod(arrayShuffle),
+	"shuffle!":        newMethod(arrayShuffleBang),
+	"sample":          newMethod(arraySample),
 }
 
 func arrayPush(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -110,14 +173,10 @@ func arrayFindAll(context CallContext, tracer trace.Tracer, args ...RubyObject)
 		return nil, NewArgumentError("(1) array find_all requires a block")
 	}
 	block := args[0]
-	proc, ok := block.(*Symbol)
+	fn, ok := blockMethod(block)
 	if !ok {
 		return nil, NewArgumentError("(2) array find_all requires a block")
 	}
-	fn, ok := FUNCS_STORE.GetMethod(proc.Value)
-	if !ok {
-		return nil, NewNoMethodError(FUNCS_STORE, proc.Value)
-	}
 	result := NewArray()
 	for _, element := range array.Elements {
 		ret, err := fn.Call(context, tracer, element)
@@ -179,14 +238,10 @@ func arrayMap(context CallContext, tracer trace.Tracer, args ...RubyObject) (Rub
 		return nil, NewArgumentError("map requires a block")
 	}
 	block := args[0]
-	proc, ok := block.(*Symbol)
+	fn, ok := blockMethod(block)
 	if !ok {
 		return nil, NewArgumentError("map requires a block")
 	}
-	fn, ok := FUNCS_STORE.GetMethod(proc.Value)
-	if !ok {
-		return nil, NewNoMethodError(FUNCS_STORE, proc.Value)
-	}
 	result := NewArray()
 	for _, elem := range array.Elements {
 		ret, err := fn.Call(context, tracer, elem)
@@ -207,14 +262,10 @@ func arrayAll(context CallContext, tracer trace.Tracer, args ...RubyObject) (Rub
 		return nil, NewArgumentError("all? requires a block")
 	}
 	block := args[0]
-	proc, ok := block.(*Symbol)
+	fn, ok := blockMethod(block)
 	if !ok {
 		return nil, NewArgumentError("all? requires a block")
 	}
-	fn, ok := FUNCS_STORE.GetMethod(proc.Value)
-	if !ok {
-		return nil, NewNoMethodError(FUNCS_STORE, proc.Value)
-	}
 	for _, elem := range array.Elements {
 		ret, err := fn.Call(context, tracer, elem)
 		if err != nil {
@@ -300,14 +351,10 @@ func arrayEach(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 		return nil, NewArgumentError("map requires a block")
 	}
 	block := args[0]
-	proc, ok := block.(*Symbol)
+	fn, ok := blockMethod(block)
 	if !ok {
 		return nil, NewArgumentError("map requires a block")
 	}
-	fn, ok := FUNCS_STORE.GetMethod(proc.Value)
-	if !ok {
-		return nil, NewNoMethodError(FUNCS_STORE, proc.Value)
-	}
 	for _, elem := range array.Elements {
 		_, err := fn.Call(context, tracer, elem)
 		if err != nil {
@@ -317,6 +364,35 @@ func arrayEach(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 	return array, nil
 }
 
+func arrayEachWithIndex(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	array, _ := context.Receiver().(*Array)
+	if len(args) == 0 {
+		return nil, NewArgumentError("each_with_index requires a block")
+	}
+	fn, ok := blockMethod(args[0])
+	if !ok {
+		return nil, NewArgumentError("each_with_index requires a block")
+	}
+	for i, elem := range array.Elements {
+		_, err := fn.Call(context, tracer, elem, NewInteger(int64(i)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return array, nil
+}
+
+func arrayToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	array, _ := context.Receiver().(*Array)
+	return NewString(array.Inspect()), nil
+}
+
 func arrayReject(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
@@ -326,14 +402,10 @@ func arrayReject(context CallContext, tracer trace.Tracer, args ...RubyObject) (
 		return nil, NewArgumentError("map requires a block")
 	}
 	block := args[0]
-	proc, ok := block.(*Symbol)
+	fn, ok := blockMethod(block)
 	if !ok {
 		return nil, NewArgumentError("map requires a block")
 	}
-	fn, ok := FUNCS_STORE.GetMethod(proc.Value)
-	if !ok {
-		return nil, NewNoMethodError(FUNCS_STORE, proc.Value)
-	}
 	result := NewArray()
 	for _, elem := range array.Elements {
 		ret, err := fn.Call(context, tracer, elem)
@@ -469,3 +541,165 @@ func arrayAst(context CallContext, tracer trace.Tracer, args ...RubyObject) (Rub
 		return nil, NewArgumentError("argument must be an Integer, or a String")
 	}
 }
+
+// shuffleElements shuffles elements in place, drawing from generator
+func shuffleElements(context CallContext, tracer trace.Tracer, elements []RubyObject, generator RubyObject) error {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	for i := len(elements); i > 0; {
+		j, err := randomIndex(context, tracer, generator, i)
+		if err != nil {
+			return err
+		}
+		i--
+		elements[i], elements[j] = elements[j], elements[i]
+	}
+	return nil
+}
+
+func arrayShuffle(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	array, _ := context.Receiver().(*Array)
+	args, generator, err := randomKeyword(context, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 0 {
+		return nil, NewWrongNumberOfArgumentsError(0, len(args))
+	}
+	result := NewArray(array.Elements...)
+	if err := shuffleElements(context, tracer, result.Elements, generator); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func arrayShuffleBang(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	array, _ := context.Receiver().(*Array)
+	args, generator, err := randomKeyword(context, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 0 {
+		return nil, NewWrongNumberOfArgumentsError(0, len(args))
+	}
+	if err := shuffleElements(context, tracer, array.Elements, generator); err != nil {
+		return nil, err
+	}
+	return array, nil
+}
+
+// arraySample returns a random element, or an array of n distinct ones. The
+// indices are drawn the way CRuby draws them, so that a seeded generator
+// samples the same elements.
+func arraySample(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	array, _ := context.Receiver().(*Array)
+	args, generator, err := randomKeyword(context, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	size := len(array.Elements)
+	if len(args) == 0 {
+		if size == 0 {
+			return NIL, nil
+		}
+		i := 0
+		if size > 1 {
+			i, err = randomIndex(context, tracer, generator, size)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return array.Elements[i], nil
+	}
+	count, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(NewInteger(0), args[0])
+	}
+	if count.Sign() < 0 {
+		return nil, NewArgumentError("negative sample number")
+	}
+	n := size
+	if !count.IsBig() && count.Value < int64(size) {
+		n = int(count.Value)
+	}
+	if n > 10 {
+		result := NewArray(array.Elements...)
+		for i := 0; i < n; i++ {
+			j, err := randomIndex(context, tracer, generator, size-i)
+			if err != nil {
+				return nil, err
+			}
+			j += i
+			result.Elements[i], result.Elements[j] = result.Elements[j], result.Elements[i]
+		}
+		result.Elements = result.Elements[:n]
+		return result, nil
+	}
+	draws := make([]int, n)
+	for i := range draws {
+		draws[i], err = randomIndex(context, tracer, generator, size-i)
+		if err != nil {
+			return nil, err
+		}
+	}
+	indices := sampleIndices(draws)
+	result := NewArray()
+	for _, i := range indices {
+		result.Elements = append(result.Elements, array.Elements[i])
+	}
+	return result, nil
+}
+
+// sampleIndices turns the draws of Array#sample, each from one index fewer
+// than the one before, into distinct indices
+func sampleIndices(draws []int) []int {
+	switch len(draws) {
+	case 0, 1:
+		return draws
+	case 2:
+		i, j := draws[0], draws[1]
+		if j >= i {
+			j++
+		}
+		return []int{i, j}
+	case 3:
+		i, j, k := draws[0], draws[1], draws[2]
+		l, g := j, i
+		if j >= i {
+			l = i
+			j++
+			g = j
+		}
+		if k >= l {
+			k++
+			if k >= g {
+				k++
+			}
+		}
+		return []int{i, j, k}
+	}
+	indices := []int{draws[0]}
+	sorted := []int{draws[0]}
+	for _, k := range draws[1:] {
+		j := 0
+		for ; j < len(sorted) && k >= sorted[j]; j++ {
+			k++
+		}
+		sorted = append(sorted[:j], append([]int{k}, sorted[j:]...)...)
+		indices = append(indices, k)
+	}
+	return indices
+}
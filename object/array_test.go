@@ -64,3 +64,44 @@ func TestArrayUnshift(t *testing.T) {
 		utils.AssertEqualCmpAny(t, result, NewArray(NewInteger(17), NIL, TRUE, FALSE, NewString("first element")), CompareRubyObjectsForTests)
 	})
 }
+
+func TestDistribute(t *testing.T) {
+	one, two, three := NewInteger(1), NewInteger(2), NewInteger(3)
+	tests := []struct {
+		name   string
+		values []RubyObject
+		count  int
+		splat  int
+		result []RubyObject
+	}{
+		{"exact", []RubyObject{one, two}, 2, -1, []RubyObject{one, two}},
+		{"missing values", []RubyObject{one}, 3, -1, []RubyObject{one, NIL, NIL}},
+		{"surplus values", []RubyObject{one, two, three}, 2, -1, []RubyObject{one, two}},
+		{"middle splat", []RubyObject{one, two, three}, 3, 1, []RubyObject{one, NewArray(two), three}},
+		{"leading splat", []RubyObject{one, two, three}, 2, 0, []RubyObject{NewArray(one, two), three}},
+		{"empty splat", []RubyObject{one}, 3, 1, []RubyObject{one, NewArray(), NIL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Distribute(tt.values, tt.count, tt.splat)
+
+			utils.AssertEqualCmpAny(t, NewArray(result...), NewArray(tt.result...), CompareRubyObjectsForTests)
+		})
+	}
+}
+
+func TestImplicitArray(t *testing.T) {
+	t.Run("array", func(t *testing.T) {
+		result, err := ImplicitArray(&callContext{receiver: NIL}, nil, NewArray(NewInteger(1), NewInteger(2)))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, NewArray(result...), NewArray(NewInteger(1), NewInteger(2)), CompareRubyObjectsForTests)
+	})
+	t.Run("other object", func(t *testing.T) {
+		result, err := ImplicitArray(&callContext{receiver: NIL}, nil, NewInteger(1))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, NewArray(result...), NewArray(NewInteger(1)), CompareRubyObjectsForTests)
+	})
+}
@@ -0,0 +1,264 @@
+package object
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// maxShiftWidth is the largest number of bits an Integer may be shifted left
+// by, which bounds the size of Bignums built by shifting
+const maxShiftWidth = 1 << 26
+
+// NewBigInteger returns a new Integer with the given value, which is held in
+// 64 bits whenever it fits
+func NewBigInteger(value *big.Int) *Integer {
+	if value.IsInt64() {
+		return NewInteger(value.Int64())
+	}
+	return &Integer{Value: value.Int64(), big: value}
+}
+
+// IsBig reports whether the value of i does not fit in 64 bits
+func (i *Integer) IsBig() bool { return i.big != nil }
+
+// BigInt returns the value of i as a new big.Int
+func (i *Integer) BigInt() *big.Int {
+	if i.big != nil {
+		return new(big.Int).Set(i.big)
+	}
+	return big.NewInt(i.Value)
+}
+
+// Float64 returns the float nearest to the value of i
+func (i *Integer) Float64() float64 {
+	if i.big != nil {
+		value, _ := new(big.Float).SetInt(i.big).Float64()
+		return value
+	}
+	return float64(i.Value)
+}
+
+// Negate returns the Integer with the value -i
+func (i *Integer) Negate() *Integer {
+	if i.big == nil && i.Value != math.MinInt64 {
+		return NewInteger(-i.Value)
+	}
+	return NewBigInteger(new(big.Int).Neg(i.BigInt()))
+}
+
+// Sign returns -1, 0 or 1 depending on whether i is negative, zero or
+// positive
+func (i *Integer) Sign() int {
+	if i.big != nil {
+		return i.big.Sign()
+	}
+	switch {
+	case i.Value < 0:
+		return -1
+	case i.Value > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareIntegers returns -1, 0 or 1 depending on whether a is less than,
+// equal to or greater than b
+func compareIntegers(a, b *Integer) int {
+	if a.big == nil && b.big == nil {
+		switch {
+		case a.Value < b.Value:
+			return -1
+		case a.Value > b.Value:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return a.BigInt().Cmp(b.BigInt())
+}
+
+func addIntegers(a, b *Integer) *Integer {
+	if a.big == nil && b.big == nil {
+		sum := a.Value + b.Value
+		// the sum overflowed if its sign differs from those of both operands
+		if (a.Value^sum)&(b.Value^sum) >= 0 {
+			return NewInteger(sum)
+		}
+	}
+	return NewBigInteger(new(big.Int).Add(a.BigInt(), b.BigInt()))
+}
+
+func subIntegers(a, b *Integer) *Integer {
+	if a.big == nil && b.big == nil {
+		difference := a.Value - b.Value
+		// the difference overflowed if the operands differ in sign and the
+		// difference does not have the sign of a
+		if (a.Value^b.Value)&(a.Value^difference) >= 0 {
+			return NewInteger(difference)
+		}
+	}
+	return NewBigInteger(new(big.Int).Sub(a.BigInt(), b.BigInt()))
+}
+
+func mulIntegers(a, b *Integer) *Integer {
+	if a.big == nil && b.big == nil {
+		hi, lo := bits.Mul64(absUint64(a.Value), absUint64(b.Value))
+		if hi == 0 && lo <= math.MaxInt64 {
+			product := int64(lo)
+			if (a.Value < 0) != (b.Value < 0) {
+				product = -product
+			}
+			return NewInteger(product)
+		}
+	}
+	return NewBigInteger(new(big.Int).Mul(a.BigInt(), b.BigInt()))
+}
+
+// divmodIntegers returns the quotient of a and b rounded towards negative
+// infinity, and the remainder, which has the sign of b. b must not be zero.
+func divmodIntegers(a, b *Integer) (*Integer, *Integer) {
+	if a.big == nil && b.big == nil && !(a.Value == math.MinInt64 && b.Value == -1) {
+		quotient, remainder := a.Value/b.Value, a.Value%b.Value
+		if remainder != 0 && (remainder < 0) != (b.Value < 0) {
+			quotient--
+			remainder += b.Value
+		}
+		return NewInteger(quotient), NewInteger(remainder)
+	}
+	divisor := b.BigInt()
+	quotient, remainder := new(big.Int).QuoRem(a.BigInt(), divisor, new(big.Int))
+	if remainder.Sign() != 0 && remainder.Sign() != divisor.Sign() {
+		quotient.Sub(quotient, big.NewInt(1))
+		remainder.Add(remainder, divisor)
+	}
+	return NewBigInteger(quotient), NewBigInteger(remainder)
+}
+
+// powInteger returns base raised to the non-negative exponent exp
+func powInteger(base *Integer, exp int64) *Integer {
+	result := NewInteger(1)
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			result = mulIntegers(result, base)
+		}
+		if exp > 1 {
+			base = mulIntegers(base, base)
+		}
+	}
+	return result
+}
+
+// maxPowBits bounds the number of bits of the result of `**`
+const maxPowBits = 1 << 26
+
+// powIntegerBounded returns base raised to the non-negative exp, or an
+// ArgumentError if the result would be too large
+func powIntegerBounded(base, exp *Integer) (*Integer, error) {
+	if compareIntegers(base, NewInteger(-1)) >= 0 && compareIntegers(base, NewInteger(1)) <= 0 {
+		// 0, 1 and -1 stay small whatever the exponent
+		if base.Value == -1 && exp.BigInt().Bit(0) == 0 {
+			return NewInteger(1), nil
+		}
+		if exp.Sign() == 0 {
+			return NewInteger(1), nil
+		}
+		return base, nil
+	}
+	if exp.IsBig() || exp.Value > maxPowBits/int64(bitLength(base)) {
+		return nil, NewArgumentError("exponent is too large")
+	}
+	return powInteger(base, exp.Value), nil
+}
+
+// shiftInteger shifts i left by n bits, or right if n is negative. Bits
+// shifted out to the right are lost, as with floor division by powers of two.
+func shiftInteger(i *Integer, n int64) *Integer {
+	if n < 0 {
+		if i.big == nil {
+			if n <= -64 {
+				return NewInteger(i.Value >> 63)
+			}
+			return NewInteger(i.Value >> uint(-n))
+		}
+		if n < -int64(i.big.BitLen()) {
+			return NewInteger(int64(i.big.Sign()) >> 1)
+		}
+		return NewBigInteger(new(big.Int).Rsh(i.big, uint(-n)))
+	}
+	if i.big == nil && n < 63 && bits.Len64(absUint64(i.Value))+int(n) < 63 {
+		return NewInteger(i.Value << uint(n))
+	}
+	return NewBigInteger(new(big.Int).Lsh(i.BigInt(), uint(n)))
+}
+
+// bitwiseIntegers applies a bitwise operation to a and b, whose negative
+// values behave as infinitely many ones in two's complement
+func bitwiseIntegers(a, b *Integer, small func(x, y int64) int64, large func(z, x, y *big.Int) *big.Int) *Integer {
+	if a.big == nil && b.big == nil {
+		return NewInteger(small(a.Value, b.Value))
+	}
+	return NewBigInteger(large(new(big.Int), a.BigInt(), b.BigInt()))
+}
+
+// bitLength returns the number of bits of i, not counting its sign
+func bitLength(i *Integer) int {
+	if i.big == nil {
+		value := i.Value
+		if value < 0 {
+			// the bits of a negative number are those of its complement
+			value = ^value
+		}
+		return bits.Len64(uint64(value))
+	}
+	if i.big.Sign() < 0 {
+		return new(big.Int).Not(i.big).BitLen()
+	}
+	return i.big.BitLen()
+}
+
+func absUint64(value int64) uint64 {
+	if value < 0 {
+		return uint64(-value)
+	}
+	return uint64(value)
+}
+
+// roundingMode tells how to round numbers which lie between two multiples
+type roundingMode int
+
+const (
+	roundHalfUp   roundingMode = iota // to the nearest, halves away from zero
+	roundFloor                        // towards negative infinity
+	roundCeil                         // towards positive infinity
+	roundTruncate                     // towards zero
+)
+
+// roundInteger rounds i to a multiple of 10**digits as mode tells, e.g. 1250
+// to 1300 with roundHalfUp and 2 digits
+func roundInteger(i *Integer, digits int64, mode roundingMode) *Integer {
+	if digits <= 0 {
+		return i
+	}
+	if mode == roundTruncate {
+		mode = roundFloor
+		if i.Sign() < 0 {
+			mode = roundCeil
+		}
+	}
+	unit := NewBigInteger(new(big.Int).Exp(big.NewInt(10), big.NewInt(digits), nil))
+	quotient, remainder := divmodIntegers(i, unit)
+	if remainder.Sign() != 0 {
+		switch mode {
+		case roundCeil:
+			quotient = addIntegers(quotient, NewInteger(1))
+		case roundHalfUp:
+			half := compareIntegers(mulIntegers(remainder, NewInteger(2)), unit)
+			if half > 0 || (half == 0 && i.Sign() > 0) {
+				quotient = addIntegers(quotient, NewInteger(1))
+			}
+		}
+	}
+	return mulIntegers(quotient, unit)
+}
@@ -0,0 +1,221 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func bigInteger(value string) *Integer {
+	i, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		panic("invalid integer " + value)
+	}
+	return NewBigInteger(i)
+}
+
+func TestNewBigInteger(t *testing.T) {
+	t.Run("demotes values which fit in 64 bits", func(t *testing.T) {
+		i := bigInteger("-9223372036854775808")
+
+		utils.Assert(t, !i.IsBig(), "expected %s to fit in 64 bits", i.Inspect())
+		utils.AssertEqual(t, i.Value, int64(-9223372036854775808))
+	})
+	t.Run("promotes values beyond 64 bits", func(t *testing.T) {
+		i := bigInteger("9223372036854775808")
+
+		utils.Assert(t, i.IsBig(), "expected %s not to fit in 64 bits", i.Inspect())
+		utils.AssertEqual(t, i.Inspect(), "9223372036854775808")
+	})
+}
+
+func TestBigIntegerHashKey(t *testing.T) {
+	a := bigInteger("100000000000000000000")
+	b := mulIntegers(NewInteger(10000000000), NewInteger(10000000000))
+	c := bigInteger("100000000000000000001")
+
+	utils.AssertEqual(t, a.HashKey(), b.HashKey())
+	utils.AssertNotEqual(t, a.HashKey(), c.HashKey())
+	utils.AssertEqual(t, subIntegers(c, a).HashKey(), NewInteger(1).HashKey())
+	utils.Assert(t, RubyObjectsEqual(a, b), "expected %s to equal %s", a.Inspect(), b.Inspect())
+	utils.Assert(t, !RubyObjectsEqual(a, c), "expected %s not to equal %s", a.Inspect(), c.Inspect())
+
+	hash := &Hash{}
+	hash.Set(a, NewString("a"))
+	value, ok := hash.Get(b)
+	utils.Assert(t, ok, "expected %s to be a key of the hash", b.Inspect())
+	utils.AssertEqualCmpAny(t, value, NewString("a"), CompareRubyObjectsForTests)
+}
+
+func TestBigIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   func(CallContext, trace.Tracer, ...RubyObject) (RubyObject, error)
+		receiver *Integer
+		argument *Integer
+		result   *Integer
+	}{
+		{"+ overflows", integerAdd, NewInteger(9223372036854775807), NewInteger(1), bigInteger("9223372036854775808")},
+		{"+ demotes", integerAdd, bigInteger("9223372036854775808"), NewInteger(-1), NewInteger(9223372036854775807)},
+		{"- overflows", integerSub, NewInteger(-9223372036854775808), NewInteger(1), bigInteger("-9223372036854775809")},
+		{"- demotes", integerSub, bigInteger("-9223372036854775809"), NewInteger(-1), NewInteger(-9223372036854775808)},
+		{"* overflows", integerMul, NewInteger(4294967296), NewInteger(4294967296), bigInteger("18446744073709551616")},
+		{"* of negatives", integerMul, NewInteger(-3037000500), NewInteger(3037000500), bigInteger("-9223372037000250000")},
+		{"* to the smallest integer", integerMul, NewInteger(-4611686018427387904), NewInteger(2), NewInteger(-9223372036854775808)},
+		{"/ of the smallest integer", integerDiv, NewInteger(-9223372036854775808), NewInteger(-1), bigInteger("9223372036854775808")},
+		{"/ demotes", integerDiv, bigInteger("18446744073709551616"), NewInteger(4294967296), NewInteger(4294967296)},
+		{"/ rounds down", integerDiv, bigInteger("-18446744073709551617"), NewInteger(2), bigInteger("-9223372036854775809")},
+		{"% has the sign of the divisor", integerModulo, bigInteger("18446744073709551617"), NewInteger(-10), NewInteger(-3)},
+		{"** overflows", integerPow, NewInteger(2), NewInteger(64), bigInteger("18446744073709551616")},
+		{"** of a bignum", integerPow, bigInteger("18446744073709551616"), NewInteger(2), bigInteger("340282366920938463463374607431768211456")},
+		{"** of -1", integerPow, NewInteger(-1), bigInteger("18446744073709551617"), NewInteger(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(&callContext{receiver: tt.receiver}, nil, tt.argument)
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+			utils.AssertEqual(t, result.(*Integer).IsBig(), tt.result.IsBig())
+		})
+	}
+}
+
+func TestIntegerDivmod(t *testing.T) {
+	tests := []struct {
+		receiver  RubyObject
+		argument  RubyObject
+		quotient  RubyObject
+		remainder RubyObject
+	}{
+		{NewInteger(7), NewInteger(3), NewInteger(2), NewInteger(1)},
+		{NewInteger(-7), NewInteger(3), NewInteger(-3), NewInteger(2)},
+		{NewInteger(7), NewInteger(-3), NewInteger(-3), NewInteger(-2)},
+		{NewInteger(-7), NewInteger(-3), NewInteger(2), NewInteger(-1)},
+		{bigInteger("100000000000000000000"), NewInteger(7), bigInteger("14285714285714285714"), NewInteger(2)},
+		{bigInteger("-100000000000000000000"), bigInteger("30000000000000000000"), NewInteger(-4), bigInteger("20000000000000000000")},
+	}
+
+	for _, tt := range tests {
+		result, err := integerDivmod(&callContext{receiver: tt.receiver}, nil, tt.argument)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewArray(tt.quotient, tt.remainder), CompareRubyObjectsForTests)
+	}
+
+	t.Run("by zero", func(t *testing.T) {
+		_, err := integerDivmod(&callContext{receiver: bigInteger("100000000000000000000")}, nil, NewInteger(0))
+
+		utils.AssertError(t, err, NewZeroDivisionError())
+	})
+}
+
+func TestIntegerPowMod(t *testing.T) {
+	tests := []struct {
+		receiver  RubyObject
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{NewInteger(3), []RubyObject{NewInteger(4)}, NewInteger(81), nil},
+		{NewInteger(3), []RubyObject{NewInteger(4), NewInteger(5)}, NewInteger(1), nil},
+		{NewInteger(-3), []RubyObject{NewInteger(3), NewInteger(5)}, NewInteger(3), nil},
+		{NewInteger(3), []RubyObject{NewInteger(2), NewInteger(-5)}, NewInteger(-1), nil},
+		{NewInteger(2), []RubyObject{bigInteger("100000000000000000000"), NewInteger(1000000007)}, NewInteger(855473248), nil},
+		{NewInteger(2), []RubyObject{NewInteger(-1), NewInteger(5)}, nil, NewRangeError("Integer#pow() 1st argument cannot be negative when 2nd argument specified")},
+		{NewInteger(2), []RubyObject{NewInteger(3), NewInteger(0)}, nil, NewZeroDivisionError()},
+	}
+
+	for _, tt := range tests {
+		result, err := integerPowMod(&callContext{receiver: tt.receiver}, nil, tt.arguments...)
+
+		utils.AssertError(t, err, tt.err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
+
+func TestBigIntegerComparison(t *testing.T) {
+	large := bigInteger("100000000000000000000")
+	negative := bigInteger("-100000000000000000000")
+
+	tests := []struct {
+		receiver *Integer
+		argument *Integer
+		result   int64
+	}{
+		{large, NewInteger(1), 1},
+		{NewInteger(1), large, -1},
+		{negative, NewInteger(-1), -1},
+		{negative, large, -1},
+		{large, bigInteger("100000000000000000000"), 0},
+	}
+
+	for _, tt := range tests {
+		result, err := integerSpaceship(&callContext{receiver: tt.receiver}, nil, tt.argument)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(tt.result), CompareRubyObjectsForTests)
+	}
+}
+
+func TestBigIntegerBitwise(t *testing.T) {
+	large := bigInteger("340282366920938463463374607431768211457") // 2**128 + 1
+
+	tests := []struct {
+		name   string
+		method func(CallContext, trace.Tracer, ...RubyObject) (RubyObject, error)
+		args   []RubyObject
+		result RubyObject
+	}{
+		{"&", integerAnd, []RubyObject{NewInteger(3)}, NewInteger(1)},
+		{"|", integerOr, []RubyObject{NewInteger(2)}, bigInteger("340282366920938463463374607431768211459")},
+		{"^ demotes", integerXor, []RubyObject{bigInteger("340282366920938463463374607431768211456")}, NewInteger(1)},
+		{">>", integerRightShift, []RubyObject{NewInteger(64)}, bigInteger("18446744073709551616")},
+		{">> demotes", integerRightShift, []RubyObject{NewInteger(128)}, NewInteger(1)},
+		{">> beyond", integerRightShift, []RubyObject{bigInteger("100000000000000000000")}, NewInteger(0)},
+		{"[]", integerBitReference, []RubyObject{NewInteger(128)}, NewInteger(1)},
+		{"[] with a length", integerBitReference, []RubyObject{NewInteger(127), NewInteger(3)}, NewInteger(2)},
+		{"bit_length", integerBitLength, nil, NewInteger(129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(&callContext{receiver: large}, nil, tt.args...)
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+	t.Run("~", func(t *testing.T) {
+		result, err := integerInvert(&callContext{receiver: large}, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, bigInteger("-340282366920938463463374607431768211458"), CompareRubyObjectsForTests)
+	})
+	t.Run("<< too far", func(t *testing.T) {
+		_, err := integerLeftShift(&callContext{receiver: NewInteger(1)}, nil, bigInteger("100000000000000000000"))
+
+		utils.AssertError(t, err, NewRangeError("shift width too big"))
+	})
+}
+
+func TestBigIntegerToS(t *testing.T) {
+	tests := []struct {
+		base   int64
+		result string
+	}{
+		{10, "-340282366920938463463374607431768211456"},
+		{16, "-100000000000000000000000000000000"},
+		{36, "-f5lxx1zz5pnorynqglhzmsp34"},
+	}
+
+	for _, tt := range tests {
+		result, err := integerToS(&callContext{receiver: bigInteger("-340282366920938463463374607431768211456")}, nil, NewInteger(tt.base))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewString(tt.result), CompareRubyObjectsForTests)
+	}
+}
@@ -2,6 +2,7 @@ package object
 
 import (
 	"fmt"
+	"os/exec"
 	"strings"
 
 	"github.com/MarcinKonowalczyk/goruby/trace"
@@ -37,16 +38,33 @@ func (o *Bottom) Class() RubyClass { return bottomClass }
 func (o *Bottom) HashKey() HashKey { return HASH_KEY_BOTTOM }
 
 var bottomMethodSet = map[string]RubyMethod{
-	"to_s":    withArity(0, newMethod(bottomToS)),
-	"is_a?":   withArity(1, newMethod(bottomIsA)),
-	"nil?":    withArity(0, newMethod(bottomIsNil)),
-	"methods": newMethod(bottomMethods),
-	"class":   withArity(0, newMethod(bottomClassMethod)),
-	"puts":    newMethod(bottomPuts),
-	"print":   newMethod(bottomPrint),
-	"raise":   newMethod(bottomRaise),
-	"==":      withArity(1, newMethod(bottomEqual)),
-	"!=":      withArity(1, newMethod(bottomNotEqual)),
+	"to_s":     withArity(0, newMethod(bottomToS)),
+	"is_a?":    withArity(1, newMethod(bottomIsA)),
+	"kind_of?": withArity(1, newMethod(bottomIsA)),
+	"nil?":     withArity(0, newMethod(bottomIsNil)),
+	"methods":  newMethod(bottomMethods),
+	"class":    withArity(0, newMethod(bottomClassMethod)),
+	"extend":   newMethod(bottomExtend),
+	"puts":     newMethod(bottomPuts),
+	"print":    newMethod(bottomPrint),
+	"raise":    newMethod(bottomRaise),
+	"`":        withArity(1, newMethod(bottomBacktick)),
+	"==":       withArity(1, newMethod(bottomEqual)),
+	"!=":       withArity(1, newMethod(bottomNotEqual)),
+	"===":      withArity(1, newMethod(bottomCaseEqual)),
+	"=~":       withArity(1, newMethod(bottomMatch)),
+	"!~":       withArity(1, newMethod(bottomNotMatch)),
+
+	"caller":           newMethod(bottomCaller),
+	"caller_locations": newMethod(bottomCallerLocations),
+	"block_given?":     withArity(0, newMethod(bottomBlockGiven)),
+	"proc":             newMethod(bottomProc),
+	"lambda":           newMethod(bottomLambda),
+	"method":           withArity(1, newMethod(bottomMethod)),
+	"Rational":         newMethod(bottomRational),
+	"Complex":          newMethod(bottomComplex),
+	"rand":             newMethod(bottomRand),
+	"srand":            newMethod(bottomSrand),
 }
 
 func bottomToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -62,14 +80,12 @@ func bottomIsA(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
-	receiver_class := context.Receiver().Class()
 	switch arg := args[0].(type) {
 	case RubyClassObject:
-		if arg.Name() == receiver_class.Name() {
+		if IsKindOf(context.Receiver(), arg) {
 			return TRUE, nil
-		} else {
-			return FALSE, nil
 		}
+		return FALSE, nil
 	default:
 		return nil, NewTypeError("argument must be a Class")
 	}
@@ -116,6 +132,23 @@ func bottomPuts(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 	return NIL, nil
 }
 
+// bottomBacktick runs its argument with the shell and returns the standard
+// output of the command, as in “ `ls #{dir}` “
+func bottomBacktick(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	command, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(NewString(""), args[0])
+	}
+	out, err := exec.Command("sh", "-c", command.Value).Output()
+	if _, exited := err.(*exec.ExitError); err != nil && !exited {
+		return nil, NewRuntimeError("%s", err.Error())
+	}
+	return NewString(string(out)), nil
+}
+
 func bottomPrint(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
@@ -189,36 +222,240 @@ func bottomClassMethod(context CallContext, tracer trace.Tracer, args ...RubyObj
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	receiver := context.Receiver()
+	if cls, ok := receiver.(*class); ok {
+		return cls.kind(), nil
+	}
 	if _, ok := receiver.(RubyClassObject); ok {
-		return nil, nil
+		return classClass, nil
 	}
-	return receiver.Class().(RubyClassObject), nil
+	class := receiver.Class()
+	// singleton classes are hidden from the object's class
+	if singleton, ok := class.(*eigenclass); ok && singleton.attached == nil && singleton.wrappedClass != nil {
+		class = singleton.wrappedClass
+	}
+	return class.(RubyClassObject), nil
+}
+
+func bottomExtend(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	receiver := context.Receiver()
+	for i := len(args) - 1; i >= 0; i-- {
+		module, err := toModule(args[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := extendObject(receiver, module); err != nil {
+			return nil, err
+		}
+	}
+	return receiver, nil
 }
 
 func bottomRaise(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
+	current, _ := context.Env().Get("$!")
+	var exc RubyObject
 	switch len(args) {
+	case 0:
+		if current, ok := current.(exception); ok {
+			// re-raise the exception currently handled
+			return nil, current
+		}
+		return nil, NewRuntimeError("")
 	case 1:
 		switch arg := args[0].(type) {
 		case *String:
-			return nil, NewRuntimeError("%s", arg.Value)
+			exc = NewRuntimeError("%s", arg.Value)
+		case exception:
+			exc = arg
+		case RubyClassObject:
+			var err error
+			exc, err = Send(withReceiver(context, arg), "exception", tracer)
+			if err != nil {
+				return nil, NewTypeError("exception class/object expected")
+			}
 		default:
-			return nil, NewRuntimeError("%s", arg.Inspect())
-			// default:
-			// 	exc, err := Send(NewCallContext(context.Env(), arg), "exception")
-			// 	if err != nil {
-			// 		return nil, NewTypeError("exception class/object expected")
-			// 	}
-			// 	if excAsErr, ok := exc.(error); ok {
-			// 		return nil, excAsErr
-			// 	}
-			// 	return nil, nil
+			exc = NewRuntimeError("%s", arg.Inspect())
+		}
+	case 2, 3:
+		var err error
+		exc, err = Send(withReceiver(context, args[0]), "exception", tracer, args[1])
+		if err != nil {
+			return nil, NewTypeError("exception class/object expected")
+		}
+		if len(args) == 3 {
+			backtrace, ok := args[2].(*Array)
+			if !ok {
+				return nil, NewTypeError("backtrace must be an Array of String")
+			}
+			lines := make([]string, len(backtrace.Elements))
+			for i, line := range backtrace.Elements {
+				str, ok := line.(*String)
+				if !ok {
+					return nil, NewTypeError("backtrace must be an Array of String")
+				}
+				lines[i] = str.Value
+			}
+			if exc, ok := exc.(Backtracer); ok {
+				exc.SetBacktrace(lines)
+			}
 		}
 	default:
-		return nil, NewRuntimeError("")
+		return nil, NewWrongNumberOfArgumentsError(3, len(args))
+	}
+	raised, ok := exc.(exception)
+	if !ok {
+		return nil, NewTypeError("exception object expected")
 	}
+	if current, ok := current.(exception); ok && current != raised && raised.Cause() == nil {
+		raised.SetCause(current)
+	}
+	return nil, raised
+}
+
+// callerLocations returns the frames selected by the (start = 1, length = nil)
+// arguments of caller and caller_locations
+func callerLocations(context CallContext, args []RubyObject) ([]*Location, error) {
+	if len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	stack := callStackOf(context)
+	if stack == nil {
+		return []*Location{}, nil
+	}
+	locations := stack.Locations()
+	start, length := int64(1), int64(len(locations))
+	if len(args) > 0 {
+		arg, ok := args[0].(*Integer)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(NewInteger(0), args[0])
+		}
+		start = arg.Value
+	}
+	if len(args) > 1 {
+		arg, ok := args[1].(*Integer)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(NewInteger(0), args[1])
+		}
+		length = arg.Value
+	}
+	if start < 0 {
+		return nil, NewArgumentError("negative level (%d)", start)
+	}
+	if length < 0 {
+		return nil, NewArgumentError("negative size (%d)", length)
+	}
+	if start > int64(len(locations)) {
+		return nil, nil
+	}
+	locations = locations[start:]
+	if length < int64(len(locations)) {
+		locations = locations[:length]
+	}
+	return locations, nil
+}
+
+// bottomBlockGiven returns true if a block was passed to the method calling
+// block_given?, or to the method a block calling it was created in
+func bottomBlockGiven(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	stack := callStackOf(context)
+	if stack != nil && stack.Top().Block != nil {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func bottomProc(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	block := blockOf(context)
+	if block == nil {
+		return nil, NewArgumentError("tried to create Proc object without a block")
+	}
+	return block, nil
+}
+
+func bottomLambda(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	block := blockOf(context)
+	if block == nil {
+		return nil, NewArgumentError("tried to create Proc object without a block")
+	}
+	if block.Function == nil {
+		return block, nil
+	}
+	return NewLambda(block.Function), nil
+}
+
+func bottomMethod(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	var name string
+	switch arg := args[0].(type) {
+	case *Symbol:
+		name = arg.Value
+	case *String:
+		name = arg.Value
+	default:
+		return nil, NewTypeError(fmt.Sprintf("%s is not a symbol nor a string", arg.Inspect()))
+	}
+	receiver := context.Receiver()
+	// methods defined at the top level are callable from everywhere
+	if !RespondTo(receiver, name) && RespondTo(FUNCS_STORE, name) {
+		receiver = FUNCS_STORE
+	}
+	method, err := NewMethod(receiver, name)
+	if err != nil {
+		return nil, err
+	}
+	return method, nil
+}
+
+func bottomCaller(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	locations, err := callerLocations(context, args)
+	if err != nil {
+		return nil, err
+	}
+	if locations == nil {
+		return NIL, nil
+	}
+	result := NewArray()
+	for _, location := range locations {
+		result.Elements = append(result.Elements, NewString(location.String()))
+	}
+	return result, nil
+}
+
+func bottomCallerLocations(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	locations, err := callerLocations(context, args)
+	if err != nil {
+		return nil, err
+	}
+	if locations == nil {
+		return NIL, nil
+	}
+	result := NewArray()
+	for _, location := range locations {
+		result.Elements = append(result.Elements, location)
+	}
+	return result, nil
 }
 
 func swapOrFalse(left, right RubyObject, swapped bool) bool {
@@ -321,7 +558,7 @@ func rubyObjectsEqual(left, right RubyObject, swapped bool) bool {
 		if !ok {
 			return swapOrFalse(left, right, swapped)
 		} else {
-			return left.Value == right_t
+			return compareIntegers(left, right_t) == 0
 		}
 	case *Float:
 		right_t, ok := safeObjectToFloat(right)
@@ -330,6 +567,21 @@ func rubyObjectsEqual(left, right RubyObject, swapped bool) bool {
 		} else {
 			return left.Value == right_t
 		}
+	case *Rational:
+		if right_t, ok := exactValue(right); ok {
+			return left.Value.Cmp(right_t) == 0
+		}
+		if right_t, ok := right.(*Float); ok {
+			return left.Float64() == right_t.Value
+		}
+		return swapOrFalse(left, right, swapped)
+	case *Complex:
+		real, imaginary, ok := complexParts(right)
+		if !ok {
+			return swapOrFalse(left, right, swapped)
+		}
+		return rubyObjectsEqual(left.Real, real, false) &&
+			rubyObjectsEqual(left.Imaginary, imaginary, false)
 	case *String:
 		if right_t, ok := right.(*String); !ok {
 			return swapOrFalse(left, right, swapped)
@@ -375,8 +627,19 @@ func rubyObjectsEqual(left, right RubyObject, swapped bool) bool {
 		} else {
 			return left.Value == right_t.Value
 		}
+	case *Regexp:
+		if right_t, ok := right.(*Regexp); !ok {
+			return swapOrFalse(left, right, swapped)
+		} else {
+			return left.Source == right_t.Source && left.Options == right_t.Options
+		}
+	case *Object:
+		// user defined objects are only equal to themselves
+		right_t, ok := right.(*Object)
+		return ok && left == right_t
 	default:
-		return false
+		// any other object is only equal to itself
+		return left == right
 	}
 }
 
@@ -403,3 +666,35 @@ func bottomNotEqual(context CallContext, tracer trace.Tracer, args ...RubyObject
 	}
 	return TRUE, nil
 }
+
+// bottomMatch implements the default =~, which matches nothing
+func bottomMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return NIL, nil
+}
+
+// bottomNotMatch implements !~ as the negation of =~
+func bottomNotMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	matched, err := Send(context, "=~", tracer, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if matched == NIL || matched == FALSE {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+// bottomCaseEqual implements the default ===, which sends == so that classes
+// redefining equality match in case expressions as well
+func bottomCaseEqual(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return Send(context, "==", tracer, args[0])
+}
@@ -59,5 +59,21 @@ func TestBottomRaise(t *testing.T) {
 			utils.AssertEqualCmpAny(t, result, nil, CompareRubyObjectsForTests)
 			utils.AssertError(t, err, NewRuntimeError("%s", obj.Inspect()))
 		})
+		t.Run("exception class", func(t *testing.T) {
+			result, err := bottomRaise(context, nil, typeErrorClass)
+			utils.AssertEqualCmpAny(t, result, nil, CompareRubyObjectsForTests)
+			utils.AssertError(t, err, NewTypeError("TypeError"))
+		})
+		t.Run("exception object", func(t *testing.T) {
+			exc := NewArgumentError("ouch")
+			_, err := bottomRaise(context, nil, exc)
+			utils.Assert(t, err == exc, "Expected the exception itself to be raised, got %v", err)
+		})
+	})
+
+	t.Run("with class and message", func(t *testing.T) {
+		result, err := bottomRaise(context, nil, argumentErrorClass, NewString("ouch"))
+		utils.AssertEqualCmpAny(t, result, nil, CompareRubyObjectsForTests)
+		utils.AssertError(t, err, NewArgumentError("ouch"))
 	})
 }
@@ -40,3 +40,47 @@ func (c *callContext) Eval(node ast.Node, env Environment) (RubyObject, error) {
 	return c.eval(node, env)
 }
 func (c *callContext) Receiver() RubyObject { return c.receiver }
+
+// A blockHolder is a CallContext which carries the block attached to a
+// method call
+type blockHolder interface {
+	Block() *Proc
+}
+
+// blockOf returns the block attached to the call of context, or nil if there
+// is none
+func blockOf(context CallContext) *Proc {
+	if holder, ok := context.(blockHolder); ok {
+		return holder.Block()
+	}
+	return nil
+}
+
+// withReceiver returns a copy of context sending messages to receiver. The
+// environment, the eval function, the call stack and the block of context are
+// kept.
+func withReceiver(context CallContext, receiver RubyObject) CallContext {
+	return &receiverContext{CallContext: context, receiver: receiver}
+}
+
+type receiverContext struct {
+	CallContext
+	receiver RubyObject
+}
+
+func (c *receiverContext) Receiver() RubyObject  { return c.receiver }
+func (c *receiverContext) CallStack() *CallStack { return callStackOf(c.CallContext) }
+func (c *receiverContext) Block() *Proc          { return blockOf(c.CallContext) }
+
+// withoutBlock returns a copy of context which carries no block, e.g. for
+// calls made by a proc on behalf of a builtin method which received a block
+func withoutBlock(context CallContext) CallContext {
+	return &blocklessContext{CallContext: context}
+}
+
+type blocklessContext struct {
+	CallContext
+}
+
+func (c *blocklessContext) CallStack() *CallStack { return callStackOf(c.CallContext) }
+func (c *blocklessContext) Block() *Proc          { return nil }
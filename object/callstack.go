@@ -0,0 +1,203 @@
+package object
+
+import (
+	"fmt"
+	gotoken "go/token"
+	"hash/fnv"
+	"strings"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+// A Frame represents a single method or block invocation on the CallStack
+type Frame struct {
+	Method   string      // name of the method, or e.g. "block in foo" for blocks
+	Class    string      // class of the receiver, empty for top level functions and blocks
+	Pos      gotoken.Pos // position of the expression currently evaluated within the frame
+	Function *Function   // the method executed, nil for blocks and the top level
+	Block    *Proc       // the block yield calls within the frame, if any
+}
+
+// Label returns the frame name as shown in backtraces
+func (f *Frame) Label() string {
+	if f.Class == "" {
+		return f.Method
+	}
+	return f.Class + "#" + f.Method
+}
+
+// CallStack keeps track of the Ruby methods and blocks being executed. Frame
+// positions are resolved through fset.
+type CallStack struct {
+	fset   *gotoken.FileSet
+	frames []*Frame
+}
+
+// NewCallStack returns a CallStack holding only the top level '<main>' frame
+func NewCallStack(fset *gotoken.FileSet) *CallStack {
+	return &CallStack{
+		fset:   fset,
+		frames: []*Frame{{Method: "<main>"}},
+	}
+}
+
+// Push adds a new innermost frame to the stack and returns it
+func (s *CallStack) Push(method, class string) *Frame {
+	frame := &Frame{Method: method, Class: class}
+	s.frames = append(s.frames, frame)
+	return frame
+}
+
+// Pop removes the innermost frame. The '<main>' frame is never removed.
+func (s *CallStack) Pop() {
+	if len(s.frames) > 1 {
+		s.frames = s.frames[:len(s.frames)-1]
+	}
+}
+
+// Top returns the innermost frame
+func (s *CallStack) Top() *Frame {
+	return s.frames[len(s.frames)-1]
+}
+
+// MethodFrame returns the innermost frame executing a method, skipping the
+// frames of blocks. It returns nil if no method is executed.
+func (s *CallStack) MethodFrame() *Frame {
+	for i := len(s.frames) - 1; i >= 0; i-- {
+		if s.frames[i].Function != nil {
+			return s.frames[i]
+		}
+	}
+	return nil
+}
+
+// Contains returns true if frame is on the stack
+func (s *CallStack) Contains(frame *Frame) bool {
+	for _, f := range s.frames {
+		if f == frame {
+			return true
+		}
+	}
+	return false
+}
+
+// Locations returns the locations of all frames, innermost first
+func (s *CallStack) Locations() []*Location {
+	locations := make([]*Location, len(s.frames))
+	for i, frame := range s.frames {
+		location := &Location{Label: frame.Label()}
+		if s.fset != nil && frame.Pos.IsValid() {
+			pos := s.fset.Position(frame.Pos)
+			location.Path = pos.Filename
+			location.Line = pos.Line
+		}
+		locations[len(s.frames)-1-i] = location
+	}
+	return locations
+}
+
+// Backtrace returns the frames of the stack formatted the way MRI does,
+// innermost first
+func (s *CallStack) Backtrace() []string {
+	locations := s.Locations()
+	backtrace := make([]string, len(locations))
+	for i, location := range locations {
+		backtrace[i] = location.String()
+	}
+	return backtrace
+}
+
+// A callStackHolder is a CallContext which gives access to the call stack of
+// the running program
+type callStackHolder interface {
+	CallStack() *CallStack
+}
+
+// callStackOf returns the call stack of the context, or nil if the context
+// does not track one
+func callStackOf(context CallContext) *CallStack {
+	if holder, ok := context.(callStackHolder); ok {
+		return holder.CallStack()
+	}
+	return nil
+}
+
+var locationClass RubyClassObject = newClass(
+	"Thread::Backtrace::Location",
+	locationMethods,
+	nil,
+	notInstantiatable,
+)
+
+// Location represents a Thread::Backtrace::Location, i.e. a single entry of
+// the backtrace as returned by caller_locations
+type Location struct {
+	Path  string
+	Line  int
+	Label string
+}
+
+func (l *Location) String() string {
+	return fmt.Sprintf("%s:%d:in '%s'", l.Path, l.Line, l.Label)
+}
+
+func (l *Location) Inspect() string  { return fmt.Sprintf("%q", l.String()) }
+func (l *Location) Class() RubyClass { return locationClass }
+func (l *Location) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(l.String()))
+	return HashKey(h.Sum64())
+}
+
+var (
+	_ RubyObject = &Location{}
+)
+
+var locationMethods = map[string]RubyMethod{
+	"path":   withArity(0, newMethod(locationPath)),
+	"lineno": withArity(0, newMethod(locationLineno)),
+	"label":  withArity(0, newMethod(locationLabel)),
+	"to_s":   withArity(0, newMethod(locationToS)),
+}
+
+func locationPath(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	location, _ := context.Receiver().(*Location)
+	return NewString(location.Path), nil
+}
+
+func locationLineno(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	location, _ := context.Receiver().(*Location)
+	return NewInteger(int64(location.Line)), nil
+}
+
+func locationLabel(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	location, _ := context.Receiver().(*Location)
+	return NewString(location.Label), nil
+}
+
+func locationToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	location, _ := context.Receiver().(*Location)
+	return NewString(location.String()), nil
+}
+
+// blockLabel returns the frame name of a block created within the frame
+// called scope, e.g. "block in foo". Nested blocks share the label of the
+// outermost one.
+func blockLabel(scope string) string {
+	if scope == "" {
+		scope = "<main>"
+	}
+	return "block in " + strings.TrimPrefix(scope, "block in ")
+}
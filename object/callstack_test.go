@@ -0,0 +1,111 @@
+package object
+
+import (
+	gotoken "go/token"
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestCallStack(t *testing.T) {
+	fset := gotoken.NewFileSet()
+	file := fset.AddFile("test.rb", -1, 100)
+	file.SetLinesForContent([]byte("foo\nbar\nbaz\n"))
+
+	stack := NewCallStack(fset)
+	stack.Top().Pos = file.Pos(8)
+	stack.Push("foo", "").Pos = file.Pos(0)
+	stack.Push("block in foo", "").Pos = file.Pos(4)
+	stack.Push("bar", "Integer")
+
+	utils.AssertEqualCmp(t, stack.Backtrace(), []string{
+		":0:in 'Integer#bar'",
+		"test.rb:2:in 'block in foo'",
+		"test.rb:1:in 'foo'",
+		"test.rb:3:in '<main>'",
+	}, utils.CompareArrays)
+
+	stack.Pop()
+	stack.Pop()
+	stack.Pop()
+	stack.Pop()
+
+	utils.AssertEqualCmp(t, stack.Backtrace(), []string{"test.rb:3:in '<main>'"}, utils.CompareArrays)
+}
+
+func TestBottomCaller(t *testing.T) {
+	fset := gotoken.NewFileSet()
+	file := fset.AddFile("test.rb", -1, 100)
+	file.SetLinesForContent([]byte("foo\nbar\nbaz\n"))
+
+	stack := NewCallStack(fset)
+	stack.Top().Pos = file.Pos(8)
+	stack.Push("foo", "").Pos = file.Pos(4)
+	context := &stackContext{
+		CallContext: &callContext{receiver: FUNCS_STORE, env: NewMainEnvironment()},
+		stack:       stack,
+	}
+
+	tests := []struct {
+		arguments []RubyObject
+		result    RubyObject
+	}{
+		{
+			[]RubyObject{},
+			NewArray(NewString("test.rb:3:in '<main>'")),
+		},
+		{
+			[]RubyObject{NewInteger(0)},
+			NewArray(NewString("test.rb:2:in 'foo'"), NewString("test.rb:3:in '<main>'")),
+		},
+		{
+			[]RubyObject{NewInteger(0), NewInteger(1)},
+			NewArray(NewString("test.rb:2:in 'foo'")),
+		},
+		{
+			[]RubyObject{NewInteger(2)},
+			NewArray(),
+		},
+		{
+			[]RubyObject{NewInteger(3)},
+			NIL,
+		},
+	}
+
+	for _, testCase := range tests {
+		result, err := bottomCaller(context, nil, testCase.arguments...)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, testCase.result, CompareRubyObjectsForTests)
+	}
+
+	t.Run("negative level", func(t *testing.T) {
+		_, err := bottomCaller(context, nil, NewInteger(-1))
+
+		utils.AssertError(t, err, NewArgumentError("negative level (-1)"))
+	})
+	t.Run("locations", func(t *testing.T) {
+		result, err := bottomCallerLocations(context, nil, NewInteger(0))
+
+		utils.AssertNoError(t, err)
+		locations, ok := result.(*Array)
+		utils.Assert(t, ok, "Expected array, got %T", result)
+		utils.AssertEqual(t, len(locations.Elements), 2)
+		utils.AssertEqualCmpAny(t, locations.Elements[0], &Location{Path: "test.rb", Line: 2, Label: "foo"}, CompareRubyObjectsForTests)
+	})
+}
+
+func TestFullMessage(t *testing.T) {
+	exc := NewRuntimeError("boom")
+	utils.AssertEqual(t, FullMessage(exc), "boom (RuntimeError)")
+
+	exc.SetBacktrace([]string{"test.rb:2:in 'foo'", "test.rb:5:in '<main>'"})
+	utils.AssertEqual(t, FullMessage(exc), "test.rb:2:in 'foo': boom (RuntimeError)\n\tfrom test.rb:5:in '<main>'")
+}
+
+type stackContext struct {
+	CallContext
+	stack *CallStack
+}
+
+func (s *stackContext) CallStack() *CallStack { return s.stack }
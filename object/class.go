@@ -1,7 +1,10 @@
 package object
 
 import (
+	"fmt"
 	"hash/fnv"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
 )
 
 var notInstantiatable = func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
@@ -29,33 +32,70 @@ func newClass(
 		Environment:     NewEnclosedEnvironment(nil),
 	}
 	cls.class.(*eigenclass).methods = NewMethodSet(classMethods)
+	cls.class.(*eigenclass).attached = cls
 	if cls == nil_class {
 		panic("newClass tried to return is nil_class")
 	}
 	return cls
 }
 
-// class represents a Ruby Class object
+// newSubclass returns a new Ruby Class inheriting from superClass. Both the
+// instance methods and the class methods of superClass are available on the
+// new class.
+func newSubclass(
+	superClass *class,
+	name string,
+	instanceMethods,
+	classMethods map[string]RubyMethod,
+	builder func(RubyClassObject, ...RubyObject) (RubyObject, error),
+) *class {
+	cls := newClass(name, instanceMethods, classMethods, builder)
+	cls.superClass = superClass
+	cls.class.(*eigenclass).parent = superClass.class.(*eigenclass)
+	return cls
+}
+
+// class represents a Ruby Class or Module object. The embedded Environment
+// holds the constants defined within the class.
 type class struct {
 	name            string
 	class           RubyClass
+	superClass      RubyClass
 	instanceMethods SettableMethodSet
 	builder         func(RubyClassObject, ...RubyObject) (RubyObject, error)
+	module          bool     // true for modules, which have no instances nor superclass
+	includes        []*class // included modules, the most recently included first
+	prepends        []*class // prepended modules, the most recently prepended first
 	Environment
+	instanceVariables
+}
+
+func (c *class) Inspect() string {
+	if c.name == "" {
+		return fmt.Sprintf("#<%s:%p>", c.kind().Name(), c)
+	}
+	return c.name
 }
 
-func (c *class) Inspect() string    { return c.name }
+// kind returns the class of c itself, i.e. Module for modules and Class
+// otherwise
+func (c *class) kind() *class {
+	if c.module {
+		return moduleClass
+	}
+	return classClass
+}
 func (c *class) Class() RubyClass   { return c.class }
 func (c *class) Methods() MethodSet { return c.instanceMethods }
 
 func (c *class) GetMethod(name string) (RubyMethod, bool) {
-	method, ok := c.instanceMethods.Get(name)
-	if ok {
-		return method, true
-	}
-	return nil, false
+	return c.instanceMethods.Get(name)
 }
 
+// SuperClass returns the parent of c, or nil if c is at the top of the class
+// hierarchy
+func (c *class) SuperClass() RubyClass { return c.superClass }
+
 func (c *class) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(c.name))
@@ -69,6 +109,199 @@ func (c *class) New(args ...RubyObject) (RubyObject, error) {
 }
 func (c *class) Name() string { return c.name }
 
+// superClassOf returns the parent of class within the class hierarchy or nil
+// if there is none
+func superClassOf(class RubyClass) RubyClass {
+	if class == RubyClass(nil_class) {
+		return nil
+	}
+	if inherited, ok := class.(interface{ SuperClass() RubyClass }); ok {
+		return inherited.SuperClass()
+	}
+	return nil
+}
+
+// isClassObject returns true if obj is a class
+func isClassObject(obj RubyObject) bool {
+	_, ok := obj.(*class)
+	return ok
+}
+
+// IsKindOf returns true if obj is an instance of class, of any class
+// inheriting from class or of a class which includes the module class. Every
+// object is an Object.
+func IsKindOf(obj RubyObject, class RubyClass) bool {
+	if class == RubyClass(objectClass) {
+		return true
+	}
+	for _, c := range ancestorsOf(obj.Class()) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// SetClassName gives an anonymous class, as returned by Class.new, the name
+// of the constant it is assigned to. Other objects are left untouched.
+func SetClassName(obj RubyObject, name string) {
+	if cls, ok := obj.(*class); ok && cls.name == "" {
+		cls.name = name
+	}
+}
+
+// OpenClass returns the class called name defined in env, so that a class
+// definition can reopen it, or defines a new one. superClass is nil if the
+// definition does not name one, in which case new classes inherit from
+// Object. env is either the top level environment or the module the class is
+// nested in.
+func OpenClass(env Environment, name string, superClass RubyObject) (RubyClassObject, error) {
+	var parent *class
+	if superClass != nil {
+		var ok bool
+		parent, ok = superClass.(*class)
+		if !ok {
+			return nil, NewTypeError("superclass must be a Class")
+		}
+	}
+	if existing, ok := env.Get(name); ok {
+		cls, ok := existing.(*class)
+		if !ok || cls.module {
+			return nil, NewTypeError(fmt.Sprintf("%s is not a class", name))
+		}
+		if parent != nil && cls.superClass != RubyClass(parent) {
+			return nil, NewTypeError(fmt.Sprintf("superclass mismatch for class %s", name))
+		}
+		return cls, nil
+	}
+	if parent == nil {
+		parent = objectClass
+	}
+	cls := newSubclass(parent, qualifiedName(env, name), nil, nil, parent.builder)
+	env.Set(name, cls)
+	return cls, nil
+}
+
+// qualifiedName returns the full name of the constant name defined within
+// env, e.g. Foo::Bar for Bar defined within the module Foo
+func qualifiedName(env Environment, name string) string {
+	if outer, ok := env.(*class); ok && outer.name != "" {
+		return outer.name + "::" + name
+	}
+	return name
+}
+
+// AddSingletonMethod defines method on obj alone, as done by `def self.foo`
+// within a class body
+func AddSingletonMethod(obj RubyObject, name string, method *Function) error {
+	singleton, err := singletonClassOf(obj)
+	if err != nil {
+		return err
+	}
+	method.Owner = singleton
+	singleton.addMethod(name, method)
+	return nil
+}
+
+// singletonClassOf returns the eigenclass holding the methods defined on obj
+// alone. Only classes, modules and user defined objects can have singleton
+// methods.
+func singletonClassOf(obj RubyObject) (*eigenclass, error) {
+	switch obj := obj.(type) {
+	case *class:
+		if singleton, ok := obj.class.(*eigenclass); ok {
+			return singleton, nil
+		}
+	case *Object:
+		if obj.singleton == nil {
+			obj.singleton = newEigenclass(obj.class)
+		}
+		return obj.singleton, nil
+	case *extendedObject:
+		return obj.eigenclass, nil
+	}
+	return nil, NewTypeError("can't define singleton")
+}
+
+var classClass *class
+
+func init() {
+	// NOTE: created in init as Class#new sends initialize, which refers back
+	// to classClass and moduleClass through the method lookup
+	moduleClass = newSubclass(
+		objectClass,
+		"Module",
+		moduleMethods,
+		map[string]RubyMethod{
+			"new": withArity(0, newMethod(moduleNew)),
+		},
+		notInstantiatable,
+	)
+	CLASSES.Set("Module", moduleClass)
+	classClass = newSubclass(
+		moduleClass,
+		"Class",
+		classMethods,
+		map[string]RubyMethod{
+			"new": newMethod(classNew),
+		},
+		notInstantiatable,
+	)
+	CLASSES.Set("Class", classClass)
+}
+
+var classMethods = map[string]RubyMethod{
+	"new":        newMethod(classNewInstance),
+	"superclass": withArity(0, newMethod(classSuperclass)),
+}
+
+func classNew(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	superClass := objectClass
+	if len(args) == 1 {
+		var ok bool
+		superClass, ok = args[0].(*class)
+		if !ok {
+			return nil, NewTypeError("superclass must be a Class")
+		}
+	}
+	return newSubclass(superClass, "", nil, nil, superClass.builder), nil
+}
+
+// classNewInstance allocates an instance of the receiver and initializes it
+// with args
+func classNewInstance(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	class, _ := context.Receiver().(RubyClassObject)
+	instance, err := class.New()
+	if err != nil {
+		return nil, err
+	}
+	_, err = Send(withReceiver(context, instance), "initialize", tracer, args...)
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+func classSuperclass(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	superClass, ok := superClassOf(context.Receiver().(RubyClass)).(RubyClassObject)
+	if !ok || superClass == RubyClassObject(nil_class) {
+		return NIL, nil
+	}
+	return superClass, nil
+}
+
 var (
 	_ RubyObject = &class{}
 	_ RubyClass  = &class{}
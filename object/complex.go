@@ -0,0 +1,582 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+var complexClass RubyClassObject = newSubclass(
+	numericClass, "Complex", complexMethods, nil, notInstantiatable,
+)
+
+func init() {
+	CLASSES.Set("Complex", complexClass)
+}
+
+// NewComplex returns a new Complex with the given real and imaginary parts,
+// which are Integers, Floats or Rationals
+func NewComplex(real, imaginary RubyObject) *Complex {
+	return &Complex{Real: real, Imaginary: imaginary}
+}
+
+// Complex represents a complex number in Ruby, e.g. `Complex(1, 2)` or `2i`
+type Complex struct {
+	Real      RubyObject
+	Imaginary RubyObject
+}
+
+// Inspect returns the parts in parentheses, e.g. `(1+2i)`
+func (c *Complex) Inspect() string {
+	return "(" + formatComplex(c, inspectReal) + ")"
+}
+
+// Class returns complexClass
+func (c *Complex) Class() RubyClass { return complexClass }
+
+func (c *Complex) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(c.Inspect()))
+	return HashKey(h.Sum64())
+}
+
+var (
+	_ RubyObject = &Complex{}
+)
+
+var complexMethods = map[string]RubyMethod{
+	"+":           withArity(1, newMethod(complexAdd)),
+	"-":           withArity(1, newMethod(complexSub)),
+	"*":           withArity(1, newMethod(complexMul)),
+	"/":           withArity(1, newMethod(complexDiv)),
+	"quo":         withArity(1, newMethod(complexDiv)),
+	"**":          withArity(1, newMethod(complexPow)),
+	"<=>":         withArity(1, newMethod(complexSpaceship)),
+	"-@":          withArity(0, newMethod(complexNegate)),
+	"real":        withArity(0, newMethod(complexReal)),
+	"imaginary":   withArity(0, newMethod(complexImaginary)),
+	"imag":        withArity(0, newMethod(complexImaginary)),
+	"abs":         withArity(0, newMethod(complexAbs)),
+	"magnitude":   withArity(0, newMethod(complexAbs)),
+	"abs2":        withArity(0, newMethod(complexAbs2)),
+	"arg":         withArity(0, newMethod(complexArg)),
+	"angle":       withArity(0, newMethod(complexArg)),
+	"phase":       withArity(0, newMethod(complexArg)),
+	"conj":        withArity(0, newMethod(complexConjugate)),
+	"conjugate":   withArity(0, newMethod(complexConjugate)),
+	"rectangular": withArity(0, newMethod(complexRectangular)),
+	"rect":        withArity(0, newMethod(complexRectangular)),
+	"polar":       withArity(0, newMethod(complexPolar)),
+	"coerce":      withArity(1, newMethod(complexCoerce)),
+	"to_i":        withArity(0, newMethod(complexToI)),
+	"to_f":        withArity(0, newMethod(complexToF)),
+	"to_r":        withArity(0, newMethod(complexToR)),
+	"to_s":        withArity(0, newMethod(complexToS)),
+}
+
+// inspectReal returns the real number obj as shown by inspect
+func inspectReal(obj RubyObject) string {
+	if f, ok := obj.(*Float); ok {
+		return formatFloat(f.Value)
+	}
+	return obj.Inspect()
+}
+
+// realToS returns the real number obj as shown by to_s
+func realToS(obj RubyObject) string {
+	if r, ok := obj.(*Rational); ok {
+		return r.Value.String()
+	}
+	return inspectReal(obj)
+}
+
+// formatComplex returns the parts of c formatted by format, e.g. `1+2i`. A
+// `*` separates an imaginary part not ending in a digit, e.g. `1+(1/2)*i` or
+// `0+Infinity*i`.
+func formatComplex(c *Complex, format func(RubyObject) string) string {
+	var out strings.Builder
+	out.WriteString(format(c.Real))
+	if isNegativeReal(c.Imaginary) {
+		out.WriteString("-")
+	} else {
+		out.WriteString("+")
+	}
+	imaginary := format(absReal(c.Imaginary))
+	out.WriteString(imaginary)
+	if last := imaginary[len(imaginary)-1]; last < '0' || last > '9' {
+		out.WriteString("*")
+	}
+	out.WriteString("i")
+	return out.String()
+}
+
+// isNegativeReal reports whether the real number obj has a minus sign, which
+// -0.0 has as well
+func isNegativeReal(obj RubyObject) bool {
+	switch obj := obj.(type) {
+	case *Integer:
+		return obj.Sign() < 0
+	case *Rational:
+		return obj.Value.Sign() < 0
+	case *Float:
+		return math.Signbit(obj.Value) && !math.IsNaN(obj.Value)
+	default:
+		return false
+	}
+}
+
+func absReal(obj RubyObject) RubyObject {
+	switch obj := obj.(type) {
+	case *Integer:
+		if obj.Sign() < 0 {
+			return obj.Negate()
+		}
+	case *Rational:
+		return NewRational(new(big.Rat).Abs(obj.Value))
+	case *Float:
+		return NewFloat(math.Abs(obj.Value))
+	}
+	return obj
+}
+
+func negateReal(obj RubyObject) RubyObject {
+	switch obj := obj.(type) {
+	case *Integer:
+		return obj.Negate()
+	case *Rational:
+		return NewRational(new(big.Rat).Neg(obj.Value))
+	case *Float:
+		return NewFloat(-obj.Value)
+	}
+	return obj
+}
+
+// isExactZero reports whether obj is the Integer or Rational zero
+func isExactZero(obj RubyObject) bool {
+	value, ok := exactValue(obj)
+	return ok && value.Sign() == 0
+}
+
+// isZeroReal reports whether obj is zero, including 0.0
+func isZeroReal(obj RubyObject) bool {
+	value, ok := safeObjectToFloat(obj)
+	return ok && value == 0
+}
+
+// canonicalReal turns Rationals without a fractional part into Integers,
+// as the exact parts of the results of complex divisions are
+func canonicalReal(obj RubyObject) RubyObject {
+	if r, ok := obj.(*Rational); ok && r.Value.IsInt() {
+		return NewBigInteger(new(big.Int).Set(r.Value.Num()))
+	}
+	return obj
+}
+
+// complexParts returns the real and imaginary parts of the number obj, which
+// is a Complex or a real number
+func complexParts(obj RubyObject) (real, imaginary RubyObject, ok bool) {
+	switch obj := obj.(type) {
+	case *Complex:
+		return obj.Real, obj.Imaginary, true
+	case *Integer, *Float, *Rational:
+		return obj, NewInteger(0), true
+	default:
+		return nil, nil, false
+	}
+}
+
+// complex128Of returns c as a Go complex number
+func complex128Of(c *Complex) complex128 {
+	real, _ := safeObjectToFloat(c.Real)
+	imaginary, _ := safeObjectToFloat(c.Imaginary)
+	return complex(real, imaginary)
+}
+
+// numberArithmetic sends arithmetic operators to the parts of complex numbers
+// on behalf of a method, and keeps the first error any of them returns
+type numberArithmetic struct {
+	context CallContext
+	tracer  trace.Tracer
+	err     error
+}
+
+func (a *numberArithmetic) apply(x RubyObject, op string, y RubyObject) RubyObject {
+	if a.err != nil {
+		return nil
+	}
+	result, err := Send(withReceiver(a.context, x), op, a.tracer, y)
+	if err != nil {
+		a.err = err
+		return nil
+	}
+	return result
+}
+
+// mulComplex returns the product of x and y
+func (a *numberArithmetic) mulComplex(x, y *Complex) *Complex {
+	real := a.apply(a.apply(x.Real, "*", y.Real), "-", a.apply(x.Imaginary, "*", y.Imaginary))
+	imaginary := a.apply(a.apply(x.Real, "*", y.Imaginary), "+", a.apply(x.Imaginary, "*", y.Real))
+	return NewComplex(real, imaginary)
+}
+
+// quoComplex returns the quotient of x and y, which keeps exact parts exact,
+// e.g. `Complex(1, 2) / Complex(3, 4)` is `((11/25)+(2/25)*i)`
+func (a *numberArithmetic) quoComplex(x, y *Complex) *Complex {
+	var real, imaginary RubyObject
+	if greater := a.apply(absReal(y.Real), ">", absReal(y.Imaginary)); greater == TRUE {
+		r := a.apply(y.Imaginary, "quo", y.Real)
+		n := a.apply(y.Real, "*", a.apply(NewInteger(1), "+", a.apply(r, "*", r)))
+		real = a.apply(a.apply(x.Real, "+", a.apply(x.Imaginary, "*", r)), "quo", n)
+		imaginary = a.apply(a.apply(x.Imaginary, "-", a.apply(x.Real, "*", r)), "quo", n)
+	} else {
+		r := a.apply(y.Real, "quo", y.Imaginary)
+		n := a.apply(y.Imaginary, "*", a.apply(NewInteger(1), "+", a.apply(r, "*", r)))
+		real = a.apply(a.apply(a.apply(x.Real, "*", r), "+", x.Imaginary), "quo", n)
+		imaginary = a.apply(a.apply(a.apply(x.Imaginary, "*", r), "-", x.Real), "quo", n)
+	}
+	return NewComplex(canonicalReal(real), canonicalReal(imaginary))
+}
+
+// complexArithmetic applies the operator op to the receiver and arg part by
+// part, which suits addition and subtraction. Arguments which are not
+// numbers are coerced.
+func complexArithmetic(context CallContext, tracer trace.Tracer, op string, arg RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	real, imaginary, ok := complexParts(arg)
+	if !ok {
+		return coerceBinop(context, tracer, op, arg)
+	}
+	a := &numberArithmetic{context: context, tracer: tracer}
+	result := NewComplex(a.apply(c.Real, op, real), c.Imaginary)
+	if _, isComplex := arg.(*Complex); isComplex {
+		result.Imaginary = a.apply(c.Imaginary, op, imaginary)
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return result, nil
+}
+
+func complexAdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return complexArithmetic(context, tracer, "+", args[0])
+}
+
+func complexSub(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return complexArithmetic(context, tracer, "-", args[0])
+}
+
+func complexMul(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	a := &numberArithmetic{context: context, tracer: tracer}
+	var result *Complex
+	switch arg := args[0].(type) {
+	case *Complex:
+		result = a.mulComplex(c, arg)
+	case *Integer, *Float, *Rational:
+		result = NewComplex(a.apply(c.Real, "*", arg), a.apply(c.Imaginary, "*", arg))
+	default:
+		return coerceBinop(context, tracer, "*", args[0])
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return result, nil
+}
+
+func complexDiv(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	a := &numberArithmetic{context: context, tracer: tracer}
+	var result *Complex
+	switch arg := args[0].(type) {
+	case *Complex:
+		result = a.quoComplex(c, arg)
+	case *Integer, *Float, *Rational:
+		real, imaginary := a.apply(c.Real, "quo", arg), a.apply(c.Imaginary, "quo", arg)
+		result = NewComplex(canonicalReal(real), canonicalReal(imaginary))
+	default:
+		return coerceBinop(context, tracer, "/", args[0])
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return result, nil
+}
+
+// complexPow raises the receiver to the argument, exactly for Integer
+// exponents and by the polar form of the receiver otherwise
+func complexPow(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	exp := args[0]
+	if r, ok := exp.(*Rational); ok && r.Value.IsInt() {
+		exp = canonicalReal(r)
+	}
+	if other, ok := exp.(*Complex); ok && isExactZero(other.Imaginary) {
+		exp = other.Real
+	}
+	a := &numberArithmetic{context: context, tracer: tracer}
+	switch arg := exp.(type) {
+	case *Integer:
+		if arg.IsBig() {
+			return nil, NewArgumentError("exponent is too large")
+		}
+		result, base, n := NewComplex(NewInteger(1), NewInteger(0)), c, arg.Value
+		if n < 0 {
+			base, n = a.quoComplex(NewComplex(NewInteger(1), NewInteger(0)), c), -n
+		}
+		for ; n > 0 && a.err == nil; n >>= 1 {
+			if n&1 == 1 {
+				result = a.mulComplex(result, base)
+			}
+			if n > 1 {
+				base = a.mulComplex(base, base)
+			}
+		}
+		if a.err != nil {
+			return nil, a.err
+		}
+		return result, nil
+	case *Float, *Rational:
+		exponent, _ := safeObjectToFloat(arg)
+		z := complex128Of(c)
+		r, theta := math.Hypot(real(z), imag(z)), math.Atan2(imag(z), real(z))
+		return polarToComplex(math.Pow(r, exponent), theta*exponent), nil
+	case *Complex:
+		z, w := complex128Of(c), complex128Of(arg)
+		r, theta := math.Hypot(real(z), imag(z)), math.Atan2(imag(z), real(z))
+		logR := math.Log(r)
+		return polarToComplex(
+			math.Exp(real(w)*logR-imag(w)*theta),
+			theta*real(w)+imag(w)*logR,
+		), nil
+	default:
+		return coerceBinop(context, tracer, "**", args[0])
+	}
+}
+
+// polarToComplex returns the Complex of the given magnitude and angle
+func polarToComplex(abs, arg float64) *Complex {
+	return NewComplex(NewFloat(abs*math.Cos(arg)), NewFloat(abs*math.Sin(arg)))
+}
+
+// complexSpaceship compares Complexes without an imaginary part by their
+// real parts, and returns nil for any other
+func complexSpaceship(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	real, imaginary, ok := complexParts(args[0])
+	if !ok {
+		return coerceCmp(context, tracer, args[0])
+	}
+	if !isZeroReal(c.Imaginary) || !isZeroReal(imaginary) {
+		return NIL, nil
+	}
+	return Send(withReceiver(context, c.Real), "<=>", tracer, real)
+}
+
+func complexNegate(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	return NewComplex(negateReal(c.Real), negateReal(c.Imaginary)), nil
+}
+
+func complexReal(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return context.Receiver().(*Complex).Real, nil
+}
+
+func complexImaginary(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return context.Receiver().(*Complex).Imaginary, nil
+}
+
+// complexAbs returns the magnitude of the receiver, which is a Float unless
+// one of the parts is zero and the other exact, e.g. `Complex(3, 0).abs` is 3
+func complexAbs(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	for _, parts := range [][2]RubyObject{{c.Real, c.Imaginary}, {c.Imaginary, c.Real}} {
+		zero, other := parts[0], parts[1]
+		if !isZeroReal(zero) {
+			continue
+		}
+		if _, isFloat := zero.(*Float); isFloat {
+			value, _ := safeObjectToFloat(other)
+			return NewFloat(math.Abs(value)), nil
+		}
+		return absReal(other), nil
+	}
+	z := complex128Of(c)
+	return NewFloat(math.Hypot(real(z), imag(z))), nil
+}
+
+// complexAbs2 returns the square of the magnitude of the receiver, which is
+// exact for exact parts
+func complexAbs2(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	a := &numberArithmetic{context: context, tracer: tracer}
+	result := a.apply(a.apply(c.Real, "*", c.Real), "+", a.apply(c.Imaginary, "*", c.Imaginary))
+	if a.err != nil {
+		return nil, a.err
+	}
+	return result, nil
+}
+
+func complexArg(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	z := complex128Of(context.Receiver().(*Complex))
+	return NewFloat(math.Atan2(imag(z), real(z))), nil
+}
+
+func complexConjugate(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	return NewComplex(c.Real, negateReal(c.Imaginary)), nil
+}
+
+func complexRectangular(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	return NewArray(c.Real, c.Imaginary), nil
+}
+
+func complexPolar(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	abs, err := complexAbs(context, tracer)
+	if err != nil {
+		return nil, err
+	}
+	arg, err := complexArg(context, tracer)
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(abs, arg), nil
+}
+
+// complexCoerce turns real numbers into Complexes without an imaginary part
+func complexCoerce(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	real, imaginary, ok := complexParts(args[0])
+	if !ok {
+		return nil, NewTypeError(fmt.Sprintf("%s can't be coerced into Complex", args[0].Class().(RubyObject).Inspect()))
+	}
+	return NewArray(NewComplex(real, imaginary), context.Receiver()), nil
+}
+
+// complexToReal converts the receiver, which must not have an imaginary part,
+// to a real number by sending method to its real part
+func complexToReal(context CallContext, tracer trace.Tracer, method, class string) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	c := context.Receiver().(*Complex)
+	if !isExactZero(c.Imaginary) {
+		return nil, NewRangeError("can't convert %s into %s", formatComplex(c, realToS), class)
+	}
+	return Send(withReceiver(context, c.Real), method, tracer)
+}
+
+func complexToI(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return complexToReal(context, tracer, "to_i", "Integer")
+}
+
+func complexToF(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return complexToReal(context, tracer, "to_f", "Float")
+}
+
+func complexToR(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return complexToReal(context, tracer, "to_r", "Rational")
+}
+
+func complexToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return NewString(formatComplex(context.Receiver().(*Complex), realToS)), nil
+}
+
+// bottomComplex implements Kernel#Complex, which returns the complex number
+// with the given real and imaginary parts, e.g. `Complex(1, 2)`
+func bottomComplex(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	real, imaginary, ok := complexParts(args[0])
+	if !ok {
+		return nil, NewTypeError(fmt.Sprintf("can't convert %s into Complex", args[0].Class().(RubyObject).Inspect()))
+	}
+	if len(args) == 1 {
+		return NewComplex(real, imaginary), nil
+	}
+	switch arg := args[1].(type) {
+	case *Integer, *Float, *Rational:
+		if _, isComplex := args[0].(*Complex); !isComplex {
+			return NewComplex(real, arg), nil
+		}
+		// Complex(a, b) is a + b * i for a complex a
+		a := &numberArithmetic{context: context, tracer: tracer}
+		result := NewComplex(real, a.apply(imaginary, "+", arg))
+		if a.err != nil {
+			return nil, a.err
+		}
+		return result, nil
+	default:
+		return nil, NewTypeError("not a real")
+	}
+}
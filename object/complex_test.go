@@ -0,0 +1,109 @@
+package object
+
+import (
+	"math"
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestComplexInspect(t *testing.T) {
+	tests := []struct {
+		value   *Complex
+		inspect string
+		toS     string
+	}{
+		{NewComplex(NewInteger(1), NewInteger(2)), "(1+2i)", "1+2i"},
+		{NewComplex(NewInteger(1), NewInteger(-2)), "(1-2i)", "1-2i"},
+		{NewComplex(NewFloat(1.5), NewFloat(math.Copysign(0, -1))), "(1.5-0.0i)", "1.5-0.0i"},
+		{NewComplex(NewInteger(0), rational(1, 2)), "(0+(1/2)*i)", "0+1/2i"},
+		{NewComplex(NewInteger(0), NewFloat(math.Inf(1))), "(0+Infinity*i)", "0+Infinity*i"},
+	}
+
+	for _, tt := range tests {
+		utils.AssertEqual(t, tt.value.Inspect(), tt.inspect)
+
+		toS, err := complexToS(&callContext{receiver: tt.value}, nil)
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, toS, NewString(tt.toS), CompareRubyObjectsForTests)
+	}
+}
+
+func TestComplexArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   func(CallContext, trace.Tracer, ...RubyObject) (RubyObject, error)
+		receiver RubyObject
+		argument RubyObject
+		result   RubyObject
+	}{
+		{"+", complexAdd, NewComplex(NewInteger(1), NewInteger(2)), NewComplex(NewInteger(3), NewInteger(4)), NewComplex(NewInteger(4), NewInteger(6))},
+		{"+ Integer", complexAdd, NewComplex(NewInteger(1), NewInteger(2)), NewInteger(1), NewComplex(NewInteger(2), NewInteger(2))},
+		{"-", complexSub, NewComplex(NewInteger(1), NewInteger(2)), NewFloat(0.5), NewComplex(NewFloat(0.5), NewInteger(2))},
+		{"*", complexMul, NewComplex(NewInteger(1), NewInteger(2)), NewComplex(NewInteger(3), NewInteger(4)), NewComplex(NewInteger(-5), NewInteger(10))},
+		{"/", complexDiv, NewComplex(NewInteger(1), NewInteger(2)), NewComplex(NewInteger(3), NewInteger(4)), NewComplex(rational(11, 25), rational(2, 25))},
+		{"/ Integer", complexDiv, NewComplex(NewInteger(2), NewInteger(1)), NewInteger(2), NewComplex(NewInteger(1), rational(1, 2))},
+		{"**", complexPow, NewComplex(NewInteger(1), NewInteger(1)), NewInteger(4), NewComplex(NewInteger(-4), NewInteger(0))},
+		{"Integer +", integerAdd, NewInteger(1), NewComplex(NewInteger(0), NewInteger(1)), NewComplex(NewInteger(1), NewInteger(1))},
+		{"Float *", floatMul, NewFloat(2), NewComplex(NewInteger(1), NewInteger(1)), NewComplex(NewFloat(2), NewFloat(2))},
+		{"Rational -", rationalSub, rational(1, 2), NewComplex(NewInteger(0), NewInteger(1)), NewComplex(rational(1, 2), NewInteger(-1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(&callContext{receiver: tt.receiver}, nil, tt.argument)
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+	t.Run("by zero", func(t *testing.T) {
+		_, err := complexDiv(&callContext{receiver: NewComplex(NewInteger(1), NewInteger(1))}, nil, NewComplex(NewInteger(0), NewInteger(0)))
+
+		utils.AssertError(t, err, NewZeroDivisionError())
+	})
+}
+
+func TestComplexAbs(t *testing.T) {
+	tests := []struct {
+		value *Complex
+		abs   RubyObject
+	}{
+		{NewComplex(NewInteger(3), NewInteger(4)), NewFloat(5)},
+		{NewComplex(NewInteger(-3), NewInteger(0)), NewInteger(3)},
+		{NewComplex(NewFloat(0), NewInteger(-3)), NewFloat(3)},
+		{NewComplex(NewInteger(0), rational(-1, 2)), rational(1, 2)},
+	}
+
+	for _, tt := range tests {
+		result, err := complexAbs(&callContext{receiver: tt.value}, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, tt.abs, CompareRubyObjectsForTests)
+	}
+}
+
+func TestComplexToReal(t *testing.T) {
+	result, err := complexToI(&callContext{receiver: NewComplex(NewFloat(2.5), NewInteger(0))}, nil)
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewInteger(2), CompareRubyObjectsForTests)
+
+	_, err = complexToF(&callContext{receiver: NewComplex(NewInteger(1), NewInteger(2))}, nil)
+	utils.AssertError(t, err, NewRangeError("can't convert 1+2i into Float"))
+}
+
+func TestPowFloat(t *testing.T) {
+	tests := []struct {
+		x, y   float64
+		result RubyObject
+	}{
+		{2, 0.5, NewFloat(1.4142135623730951)},
+		{-4, 0.5, NewComplex(NewFloat(0), NewFloat(2))},
+		{-8, 2, NewFloat(64)},
+	}
+
+	for _, tt := range tests {
+		utils.AssertEqualCmpAny(t, powFloat(tt.x, tt.y), tt.result, CompareRubyObjectsForTests)
+	}
+}
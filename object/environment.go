@@ -35,6 +35,22 @@ func NewEnvironment() Environment {
 	return &environment{store: s, outer: nil}
 }
 
+// holdingEnvironment returns the innermost environment enclosing env, env
+// included, which holds name itself, or the outermost one if none does
+func holdingEnvironment(env Environment, name string) Environment {
+	for {
+		if e, ok := env.(*environment); ok {
+			if _, ok := e.store[name]; ok {
+				return env
+			}
+		}
+		if env.Outer() == nil {
+			return env
+		}
+		env = env.Outer()
+	}
+}
+
 // Environment holds Ruby object referenced by strings
 type Environment interface {
 	// Get returns the RubyObject found for this key. If it is not found,
@@ -9,28 +9,235 @@ import (
 )
 
 var (
-	exceptionClass RubyClassObject = newClass(
+	exceptionClass = newClass(
 		"Exception",
 		exceptionMethods,
-		nil,
+		exceptionClassMethods,
 		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
-			return &Exception{message: c.Name()}, nil
+			return &Exception{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	standardErrorClass = newSubclass(
+		exceptionClass, "StandardError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &StandardError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	runtimeErrorClass = newSubclass(
+		standardErrorClass, "RuntimeError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &RuntimeError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	arithmeticErrorClass = newSubclass(
+		standardErrorClass, "ArithmeticError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &ArithmeticError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	zeroDivisionErrorClass = newSubclass(
+		arithmeticErrorClass, "ZeroDivisionError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &ZeroDivisionError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	argumentErrorClass = newSubclass(
+		standardErrorClass, "ArgumentError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &ArgumentError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	nameErrorClass = newSubclass(
+		standardErrorClass, "NameError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &NameError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	noMethodErrorClass = newSubclass(
+		nameErrorClass, "NoMethodError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &NoMethodError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	typeErrorClass = newSubclass(
+		standardErrorClass, "TypeError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &TypeError{Message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	noMatchingPatternErrorClass = newSubclass(
+		standardErrorClass, "NoMatchingPatternError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &NoMatchingPatternError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	localJumpErrorClass = newSubclass(
+		standardErrorClass, "LocalJumpError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &LocalJumpError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	indexErrorClass = newSubclass(
+		standardErrorClass, "IndexError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &IndexError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	keyErrorClass = newSubclass(
+		indexErrorClass, "KeyError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &KeyError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	stopIterationClass = newSubclass(
+		indexErrorClass, "StopIteration", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &StopIteration{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	rangeErrorClass = newSubclass(
+		standardErrorClass, "RangeError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &RangeError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	floatDomainErrorClass = newSubclass(
+		rangeErrorClass, "FloatDomainError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &FloatDomainError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	domainErrorClass = newSubclass(
+		argumentErrorClass, "Math::DomainError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &DomainError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	regexpErrorClass = newSubclass(
+		standardErrorClass, "RegexpError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &RegexpError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	scriptErrorClass = newSubclass(
+		exceptionClass, "ScriptError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &ScriptError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	syntaxErrorClass = newSubclass(
+		scriptErrorClass, "SyntaxError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &SyntaxError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
+		},
+	)
+	notImplementedErrorClass = newSubclass(
+		scriptErrorClass, "NotImplementedError", nil, nil,
+		func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+			return &NotImplementedError{message: c.Name(), exceptionState: exceptionState{class: c}}, nil
 		},
 	)
 )
 
 func init() {
 	CLASSES.Set("Exception", exceptionClass)
+	CLASSES.Set("StandardError", standardErrorClass)
+	CLASSES.Set("RuntimeError", runtimeErrorClass)
+	CLASSES.Set("ArithmeticError", arithmeticErrorClass)
+	CLASSES.Set("ZeroDivisionError", zeroDivisionErrorClass)
+	CLASSES.Set("ArgumentError", argumentErrorClass)
+	CLASSES.Set("NameError", nameErrorClass)
+	CLASSES.Set("NoMethodError", noMethodErrorClass)
+	CLASSES.Set("TypeError", typeErrorClass)
+	CLASSES.Set("NoMatchingPatternError", noMatchingPatternErrorClass)
+	CLASSES.Set("LocalJumpError", localJumpErrorClass)
+	CLASSES.Set("IndexError", indexErrorClass)
+	CLASSES.Set("KeyError", keyErrorClass)
+	CLASSES.Set("StopIteration", stopIterationClass)
+	CLASSES.Set("RangeError", rangeErrorClass)
+	CLASSES.Set("FloatDomainError", floatDomainErrorClass)
+	CLASSES.Set("RegexpError", regexpErrorClass)
+	CLASSES.Set("ScriptError", scriptErrorClass)
+	CLASSES.Set("SyntaxError", syntaxErrorClass)
+	CLASSES.Set("NotImplementedError", notImplementedErrorClass)
 }
 
 func formatException(exception RubyObject, message string) string {
-	return fmt.Sprintf("%s: %s", RubyObjectToTypeString(exception), message)
+	return fmt.Sprintf("%s: %s", exception.Class().Name(), message)
 }
 
 type exception interface {
 	RubyObject
 	setErrorMessage(string)
 	error
+	Backtracer
+	Causer
+}
+
+// A Backtracer is an exception which remembers where it was raised
+type Backtracer interface {
+	// Backtrace returns the backtrace recorded when the exception was raised,
+	// innermost frame first. It is nil for exceptions not raised yet.
+	Backtrace() []string
+	SetBacktrace(backtrace []string)
+}
+
+// A Causer is an exception which remembers the exception being handled when
+// it was raised
+type Causer interface {
+	// Cause returns the exception which was being handled when this
+	// exception was raised, or nil
+	Cause() RubyObject
+	SetCause(cause RubyObject)
+}
+
+// exceptionState is embedded by every exception. Besides the backtrace and
+// the cause it holds the class the exception was instantiated from, which
+// differs from the Go type for user defined exception classes.
+type exceptionState struct {
+	class RubyClass
+	lines []string
+	cause RubyObject
+	instanceVariables
+}
+
+func (s *exceptionState) Backtrace() []string             { return s.lines }
+func (s *exceptionState) SetBacktrace(backtrace []string) { s.lines = backtrace }
+func (s *exceptionState) Cause() RubyObject               { return s.cause }
+func (s *exceptionState) SetCause(cause RubyObject)       { s.cause = cause }
+
+// classOr returns the class the exception was instantiated from, or def if
+// it was created from Go
+func (s *exceptionState) classOr(def RubyClass) RubyClass {
+	if s.class != nil {
+		return s.class
+	}
+	return def
+}
+
+// FullMessage formats exc the way MRI reports an uncaught exception: the
+// innermost backtrace entry, the message and the class, followed by the
+// remaining backtrace entries.
+func FullMessage(exc RubyObject) string {
+	var out strings.Builder
+	var lines []string
+	if b, ok := exc.(Backtracer); ok {
+		lines = b.Backtrace()
+	}
+	if len(lines) > 0 {
+		out.WriteString(lines[0])
+		out.WriteString(": ")
+	}
+	message := exc.Inspect()
+	if err, ok := exc.(error); ok {
+		message = err.Error()
+	}
+	out.WriteString(fmt.Sprintf("%s (%s)", message, exc.Class().Name()))
+	for i := 1; i < len(lines); i++ {
+		out.WriteString("\n\tfrom ")
+		out.WriteString(lines[i])
+	}
+	return out.String()
 }
 
 // NewException creates a new exception with the given message template and
@@ -41,12 +248,13 @@ func NewException(message string, args ...interface{}) *Exception {
 
 type Exception struct {
 	message string
+	exceptionState
 }
 
 func (e *Exception) Inspect() string            { return formatException(e, e.message) }
 func (e *Exception) Error() string              { return e.message }
 func (e *Exception) setErrorMessage(msg string) { e.message = msg }
-func (e *Exception) Class() RubyClass           { return exceptionClass }
+func (e *Exception) Class() RubyClass           { return e.classOr(exceptionClass) }
 func (e *Exception) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -55,10 +263,18 @@ var (
 	_ exception = &Exception{}
 	_ error     = &Exception{}
 )
+var exceptionClassMethods = map[string]RubyMethod{
+	"exception": newMethod(classNewInstance),
+}
+
 var exceptionMethods = map[string]RubyMethod{
-	"initialize": newMethod(exceptionInitialize),
-	"exception":  newMethod(exceptionException),
-	"to_s":       withArity(0, newMethod(exceptionToS)),
+	"initialize":   newMethod(exceptionInitialize),
+	"exception":    newMethod(exceptionException),
+	"to_s":         withArity(0, newMethod(exceptionToS)),
+	"message":      withArity(0, newMethod(exceptionMessage)),
+	"full_message": withArity(0, newMethod(exceptionFullMessage)),
+	"backtrace":    withArity(0, newMethod(exceptionBacktrace)),
+	"cause":        withArity(0, newMethod(exceptionCause)),
 }
 
 func exceptionInitialize(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -123,6 +339,46 @@ func exceptionToS(context CallContext, tracer trace.Tracer, args ...RubyObject)
 	return nil, nil
 }
 
+func exceptionMessage(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return Send(context, "to_s", tracer)
+}
+
+func exceptionFullMessage(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return NewString(FullMessage(context.Receiver())), nil
+}
+
+func exceptionCause(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	receiver, ok := context.Receiver().(Causer)
+	if !ok || receiver.Cause() == nil {
+		return NIL, nil
+	}
+	return receiver.Cause(), nil
+}
+
+func exceptionBacktrace(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	receiver, ok := context.Receiver().(Backtracer)
+	if !ok || receiver.Backtrace() == nil {
+		return NIL, nil
+	}
+	backtrace := NewArray()
+	for _, line := range receiver.Backtrace() {
+		backtrace.Elements = append(backtrace.Elements, NewString(line))
+	}
+	return backtrace, nil
+}
+
 func hashException(exception RubyObject) HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(fmt.Sprintf("%T", exception)))
@@ -138,12 +394,13 @@ func NewStandardError(message string) *StandardError {
 
 type StandardError struct {
 	message string
+	exceptionState
 }
 
 func (e *StandardError) Inspect() string            { return formatException(e, e.message) }
 func (e *StandardError) Error() string              { return e.message }
 func (e *StandardError) setErrorMessage(msg string) { e.message = msg }
-func (e *StandardError) Class() RubyClass           { return exceptionClass }
+func (e *StandardError) Class() RubyClass           { return e.classOr(standardErrorClass) }
 func (e *StandardError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -160,12 +417,13 @@ func NewRuntimeError(format string, args ...interface{}) *RuntimeError {
 
 type RuntimeError struct {
 	message string
+	exceptionState
 }
 
 func (e *RuntimeError) Inspect() string            { return formatException(e, e.message) }
 func (e *RuntimeError) Error() string              { return e.message }
 func (e *RuntimeError) setErrorMessage(msg string) { e.message = msg }
-func (e *RuntimeError) Class() RubyClass           { return exceptionClass }
+func (e *RuntimeError) Class() RubyClass           { return e.classOr(runtimeErrorClass) }
 func (e *RuntimeError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -174,6 +432,28 @@ var (
 	_ exception  = &RuntimeError{}
 )
 
+// NewArithmeticError returns the error raised when a calculation fails
+func NewArithmeticError(format string, args ...interface{}) *ArithmeticError {
+	return &ArithmeticError{message: fmt.Sprintf(format, args...)}
+}
+
+type ArithmeticError struct {
+	message string
+	exceptionState
+}
+
+func (e *ArithmeticError) Inspect() string            { return formatException(e, e.message) }
+func (e *ArithmeticError) Error() string              { return e.message }
+func (e *ArithmeticError) setErrorMessage(msg string) { e.message = msg }
+func (e *ArithmeticError) Class() RubyClass           { return e.classOr(arithmeticErrorClass) }
+func (e *ArithmeticError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &ArithmeticError{}
+	_ error      = &ArithmeticError{}
+	_ exception  = &ArithmeticError{}
+)
+
 func NewZeroDivisionError() *ZeroDivisionError {
 	return &ZeroDivisionError{
 		message: "divided by 0",
@@ -182,12 +462,13 @@ func NewZeroDivisionError() *ZeroDivisionError {
 
 type ZeroDivisionError struct {
 	message string
+	exceptionState
 }
 
 func (e *ZeroDivisionError) Inspect() string            { return formatException(e, e.message) }
 func (e *ZeroDivisionError) Error() string              { return e.message }
 func (e *ZeroDivisionError) setErrorMessage(msg string) { e.message = msg }
-func (e *ZeroDivisionError) Class() RubyClass           { return exceptionClass }
+func (e *ZeroDivisionError) Class() RubyClass           { return e.classOr(zeroDivisionErrorClass) }
 func (e *ZeroDivisionError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -206,6 +487,29 @@ func NewWrongNumberOfArgumentsError(expected, actual int) *ArgumentError {
 	}
 }
 
+// NewMissingKeywordError returns an ArgumentError for the required keywords
+// a method was called without
+func NewMissingKeywordError(keys ...RubyObject) *ArgumentError {
+	return &ArgumentError{message: keywordErrorMessage("missing keyword", keys)}
+}
+
+// NewUnknownKeywordError returns an ArgumentError for the keywords passed to a
+// method which it does not accept
+func NewUnknownKeywordError(keys ...RubyObject) *ArgumentError {
+	return &ArgumentError{message: keywordErrorMessage("unknown keyword", keys)}
+}
+
+func keywordErrorMessage(problem string, keys []RubyObject) string {
+	if len(keys) > 1 {
+		problem += "s"
+	}
+	inspected := make([]string, len(keys))
+	for i, key := range keys {
+		inspected[i] = key.Inspect()
+	}
+	return problem + ": " + strings.Join(inspected, ", ")
+}
+
 func NewArgumentError(format string, args ...interface{}) *ArgumentError {
 	return &ArgumentError{
 		message: fmt.Sprintf(format, args...),
@@ -214,12 +518,13 @@ func NewArgumentError(format string, args ...interface{}) *ArgumentError {
 
 type ArgumentError struct {
 	message string
+	exceptionState
 }
 
 func (e *ArgumentError) Inspect() string            { return formatException(e, e.message) }
 func (e *ArgumentError) Error() string              { return e.message }
 func (e *ArgumentError) setErrorMessage(msg string) { e.message = msg }
-func (e *ArgumentError) Class() RubyClass           { return exceptionClass }
+func (e *ArgumentError) Class() RubyClass           { return e.classOr(argumentErrorClass) }
 func (e *ArgumentError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -237,14 +542,26 @@ func NewUninitializedConstantNameError(name string) *NameError {
 	}
 }
 
+func NewUndefinedMethodNameError(receiver RubyObject, method string) *NameError {
+	return &NameError{
+		message: fmt.Sprintf(
+			"undefined method `%s' for %s:%s",
+			method,
+			receiver.Inspect(),
+			receiver.Class().Inspect(),
+		),
+	}
+}
+
 type NameError struct {
 	message string
+	exceptionState
 }
 
 func (e *NameError) Inspect() string            { return formatException(e, e.message) }
 func (e *NameError) Error() string              { return e.message }
 func (e *NameError) setErrorMessage(msg string) { e.message = msg }
-func (e *NameError) Class() RubyClass           { return exceptionClass }
+func (e *NameError) Class() RubyClass           { return e.classOr(nameErrorClass) }
 func (e *NameError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -265,14 +582,28 @@ func NewNoMethodError(context RubyObject, method string) *NoMethodError {
 	}
 }
 
+// NewNoSuperclassMethodError returns a NoMethodError for a super call within
+// method which no superclass implements
+func NewNoSuperclassMethodError(context RubyObject, method string) *NoMethodError {
+	return &NoMethodError{
+		message: fmt.Sprintf(
+			"super: no superclass method `%s' for %s:%s",
+			method,
+			context.Inspect(),
+			context.Class().Inspect(),
+		),
+	}
+}
+
 type NoMethodError struct {
 	message string
+	exceptionState
 }
 
 func (e *NoMethodError) Inspect() string            { return formatException(e, e.message) }
 func (e *NoMethodError) Error() string              { return e.message }
 func (e *NoMethodError) setErrorMessage(msg string) { e.message = msg }
-func (e *NoMethodError) Class() RubyClass           { return exceptionClass }
+func (e *NoMethodError) Class() RubyClass           { return e.classOr(noMethodErrorClass) }
 func (e *NoMethodError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -333,12 +664,13 @@ func NewTypeError(message string) *TypeError {
 
 type TypeError struct {
 	Message string
+	exceptionState
 }
 
 func (e *TypeError) Inspect() string            { return formatException(e, e.Message) }
 func (e *TypeError) Error() string              { return e.Message }
 func (e *TypeError) setErrorMessage(msg string) { e.Message = msg }
-func (e *TypeError) Class() RubyClass           { return exceptionClass }
+func (e *TypeError) Class() RubyClass           { return e.classOr(typeErrorClass) }
 func (e *TypeError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -347,18 +679,224 @@ var (
 	_ exception  = &TypeError{}
 )
 
+// NewNoMatchingPatternError returns the error raised when value matches none
+// of the patterns of a case/in expression or a rightward assignment
+func NewNoMatchingPatternError(value RubyObject) *NoMatchingPatternError {
+	return &NoMatchingPatternError{message: value.Inspect()}
+}
+
+type NoMatchingPatternError struct {
+	message string
+	exceptionState
+}
+
+func (e *NoMatchingPatternError) Inspect() string            { return formatException(e, e.message) }
+func (e *NoMatchingPatternError) Error() string              { return e.message }
+func (e *NoMatchingPatternError) setErrorMessage(msg string) { e.message = msg }
+func (e *NoMatchingPatternError) Class() RubyClass           { return e.classOr(noMatchingPatternErrorClass) }
+func (e *NoMatchingPatternError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &NoMatchingPatternError{}
+	_ error      = &NoMatchingPatternError{}
+	_ exception  = &NoMatchingPatternError{}
+)
+
+// NewLocalJumpError returns the error raised when a break or return within a
+// block cannot reach its target anymore
+func NewLocalJumpError(message string) *LocalJumpError {
+	return &LocalJumpError{message: message}
+}
+
+type LocalJumpError struct {
+	message string
+	exceptionState
+}
+
+func (e *LocalJumpError) Inspect() string            { return formatException(e, e.message) }
+func (e *LocalJumpError) Error() string              { return e.message }
+func (e *LocalJumpError) setErrorMessage(msg string) { e.message = msg }
+func (e *LocalJumpError) Class() RubyClass           { return e.classOr(localJumpErrorClass) }
+func (e *LocalJumpError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &LocalJumpError{}
+	_ error      = &LocalJumpError{}
+	_ exception  = &LocalJumpError{}
+)
+
+// NewDomainError returns the error raised when the argument of the Math
+// function is outside of its domain, e.g. `Math.sqrt(-1)`
+func NewDomainError(function string) *DomainError {
+	return &DomainError{message: fmt.Sprintf("Numerical argument is out of domain - %q", function)}
+}
+
+type DomainError struct {
+	message string
+	exceptionState
+}
+
+func (e *DomainError) Inspect() string            { return formatException(e, e.message) }
+func (e *DomainError) Error() string              { return e.message }
+func (e *DomainError) setErrorMessage(msg string) { e.message = msg }
+func (e *DomainError) Class() RubyClass           { return e.classOr(domainErrorClass) }
+func (e *DomainError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &DomainError{}
+	_ error      = &DomainError{}
+	_ exception  = &DomainError{}
+)
+
+// NewIndexError returns the error raised when an index is out of range
+func NewIndexError(format string, args ...interface{}) *IndexError {
+	return &IndexError{message: fmt.Sprintf(format, args...)}
+}
+
+type IndexError struct {
+	message string
+	exceptionState
+}
+
+func (e *IndexError) Inspect() string            { return formatException(e, e.message) }
+func (e *IndexError) Error() string              { return e.message }
+func (e *IndexError) setErrorMessage(msg string) { e.message = msg }
+func (e *IndexError) Class() RubyClass           { return e.classOr(indexErrorClass) }
+func (e *IndexError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &IndexError{}
+	_ error      = &IndexError{}
+	_ exception  = &IndexError{}
+)
+
+// NewKeyError returns the error raised when a key is not found
+func NewKeyError(format string, args ...interface{}) *KeyError {
+	return &KeyError{message: fmt.Sprintf(format, args...)}
+}
+
+type KeyError struct {
+	message string
+	exceptionState
+}
+
+func (e *KeyError) Inspect() string            { return formatException(e, e.message) }
+func (e *KeyError) Error() string              { return e.message }
+func (e *KeyError) setErrorMessage(msg string) { e.message = msg }
+func (e *KeyError) Class() RubyClass           { return e.classOr(keyErrorClass) }
+func (e *KeyError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &KeyError{}
+	_ error      = &KeyError{}
+	_ exception  = &KeyError{}
+)
+
+// NewStopIteration returns the error raised when an iteration has reached its
+// end
+func NewStopIteration(format string, args ...interface{}) *StopIteration {
+	return &StopIteration{message: fmt.Sprintf(format, args...)}
+}
+
+type StopIteration struct {
+	message string
+	exceptionState
+}
+
+func (e *StopIteration) Inspect() string            { return formatException(e, e.message) }
+func (e *StopIteration) Error() string              { return e.message }
+func (e *StopIteration) setErrorMessage(msg string) { e.message = msg }
+func (e *StopIteration) Class() RubyClass           { return e.classOr(stopIterationClass) }
+func (e *StopIteration) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &StopIteration{}
+	_ error      = &StopIteration{}
+	_ exception  = &StopIteration{}
+)
+
+// NewRangeError returns the error raised when a number is out of the range a
+// method can handle
+func NewRangeError(format string, args ...interface{}) *RangeError {
+	return &RangeError{message: fmt.Sprintf(format, args...)}
+}
+
+type RangeError struct {
+	message string
+	exceptionState
+}
+
+func (e *RangeError) Inspect() string            { return formatException(e, e.message) }
+func (e *RangeError) Error() string              { return e.message }
+func (e *RangeError) setErrorMessage(msg string) { e.message = msg }
+func (e *RangeError) Class() RubyClass           { return e.classOr(rangeErrorClass) }
+func (e *RangeError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &RangeError{}
+	_ error      = &RangeError{}
+	_ exception  = &RangeError{}
+)
+
+// NewFloatDomainError returns the error raised when an infinite or NaN float
+// is converted to a number type which cannot represent it
+func NewFloatDomainError(format string, args ...interface{}) *FloatDomainError {
+	return &FloatDomainError{message: fmt.Sprintf(format, args...)}
+}
+
+type FloatDomainError struct {
+	message string
+	exceptionState
+}
+
+func (e *FloatDomainError) Inspect() string            { return formatException(e, e.message) }
+func (e *FloatDomainError) Error() string              { return e.message }
+func (e *FloatDomainError) setErrorMessage(msg string) { e.message = msg }
+func (e *FloatDomainError) Class() RubyClass           { return e.classOr(floatDomainErrorClass) }
+func (e *FloatDomainError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &FloatDomainError{}
+	_ error      = &FloatDomainError{}
+	_ exception  = &FloatDomainError{}
+)
+
+// NewRegexpError returns the error raised when a regular expression is
+// invalid or uses a construct the regexp engine does not support
+func NewRegexpError(format string, args ...interface{}) *RegexpError {
+	return &RegexpError{message: fmt.Sprintf(format, args...)}
+}
+
+type RegexpError struct {
+	message string
+	exceptionState
+}
+
+func (e *RegexpError) Inspect() string            { return formatException(e, e.message) }
+func (e *RegexpError) Error() string              { return e.message }
+func (e *RegexpError) setErrorMessage(msg string) { e.message = msg }
+func (e *RegexpError) Class() RubyClass           { return e.classOr(regexpErrorClass) }
+func (e *RegexpError) HashKey() HashKey           { return hashException(e) }
+
+var (
+	_ RubyObject = &RegexpError{}
+	_ error      = &RegexpError{}
+	_ exception  = &RegexpError{}
+)
+
 func NewScriptError(format string, args ...interface{}) *ScriptError {
 	return &ScriptError{message: fmt.Sprintf(format, args...)}
 }
 
 type ScriptError struct {
 	message string
+	exceptionState
 }
 
 func (e *ScriptError) Inspect() string            { return formatException(e, e.message) }
 func (e *ScriptError) Error() string              { return e.message }
 func (e *ScriptError) setErrorMessage(msg string) { e.message = msg }
-func (e *ScriptError) Class() RubyClass           { return exceptionClass }
+func (e *ScriptError) Class() RubyClass           { return e.classOr(scriptErrorClass) }
 func (e *ScriptError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -380,12 +918,13 @@ func NewSyntaxError(syntaxError error) *SyntaxError {
 type SyntaxError struct {
 	err     error
 	message string
+	exceptionState
 }
 
 func (e *SyntaxError) Inspect() string            { return formatException(e, e.message) }
 func (e *SyntaxError) Error() string              { return e.message }
 func (e *SyntaxError) setErrorMessage(msg string) { e.message = msg }
-func (e *SyntaxError) Class() RubyClass           { return exceptionClass }
+func (e *SyntaxError) Class() RubyClass           { return e.classOr(syntaxErrorClass) }
 func (e *SyntaxError) UnderlyingError() error     { return e.err }
 func (e *SyntaxError) HashKey() HashKey           { return hashException(e) }
 
@@ -401,12 +940,13 @@ func NewNotImplementedError(format string, args ...interface{}) *NotImplementedE
 
 type NotImplementedError struct {
 	message string
+	exceptionState
 }
 
 func (e *NotImplementedError) Inspect() string            { return formatException(e, e.message) }
 func (e *NotImplementedError) Error() string              { return e.message }
 func (e *NotImplementedError) setErrorMessage(msg string) { e.message = msg }
-func (e *NotImplementedError) Class() RubyClass           { return exceptionClass }
+func (e *NotImplementedError) Class() RubyClass           { return e.classOr(notImplementedErrorClass) }
 func (e *NotImplementedError) HashKey() HashKey           { return hashException(e) }
 
 var (
@@ -415,6 +955,12 @@ var (
 	_ exception  = &NotImplementedError{}
 )
 
+// IsStandardError returns true if obj is an instance of StandardError or of
+// any class inheriting from it, i.e. if obj is rescued by a bare rescue clause
+func IsStandardError(obj RubyObject) bool {
+	return IsKindOf(obj, standardErrorClass)
+}
+
 // IsError returns true if the given RubyObject is an object.Error or an
 // object.Exception (or any subclass of object.Exception)
 func IsError(obj RubyObject) bool {
@@ -78,3 +78,69 @@ func TestExceptionToS(t *testing.T) {
 
 	utils.AssertEqualCmpAny(t, result, NewString("x"), CompareRubyObjectsForTests)
 }
+
+func TestExceptionClassNew(t *testing.T) {
+	context := &callContext{
+		receiver: argumentErrorClass,
+		env:      NewMainEnvironment(),
+	}
+
+	result, err := classNewInstance(context, nil, NewString("err"))
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewArgumentError("err"), CompareRubyObjectsForTests)
+
+	t.Run("user defined subclass", func(t *testing.T) {
+		subclass := newSubclass(argumentErrorClass, "MyError", nil, nil, argumentErrorClass.builder)
+		context := &callContext{
+			receiver: subclass,
+			env:      NewMainEnvironment(),
+		}
+
+		result, err := classNewInstance(context, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, result.Class(), RubyClass(subclass))
+		utils.AssertEqual(t, result.Inspect(), "MyError: MyError")
+		utils.Assert(t, IsKindOf(result, standardErrorClass), "Expected MyError to be a StandardError")
+	})
+}
+
+func TestExceptionHierarchy(t *testing.T) {
+	tests := []struct {
+		exception RubyObject
+		class     RubyClass
+		expected  bool
+	}{
+		{NewRuntimeError("x"), standardErrorClass, true},
+		{NewRuntimeError("x"), exceptionClass, true},
+		{NewNoMethodError(NIL, "x"), nameErrorClass, true},
+		{NewSyntaxError(NewException("x")), scriptErrorClass, true},
+		{NewSyntaxError(NewException("x")), standardErrorClass, false},
+		{NewTypeError("x"), argumentErrorClass, false},
+		{NewInteger(1), exceptionClass, false},
+	}
+
+	for _, testCase := range tests {
+		utils.AssertEqual(t, IsKindOf(testCase.exception, testCase.class), testCase.expected)
+	}
+}
+
+func TestExceptionCause(t *testing.T) {
+	exc := NewRuntimeError("x")
+	context := &callContext{
+		receiver: exc,
+		env:      NewMainEnvironment(),
+	}
+
+	result, err := exceptionCause(context, nil)
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NIL, CompareRubyObjectsForTests)
+
+	exc.SetCause(NewArgumentError("cause"))
+	result, err = exceptionCause(context, nil)
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewArgumentError("cause"), CompareRubyObjectsForTests)
+}
@@ -1,21 +1,27 @@
 package object
 
 import (
-	"fmt"
 	"math"
-	"runtime"
+	"math/big"
+	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/MarcinKonowalczyk/goruby/trace"
-	"github.com/pkg/errors"
 )
 
-var floatClass RubyClassObject = newClass(
-	"Float", floatMethods, nil, notInstantiatable,
+var floatClass RubyClassObject = newSubclass(
+	numericClass, "Float", floatMethods, nil, notInstantiatable,
 )
 
 func init() {
 	CLASSES.Set("Float", floatClass)
+	floatClass.(*class).Set("INFINITY", NewFloat(math.Inf(1)))
+	floatClass.(*class).Set("NAN", NewFloat(math.NaN()))
+	floatClass.(*class).Set("EPSILON", NewFloat(0x1p-52))
+	floatClass.(*class).Set("MAX", NewFloat(math.MaxFloat64))
+	floatClass.(*class).Set("MIN", NewFloat(0x1p-1022))
+	floatClass.(*class).Set("DIG", NewInteger(15))
 }
 
 // NewFloat returns a new Float with the given value
@@ -28,7 +34,7 @@ type Float struct {
 	Value float64
 }
 
-func (i *Float) Inspect() string  { return fmt.Sprintf("%.16f", i.Value) }
+func (i *Float) Inspect() string  { return formatFloat(i.Value) }
 func (i *Float) Class() RubyClass { return floatClass }
 
 func reinterpretCastFloatToUint64(value float64) uint64 {
@@ -50,86 +56,134 @@ var (
 )
 
 var floatMethods = map[string]RubyMethod{
-	"div":  withArity(1, newMethod(floatDiv)),
-	"/":    withArity(1, newMethod(floatDiv)),
-	"*":    withArity(1, newMethod(floatMul)),
-	"+":    withArity(1, newMethod(floatAdd)),
-	"-":    withArity(1, newMethod(floatSub)),
-	"<":    withArity(1, newMethod(floatLt)),
-	">":    withArity(1, newMethod(floatGt)),
-	">=":   withArity(1, newMethod(floatGte)),
-	"<=":   withArity(1, newMethod(floatLte)),
-	"<=>":  withArity(1, newMethod(floatSpaceship)),
-	"to_i": withArity(0, newMethod(floatToI)),
-	"**":   withArity(1, newMethod(floatPow)),
+	"div":    withArity(1, newMethod(floatDiv)),
+	"/":      withArity(1, newMethod(floatDiv)),
+	"quo":    withArity(1, newMethod(floatDiv)),
+	"*":      withArity(1, newMethod(floatMul)),
+	"+":      withArity(1, newMethod(floatAdd)),
+	"-":      withArity(1, newMethod(floatSub)),
+	"%":      withArity(1, newMethod(floatModulo)),
+	"modulo": withArity(1, newMethod(floatModulo)),
+	"divmod": withArity(1, newMethod(floatDivmod)),
+	"<":      withArity(1, newMethod(floatLt)),
+	">":      withArity(1, newMethod(floatGt)),
+	">=":     withArity(1, newMethod(floatGte)),
+	"<=":     withArity(1, newMethod(floatLte)),
+	"<=>":    withArity(1, newMethod(floatSpaceship)),
+	"to_i":   withArity(0, newMethod(floatToI)),
+	"to_f":   withArity(0, newMethod(floatToF)),
+	"to_r":   withArity(0, newMethod(floatToR)),
+	"to_s":   withArity(0, newMethod(floatToS)),
+	"**":     withArity(1, newMethod(floatPow)),
+
+	"rationalize": newMethod(floatRationalize),
+	"round":       newMethod(floatRoundHalfUp),
+	"floor":       newMethod(floatFloor),
+	"ceil":        newMethod(floatCeil),
+	"truncate":    newMethod(floatTruncate),
+	"abs":         withArity(0, newMethod(floatAbs)),
+	"magnitude":   withArity(0, newMethod(floatAbs)),
+	"nan?":        withArity(0, newMethod(floatIsNaN)),
+	"infinite?":   withArity(0, newMethod(floatIsInfinite)),
+	"finite?":     withArity(0, newMethod(floatIsFinite)),
 }
 
 func floatDiv(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
+	i := context.Receiver().(*Float)
+	divisor, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceBinop(context, tracer, "/", args[0])
+	}
+	// dividing by zero results in Infinity or NaN, as with IEEE 754
+	return NewFloat(i.Value / divisor), nil
+}
+
+func floatMul(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	var divisor float64
-	switch arg := args[0].(type) {
-	case *Integer:
-		divisor = float64(arg.Value)
-	case *Float:
-		divisor = arg.Value
-	default:
-		return nil, NewCoercionTypeError(args[0], i)
-	}
-	if divisor == 0 {
-		return nil, NewZeroDivisionError()
+	factor, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceBinop(context, tracer, "*", args[0])
 	}
-	return NewFloat(i.Value / divisor), nil
+	return NewFloat(i.Value * factor), nil
 }
 
-func floatMul(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+func floatAdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
+	i := context.Receiver().(*Float)
+	add, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceBinop(context, tracer, "+", args[0])
+	}
+	return NewFloat(i.Value + add), nil
+}
+
+func floatSub(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	var factor float64
-	switch arg := args[0].(type) {
-	case *Integer:
-		factor = float64(arg.Value)
-	case *Float:
-		factor = arg.Value
-	default:
-		return nil, NewCoercionTypeError(args[0], i)
+	sub, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceBinop(context, tracer, "-", args[0])
 	}
+	return NewFloat(i.Value - sub), nil
+}
 
-	return NewFloat(i.Value * factor), nil
+// divmodFloats returns the quotient of x and y rounded towards negative
+// infinity, and the remainder, which has the sign of y
+func divmodFloats(x, y float64) (float64, float64) {
+	mod := math.Mod(x, y)
+	div := math.Round((x - mod) / y)
+	if y*mod < 0 {
+		mod += y
+		div--
+	}
+	return div, mod
 }
 
-func floatAdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+// floatModulo returns the remainder of the floor division by the argument,
+// which has the sign of the argument, e.g. `-7.5 % 2` is 0.5
+func floatModulo(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	add, ok := args[0].(*Float)
+	divisor, ok := safeObjectToFloat(args[0])
 	if !ok {
-		return nil, NewCoercionTypeError(args[0], i)
+		return coerceBinop(context, tracer, "%", args[0])
 	}
-	return NewFloat(i.Value + add.Value), nil
+	_, mod := divmodFloats(i.Value, divisor)
+	return NewFloat(mod), nil
 }
 
-func floatSub(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+// floatDivmod returns the floored quotient, as an Integer, and the remainder
+// of the division by the argument, e.g. `7.5.divmod(2)` is `[3, 1.5]`
+func floatDivmod(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	sub, ok := args[0].(*Float)
+	divisor, ok := safeObjectToFloat(args[0])
 	if !ok {
-		return nil, NewCoercionTypeError(args[0], i)
+		return coerceBinop(context, tracer, "divmod", args[0])
 	}
-	return NewFloat(i.Value - sub.Value), nil
+	if divisor == 0 {
+		return nil, NewZeroDivisionError()
+	}
+	div, mod := divmodFloats(i.Value, divisor)
+	quotient, err := floatToInteger(div)
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(quotient, NewFloat(mod)), nil
 }
 
 // Objects which can *safely* be converted to a float
@@ -139,46 +193,23 @@ func safeObjectToFloat(arg RubyObject) (float64, bool) {
 	case *Float:
 		right = arg.Value
 	case *Integer:
-		right = float64(arg.Value)
+		right = arg.Float64()
+	case *Rational:
+		right = arg.Float64()
 	default:
 		return 0, false
 	}
 	return right, true
 }
 
-func callersName() string {
-	parent, _, _, _ := runtime.Caller(1)
-	fn := runtime.FuncForPC(parent)
-	return fn.Name()
-}
-
-func floatCmpHelper(args []RubyObject) (float64, error) {
-	if len(args) != 1 {
-		return 0, errors.WithMessage(
-			NewWrongNumberOfArgumentsError(1, len(args)),
-			callersName(),
-		)
-	}
-	right, ok := safeObjectToFloat(args[0])
-	if !ok {
-		return 0, errors.WithMessage(
-			NewArgumentError(
-				"comparison of Float with %s failed",
-				args[0].Class().(RubyObject).Inspect(),
-			),
-			callersName(),
-		)
-	}
-	return right, nil
-}
 func floatLt(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	right, err := floatCmpHelper(args)
-	if err != nil {
-		return nil, err
+	right, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceRelop(context, tracer, "<", args[0])
 	}
 	if i.Value < right {
 		return TRUE, nil
@@ -191,9 +222,9 @@ func floatGt(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ruby
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	right, err := floatCmpHelper(args)
-	if err != nil {
-		return nil, err
+	right, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceRelop(context, tracer, ">", args[0])
 	}
 	if i.Value > right {
 		return TRUE, nil
@@ -206,19 +237,20 @@ func floatSpaceship(context CallContext, tracer trace.Tracer, args ...RubyObject
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	right, err := floatCmpHelper(args)
-	if err != nil {
-		return NIL, err
+	right, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceCmp(context, tracer, args[0])
 	}
 	switch {
 	case i.Value > right:
-		return NewFloat(1), nil
+		return NewInteger(1), nil
 	case i.Value < right:
-		return NewFloat(-1), nil
+		return NewInteger(-1), nil
 	case i.Value == right:
-		return NewFloat(0), nil
+		return NewInteger(0), nil
 	default:
-		panic("not reachable")
+		// NaN is not comparable
+		return NIL, nil
 	}
 }
 
@@ -227,9 +259,9 @@ func floatGte(context CallContext, tracer trace.Tracer, args ...RubyObject) (Rub
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	right, err := floatCmpHelper(args)
-	if err != nil {
-		return nil, err
+	right, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceRelop(context, tracer, ">=", args[0])
 	}
 	if i.Value >= right {
 		return TRUE, nil
@@ -242,9 +274,9 @@ func floatLte(context CallContext, tracer trace.Tracer, args ...RubyObject) (Rub
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	right, err := floatCmpHelper(args)
-	if err != nil {
-		return nil, err
+	right, ok := safeObjectToFloat(args[0])
+	if !ok {
+		return coerceRelop(context, tracer, "<=", args[0])
 	}
 	if i.Value <= right {
 		return TRUE, nil
@@ -257,27 +289,346 @@ func floatToI(context CallContext, tracer trace.Tracer, args ...RubyObject) (Rub
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Float)
-	return NewInteger(int64(i.Value)), nil
+	return floatToInteger(math.Trunc(i.Value))
 }
 
-func floatPow(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+// floatToInteger converts the integral value to an Integer
+func floatToInteger(value float64) (*Integer, error) {
+	switch {
+	case math.IsNaN(value):
+		return nil, NewFloatDomainError("NaN")
+	case math.IsInf(value, 1):
+		return nil, NewFloatDomainError("Infinity")
+	case math.IsInf(value, -1):
+		return nil, NewFloatDomainError("-Infinity")
+	}
+	if value >= -(1<<63) && value < 1<<63 {
+		return NewInteger(int64(value)), nil
+	}
+	integer, _ := big.NewFloat(value).Int(nil)
+	return NewBigInteger(integer), nil
+}
+
+func floatToF(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
-	i := context.Receiver().(*Float)
-	if len(args) != 1 {
+	return context.Receiver(), nil
+}
+
+// floatToR returns the exact value of the receiver, e.g. `0.5.to_r` is
+// `(1/2)`
+func floatToR(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	value, err := toRational(context.Receiver())
+	if err != nil {
+		return nil, err
+	}
+	return NewRational(value), nil
+}
+
+// floatRationalize returns the simplest rational within the optional
+// argument of the receiver, which defaults to the precision of the receiver,
+// e.g. `0.1.rationalize` is `(1/10)` whereas `0.1.to_r` is not
+func floatRationalize(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	f := context.Receiver().(*Float)
+	if len(args) > 1 {
 		return nil, NewWrongNumberOfArgumentsError(1, len(args))
 	}
+	value, err := toRational(NewFloat(math.Abs(f.Value)))
+	if err != nil {
+		return nil, err
+	}
+	var e *big.Rat
+	if len(args) == 1 {
+		e, err = toRational(args[0])
+		if err != nil {
+			return nil, err
+		}
+		e = new(big.Rat).Abs(e)
+	} else {
+		// half the distance to the neighbouring floats
+		mantissa, exp := math.Frexp(math.Abs(f.Value))
+		if mantissa != 0 && exp < 53 {
+			e = new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), uint(54-exp)))
+		} else {
+			e = new(big.Rat)
+		}
+	}
+	value = rationalize(value, e)
+	if f.Value < 0 {
+		value = new(big.Rat).Neg(value)
+	}
+	return NewRational(value), nil
+}
+
+func floatToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	f := context.Receiver().(*Float)
+	return NewString(formatFloat(f.Value)), nil
+}
+
+// formatFloat returns the shortest representation of value which reads back
+// the same, the way Float#to_s does. It always has a fractional part and
+// switches to scientific notation for very large and very small values.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	}
+	if abs := math.Abs(value); abs != 0 && (abs >= 1e16 || abs < 1e-4) {
+		str := strconv.FormatFloat(value, 'e', -1, 64)
+		mantissa, exponent, _ := strings.Cut(str, "e")
+		if !strings.Contains(mantissa, ".") {
+			mantissa += ".0"
+		}
+		return mantissa + "e" + exponent
+	}
+	str := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(str, ".") {
+		str += ".0"
+	}
+	return str
+}
+
+// powFloat returns x raised to y, which is a Complex for a negative x and a
+// fractional y, e.g. `(-8.0) ** (1.0 / 3)`
+func powFloat(x, y float64) RubyObject {
+	if x >= 0 || y == math.Round(y) {
+		return NewFloat(math.Pow(x, y))
+	}
+	abs := math.Pow(-x, y)
+	if _, frac := math.Modf(y); math.Abs(frac) == 0.5 {
+		// purely imaginary, without the rounding error of the cosine
+		return NewComplex(NewFloat(0), NewFloat(math.Copysign(abs, math.Sin(math.Pi*y))))
+	}
+	return NewComplex(NewFloat(abs*math.Cos(math.Pi*y)), NewFloat(abs*math.Sin(math.Pi*y)))
+}
+
+func floatPow(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Float)
 	right, ok := safeObjectToFloat(args[0])
 	if !ok {
-		return nil, NewArgumentError(
-			"comparison of Float with %s failed",
-			args[0].Class().(RubyObject).Inspect(),
-		)
+		return coerceBinop(context, tracer, "**", args[0])
+	}
+	return powFloat(i.Value, right), nil
+}
+
+// floatRoundOverflow reports whether rounding a float of the binary exponent
+// binexp to ndigits decimal digits leaves it as it is, as it has no more
+// significant digits to round off
+func floatRoundOverflow(ndigits int64, binexp int) bool {
+	const floatDigits = 17 // the most decimal digits a float can need
+	if binexp > 0 {
+		return ndigits >= floatDigits-int64(binexp/4)
+	}
+	return ndigits >= floatDigits-int64(binexp/3-1)
+}
+
+// floatRoundUnderflow reports whether rounding a float of the binary exponent
+// binexp to ndigits decimal digits rounds it to zero
+func floatRoundUnderflow(ndigits int64, binexp int) bool {
+	if binexp > 0 {
+		return ndigits < -int64(binexp/3+1)
+	}
+	return ndigits < -int64(binexp/4)
+}
+
+// roundHalfUpScaled rounds x scaled by s to the nearest integer, halves away from
+// zero, and corrects for the error of the scaling
+func roundHalfUpScaled(x, s float64) float64 {
+	f := math.Round(x * s)
+	if s == 1 {
+		return f
+	}
+	if x > 0 && (f+0.5)/s <= x {
+		f++
+	} else if x < 0 && (f-0.5)/s >= x {
+		f--
+	}
+	return f
+}
+
+// roundFloatExactly rounds value to ndigits decimal digits, halves away from
+// zero, using its exact rational value, for when 10**ndigits can't be
+// represented exactly as a float
+func roundFloatExactly(value float64, ndigits int64) float64 {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(ndigits), nil)
+	scaled := new(big.Rat).SetFloat64(math.Abs(value))
+	scaled.Mul(scaled, new(big.Rat).SetInt(scale))
+	scaled.Add(scaled, big.NewRat(1, 2))
+	rounded := floorRational(scaled).BigInt()
+	result, _ := new(big.Rat).SetFrac(rounded, scale).Float64()
+	return math.Copysign(result, value)
+}
+
+// roundFloat rounds value to ndigits decimal digits as mode tells. The result
+// is a Float for positive ndigits, and an Integer otherwise.
+func roundFloat(value float64, ndigits int64, mode roundingMode) (RubyObject, error) {
+	if mode == roundTruncate {
+		mode = roundFloor
+		if value < 0 {
+			mode = roundCeil
+		}
+	}
+	if value == 0 {
+		if ndigits > 0 {
+			return NewFloat(value), nil
+		}
+		return NewInteger(0), nil
+	}
+	if ndigits <= 0 {
+		var integral float64
+		switch mode {
+		case roundFloor:
+			integral = math.Floor(value)
+		case roundCeil:
+			integral = math.Ceil(value)
+		default:
+			integral = math.Round(value)
+			if ndigits < 0 {
+				// the fractional part never matters for a rounding to tens
+				integral = math.Trunc(value)
+			}
+		}
+		i, err := floatToInteger(integral)
+		if err != nil {
+			return nil, err
+		}
+		return roundInteger(i, -ndigits, mode), nil
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return NewFloat(value), nil
+	}
+	_, binexp := math.Frexp(value)
+	if floatRoundOverflow(ndigits, binexp) {
+		return NewFloat(value), nil
+	}
+	if floatRoundUnderflow(ndigits, binexp) {
+		// floor of a small negative value is -10**-ndigits, ceil of a small
+		// positive value 10**-ndigits
+		if mode == roundHalfUp || (mode == roundFloor) == (value > 0) {
+			return NewFloat(0), nil
+		}
+	}
+	s := math.Pow(10, float64(ndigits))
+	switch mode {
+	case roundFloor:
+		floor := math.Floor(value * s)
+		if result := (floor + 1) / s; result <= value {
+			return NewFloat(result), nil
+		}
+		return NewFloat(floor / s), nil
+	case roundCeil:
+		ceil := math.Ceil(value * s)
+		if result := (ceil - 1) / s; result >= value {
+			return NewFloat(result), nil
+		}
+		return NewFloat(ceil / s), nil
+	default:
+		if ndigits > 14 {
+			return NewFloat(roundFloatExactly(value, ndigits)), nil
+		}
+		return NewFloat(roundHalfUpScaled(value, s) / s), nil
+	}
+}
+
+// floatRound rounds the receiver to the optional number of decimal digits as
+// mode tells, e.g. `3.14159.round(2)` is 3.14
+func floatRound(context CallContext, tracer trace.Tracer, mode roundingMode, args []RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	digits, err := roundingDigits(args)
+	if err != nil {
+		return nil, err
+	}
+	return roundFloat(context.Receiver().(*Float).Value, digits, mode)
+}
+
+func floatRoundHalfUp(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return floatRound(context, tracer, roundHalfUp, args)
+}
+
+func floatFloor(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return floatRound(context, tracer, roundFloor, args)
+}
+
+func floatCeil(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return floatRound(context, tracer, roundCeil, args)
+}
+
+func floatTruncate(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return floatRound(context, tracer, roundTruncate, args)
+}
+
+func floatAbs(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return NewFloat(math.Abs(context.Receiver().(*Float).Value)), nil
+}
+
+func floatIsNaN(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if math.IsNaN(context.Receiver().(*Float).Value) {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+// floatIsInfinite returns 1 for positive and -1 for negative infinity, and
+// nil for any other value
+func floatIsInfinite(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	value := context.Receiver().(*Float).Value
+	switch {
+	case math.IsInf(value, 1):
+		return NewInteger(1), nil
+	case math.IsInf(value, -1):
+		return NewInteger(-1), nil
+	default:
+		return NIL, nil
+	}
+}
+
+func floatIsFinite(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
-	if right < 0 {
-		return nil, NewArgumentError("negative exponent")
+	value := context.Receiver().(*Float).Value
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return FALSE, nil
 	}
-	result := math.Pow(i.Value, right)
-	return NewFloat(result), nil
+	return TRUE, nil
 }
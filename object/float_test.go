@@ -1,6 +1,7 @@
 package object
 
 import (
+	"math"
 	"testing"
 
 	"github.com/MarcinKonowalczyk/goruby/utils"
@@ -34,8 +35,8 @@ func TestFloatDiv(t *testing.T) {
 		},
 		{
 			[]RubyObject{NewFloat(0)},
+			NewFloat(math.Inf(1)),
 			nil,
-			NewZeroDivisionError(),
 		},
 	}
 
@@ -388,17 +389,17 @@ func TestFloatSpaceship(t *testing.T) {
 	}{
 		{
 			[]RubyObject{NewFloat(6)},
-			NewFloat(-1),
+			NewInteger(-1),
 			nil,
 		},
 		{
 			[]RubyObject{NewFloat(4)},
-			NewFloat(0),
+			NewInteger(0),
 			nil,
 		},
 		{
 			[]RubyObject{NewFloat(2)},
-			NewFloat(1),
+			NewInteger(1),
 			nil,
 		},
 		{
@@ -418,3 +419,87 @@ func TestFloatSpaceship(t *testing.T) {
 		utils.AssertEqualCmpAny(t, result, testCase.result, CompareRubyObjectsForTests)
 	}
 }
+
+func TestFloatToS(t *testing.T) {
+	tests := []struct {
+		value  float64
+		result string
+	}{
+		{1, "1.0"},
+		{1.5, "1.5"},
+		{-0.25, "-0.25"},
+		{0.30000000000000004, "0.30000000000000004"},
+		{1e16, "1.0e+16"},
+		{1.5e-5, "1.5e-05"},
+		{math.Inf(1), "Infinity"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, tt := range tests {
+		context := &callContext{receiver: NewFloat(tt.value)}
+
+		result, err := floatToS(context, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewString(tt.result), CompareRubyObjectsForTests)
+	}
+}
+
+func TestFloatRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   roundingMode
+		value  float64
+		digits int64
+		result RubyObject
+	}{
+		{"round", roundHalfUp, 2.5, 0, NewInteger(3)},
+		{"round", roundHalfUp, -2.5, 0, NewInteger(-3)},
+		{"round", roundHalfUp, 3.14159, 2, NewFloat(3.14)},
+		{"round", roundHalfUp, 1.005, 2, NewFloat(1.01)},
+		{"round", roundHalfUp, 0.5e-20, 2, NewFloat(0)},
+		{"round", roundHalfUp, 1.5, 20, NewFloat(1.5)},
+		{"round", roundHalfUp, 0.1234567890123456, 15, NewFloat(0.123456789012346)},
+		{"round", roundHalfUp, 1234.5678, -2, NewInteger(1200)},
+		{"round", roundHalfUp, 1e20, 0, bigInteger("100000000000000000000")},
+		{"floor", roundFloor, 1.23456, 2, NewFloat(1.23)},
+		{"floor", roundFloor, -1.5, 0, NewInteger(-2)},
+		{"floor", roundFloor, -1e-20, 2, NewFloat(-0.01)},
+		{"ceil", roundCeil, 1.23456, 2, NewFloat(1.24)},
+		{"ceil", roundCeil, 1234.5, -2, NewInteger(1300)},
+		{"truncate", roundTruncate, -1.99, 1, NewFloat(-1.9)},
+		{"truncate", roundTruncate, -1.99, 0, NewInteger(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := floatRound(&callContext{receiver: NewFloat(tt.value)}, nil, tt.mode, []RubyObject{NewInteger(tt.digits)})
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+	t.Run("infinity to an Integer", func(t *testing.T) {
+		_, err := floatRound(&callContext{receiver: NewFloat(math.Inf(1))}, nil, roundHalfUp, nil)
+
+		utils.AssertError(t, err, NewFloatDomainError("Infinity"))
+	})
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value  float64
+		result string
+	}{
+		{100, "100.0"},
+		{1e20, "1.0e+20"},
+		{1e15, "1000000000000000.0"},
+		{0.0001, "0.0001"},
+		{math.Copysign(0, -1), "-0.0"},
+		{math.Inf(-1), "-Infinity"},
+	}
+
+	for _, tt := range tests {
+		utils.AssertEqual(t, NewFloat(tt.value).Inspect(), tt.result)
+	}
+}
@@ -46,7 +46,16 @@ type hashPair struct {
 }
 
 type Hash struct {
-	Map map[HashKey]hashPair
+	Map      map[HashKey]hashPair
+	keywords bool // whether the hash holds the keyword arguments of a call
+}
+
+// KeywordArguments marks hash as the keyword arguments of a call, i.e. as
+// written without braces, as in `foo(a, key: 1)`, or built by a double splat.
+// A hash passed with braces is always a positional argument.
+func KeywordArguments(hash *Hash) *Hash {
+	hash.keywords = true
+	return hash
 }
 
 func (h *Hash) init() {
@@ -88,7 +97,19 @@ func (h *Hash) HashKey() HashKey {
 }
 
 var hashMethods = map[string]RubyMethod{
-	"has_key?": newMethod(hashHasKey),
+	"has_key?":        newMethod(hashHasKey),
+	"each":            newMethod(hashEach),
+	"each_pair":       newMethod(hashEach),
+	"each_with_index": newMethod(hashEachWithIndex),
+	"to_s":            withArity(0, newMethod(hashToS)),
+}
+
+func hashToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	hash, _ := context.Receiver().(*Hash)
+	return NewString(hash.Inspect()), nil
 }
 
 func hashHasKey(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -105,3 +126,49 @@ func hashHasKey(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 	}
 	return FALSE, nil
 }
+
+// hashEach yields each key-value pair as a two element array, which blocks
+// spread over `|key, value|`
+func hashEach(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	hash, _ := context.Receiver().(*Hash)
+	if len(args) == 0 {
+		return nil, NewArgumentError("each requires a block")
+	}
+	fn, ok := blockMethod(args[0])
+	if !ok {
+		return nil, NewArgumentError("each requires a block")
+	}
+	for _, pair := range hash.Map {
+		_, err := fn.Call(context, tracer, NewArray(pair.Key, pair.Value))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hash, nil
+}
+
+func hashEachWithIndex(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	hash, _ := context.Receiver().(*Hash)
+	if len(args) == 0 {
+		return nil, NewArgumentError("each_with_index requires a block")
+	}
+	fn, ok := blockMethod(args[0])
+	if !ok {
+		return nil, NewArgumentError("each_with_index requires a block")
+	}
+	i := 0
+	for _, pair := range hash.Map {
+		_, err := fn.Call(context, tracer, NewArray(pair.Key, pair.Value), NewInteger(int64(i)))
+		if err != nil {
+			return nil, err
+		}
+		i++
+	}
+	return hash, nil
+}
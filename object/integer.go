@@ -1,15 +1,16 @@
 package object
 
 import (
-	"fmt"
+	"hash/fnv"
 	"math"
+	"math/big"
+	"strconv"
 
 	"github.com/MarcinKonowalczyk/goruby/trace"
-	"github.com/pkg/errors"
 )
 
-var integerClass RubyClassObject = newClass(
-	"Integer", integerMethods, nil, notInstantiatable,
+var integerClass RubyClassObject = newSubclass(
+	numericClass, "Integer", integerMethods, nil, notInstantiatable,
 )
 
 func init() {
@@ -21,13 +22,20 @@ func NewInteger(value int64) *Integer {
 	return &Integer{Value: value}
 }
 
-// Integer represents an integer in Ruby
+// Integer represents an integer in Ruby. Values which do not fit in 64 bits,
+// i.e. Bignums, are held in big, while Value holds their lowest 64 bits.
 type Integer struct {
 	Value int64
+	big   *big.Int
 }
 
 // Inspect returns the value as string
-func (i *Integer) Inspect() string { return fmt.Sprintf("%d", i.Value) }
+func (i *Integer) Inspect() string {
+	if i.big != nil {
+		return i.big.String()
+	}
+	return strconv.FormatInt(i.Value, 10)
+}
 
 // Class returns integerClass
 func (i *Integer) Class() RubyClass { return integerClass }
@@ -37,24 +45,87 @@ var (
 )
 
 func (i *Integer) HashKey() HashKey {
+	if i.big != nil {
+		h := fnv.New64a()
+		h.Write([]byte(i.big.String()))
+		return HashKey(h.Sum64())
+	}
 	return HashKey(uint64(i.Value))
 }
 
 var integerMethods = map[string]RubyMethod{
-	"div":  withArity(1, newMethod(integerDiv)),
-	"/":    withArity(1, newMethod(integerDiv)),
-	"*":    withArity(1, newMethod(integerMul)),
-	"+":    withArity(1, newMethod(integerAdd)),
-	"-":    withArity(1, newMethod(integerSub)),
-	"%":    withArity(1, newMethod(integerModulo)),
-	"<":    withArity(1, newMethod(integerLt)),
-	">":    withArity(1, newMethod(integerGt)),
-	">=":   withArity(1, newMethod(integerGte)),
-	"<=":   withArity(1, newMethod(integerLte)),
-	"<=>":  withArity(1, newMethod(integerSpaceship)),
-	"to_i": withArity(0, newMethod(integerToI)),
-	"**":   withArity(1, newMethod(integerPow)),
-	"chr":  withArity(0, newMethod(integerChr)),
+	"div":    withArity(1, newMethod(integerDiv)),
+	"/":      withArity(1, newMethod(integerDiv)),
+	"*":      withArity(1, newMethod(integerMul)),
+	"+":      withArity(1, newMethod(integerAdd)),
+	"-":      withArity(1, newMethod(integerSub)),
+	"%":      withArity(1, newMethod(integerModulo)),
+	"modulo": withArity(1, newMethod(integerModulo)),
+	"divmod": withArity(1, newMethod(integerDivmod)),
+	"<":      withArity(1, newMethod(integerLt)),
+	">":      withArity(1, newMethod(integerGt)),
+	">=":     withArity(1, newMethod(integerGte)),
+	"<=":     withArity(1, newMethod(integerLte)),
+	"<=>":    withArity(1, newMethod(integerSpaceship)),
+	"to_i":   withArity(0, newMethod(integerToI)),
+	"to_s":   newMethod(integerToS),
+	"**":     withArity(1, newMethod(integerPow)),
+	"pow":    newMethod(integerPowMod),
+	"chr":    withArity(0, newMethod(integerChr)),
+	"quo":    withArity(1, newMethod(integerQuo)),
+	"to_f":   withArity(0, newMethod(integerToF)),
+	"to_r":   withArity(0, newMethod(integerToR)),
+
+	"rationalize": newMethod(integerToR),
+	"numerator":   withArity(0, newMethod(integerToI)),
+	"denominator": withArity(0, newMethod(integerDenominator)),
+
+	"&":          withArity(1, newMethod(integerAnd)),
+	"|":          withArity(1, newMethod(integerOr)),
+	"^":          withArity(1, newMethod(integerXor)),
+	"~":          withArity(0, newMethod(integerInvert)),
+	"<<":         withArity(1, newMethod(integerLeftShift)),
+	">>":         withArity(1, newMethod(integerRightShift)),
+	"[]":         newMethod(integerBitReference),
+	"bit_length": withArity(0, newMethod(integerBitLength)),
+
+	"times":     newMethod(integerTimes),
+	"upto":      newMethod(integerUpto),
+	"downto":    newMethod(integerDownto),
+	"digits":    newMethod(integerDigits),
+	"gcd":       withArity(1, newMethod(integerGcd)),
+	"lcm":       withArity(1, newMethod(integerLcm)),
+	"abs":       withArity(0, newMethod(integerAbs)),
+	"magnitude": withArity(0, newMethod(integerAbs)),
+	"even?":     withArity(0, newMethod(integerIsEven)),
+	"odd?":      withArity(0, newMethod(integerIsOdd)),
+	"succ":      withArity(0, newMethod(integerSucc)),
+	"next":      withArity(0, newMethod(integerSucc)),
+	"pred":      withArity(0, newMethod(integerPred)),
+	"round":     newMethod(integerRoundHalfUp),
+	"floor":     newMethod(integerFloor),
+	"ceil":      newMethod(integerCeil),
+	"truncate":  newMethod(integerTruncate),
+}
+
+// integerArgument returns the Integer argument of an arithmetic or bitwise
+// operation on i
+func integerArgument(i *Integer, arg RubyObject) (*Integer, error) {
+	other, ok := arg.(*Integer)
+	if !ok {
+		return nil, NewCoercionTypeError(arg, i)
+	}
+	return other, nil
+}
+
+// integerDivisor returns the Integer argument of a division. ok is false if
+// the argument is of another class, and has to be coerced.
+func integerDivisor(arg RubyObject) (divisor *Integer, ok bool, err error) {
+	divisor, ok = arg.(*Integer)
+	if ok && divisor.Sign() == 0 {
+		return nil, true, NewZeroDivisionError()
+	}
+	return divisor, ok, nil
 }
 
 func integerDiv(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -62,14 +133,15 @@ func integerDiv(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	divisor, ok := args[0].(*Integer)
-	if !ok {
-		return nil, NewCoercionTypeError(args[0], i)
+	divisor, ok, err := integerDivisor(args[0])
+	if err != nil {
+		return nil, err
 	}
-	if divisor.Value == 0 {
-		return nil, NewZeroDivisionError()
+	if !ok {
+		return coerceBinop(context, tracer, "/", args[0])
 	}
-	return NewInteger(i.Value / divisor.Value), nil
+	quotient, _ := divmodIntegers(i, divisor)
+	return quotient, nil
 }
 
 func integerMul(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -79,9 +151,9 @@ func integerMul(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 	i := context.Receiver().(*Integer)
 	factor, ok := args[0].(*Integer)
 	if !ok {
-		return nil, NewCoercionTypeError(args[0], i)
+		return coerceBinop(context, tracer, "*", args[0])
 	}
-	return NewInteger(i.Value * factor.Value), nil
+	return mulIntegers(i, factor), nil
 }
 
 func integerAdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -91,9 +163,9 @@ func integerAdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 	i := context.Receiver().(*Integer)
 	add, ok := args[0].(*Integer)
 	if !ok {
-		return nil, NewCoercionTypeError(args[0], i)
+		return coerceBinop(context, tracer, "+", args[0])
 	}
-	return NewInteger(i.Value + add.Value), nil
+	return addIntegers(i, add), nil
 }
 
 func integerSub(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -103,17 +175,16 @@ func integerSub(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 	i := context.Receiver().(*Integer)
 	sub, ok := args[0].(*Integer)
 	if !ok {
-		return nil, NewCoercionTypeError(args[0], i)
+		return coerceBinop(context, tracer, "-", args[0])
 	}
-	return NewInteger(i.Value - sub.Value), nil
+	return subIntegers(i, sub), nil
 }
 
 // Objects which can *safely* be converted to an integer
-func safeObjectToInteger(arg RubyObject) (int64, bool) {
-	var right int64
+func safeObjectToInteger(arg RubyObject) (*Integer, bool) {
 	switch arg := arg.(type) {
 	case *Integer:
-		right = int64(arg.Value)
+		return arg, true
 	// case *Boolean:
 	// 	if arg.Value {
 	// 		right = 1
@@ -121,35 +192,44 @@ func safeObjectToInteger(arg RubyObject) (int64, bool) {
 	// 		right = 0
 	// 	}
 	default:
-		return 0, false
+		return nil, false
 	}
-	return right, true
 }
 
-func integerCmpHelper(args []RubyObject) (int64, error) {
-	right, ok := safeObjectToInteger(args[0])
+// integerModulo returns the remainder of the floor division by the argument,
+// which has the sign of the argument, e.g. `-7 % 3` is 2
+func integerModulo(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	divisor, ok, err := integerDivisor(args[0])
+	if err != nil {
+		return nil, err
+	}
 	if !ok {
-		return 0, errors.WithMessage(
-			NewArgumentError(
-				"comparison of Integer with %s failed",
-				args[0].Class().(RubyObject).Inspect(),
-			),
-			callersName(),
-		)
+		return coerceBinop(context, tracer, "%", args[0])
 	}
-	return right, nil
+	_, remainder := divmodIntegers(i, divisor)
+	return remainder, nil
 }
 
-func integerModulo(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+// integerDivmod returns the quotient and the remainder of the floor division
+// by the argument, e.g. `-7.divmod(3)` is `[-3, 2]`
+func integerDivmod(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	right, err := integerCmpHelper(args)
+	divisor, ok, err := integerDivisor(args[0])
 	if err != nil {
 		return nil, err
 	}
-	return NewInteger(i.Value % right), nil
+	if !ok {
+		return coerceBinop(context, tracer, "divmod", args[0])
+	}
+	quotient, remainder := divmodIntegers(i, divisor)
+	return NewArray(quotient, remainder), nil
 }
 
 func integerLt(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -157,11 +237,11 @@ func integerLt(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	right, err := integerCmpHelper(args)
-	if err != nil {
-		return nil, err
+	right, ok := args[0].(*Integer)
+	if !ok {
+		return coerceRelop(context, tracer, "<", args[0])
 	}
-	if i.Value < right {
+	if compareIntegers(i, right) < 0 {
 		return TRUE, nil
 	}
 	return FALSE, nil
@@ -172,11 +252,11 @@ func integerGt(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	right, err := integerCmpHelper(args)
-	if err != nil {
-		return nil, err
+	right, ok := args[0].(*Integer)
+	if !ok {
+		return coerceRelop(context, tracer, ">", args[0])
 	}
-	if i.Value > right {
+	if compareIntegers(i, right) > 0 {
 		return TRUE, nil
 	}
 	return FALSE, nil
@@ -187,20 +267,11 @@ func integerSpaceship(context CallContext, tracer trace.Tracer, args ...RubyObje
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	right, err := integerCmpHelper(args)
-	if err != nil {
-		return NIL, err
-	}
-	switch {
-	case i.Value > right:
-		return NewInteger(1), nil
-	case i.Value < right:
-		return NewInteger(-1), nil
-	case i.Value == right:
-		return NewInteger(0), nil
-	default:
-		panic("not reachable")
+	right, ok := args[0].(*Integer)
+	if !ok {
+		return coerceCmp(context, tracer, args[0])
 	}
+	return NewInteger(int64(compareIntegers(i, right))), nil
 }
 
 func integerGte(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -208,11 +279,15 @@ func integerGte(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	right, err := integerCmpHelper(args)
-	if err != nil {
-		return NIL, err
+	right, ok := args[0].(*Integer)
+	if !ok {
+		result, err := coerceRelop(context, tracer, ">=", args[0])
+		if err != nil {
+			return NIL, err
+		}
+		return result, nil
 	}
-	if i.Value >= right {
+	if compareIntegers(i, right) >= 0 {
 		return TRUE, nil
 	}
 	return FALSE, nil
@@ -223,11 +298,15 @@ func integerLte(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	right, err := integerCmpHelper(args)
-	if err != nil {
-		return NIL, err
+	right, ok := args[0].(*Integer)
+	if !ok {
+		result, err := coerceRelop(context, tracer, "<=", args[0])
+		if err != nil {
+			return NIL, err
+		}
+		return result, nil
 	}
-	if i.Value <= right {
+	if compareIntegers(i, right) <= 0 {
 		return TRUE, nil
 	}
 	return FALSE, nil
@@ -241,6 +320,38 @@ func integerToI(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 	return i, nil
 }
 
+// integerToS returns the digits of the integer in the optional base, which
+// defaults to 10
+func integerToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	base := int64(10)
+	if len(args) == 1 {
+		arg, ok := args[0].(*Integer)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(NewInteger(0), args[0])
+		}
+		base = arg.Value
+		if arg.IsBig() {
+			base = 0
+		}
+	}
+	if base < 2 || base > 36 {
+		return nil, NewArgumentError("invalid radix %s", args[0].Inspect())
+	}
+	if i.IsBig() {
+		return NewString(i.big.Text(int(base))), nil
+	}
+	return NewString(strconv.FormatInt(i.Value, int(base))), nil
+}
+
+// integerPow raises the receiver to the argument. Negative Integer exponents
+// give a Rational, e.g. `2 ** -2` is `(1/4)`.
 func integerPow(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
@@ -248,27 +359,523 @@ func integerPow(context CallContext, tracer trace.Tracer, args ...RubyObject) (R
 	i := context.Receiver().(*Integer)
 	switch arg := args[0].(type) {
 	case *Integer:
-		result := int64(1)
-		for j := int64(0); j < arg.Value; j++ {
-			result *= i.Value
+		if arg.Sign() < 0 {
+			return powRational(new(big.Rat).SetInt(i.BigInt()), arg)
 		}
-		return NewInteger(result), nil
+		return powIntegerBounded(i, arg)
 	case *Float:
-		result := math.Pow(float64(i.Value), arg.Value)
-		return NewFloat(result), nil
+		return powFloat(i.Float64(), arg.Value), nil
 	default:
-		return nil, NewCoercionTypeError(args[0], i)
+		return coerceBinop(context, tracer, "**", args[0])
 	}
 }
 
+// integerPowMod returns the receiver raised to the first argument, modulo the
+// optional second one, e.g. `3.pow(4, 5)`, without computing the full power
+func integerPowMod(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	if len(args) < 1 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	if len(args) == 1 {
+		return integerPow(context, tracer, args...)
+	}
+	exp, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewTypeError("Integer#pow() 2nd argument not allowed unless a 1st argument is integer")
+	}
+	mod, ok := args[1].(*Integer)
+	if !ok {
+		return nil, NewTypeError("Integer#pow() 2nd argument not allowed unless all arguments are integers")
+	}
+	if exp.Sign() < 0 {
+		return nil, NewRangeError("Integer#pow() 1st argument cannot be negative when 2nd argument specified")
+	}
+	if mod.Sign() == 0 {
+		return nil, NewZeroDivisionError()
+	}
+	modulus := mod.BigInt()
+	result := new(big.Int).Exp(i.BigInt(), exp.BigInt(), new(big.Int).Abs(modulus))
+	// the result has the sign of the modulus, as with %
+	result.Mod(result, new(big.Int).Abs(modulus))
+	if modulus.Sign() < 0 && result.Sign() != 0 {
+		result.Add(result, modulus)
+	}
+	return NewBigInteger(result), nil
+}
+
 func integerChr(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	i := context.Receiver().(*Integer)
-	if i.Value < 0 || i.Value > 255 {
+	if i.IsBig() || i.Value < 0 || i.Value > 255 {
 		return nil, NewArgumentError("chr out of range")
 	}
 	// return NewString(string(i.Value)), nil
 	return NewString(string(rune(i.Value))), nil
 }
+
+func integerAnd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	other, err := integerArgument(i, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return bitwiseIntegers(i, other, func(x, y int64) int64 { return x & y }, (*big.Int).And), nil
+}
+
+func integerOr(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	other, err := integerArgument(i, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return bitwiseIntegers(i, other, func(x, y int64) int64 { return x | y }, (*big.Int).Or), nil
+}
+
+func integerXor(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	other, err := integerArgument(i, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return bitwiseIntegers(i, other, func(x, y int64) int64 { return x ^ y }, (*big.Int).Xor), nil
+}
+
+func integerInvert(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	if i.IsBig() {
+		return NewBigInteger(new(big.Int).Not(i.big)), nil
+	}
+	return NewInteger(^i.Value), nil
+}
+
+// shiftWidth returns the number of bits to shift i by, which is negated for
+// right shifts
+func shiftWidth(i *Integer, arg RubyObject, negate bool) (int64, error) {
+	n, err := integerArgument(i, arg)
+	if err != nil {
+		return 0, err
+	}
+	if negate {
+		n = n.Negate()
+	}
+	if compareIntegers(n, NewInteger(maxShiftWidth)) > 0 && i.Sign() != 0 {
+		return 0, NewRangeError("shift width too big")
+	}
+	if n.IsBig() {
+		// shifted right beyond the highest bit
+		return math.MinInt64, nil
+	}
+	return n.Value, nil
+}
+
+func integerLeftShift(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	n, err := shiftWidth(i, args[0], false)
+	if err != nil {
+		return nil, err
+	}
+	return shiftInteger(i, n), nil
+}
+
+func integerRightShift(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	n, err := shiftWidth(i, args[0], true)
+	if err != nil {
+		return nil, err
+	}
+	return shiftInteger(i, n), nil
+}
+
+// integerBitReference returns the bit at a position of the receiver, e.g.
+// `5[0]`, or the bits of a length starting at a position, e.g. `5[1, 2]` or
+// `5[1..2]`
+func integerBitReference(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	if len(args) < 1 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	var start, length int64
+	switch arg := args[0].(type) {
+	case *Range:
+		if len(args) != 1 {
+			return nil, NewWrongNumberOfArgumentsError(1, len(args))
+		}
+		start, length = arg.Left, arg.Right-arg.Left
+		if arg.Inclusive {
+			length++
+		}
+	default:
+		position, err := integerArgument(i, arg)
+		if err != nil {
+			return nil, err
+		}
+		if position.IsBig() {
+			// beyond the highest bit only the sign is left
+			if position.Sign() < 0 || i.Sign() >= 0 {
+				return NewInteger(0), nil
+			}
+			return NewInteger(1), nil
+		}
+		start, length = position.Value, 1
+		if len(args) == 2 {
+			count, err := integerArgument(i, args[1])
+			if err != nil {
+				return nil, err
+			}
+			if count.Sign() < 0 {
+				return nil, NewArgumentError("negative length %s", count.Inspect())
+			}
+			length = count.Value
+		}
+	}
+	if start < 0 && length == 1 {
+		// bits below the lowest one are zero
+		return NewInteger(0), nil
+	}
+	value := shiftInteger(i, -start)
+	mask := subIntegers(shiftInteger(NewInteger(1), length), NewInteger(1))
+	return bitwiseIntegers(value, mask, func(x, y int64) int64 { return x & y }, (*big.Int).And), nil
+}
+
+func integerBitLength(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	return NewInteger(int64(bitLength(i))), nil
+}
+
+// integerQuo returns the exact quotient of the receiver and the argument,
+// e.g. `1.quo(3)` is `(1/3)`
+func integerQuo(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	divisor, ok, err := integerDivisor(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if f, ok := args[0].(*Float); ok {
+			return NewFloat(i.Float64() / f.Value), nil
+		}
+		return coerceBinop(context, tracer, "quo", args[0])
+	}
+	return NewRational(new(big.Rat).SetFrac(i.BigInt(), divisor.BigInt())), nil
+}
+
+func integerToF(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	return NewFloat(i.Float64()), nil
+}
+
+// integerToR returns the receiver as a Rational, which is also the simplest
+// rational approximating it
+func integerToR(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	return NewRational(new(big.Rat).SetInt(i.BigInt())), nil
+}
+
+func integerDenominator(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return NewInteger(1), nil
+}
+
+// integerTimes yields the integers from zero up to the receiver, exclusive
+func integerTimes(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	args, fn, err := blockArgument("times", args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 0 {
+		return nil, NewWrongNumberOfArgumentsError(0, len(args))
+	}
+	for n := NewInteger(0); compareIntegers(n, i) < 0; n = addIntegers(n, NewInteger(1)) {
+		if _, err := fn.Call(context, tracer, n); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+// integerCount yields the integers from the receiver up to the limit,
+// inclusive, counting by step, which is 1 or -1
+func integerCount(context CallContext, tracer trace.Tracer, name string, step int64, args []RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	args, fn, err := blockArgument(name, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	past := ">"
+	if step < 0 {
+		past = "<"
+	}
+	for n := i; ; n = addIntegers(n, NewInteger(step)) {
+		done, err := Send(withReceiver(context, n), past, tracer, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if done == TRUE {
+			return i, nil
+		}
+		if _, err := fn.Call(context, tracer, n); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func integerUpto(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return integerCount(context, tracer, "upto", 1, args)
+}
+
+func integerDownto(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return integerCount(context, tracer, "downto", -1, args)
+}
+
+// integerDigits returns the digits of the receiver in the given base, 10 by
+// default, starting with the least significant, e.g. `123.digits` is
+// `[3, 2, 1]`
+func integerDigits(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	base := NewInteger(10)
+	if len(args) == 1 {
+		var ok bool
+		if base, ok = args[0].(*Integer); !ok {
+			return nil, NewImplicitConversionTypeError(NewInteger(0), args[0])
+		}
+		if base.Sign() < 0 {
+			return nil, NewArgumentError("negative radix")
+		}
+		if compareIntegers(base, NewInteger(2)) < 0 {
+			return nil, NewArgumentError("invalid radix %s", base.Inspect())
+		}
+	}
+	if i.Sign() < 0 {
+		return nil, &DomainError{message: "out of domain"}
+	}
+	digits := NewArray()
+	for n := i; ; {
+		var digit *Integer
+		n, digit = divmodIntegers(n, base)
+		digits.Elements = append(digits.Elements, digit)
+		if n.Sign() == 0 {
+			return digits, nil
+		}
+	}
+}
+
+// integerGcd returns the greatest common divisor of the receiver and the
+// argument, which is never negative
+func integerGcd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	other, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewTypeError("not an integer")
+	}
+	return NewBigInteger(new(big.Int).GCD(nil, nil, i.BigInt(), other.BigInt())), nil
+}
+
+// integerLcm returns the least common multiple of the receiver and the
+// argument, which is never negative
+func integerLcm(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	other, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewTypeError("not an integer")
+	}
+	if i.Sign() == 0 || other.Sign() == 0 {
+		return NewInteger(0), nil
+	}
+	gcd := new(big.Int).GCD(nil, nil, i.BigInt(), other.BigInt())
+	lcm := new(big.Int).Mul(i.BigInt(), other.BigInt())
+	lcm.Abs(lcm).Quo(lcm, gcd)
+	return NewBigInteger(lcm), nil
+}
+
+func integerAbs(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	i := context.Receiver().(*Integer)
+	if i.Sign() < 0 {
+		return i.Negate(), nil
+	}
+	return i, nil
+}
+
+// isOdd reports whether the lowest bit of i is set, which it is for odd
+// negative numbers in two's complement as well
+func isOdd(i *Integer) bool {
+	if i.big != nil {
+		return i.big.Bit(0) == 1
+	}
+	return i.Value&1 == 1
+}
+
+func integerIsEven(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if isOdd(context.Receiver().(*Integer)) {
+		return FALSE, nil
+	}
+	return TRUE, nil
+}
+
+func integerIsOdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if isOdd(context.Receiver().(*Integer)) {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func integerSucc(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return addIntegers(context.Receiver().(*Integer), NewInteger(1)), nil
+}
+
+func integerPred(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return subIntegers(context.Receiver().(*Integer), NewInteger(1)), nil
+}
+
+// roundingDigits returns the optional number of decimal digits round, floor,
+// ceil and truncate round to
+func roundingDigits(args []RubyObject) (int64, error) {
+	if len(args) > 1 {
+		return 0, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	if len(args) == 0 {
+		return 0, nil
+	}
+	switch arg := args[0].(type) {
+	case *Integer:
+		if arg.IsBig() {
+			return 0, NewRangeError("integer %s too big to convert to 'int'", arg.Inspect())
+		}
+		return arg.Value, nil
+	case *Float:
+		digits, err := floatToInteger(math.Trunc(arg.Value))
+		if err != nil {
+			return 0, err
+		}
+		return roundingDigits([]RubyObject{digits})
+	default:
+		return 0, NewImplicitConversionTypeError(NewInteger(0), arg)
+	}
+}
+
+// integerRound rounds the receiver to a multiple of 10**-ndigits as mode
+// tells. Integers are left as they are for non-negative ndigits.
+func integerRound(context CallContext, tracer trace.Tracer, mode roundingMode, args []RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	digits, err := roundingDigits(args)
+	if err != nil {
+		return nil, err
+	}
+	return roundInteger(context.Receiver().(*Integer), -digits, mode), nil
+}
+
+func integerRoundHalfUp(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return integerRound(context, tracer, roundHalfUp, args)
+}
+
+func integerFloor(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return integerRound(context, tracer, roundFloor, args)
+}
+
+func integerCeil(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return integerRound(context, tracer, roundCeil, args)
+}
+
+func integerTruncate(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return integerRound(context, tracer, roundTruncate, args)
+}
@@ -3,6 +3,7 @@ package object
 import (
 	"testing"
 
+	"github.com/MarcinKonowalczyk/goruby/trace"
 	"github.com/MarcinKonowalczyk/goruby/utils"
 )
 
@@ -417,3 +418,199 @@ func TestIntegerSpaceship(t *testing.T) {
 		utils.AssertEqualCmpAny(t, result, testCase.result, CompareRubyObjectsForTests)
 	}
 }
+
+func TestIntegerToS(t *testing.T) {
+	tests := []struct {
+		value     int64
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{42, nil, NewString("42"), nil},
+		{-42, nil, NewString("-42"), nil},
+		{255, []RubyObject{NewInteger(16)}, NewString("ff"), nil},
+		{5, []RubyObject{NewInteger(2)}, NewString("101"), nil},
+		{5, []RubyObject{NewInteger(1)}, nil, NewArgumentError("invalid radix 1")},
+		{5, []RubyObject{NewString("")}, nil, NewImplicitConversionTypeError(NewInteger(0), NewString(""))},
+	}
+
+	for _, tt := range tests {
+		context := &callContext{receiver: NewInteger(tt.value)}
+
+		result, err := integerToS(context, nil, tt.arguments...)
+
+		utils.AssertError(t, err, tt.err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
+
+func TestIntegerBitwise(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   func(CallContext, trace.Tracer, ...RubyObject) (RubyObject, error)
+		value    int64
+		argument RubyObject
+		result   RubyObject
+		err      error
+	}{
+		{"&", integerAnd, 12, NewInteger(10), NewInteger(8), nil},
+		{"|", integerOr, 12, NewInteger(10), NewInteger(14), nil},
+		{"^", integerXor, 12, NewInteger(10), NewInteger(6), nil},
+		{"& negative", integerAnd, -1, NewInteger(10), NewInteger(10), nil},
+		{"<<", integerLeftShift, 1, NewInteger(4), NewInteger(16), nil},
+		{"<< negative", integerLeftShift, 16, NewInteger(-2), NewInteger(4), nil},
+		{"<< beyond", integerLeftShift, 1, NewInteger(64), bigInteger("18446744073709551616"), nil},
+		{">>", integerRightShift, 16, NewInteger(2), NewInteger(4), nil},
+		{">> negative receiver", integerRightShift, -5, NewInteger(1), NewInteger(-3), nil},
+		{">> negative", integerRightShift, 1, NewInteger(-3), NewInteger(8), nil},
+		{">> beyond", integerRightShift, -5, NewInteger(100), NewInteger(-1), nil},
+		{"& string", integerAnd, 1, NewString(""), nil, NewCoercionTypeError(NewString(""), NewInteger(0))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := &callContext{receiver: NewInteger(tt.value)}
+
+			result, err := tt.method(context, nil, tt.argument)
+
+			utils.AssertError(t, err, tt.err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+	t.Run("~", func(t *testing.T) {
+		result, err := integerInvert(&callContext{receiver: NewInteger(5)}, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(-6), CompareRubyObjectsForTests)
+	})
+}
+
+func TestIntegerBitReference(t *testing.T) {
+	tests := []struct {
+		value     int64
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{5, []RubyObject{NewInteger(0)}, NewInteger(1), nil},
+		{5, []RubyObject{NewInteger(1)}, NewInteger(0), nil},
+		{5, []RubyObject{NewInteger(-1)}, NewInteger(0), nil},
+		{-1, []RubyObject{NewInteger(100)}, NewInteger(1), nil},
+		{0b110110, []RubyObject{NewInteger(1), NewInteger(3)}, NewInteger(0b011), nil},
+		{0b110110, []RubyObject{&Range{Left: 1, Right: 3, Inclusive: true}}, NewInteger(0b011), nil},
+		{0b110110, []RubyObject{&Range{Left: 1, Right: 3}}, NewInteger(0b11), nil},
+		{5, []RubyObject{NewInteger(0), NewInteger(-1)}, nil, NewArgumentError("negative length -1")},
+		{5, nil, nil, NewWrongNumberOfArgumentsError(1, 0)},
+	}
+
+	for _, tt := range tests {
+		context := &callContext{receiver: NewInteger(tt.value)}
+
+		result, err := integerBitReference(context, nil, tt.arguments...)
+
+		utils.AssertError(t, err, tt.err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
+
+func TestIntegerBitLength(t *testing.T) {
+	tests := []struct {
+		value  int64
+		result int64
+	}{
+		{0, 0},
+		{1, 1},
+		{255, 8},
+		{256, 9},
+		{-1, 0},
+		{-256, 8},
+		{-257, 9},
+	}
+
+	for _, tt := range tests {
+		result, err := integerBitLength(&callContext{receiver: NewInteger(tt.value)}, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(tt.result), CompareRubyObjectsForTests)
+	}
+}
+
+func TestIntegerRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     roundingMode
+		receiver *Integer
+		digits   int64
+		result   *Integer
+	}{
+		{"round", roundHalfUp, NewInteger(1250), -2, NewInteger(1300)},
+		{"round", roundHalfUp, NewInteger(-1250), -2, NewInteger(-1300)},
+		{"round", roundHalfUp, NewInteger(-1249), -2, NewInteger(-1200)},
+		{"round", roundHalfUp, NewInteger(15), 1, NewInteger(15)},
+		{"floor", roundFloor, NewInteger(-1201), -2, NewInteger(-1300)},
+		{"ceil", roundCeil, NewInteger(1201), -2, NewInteger(1300)},
+		{"truncate", roundTruncate, NewInteger(-1299), -2, NewInteger(-1200)},
+		{"round a bignum", roundHalfUp, bigInteger("123456789012345678901"), -20, bigInteger("100000000000000000000")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := integerRound(&callContext{receiver: tt.receiver}, nil, tt.mode, []RubyObject{NewInteger(tt.digits)})
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+}
+
+func TestIntegerGcdLcm(t *testing.T) {
+	tests := []struct {
+		receiver RubyObject
+		argument RubyObject
+		gcd      RubyObject
+		lcm      RubyObject
+	}{
+		{NewInteger(12), NewInteger(18), NewInteger(6), NewInteger(36)},
+		{NewInteger(-4), NewInteger(6), NewInteger(2), NewInteger(12)},
+		{NewInteger(0), NewInteger(5), NewInteger(5), NewInteger(0)},
+		{bigInteger("100000000000000000000"), NewInteger(15), NewInteger(5), bigInteger("300000000000000000000")},
+	}
+
+	for _, tt := range tests {
+		gcd, err := integerGcd(&callContext{receiver: tt.receiver}, nil, tt.argument)
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, gcd, tt.gcd, CompareRubyObjectsForTests)
+
+		lcm, err := integerLcm(&callContext{receiver: tt.receiver}, nil, tt.argument)
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, lcm, tt.lcm, CompareRubyObjectsForTests)
+	}
+
+	t.Run("of a Float", func(t *testing.T) {
+		_, err := integerGcd(&callContext{receiver: NewInteger(2)}, nil, NewFloat(4))
+
+		utils.AssertError(t, err, NewTypeError("not an integer"))
+	})
+}
+
+func TestIntegerDigits(t *testing.T) {
+	tests := []struct {
+		receiver  *Integer
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{NewInteger(1234), nil, NewArray(NewInteger(4), NewInteger(3), NewInteger(2), NewInteger(1)), nil},
+		{NewInteger(0), nil, NewArray(NewInteger(0)), nil},
+		{NewInteger(255), []RubyObject{NewInteger(16)}, NewArray(NewInteger(15), NewInteger(15)), nil},
+		{NewInteger(10), []RubyObject{NewInteger(1)}, nil, NewArgumentError("invalid radix 1")},
+		{NewInteger(-1), nil, nil, &DomainError{message: "out of domain"}},
+	}
+
+	for _, tt := range tests {
+		result, err := integerDigits(&callContext{receiver: tt.receiver}, nil, tt.arguments...)
+
+		utils.AssertError(t, err, tt.err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
@@ -10,9 +10,17 @@ func getMethods(class RubyClass, addSuperMethods bool) *Array {
 		return &Array{Elements: methodSymbols}
 	}
 	if addSuperMethods {
-		names := bottomClass.Methods().Names()
+		seen := make(map[string]bool)
 		for _, name := range names {
-			methodSymbols = append(methodSymbols, NewSymbol(name))
+			seen[name] = true
+		}
+		for _, ancestor := range append(ancestorsOf(class)[1:], bottomClass) {
+			for _, name := range ancestor.Methods().Names() {
+				if !seen[name] {
+					seen[name] = true
+					methodSymbols = append(methodSymbols, NewSymbol(name))
+				}
+			}
 		}
 	}
 	return &Array{Elements: methodSymbols}
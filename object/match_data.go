@@ -0,0 +1,321 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+var matchDataClass RubyClassObject = newClass(
+	"MatchData",
+	matchDataMethods,
+	nil,
+	notInstantiatable,
+)
+
+func init() {
+	CLASSES.Set("MatchData", matchDataClass)
+}
+
+// MatchData is the result of matching a Regexp against a string. Offsets are
+// byte offsets into String; the Ruby methods report character offsets.
+type MatchData struct {
+	Regexp  *Regexp
+	String  string
+	indices []int // start and end of the match and of each group, -1 if unmatched
+}
+
+func (m *MatchData) Inspect() string {
+	var out strings.Builder
+	out.WriteString("#<MatchData ")
+	out.WriteString(fmt.Sprintf("%q", m.Group(0)))
+	names := m.Regexp.re.SubexpNames()
+	for i := 1; i < m.Size(); i++ {
+		name := names[i]
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		value := "nil"
+		if m.Matched(i) {
+			value = fmt.Sprintf("%q", m.Group(i))
+		}
+		out.WriteString(fmt.Sprintf(" %s:%s", name, value))
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+func (m *MatchData) Class() RubyClass { return matchDataClass }
+
+func (m *MatchData) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%d:%s:%v", m.Regexp.HashKey(), m.String, m.indices)))
+	return HashKey(h.Sum64())
+}
+
+// Size returns the number of groups of the match, including the whole match
+func (m *MatchData) Size() int { return len(m.indices) / 2 }
+
+// Matched returns true if group i took part in the match
+func (m *MatchData) Matched(i int) bool {
+	return i >= 0 && i < m.Size() && m.indices[2*i] >= 0
+}
+
+// Group returns the text matched by group i, where group 0 is the whole
+// match
+func (m *MatchData) Group(i int) string {
+	if !m.Matched(i) {
+		return ""
+	}
+	return m.String[m.indices[2*i]:m.indices[2*i+1]]
+}
+
+// Begin returns the character offset of the start of group i
+func (m *MatchData) Begin(i int) int {
+	return utf8.RuneCountInString(m.String[:m.indices[2*i]])
+}
+
+// End returns the character offset of the end of group i
+func (m *MatchData) End(i int) int {
+	return utf8.RuneCountInString(m.String[:m.indices[2*i+1]])
+}
+
+// PreMatch returns the part of the string before the match
+func (m *MatchData) PreMatch() string { return m.String[:m.indices[0]] }
+
+// PostMatch returns the part of the string after the match
+func (m *MatchData) PostMatch() string { return m.String[m.indices[1]:] }
+
+// group returns the group i as a RubyObject, i.e. nil if it did not match
+func (m *MatchData) group(i int) RubyObject {
+	if !m.Matched(i) {
+		return NIL
+	}
+	return NewString(m.Group(i))
+}
+
+// groupIndex returns the index of the group referenced by obj, which is
+// either an Integer or the name of a named group
+func (m *MatchData) groupIndex(obj RubyObject) (int, error) {
+	var name string
+	switch obj := obj.(type) {
+	case *Integer:
+		i := int(obj.Value)
+		if i < 0 {
+			i += m.Size()
+		}
+		return i, nil
+	case *String:
+		name = obj.Value
+	case *Symbol:
+		name = obj.Value
+	default:
+		return 0, NewImplicitConversionTypeError(NewInteger(0), obj)
+	}
+	// the last group of a name wins, as in Ruby
+	index := -1
+	for i, groupName := range m.Regexp.re.SubexpNames() {
+		if groupName == name && name != "" {
+			index = i
+		}
+	}
+	if index < 0 {
+		return 0, NewIndexError("undefined group name reference: %s", name)
+	}
+	return index, nil
+}
+
+// byteOffset returns the byte offset of the character offset pos within str.
+// Negative offsets count from the end of str.
+func byteOffset(str string, pos int) int {
+	if pos < 0 {
+		pos += utf8.RuneCountInString(str)
+		if pos < 0 {
+			return -1
+		}
+	}
+	offset := 0
+	for i := 0; i < pos; i++ {
+		if offset >= len(str) {
+			return len(str) + 1
+		}
+		_, size := utf8.DecodeRuneInString(str[offset:])
+		offset += size
+	}
+	return offset
+}
+
+var (
+	_ RubyObject = &MatchData{}
+)
+
+var matchDataMethods = map[string]RubyMethod{
+	"[]":             withArity(1, newMethod(matchDataIndex)),
+	"captures":       withArity(0, newMethod(matchDataCaptures)),
+	"named_captures": withArity(0, newMethod(matchDataNamedCaptures)),
+	"names":          withArity(0, newMethod(matchDataNames)),
+	"pre_match":      withArity(0, newMethod(matchDataPreMatch)),
+	"post_match":     withArity(0, newMethod(matchDataPostMatch)),
+	"to_a":           withArity(0, newMethod(matchDataToA)),
+	"to_s":           withArity(0, newMethod(matchDataToS)),
+	"begin":          withArity(1, newMethod(matchDataBegin)),
+	"end":            withArity(1, newMethod(matchDataEnd)),
+	"size":           withArity(0, newMethod(matchDataSize)),
+	"length":         withArity(0, newMethod(matchDataSize)),
+	"string":         withArity(0, newMethod(matchDataString)),
+	"regexp":         withArity(0, newMethod(matchDataRegexp)),
+	"inspect":        withArity(0, newMethod(matchDataInspect)),
+}
+
+func matchDataIndex(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	i, err := match.groupIndex(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return match.group(i), nil
+}
+
+func matchDataCaptures(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	captures := NewArray()
+	for i := 1; i < match.Size(); i++ {
+		captures.Elements = append(captures.Elements, match.group(i))
+	}
+	return captures, nil
+}
+
+func matchDataNamedCaptures(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	captures := &Hash{}
+	for i, name := range match.Regexp.re.SubexpNames() {
+		if name != "" {
+			captures.Set(NewString(name), match.group(i))
+		}
+	}
+	return captures, nil
+}
+
+func matchDataNames(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return regexpNames(withReceiver(context, match.Regexp), tracer)
+}
+
+func matchDataPreMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return NewString(match.PreMatch()), nil
+}
+
+func matchDataPostMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return NewString(match.PostMatch()), nil
+}
+
+func matchDataToA(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	groups := NewArray()
+	for i := 0; i < match.Size(); i++ {
+		groups.Elements = append(groups.Elements, match.group(i))
+	}
+	return groups, nil
+}
+
+func matchDataToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return NewString(match.Group(0)), nil
+}
+
+func matchDataBegin(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	i, err := match.groupIndex(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= match.Size() {
+		return nil, NewIndexError("index %d out of matches", i)
+	}
+	if !match.Matched(i) {
+		return NIL, nil
+	}
+	return NewInteger(int64(match.Begin(i))), nil
+}
+
+func matchDataEnd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	i, err := match.groupIndex(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= match.Size() {
+		return nil, NewIndexError("index %d out of matches", i)
+	}
+	if !match.Matched(i) {
+		return NIL, nil
+	}
+	return NewInteger(int64(match.End(i))), nil
+}
+
+func matchDataSize(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return NewInteger(int64(match.Size())), nil
+}
+
+func matchDataString(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return NewString(match.String), nil
+}
+
+func matchDataRegexp(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return match.Regexp, nil
+}
+
+func matchDataInspect(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	match, _ := context.Receiver().(*MatchData)
+	return NewString(match.Inspect()), nil
+}
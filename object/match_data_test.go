@@ -0,0 +1,96 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestMatchData(t *testing.T) {
+	re := mustRegexp(t, `(?<num>\d+)(x)?-(\w+)`, 0)
+	match := re.Match("äb 42-foo!", 0)
+	context := &callContext{receiver: match}
+
+	t.Run("[]", func(t *testing.T) {
+		tests := []struct {
+			index    RubyObject
+			expected RubyObject
+		}{
+			{NewInteger(0), NewString("42-foo")},
+			{NewInteger(1), NewString("42")},
+			{NewInteger(2), NIL},
+			{NewInteger(-1), NewString("foo")},
+			{NewInteger(9), NIL},
+			{NewString("num"), NewString("42")},
+			{NewSymbol("num"), NewString("42")},
+		}
+
+		for _, tt := range tests {
+			result, err := matchDataIndex(context, nil, tt.index)
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqualCmpAny(t, result, tt.expected, CompareRubyObjectsForTests)
+		}
+	})
+	t.Run("[] with an unknown name", func(t *testing.T) {
+		_, err := matchDataIndex(context, nil, NewSymbol("foo"))
+
+		utils.AssertError(t, err, NewIndexError("undefined group name reference: foo"))
+	})
+	t.Run("captures", func(t *testing.T) {
+		result, err := matchDataCaptures(context, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewArray(NewString("42"), NIL, NewString("foo")), CompareRubyObjectsForTests)
+	})
+	t.Run("named_captures", func(t *testing.T) {
+		result, err := matchDataNamedCaptures(context, nil)
+
+		utils.AssertNoError(t, err)
+		expected := &Hash{}
+		expected.Set(NewString("num"), NewString("42"))
+		utils.AssertEqualCmpAny(t, result, expected, CompareRubyObjectsForTests)
+	})
+	t.Run("pre_match and post_match", func(t *testing.T) {
+		pre, err := matchDataPreMatch(context, nil)
+		utils.AssertNoError(t, err)
+		post, err := matchDataPostMatch(context, nil)
+		utils.AssertNoError(t, err)
+
+		utils.AssertEqualCmpAny(t, pre, NewString("äb "), CompareRubyObjectsForTests)
+		utils.AssertEqualCmpAny(t, post, NewString("!"), CompareRubyObjectsForTests)
+	})
+	t.Run("begin and end count characters", func(t *testing.T) {
+		begin, err := matchDataBegin(context, nil, NewInteger(0))
+		utils.AssertNoError(t, err)
+		end, err := matchDataEnd(context, nil, NewInteger(0))
+		utils.AssertNoError(t, err)
+		unmatched, err := matchDataBegin(context, nil, NewInteger(2))
+		utils.AssertNoError(t, err)
+
+		utils.AssertEqualCmpAny(t, begin, NewInteger(3), CompareRubyObjectsForTests)
+		utils.AssertEqualCmpAny(t, end, NewInteger(9), CompareRubyObjectsForTests)
+		utils.AssertEqual(t, unmatched, NIL)
+	})
+	t.Run("inspect", func(t *testing.T) {
+		utils.AssertEqual(t, match.Inspect(), `#<MatchData "42-foo" num:"42" 2:nil 3:"foo">`)
+	})
+}
+
+func TestByteOffset(t *testing.T) {
+	tests := []struct {
+		pos    int
+		offset int
+	}{
+		{0, 0},
+		{1, 2},
+		{2, 3},
+		{-1, 3},
+		{3, 4},
+		{-4, -1},
+	}
+
+	for _, tt := range tests {
+		utils.AssertEqual(t, byteOffset("äbc", tt.pos), tt.offset)
+	}
+}
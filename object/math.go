@@ -0,0 +1,157 @@
+package object
+
+import (
+	"math"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+// mathModule is the Math module. Its functions are module functions, i.e.
+// they are called on Math, e.g. `Math.sqrt(2)`, or without a receiver within
+// classes including Math.
+var mathModule = newMathModule()
+
+func newMathModule() *class {
+	module := newClass("Math", mathMethods, mathMethods, notInstantiatable)
+	module.module = true
+	module.Set("PI", NewFloat(math.Pi))
+	module.Set("E", NewFloat(math.E))
+	module.Set("DomainError", domainErrorClass)
+	return module
+}
+
+func init() {
+	CLASSES.Set("Math", mathModule)
+}
+
+var mathMethods = map[string]RubyMethod{
+	"sqrt":  mathFunction("sqrt", math.Sqrt, atLeast(0)),
+	"cbrt":  mathFunction("cbrt", math.Cbrt, nil),
+	"sin":   mathFunction("sin", math.Sin, nil),
+	"cos":   mathFunction("cos", math.Cos, nil),
+	"tan":   mathFunction("tan", math.Tan, nil),
+	"asin":  mathFunction("asin", math.Asin, between(-1, 1)),
+	"acos":  mathFunction("acos", math.Acos, between(-1, 1)),
+	"atan":  mathFunction("atan", math.Atan, nil),
+	"sinh":  mathFunction("sinh", math.Sinh, nil),
+	"cosh":  mathFunction("cosh", math.Cosh, nil),
+	"tanh":  mathFunction("tanh", math.Tanh, nil),
+	"asinh": mathFunction("asinh", math.Asinh, nil),
+	"acosh": mathFunction("acosh", math.Acosh, atLeast(1)),
+	"atanh": mathFunction("atanh", math.Atanh, between(-1, 1)),
+	"exp":   mathFunction("exp", math.Exp, nil),
+	"log2":  mathFunction("log2", math.Log2, atLeast(0)),
+	"log10": mathFunction("log10", math.Log10, atLeast(0)),
+	"erf":   mathFunction("erf", math.Erf, nil),
+	"erfc":  mathFunction("erfc", math.Erfc, nil),
+	"log":   newMethod(mathLog),
+	"atan2": withArity(2, newMethod(mathAtan2)),
+	"hypot": withArity(2, newMethod(mathHypot)),
+}
+
+// atLeast returns the domain of Math functions defined from min upwards
+func atLeast(min float64) func(float64) bool {
+	return func(x float64) bool { return !(x < min) }
+}
+
+// between returns the domain of Math functions defined from min to max
+func between(min, max float64) func(float64) bool {
+	return func(x float64) bool { return !(x < min || x > max) }
+}
+
+// mathFunction returns the Math function called name, which applies fn to its
+// argument. Arguments outside of domain raise a Math::DomainError, NaN never
+// does.
+func mathFunction(name string, fn func(float64) float64, domain func(float64) bool) RubyMethod {
+	return withArity(1, newMethod(func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+		if tracer != nil {
+			defer tracer.Un(tracer.Trace(trace.Here()))
+		}
+		x, err := mathArgument(context, tracer, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if domain != nil && !domain(x) {
+			return nil, NewDomainError(name)
+		}
+		return NewFloat(fn(x)), nil
+	}))
+}
+
+// mathArgument converts the argument of a Math function to a float. Only
+// real numbers are accepted, and not the Strings and nil which respond to
+// to_f as well.
+func mathArgument(context CallContext, tracer trace.Tracer, arg RubyObject) (float64, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	switch arg.(type) {
+	case *String:
+		return 0, NewTypeError("can't convert String into Float")
+	}
+	if arg == NIL {
+		return 0, NewTypeError("can't convert nil into Float")
+	}
+	f, err := numberToFloat(context, tracer, arg)
+	if err != nil {
+		return 0, err
+	}
+	return f.Value, nil
+}
+
+// mathLog returns the natural logarithm of the first argument, or its
+// logarithm to the base given as second argument
+func mathLog(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	var values []float64
+	for _, arg := range args {
+		x, err := mathArgument(context, tracer, arg)
+		if err != nil {
+			return nil, err
+		}
+		if x < 0 {
+			return nil, NewDomainError("log")
+		}
+		values = append(values, x)
+	}
+	result := math.Log(values[0])
+	if len(values) == 2 {
+		result /= math.Log(values[1])
+	}
+	return NewFloat(result), nil
+}
+
+func mathAtan2(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	y, err := mathArgument(context, tracer, args[0])
+	if err != nil {
+		return nil, err
+	}
+	x, err := mathArgument(context, tracer, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return NewFloat(math.Atan2(y, x)), nil
+}
+
+func mathHypot(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	x, err := mathArgument(context, tracer, args[0])
+	if err != nil {
+		return nil, err
+	}
+	y, err := mathArgument(context, tracer, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return NewFloat(math.Hypot(x, y)), nil
+}
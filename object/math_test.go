@@ -0,0 +1,53 @@
+package object
+
+import (
+	"math"
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestMathFunctions(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{"sqrt", []RubyObject{NewInteger(16)}, NewFloat(4), nil},
+		{"sqrt", []RubyObject{rational(1, 4)}, NewFloat(0.5), nil},
+		{"sqrt", []RubyObject{NewFloat(-1)}, nil, NewDomainError("sqrt")},
+		{"sqrt", []RubyObject{NewString("4")}, nil, NewTypeError("can't convert String into Float")},
+		{"cbrt", []RubyObject{NewInteger(-27)}, NewFloat(-3), nil},
+		{"acos", []RubyObject{NewInteger(2)}, nil, NewDomainError("acos")},
+		{"log", []RubyObject{NewInteger(0)}, NewFloat(math.Inf(-1)), nil},
+		{"log", []RubyObject{NewInteger(8), NewInteger(2)}, NewFloat(3), nil},
+		{"log", []RubyObject{NewInteger(-1)}, nil, NewDomainError("log")},
+		{"log2", []RubyObject{NewInteger(1024)}, NewFloat(10), nil},
+		{"atan2", []RubyObject{NewInteger(0), NewInteger(-1)}, NewFloat(math.Pi), nil},
+		{"hypot", []RubyObject{NewInteger(3), NewFloat(4)}, NewFloat(5), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, ok := mathMethods[tt.name]
+			utils.Assert(t, ok, "expected Math.%s to exist", tt.name)
+
+			result, err := method.Call(&callContext{receiver: mathModule}, nil, tt.arguments...)
+
+			utils.AssertError(t, err, tt.err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+}
+
+func TestMathConstants(t *testing.T) {
+	pi, err := ScopedConstant(mathModule, "PI")
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, pi, NewFloat(math.Pi), CompareRubyObjectsForTests)
+
+	domainError, err := ScopedConstant(mathModule, "DomainError")
+	utils.AssertNoError(t, err)
+	utils.AssertEqual(t, domainError.(RubyClassObject).Name(), "Math::DomainError")
+	utils.Assert(t, IsKindOf(NewDomainError("sqrt"), argumentErrorClass), "expected Math::DomainError to be an ArgumentError")
+}
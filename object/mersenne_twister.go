@@ -0,0 +1,199 @@
+package object
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// The parameters of MT19937, the Mersenne Twister generator used by CRuby
+const (
+	mtStateSize = 624
+	mtShift     = 397
+	mtMatrixA   = 0x9908b0df
+	mtUpperMask = 0x80000000
+	mtLowerMask = 0x7fffffff
+)
+
+// mersenneTwister is the MT19937 generator. It is seeded and drawn from
+// exactly like CRuby's, so that a seed yields the same numbers on every
+// platform, and the same numbers as in CRuby.
+type mersenneTwister struct {
+	state [mtStateSize]uint32
+	index int
+}
+
+// newMersenneTwister returns a generator seeded with the absolute value of
+// seed
+func newMersenneTwister(seed *big.Int) *mersenneTwister {
+	mt := &mersenneTwister{}
+	words := seedWords(seed)
+	if len(words) == 1 {
+		mt.initGenrand(words[0])
+	} else {
+		mt.initByArray(words)
+	}
+	return mt
+}
+
+// seedWords splits the absolute value of seed into 32 bit words, least
+// significant first. There is at least one word, even for a seed of zero.
+func seedWords(seed *big.Int) []uint32 {
+	abs := new(big.Int).Abs(seed)
+	var words []uint32
+	for _, word := range abs.Bits() {
+		for i := 0; i < bits.UintSize; i += 32 {
+			words = append(words, uint32(uint64(word)>>i))
+		}
+	}
+	for len(words) > 1 && words[len(words)-1] == 0 {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		words = append(words, 0)
+	}
+	return words
+}
+
+func (mt *mersenneTwister) initGenrand(seed uint32) {
+	mt.state[0] = seed
+	for i := 1; i < mtStateSize; i++ {
+		previous := mt.state[i-1]
+		mt.state[i] = 1812433253*(previous^(previous>>30)) + uint32(i)
+	}
+	mt.index = mtStateSize
+}
+
+func (mt *mersenneTwister) initByArray(key []uint32) {
+	mt.initGenrand(19650218)
+	i, j := 1, 0
+	for k := max(mtStateSize, len(key)); k > 0; k-- {
+		previous := mt.state[i-1]
+		mt.state[i] = (mt.state[i] ^ ((previous ^ (previous >> 30)) * 1664525)) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= mtStateSize {
+			mt.state[0] = mt.state[mtStateSize-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k := mtStateSize - 1; k > 0; k-- {
+		previous := mt.state[i-1]
+		mt.state[i] = (mt.state[i] ^ ((previous ^ (previous >> 30)) * 1566083941)) - uint32(i)
+		i++
+		if i >= mtStateSize {
+			mt.state[0] = mt.state[mtStateSize-1]
+			i = 1
+		}
+	}
+	mt.state[0] = 0x80000000
+	mt.index = mtStateSize
+}
+
+// generate refills the state once all of its words have been drawn
+func (mt *mersenneTwister) generate() {
+	for i := 0; i < mtStateSize; i++ {
+		y := (mt.state[i] & mtUpperMask) | (mt.state[(i+1)%mtStateSize] & mtLowerMask)
+		next := mt.state[(i+mtShift)%mtStateSize] ^ (y >> 1)
+		if y&1 != 0 {
+			next ^= mtMatrixA
+		}
+		mt.state[i] = next
+	}
+	mt.index = 0
+}
+
+// uint32 returns the next 32 random bits
+func (mt *mersenneTwister) uint32() uint32 {
+	if mt.index >= mtStateSize {
+		mt.generate()
+	}
+	y := mt.state[mt.index]
+	mt.index++
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// float64 returns a float in [0, 1) with 53 random bits
+func (mt *mersenneTwister) float64() float64 {
+	a, b := mt.uint32()>>5, mt.uint32()>>6
+	return (float64(a)*67108864 + float64(b)) * (1.0 / 9007199254740992.0)
+}
+
+// limited returns an integer in [0, limit]. It draws 32 bits at a time,
+// masked to the bit length of limit, and starts over whenever the value
+// exceeds limit.
+func (mt *mersenneTwister) limited(limit uint64) uint64 {
+	if limit == 0 {
+		return 0
+	}
+	mask := uint64(1)<<bits.Len64(limit) - 1
+retry:
+	value := uint64(0)
+	for i := 1; i >= 0; i-- {
+		if (mask>>(i*32))&0xffffffff == 0 {
+			continue
+		}
+		value |= uint64(mt.uint32()) << (i * 32)
+		value &= mask
+		if value > limit {
+			goto retry
+		}
+	}
+	return value
+}
+
+// limitedBig returns an integer in [0, limit] for limits beyond 64 bits. The
+// words of limit are drawn from the most significant one downwards, starting
+// over whenever the words drawn so far exceed those of limit.
+func (mt *mersenneTwister) limitedBig(limit *big.Int) *big.Int {
+	words := seedWords(limit)
+	drawn := make([]uint32, len(words))
+retry:
+	mask := uint32(0)
+	boundary := true
+	for i := len(words) - 1; i >= 0; i-- {
+		if mask != 0 {
+			mask = 0xffffffff
+		} else {
+			mask = uint32(1)<<bits.Len32(words[i]) - 1
+		}
+		drawn[i] = 0
+		if mask == 0 {
+			continue
+		}
+		drawn[i] = mt.uint32() & mask
+		if boundary {
+			if drawn[i] > words[i] {
+				goto retry
+			}
+			if drawn[i] < words[i] {
+				boundary = false
+			}
+		}
+	}
+	value := new(big.Int)
+	for i := len(drawn) - 1; i >= 0; i-- {
+		value.Lsh(value, 32)
+		value.Or(value, big.NewInt(int64(drawn[i])))
+	}
+	return value
+}
+
+// bytes returns n random bytes, taken from successive 32 bit draws least
+// significant byte first
+func (mt *mersenneTwister) bytes(n int) []byte {
+	result := make([]byte, 0, n)
+	for len(result) < n {
+		word := mt.uint32()
+		for i := 0; i < 4 && len(result) < n; i++ {
+			result = append(result, byte(word>>(8*i)))
+		}
+	}
+	return result
+}
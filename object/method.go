@@ -17,15 +17,17 @@ func withArity(arity int, fn RubyMethod) RubyMethod {
 			}
 			return fn.Call(context, tracer, args...)
 		},
+		arity: arity,
 	}
 }
 
 func newMethod(fn func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error)) RubyMethod {
-	return &method{fn: fn}
+	return &method{fn: fn, arity: -1}
 }
 
 type method struct {
-	fn func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error)
+	fn    func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error)
+	arity int // the number of arguments, -1 if variable
 }
 
 func (m *method) Call(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
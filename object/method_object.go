@@ -0,0 +1,191 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+var methodClass RubyClassObject = newClass(
+	"Method",
+	methodObjectMethods,
+	nil,
+	notInstantiatable,
+)
+
+func init() {
+	CLASSES.Set("Method", methodClass)
+}
+
+// NewMethod returns a Method calling the method name of receiver, as
+// returned by `method(:name)`
+func NewMethod(receiver RubyObject, name string) (*Method, error) {
+	fn, ok := lookupMethod(receiver.Class(), name)
+	if !ok {
+		return nil, NewUndefinedMethodNameError(receiver, name)
+	}
+	return &Method{Receiver: receiver, Name: name, Body: fn}, nil
+}
+
+// A Method is a method bound to its receiver
+type Method struct {
+	Receiver RubyObject
+	Name     string
+	Body     RubyMethod
+}
+
+func (m *Method) Inspect() string {
+	return fmt.Sprintf("#<Method: %s#%s>", m.Owner().Name(), m.Name)
+}
+func (m *Method) Class() RubyClass { return methodClass }
+func (m *Method) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%d:%p", m.Receiver.HashKey(), m.Body)))
+	return HashKey(h.Sum64())
+}
+
+// Owner returns the class or module defining the method
+func (m *Method) Owner() RubyClass {
+	if fn, ok := m.Body.(*Function); ok && fn.Owner != nil {
+		return fn.Owner
+	}
+	return m.Receiver.Class()
+}
+
+// Arity returns the number of arguments the method takes, or its one's
+// complement if the number of arguments is variable. Builtin methods with a
+// variable number of arguments report -1.
+func (m *Method) Arity() int {
+	switch body := m.Body.(type) {
+	case *Function:
+		return functionParameters(body.Parameters).arity()
+	case *method:
+		return body.arity
+	default:
+		return -1
+	}
+}
+
+// Call implements the RubyMethod interface. It sends the method to its
+// receiver.
+func (m *Method) Call(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return m.Body.Call(withReceiver(context, m.Receiver), tracer, args...)
+}
+
+var (
+	_ RubyObject = &Method{}
+	_ RubyMethod = &Method{}
+)
+
+var methodObjectMethods = map[string]RubyMethod{
+	"call":       newMethod(methodCall),
+	"[]":         newMethod(methodCall),
+	"===":        newMethod(methodCall),
+	"arity":      withArity(0, newMethod(methodArity)),
+	"parameters": withArity(0, newMethod(methodParameters)),
+	"name":       withArity(0, newMethod(methodName)),
+	"owner":      withArity(0, newMethod(methodOwner)),
+	"receiver":   withArity(0, newMethod(methodReceiver)),
+	">>":         withArity(1, newMethod(methodComposeRight)),
+	"<<":         withArity(1, newMethod(methodComposeLeft)),
+	"to_proc":    withArity(0, newMethod(methodToProc)),
+}
+
+func methodCall(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method, _ := context.Receiver().(*Method)
+	return method.Call(context, tracer, args...)
+}
+
+func methodArity(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method, _ := context.Receiver().(*Method)
+	return NewInteger(int64(method.Arity())), nil
+}
+
+func methodParameters(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method, _ := context.Receiver().(*Method)
+	if fn, ok := method.Body.(*Function); ok {
+		return functionParameters(fn.Parameters).describe(true), nil
+	}
+	arity := method.Arity()
+	if arity < 0 {
+		return NewArray(NewArray(NewSymbol("rest"))), nil
+	}
+	params := NewArray()
+	for i := 0; i < arity; i++ {
+		params.Elements = append(params.Elements, NewArray(NewSymbol("req")))
+	}
+	return params, nil
+}
+
+func methodName(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method, _ := context.Receiver().(*Method)
+	return NewSymbol(method.Name), nil
+}
+
+func methodOwner(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method, _ := context.Receiver().(*Method)
+	owner, ok := method.Owner().(RubyObject)
+	if !ok {
+		return NIL, nil
+	}
+	return owner, nil
+}
+
+func methodReceiver(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method, _ := context.Receiver().(*Method)
+	return method.Receiver, nil
+}
+
+// methodToProc returns a lambda calling the method, as used in
+// `list.each(&method(:puts))`
+func methodToProc(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method, _ := context.Receiver().(*Method)
+	return &Proc{
+		lambda: true,
+		arity:  method.Arity(),
+		call: func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+			return method.Call(withoutBlock(context), tracer, args...)
+		},
+	}, nil
+}
+
+func methodComposeRight(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method := context.Receiver()
+	return compose(true, method, args[0])
+}
+
+func methodComposeLeft(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	method := context.Receiver()
+	return compose(true, args[0], method)
+}
@@ -0,0 +1,70 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestNewMethod(t *testing.T) {
+	t.Run("existing method", func(t *testing.T) {
+		method, err := NewMethod(NewInteger(1), "+")
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, method.Name, "+")
+		utils.AssertEqual(t, method.Owner(), RubyClass(integerClass))
+	})
+	t.Run("undefined method", func(t *testing.T) {
+		_, err := NewMethod(NewInteger(1), "foo")
+
+		utils.AssertError(t, err, NewUndefinedMethodNameError(NewInteger(1), "foo"))
+	})
+}
+
+func TestMethodArity(t *testing.T) {
+	tests := []struct {
+		body  RubyMethod
+		arity int
+	}{
+		{withArity(1, newMethod(integerAdd)), 1},
+		{newMethod(integerAdd), -1},
+		{&Function{Name: "foo", Parameters: []*FunctionParameter{{Name: "x"}, {Name: "y"}}}, 2},
+		{&Function{Name: "foo", Parameters: []*FunctionParameter{{Name: "x"}, {Name: "y", Default: NIL}}}, -2},
+	}
+
+	for _, tt := range tests {
+		context := &callContext{receiver: &Method{Receiver: NIL, Name: "foo", Body: tt.body}}
+
+		result, err := methodArity(context, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(int64(tt.arity)), CompareRubyObjectsForTests)
+	}
+}
+
+func TestMethodCall(t *testing.T) {
+	method, err := NewMethod(NewInteger(2), "+")
+	utils.AssertNoError(t, err)
+	context := &callContext{receiver: method}
+
+	result, err := methodCall(context, nil, NewInteger(3))
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewInteger(5), CompareRubyObjectsForTests)
+}
+
+func TestMethodToProc(t *testing.T) {
+	method, err := NewMethod(NewInteger(2), "+")
+	utils.AssertNoError(t, err)
+
+	result, err := methodToProc(&callContext{receiver: method}, nil)
+	utils.AssertNoError(t, err)
+	proc, ok := result.(*Proc)
+	utils.Assert(t, ok, "Expected Proc, got %T", result)
+	utils.Assert(t, proc.IsLambda(), "Expected a lambda")
+
+	result, err = proc.Call(&callContext{receiver: NIL}, nil, NewInteger(3))
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewInteger(5), CompareRubyObjectsForTests)
+}
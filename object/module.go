@@ -0,0 +1,405 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+// NOTE: created in the init of class.go, as Class inherits from Module
+var moduleClass *class
+
+// newModule returns a new Ruby Module
+func newModule(name string, methods map[string]RubyMethod) *class {
+	module := newClass(name, methods, nil, notInstantiatable)
+	module.module = true
+	return module
+}
+
+// OpenModule returns the module called name defined in env, so that a module
+// definition can reopen it, or defines a new one. env is either the top level
+// environment or the module the new module is nested in.
+func OpenModule(env Environment, name string) (RubyClassObject, error) {
+	if existing, ok := env.Get(name); ok {
+		module, ok := existing.(*class)
+		if !ok || !module.module {
+			return nil, NewTypeError(fmt.Sprintf("%s is not a module", name))
+		}
+		return module, nil
+	}
+	module := newModule(qualifiedName(env, name), nil)
+	env.Set(name, module)
+	return module, nil
+}
+
+// A mixinHolder is a class which modules can be mixed into
+type mixinHolder interface {
+	// mixins returns the modules searched for methods before and after the
+	// class itself
+	mixins() (prepends, includes []*class)
+}
+
+func (c *class) mixins() ([]*class, []*class)      { return c.prepends, c.includes }
+func (e *eigenclass) mixins() ([]*class, []*class) { return nil, e.includes }
+
+// ancestorsOf returns cls followed by all classes and modules searched for
+// methods after it, in the order Ruby searches them. Every class has Object
+// among its ancestors.
+func ancestorsOf(cls RubyClass) []RubyClass {
+	var chain []RubyClass
+	for c := cls; c != nil && c != RubyClass(nil_class); c = superClassOf(c) {
+		chain = appendWithMixins(chain, c)
+	}
+	if module, ok := cls.(*class); (!ok || !module.module) && !containsClass(chain, objectClass) {
+		chain = append(chain, objectClass)
+	}
+	// a module mixed in several times is only searched at its last position,
+	// i.e. after all classes and modules which mixed it in
+	ancestors := chain[:0]
+	for i, c := range chain {
+		if !containsClass(chain[i+1:], c) {
+			ancestors = append(ancestors, c)
+		}
+	}
+	return ancestors
+}
+
+func appendWithMixins(chain []RubyClass, class RubyClass) []RubyClass {
+	holder, ok := class.(mixinHolder)
+	if !ok {
+		return append(chain, class)
+	}
+	prepends, includes := holder.mixins()
+	for _, module := range prepends {
+		chain = appendWithMixins(chain, module)
+	}
+	chain = append(chain, class)
+	for _, module := range includes {
+		chain = appendWithMixins(chain, module)
+	}
+	return chain
+}
+
+func containsClass(classes []RubyClass, class RubyClass) bool {
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// toModule returns obj if it is a module
+func toModule(obj RubyObject) (*class, error) {
+	module, ok := obj.(*class)
+	if !ok || !module.module {
+		return nil, NewTypeError(fmt.Sprintf("wrong argument type %s (expected Module)", obj.Class().Name()))
+	}
+	return module, nil
+}
+
+// includeModule inserts module right after target within the ancestors of
+// target. Modules which already are ancestors of target are skipped.
+func includeModule(target, module *class) error {
+	if containsClass(ancestorsOf(module), target) {
+		return NewArgumentError("cyclic include detected")
+	}
+	if containsClass(ancestorsOf(target), module) {
+		return nil
+	}
+	target.includes = append([]*class{module}, target.includes...)
+	return nil
+}
+
+// prependModule inserts module right before target within the ancestors of
+// target
+func prependModule(target, module *class) error {
+	if containsClass(ancestorsOf(module), target) {
+		return NewArgumentError("cyclic prepend detected")
+	}
+	for _, prepended := range target.prepends {
+		if prepended == module {
+			return nil
+		}
+	}
+	target.prepends = append([]*class{module}, target.prepends...)
+	return nil
+}
+
+// extendObject adds the methods of module to the singleton class of obj
+func extendObject(obj RubyObject, module *class) error {
+	singleton, err := singletonClassOf(obj)
+	if err != nil {
+		return err
+	}
+	if containsClass(ancestorsOf(singleton), module) {
+		return nil
+	}
+	singleton.includes = append([]*class{module}, singleton.includes...)
+	return nil
+}
+
+// LookupConstant searches the constant name within the modules lexically
+// enclosing the executed code, innermost first, and then within the
+// ancestors of the innermost of them. It does not search the top level.
+func LookupConstant(nesting []RubyObject, name string) (RubyObject, bool) {
+	for _, scope := range nesting {
+		if module, ok := scope.(*class); ok {
+			if value, ok := module.Get(name); ok {
+				return value, true
+			}
+		}
+	}
+	if len(nesting) > 0 {
+		if module, ok := nesting[0].(*class); ok {
+			return constantOf(module, name)
+		}
+	}
+	return nil, false
+}
+
+// ScopedConstant returns the constant name defined within scope or its
+// ancestors, as referenced by scope::name
+func ScopedConstant(scope RubyObject, name string) (RubyObject, error) {
+	module, ok := scope.(*class)
+	if !ok {
+		return nil, NewTypeError(fmt.Sprintf("%s is not a class/module", scope.Inspect()))
+	}
+	if value, ok := constantOf(module, name); ok {
+		return value, nil
+	}
+	return nil, NewUninitializedConstantNameError(qualifiedName(module, name))
+}
+
+// constantOf searches the constant name within module and its ancestors
+func constantOf(module *class, name string) (RubyObject, bool) {
+	for _, ancestor := range ancestorsOf(module) {
+		if ancestor, ok := ancestor.(*class); ok && ancestor.Environment != nil {
+			if value, ok := ancestor.Get(name); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+var moduleMethods = map[string]RubyMethod{
+	"name":             withArity(0, newMethod(moduleName)),
+	"to_s":             withArity(0, newMethod(moduleToS)),
+	"inspect":          withArity(0, newMethod(moduleToS)),
+	"ancestors":        withArity(0, newMethod(moduleAncestors)),
+	"instance_methods": newMethod(moduleInstanceMethods),
+	"include":          newMethod(moduleInclude),
+	"prepend":          newMethod(modulePrepend),
+	"include?":         withArity(1, newMethod(moduleIncludes)),
+	"===":              withArity(1, newMethod(moduleCaseEqual)),
+	"attr_reader":      newMethod(moduleAttrReader),
+	"attr_writer":      newMethod(moduleAttrWriter),
+	"attr_accessor":    newMethod(moduleAttrAccessor),
+}
+
+func moduleNew(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return newModule("", nil), nil
+}
+
+func moduleAncestors(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	ancestors := NewArray()
+	for _, ancestor := range ancestorsOf(context.Receiver().(RubyClass)) {
+		// Bottom is an implementation detail rather than a Ruby class
+		if ancestor, ok := ancestor.(*class); ok && ancestor != bottomClass {
+			ancestors.Elements = append(ancestors.Elements, ancestor)
+		}
+	}
+	return ancestors, nil
+}
+
+func moduleInstanceMethods(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	inherited := true
+	if len(args) == 1 {
+		if val, ok := SymbolToBool(args[0]); ok {
+			inherited = val
+		}
+	}
+	module := context.Receiver().(RubyClass)
+	classes := []RubyClass{module}
+	if inherited {
+		classes = ancestorsOf(module)
+	}
+	seen := make(map[string]bool)
+	methods := NewArray()
+	for _, c := range classes {
+		if c == RubyClass(bottomClass) {
+			continue
+		}
+		names := c.Methods().Names()
+		sort.Strings(names)
+		for _, name := range names {
+			// initialize is private in Ruby
+			if seen[name] || name == "initialize" {
+				continue
+			}
+			seen[name] = true
+			methods.Elements = append(methods.Elements, NewSymbol(name))
+		}
+	}
+	return methods, nil
+}
+
+func moduleInclude(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	target := context.Receiver().(*class)
+	// the first argument ends up first within the ancestors
+	for i := len(args) - 1; i >= 0; i-- {
+		module, err := toModule(args[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := includeModule(target, module); err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+func modulePrepend(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	target := context.Receiver().(*class)
+	for i := len(args) - 1; i >= 0; i-- {
+		module, err := toModule(args[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := prependModule(target, module); err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+func moduleIncludes(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	module, err := toModule(args[0])
+	if err != nil {
+		return nil, err
+	}
+	target := context.Receiver().(RubyClass)
+	if RubyClass(module) != target && containsClass(ancestorsOf(target), module) {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+// moduleCaseEqual implements Module#===, which is true if the argument is an
+// instance of the receiver or one of its descendants
+func moduleCaseEqual(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if IsKindOf(args[0], context.Receiver().(RubyClass)) {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func moduleName(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	name := context.Receiver().(RubyClass).Name()
+	if name == "" {
+		return NIL, nil
+	}
+	return NewString(name), nil
+}
+
+// moduleToS returns the name of the module, or a description of its
+// identity if it is anonymous
+func moduleToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return NewString(context.Receiver().Inspect()), nil
+}
+
+func moduleAttrReader(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return defineAttributes(context, args, true, false)
+}
+
+func moduleAttrWriter(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return defineAttributes(context, args, false, true)
+}
+
+func moduleAttrAccessor(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return defineAttributes(context, args, true, true)
+}
+
+// defineAttributes adds reader and/or writer methods for the instance
+// variables named by args to the receiving class or module. It returns the names of
+// the defined methods.
+func defineAttributes(context CallContext, args []RubyObject, reader, writer bool) (RubyObject, error) {
+	cls, ok := context.Receiver().(*class)
+	if !ok {
+		return nil, NewNoMethodError(context.Receiver(), "attr_accessor")
+	}
+	names := NewArray()
+	for _, arg := range args {
+		var name string
+		switch arg := arg.(type) {
+		case *Symbol:
+			name = arg.Value
+		case *String:
+			name = arg.Value
+		default:
+			return nil, NewTypeError(fmt.Sprintf("%s is not a symbol nor a string", arg.Inspect()))
+		}
+		variable := "@" + name
+		if reader {
+			cls.addMethod(name, withArity(0, newMethod(
+				func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+					return InstanceVariableGet(context.Receiver(), variable), nil
+				},
+			)))
+			names.Elements = append(names.Elements, NewSymbol(name))
+		}
+		if writer {
+			cls.addMethod(name+"=", withArity(1, newMethod(
+				func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+					if err := InstanceVariableSet(context.Receiver(), variable, args[0]); err != nil {
+						return nil, err
+					}
+					return args[0], nil
+				},
+			)))
+			names.Elements = append(names.Elements, NewSymbol(name+"="))
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,128 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestAncestors(t *testing.T) {
+	env := NewEnvironment()
+	base, _ := OpenClass(env, "Base", nil)
+	derived, _ := OpenClass(env, "Derived", base)
+	shared, _ := OpenModule(env, "Shared")
+	first, _ := OpenModule(env, "First")
+	second, _ := OpenModule(env, "Second")
+	wrapper, _ := OpenModule(env, "Wrapper")
+
+	utils.AssertNoError(t, includeModule(first.(*class), shared.(*class)))
+	utils.AssertNoError(t, includeModule(second.(*class), shared.(*class)))
+	utils.AssertNoError(t, includeModule(derived.(*class), first.(*class)))
+	utils.AssertNoError(t, includeModule(derived.(*class), second.(*class)))
+	utils.AssertNoError(t, prependModule(derived.(*class), wrapper.(*class)))
+
+	names := func(classes []RubyClass) []string {
+		var names []string
+		for _, c := range classes {
+			names = append(names, c.Name())
+		}
+		return names
+	}
+
+	utils.AssertEqualCmp(
+		t,
+		names(ancestorsOf(derived)),
+		[]string{"Wrapper", "Derived", "Second", "First", "Shared", "Base", "Object"},
+		utils.CompareArrays,
+	)
+	utils.AssertEqualCmp(t, names(ancestorsOf(first)), []string{"First", "Shared"}, utils.CompareArrays)
+
+	t.Run("include twice", func(t *testing.T) {
+		utils.AssertNoError(t, includeModule(derived.(*class), first.(*class)))
+		utils.AssertEqual(t, len(derived.(*class).includes), 2)
+	})
+	t.Run("cyclic include", func(t *testing.T) {
+		err := includeModule(shared.(*class), first.(*class))
+
+		utils.AssertError(t, err, NewArgumentError("cyclic include detected"))
+	})
+}
+
+func TestLookupMethodThroughModules(t *testing.T) {
+	env := NewEnvironment()
+	foo, _ := OpenClass(env, "Foo", nil)
+	mixin, _ := OpenModule(env, "Mixin")
+	mixin.(*class).addMethod("hello", withArity(0, newMethod(objectInitialize)))
+
+	instance, err := foo.New()
+	utils.AssertNoError(t, err)
+	utils.Assert(t, !RespondTo(instance, "hello"), "Expected instance not to respond to hello")
+
+	utils.AssertNoError(t, includeModule(foo.(*class), mixin.(*class)))
+
+	utils.Assert(t, RespondTo(instance, "hello"), "Expected instance to respond to hello")
+	utils.Assert(t, IsKindOf(instance, mixin), "Expected instance to be a Mixin")
+
+	t.Run("extend", func(t *testing.T) {
+		other, _ := OpenClass(env, "Other", nil)
+		instance, err := other.New()
+		utils.AssertNoError(t, err)
+
+		utils.AssertNoError(t, extendObject(instance, mixin.(*class)))
+
+		utils.Assert(t, RespondTo(instance, "hello"), "Expected instance to respond to hello")
+		utils.Assert(t, !RespondTo(other, "hello"), "Expected class not to respond to hello")
+	})
+	t.Run("extend builtin object", func(t *testing.T) {
+		err := extendObject(NewInteger(1), mixin.(*class))
+
+		utils.AssertError(t, err, NewTypeError("can't define singleton"))
+	})
+}
+
+func TestConstants(t *testing.T) {
+	env := NewEnvironment()
+	outer, _ := OpenModule(env, "Outer")
+	inner, err := OpenClass(outer.(Environment), "Inner", nil)
+	utils.AssertNoError(t, err)
+	utils.AssertEqual(t, inner.Name(), "Outer::Inner")
+
+	outer.(Environment).Set("VALUE", NewInteger(1))
+	mixin, _ := OpenModule(env, "Mixin")
+	mixin.(Environment).Set("MIXED", NewInteger(2))
+	utils.AssertNoError(t, includeModule(inner.(*class), mixin.(*class)))
+
+	t.Run("scoped", func(t *testing.T) {
+		value, err := ScopedConstant(outer, "Inner")
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, value, RubyObject(inner))
+
+		value, err = ScopedConstant(inner, "MIXED")
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, value, NewInteger(2), CompareRubyObjectsForTests)
+
+		_, err = ScopedConstant(outer, "Missing")
+		utils.AssertError(t, err, NewUninitializedConstantNameError("Outer::Missing"))
+
+		_, err = ScopedConstant(NewInteger(1), "Missing")
+		utils.AssertError(t, err, NewTypeError("1 is not a class/module"))
+	})
+	t.Run("lexical", func(t *testing.T) {
+		nesting := []RubyObject{inner, outer}
+
+		value, ok := LookupConstant(nesting, "VALUE")
+		utils.Assert(t, ok, "Expected VALUE to be found")
+		utils.AssertEqualCmpAny(t, value, NewInteger(1), CompareRubyObjectsForTests)
+
+		value, ok = LookupConstant(nesting, "MIXED")
+		utils.Assert(t, ok, "Expected MIXED to be found")
+		utils.AssertEqualCmpAny(t, value, NewInteger(2), CompareRubyObjectsForTests)
+
+		_, ok = LookupConstant(nesting, "Missing")
+		utils.Assert(t, !ok, "Expected Missing not to be found")
+	})
+	t.Run("not a module", func(t *testing.T) {
+		_, err := OpenModule(outer.(Environment), "Inner")
+		utils.AssertError(t, err, NewTypeError("Inner is not a module"))
+	})
+}
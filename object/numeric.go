@@ -0,0 +1,471 @@
+package object
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+// numericClass is the superclass of the builtin numbers. User defined
+// classes may inherit from it as well, and take part in arithmetic with the
+// builtin numbers by implementing coerce.
+var numericClass = newSubclass(
+	objectClass, "Numeric", numericMethods, nil, objectClass.builder,
+)
+
+func init() {
+	CLASSES.Set("Numeric", numericClass)
+}
+
+var numericMethods = map[string]RubyMethod{
+	"coerce":    withArity(1, newMethod(numericCoerce)),
+	"==":        withArity(1, newMethod(numericEqual)),
+	"-@":        withArity(0, newMethod(numericNegate)),
+	"+@":        withArity(0, newMethod(numericIdentity)),
+	"integer?":  withArity(0, newMethod(numericIsInteger)),
+	"real?":     withArity(0, newMethod(numericIsReal)),
+	"to_c":      withArity(0, newMethod(numericToC)),
+	"zero?":     withArity(0, newMethod(numericIsZero)),
+	"positive?": withArity(0, newMethod(numericIsPositive)),
+	"negative?": withArity(0, newMethod(numericIsNegative)),
+	"clamp":     newMethod(numericClamp),
+	"between?":  withArity(2, newMethod(numericBetween)),
+	"step":      newMethod(numericStep),
+}
+
+// isBuiltinNumber reports whether obj is an Integer, a Float, a Rational or a
+// Complex
+func isBuiltinNumber(obj RubyObject) bool {
+	switch obj.(type) {
+	case *Integer, *Float, *Rational, *Complex:
+		return true
+	default:
+		return false
+	}
+}
+
+// coerce returns the pair of numbers which arg converts itself and the
+// receiver of context to by `arg.coerce(receiver)`
+func coerce(context CallContext, tracer trace.Tracer, arg RubyObject) (RubyObject, RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	pair, err := Send(withReceiver(context, arg), "coerce", tracer, context.Receiver())
+	if err != nil {
+		return nil, nil, err
+	}
+	array, ok := pair.(*Array)
+	if !ok || len(array.Elements) != 2 {
+		return nil, nil, NewTypeError("coerce must return [x, y]")
+	}
+	return array.Elements[0], array.Elements[1], nil
+}
+
+// coerceBinop applies the arithmetic operator op to the receiver of context
+// and arg, which is of a class the receiver does not know. Both are coerced
+// by arg to a common class first, e.g. `1 + Rational(1, 2)` is
+// `Rational(1, 1) + Rational(1, 2)`.
+func coerceBinop(context CallContext, tracer trace.Tracer, op string, arg RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if !RespondTo(arg, "coerce") {
+		return nil, NewCoercionTypeError(arg, context.Receiver())
+	}
+	left, right, err := coerce(context, tracer, arg)
+	if err != nil {
+		return nil, err
+	}
+	return Send(withReceiver(context, left), op, tracer, right)
+}
+
+// coerceRelop is coerceBinop for the comparison operator op, which fails
+// with an ArgumentError if arg can't be coerced
+func coerceRelop(context CallContext, tracer trace.Tracer, op string, arg RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if RespondTo(arg, "coerce") {
+		left, right, err := coerce(context, tracer, arg)
+		if err == nil {
+			return Send(withReceiver(context, left), op, tracer, right)
+		}
+	}
+	return nil, NewArgumentError(
+		"comparison of %s with %s failed",
+		context.Receiver().Class().(RubyObject).Inspect(),
+		arg.Class().(RubyObject).Inspect(),
+	)
+}
+
+// coerceCmp is coerceBinop for <=>, which returns nil if arg can't be
+// coerced
+func coerceCmp(context CallContext, tracer trace.Tracer, arg RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if !RespondTo(arg, "coerce") {
+		return NIL, nil
+	}
+	left, right, err := coerce(context, tracer, arg)
+	if err != nil {
+		return NIL, nil
+	}
+	return Send(withReceiver(context, left), "<=>", tracer, right)
+}
+
+// numberToFloat converts the number obj to a Float, using to_f for numbers
+// which are not builtin
+func numberToFloat(context CallContext, tracer trace.Tracer, obj RubyObject) (*Float, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if value, ok := safeObjectToFloat(obj); ok {
+		return NewFloat(value), nil
+	}
+	if _, ok := obj.(*Complex); !ok && RespondTo(obj, "to_f") {
+		converted, err := Send(withReceiver(context, obj), "to_f", tracer)
+		if err != nil {
+			return nil, err
+		}
+		if f, ok := converted.(*Float); ok {
+			return f, nil
+		}
+	}
+	return nil, NewTypeError(fmt.Sprintf("can't convert %s into Float", obj.Class().(RubyObject).Inspect()))
+}
+
+// numericCoerce returns the argument and the receiver as numbers of the same
+// class, which are Floats unless both already are of the same class, e.g.
+// `1.coerce(2.5)` is `[2.5, 1.0]`
+func numericCoerce(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	receiver := context.Receiver()
+	if receiver.Class() == args[0].Class() {
+		return NewArray(args[0], receiver), nil
+	}
+	left, err := numberToFloat(context, tracer, args[0])
+	if err != nil {
+		return nil, err
+	}
+	right, err := numberToFloat(context, tracer, receiver)
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(left, right), nil
+}
+
+// numericEqual compares numbers by value, e.g. `1 == 1.0`. Objects which are
+// not builtin numbers are asked whether they equal the receiver instead.
+func numericEqual(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if RubyObjectsEqual(context.Receiver(), args[0]) {
+		return TRUE, nil
+	}
+	if isBuiltinNumber(args[0]) || !isBuiltinNumber(context.Receiver()) {
+		return FALSE, nil
+	}
+	equal, err := Send(withReceiver(context, args[0]), "==", tracer, context.Receiver())
+	if err != nil {
+		return nil, err
+	}
+	if equal == NIL || equal == FALSE {
+		return FALSE, nil
+	}
+	return TRUE, nil
+}
+
+// numericNegate returns the receiver subtracted from zero, both coerced by
+// the receiver
+func numericNegate(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	left, right, err := coerce(withReceiver(context, NewInteger(0)), tracer, context.Receiver())
+	if err != nil {
+		return nil, err
+	}
+	return Send(withReceiver(context, left), "-", tracer, right)
+}
+
+func numericIdentity(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return context.Receiver(), nil
+}
+
+func numericIsInteger(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if _, ok := context.Receiver().(*Integer); ok {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func numericIsReal(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if _, ok := context.Receiver().(*Complex); ok {
+		return FALSE, nil
+	}
+	return TRUE, nil
+}
+
+// numericToC returns the receiver as the real part of a Complex
+func numericToC(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	switch receiver := context.Receiver().(type) {
+	case *Complex:
+		return receiver, nil
+	case *Integer, *Float, *Rational:
+		return NewComplex(receiver, NewInteger(0)), nil
+	default:
+		return nil, NewTypeError(fmt.Sprintf("can't convert %s into Complex", receiver.Class().(RubyObject).Inspect()))
+	}
+}
+
+// compareNumbers returns the sign of `a <=> b`, or an ArgumentError if a and b
+// can't be compared
+func compareNumbers(context CallContext, tracer trace.Tracer, a, b RubyObject) (int, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	result, err := Send(withReceiver(context, a), "<=>", tracer, b)
+	if err != nil {
+		return 0, err
+	}
+	sign, ok := result.(*Integer)
+	if !ok {
+		return 0, NewArgumentError(
+			"comparison of %s with %s failed",
+			a.Class().(RubyObject).Inspect(),
+			b.Class().(RubyObject).Inspect(),
+		)
+	}
+	return sign.Sign(), nil
+}
+
+// numericSign returns the sign of the receiver, as compared to zero
+func numericSign(context CallContext, tracer trace.Tracer) (int, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return compareNumbers(context, tracer, context.Receiver(), NewInteger(0))
+}
+
+func numericIsZero(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if RubyObjectsEqual(context.Receiver(), NewInteger(0)) {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func numericIsPositive(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	sign, err := numericSign(context, tracer)
+	if err != nil {
+		return nil, err
+	}
+	if sign > 0 {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func numericIsNegative(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	sign, err := numericSign(context, tracer)
+	if err != nil {
+		return nil, err
+	}
+	if sign < 0 {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+// numericClamp returns the receiver limited to the bounds given either as min
+// and max or as an inclusive Range, e.g. `15.clamp(1, 10)` is 10
+func numericClamp(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	var min, max RubyObject
+	switch len(args) {
+	case 1:
+		rng, ok := args[0].(*Range)
+		if !ok {
+			return nil, NewTypeError(fmt.Sprintf("wrong argument type %s (expected Range)", args[0].Class().(RubyObject).Inspect()))
+		}
+		if !rng.Inclusive {
+			return nil, NewArgumentError("cannot clamp with an exclusive range")
+		}
+		min, max = NewInteger(rng.Left), NewInteger(rng.Right)
+	case 2:
+		min, max = args[0], args[1]
+	default:
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	order, err := compareNumbers(context, tracer, min, max)
+	if err != nil {
+		return nil, err
+	}
+	if order > 0 {
+		return nil, NewArgumentError("min argument must be less than or equal to max argument")
+	}
+	receiver := context.Receiver()
+	if order, err = compareNumbers(context, tracer, receiver, min); err != nil || order < 0 {
+		return min, err
+	}
+	if order, err = compareNumbers(context, tracer, receiver, max); err != nil || order > 0 {
+		return max, err
+	}
+	return receiver, nil
+}
+
+// numericBetween reports whether the receiver lies between the arguments,
+// both inclusive
+func numericBetween(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	receiver := context.Receiver()
+	above, err := compareNumbers(context, tracer, receiver, args[0])
+	if err != nil {
+		return nil, err
+	}
+	below, err := compareNumbers(context, tracer, receiver, args[1])
+	if err != nil {
+		return nil, err
+	}
+	if above >= 0 && below <= 0 {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+// numericStep yields the numbers from the receiver up to the optional limit,
+// inclusive, adding the optional step, 1 by default. It counts down for a
+// negative step and forever without a limit.
+func numericStep(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	args, fn, err := blockArgument("step", args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	receiver := context.Receiver()
+	limit, step := RubyObject(NIL), RubyObject(NewInteger(1))
+	if len(args) > 0 {
+		limit = args[0]
+	}
+	if len(args) > 1 {
+		step = args[1]
+	}
+	direction, err := compareNumbers(context, tracer, step, NewInteger(0))
+	if err != nil {
+		return nil, err
+	}
+	if direction == 0 {
+		return nil, NewArgumentError("step can't be 0")
+	}
+	for _, arg := range []RubyObject{receiver, limit, step} {
+		if _, ok := arg.(*Float); ok {
+			return receiver, floatStep(context, tracer, receiver, limit, step, fn)
+		}
+	}
+	past := ">"
+	if direction < 0 {
+		past = "<"
+	}
+	for n := receiver; ; {
+		if limit != NIL {
+			done, err := Send(withReceiver(context, n), past, tracer, limit)
+			if err != nil {
+				return nil, err
+			}
+			if done == TRUE {
+				return receiver, nil
+			}
+		}
+		if _, err := fn.Call(context, tracer, n); err != nil {
+			return nil, err
+		}
+		if n, err = Send(withReceiver(context, n), "+", tracer, step); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// floatStep is numericStep for Floats. Each value is computed from the start
+// rather than by adding up steps, so that rounding errors don't accumulate,
+// and the last one is the limit if it is within a rounding error of it.
+func floatStep(context CallContext, tracer trace.Tracer, from, limit, step RubyObject, fn RubyMethod) error {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	var values [3]float64
+	for i, arg := range []RubyObject{from, limit, step} {
+		if arg == NIL {
+			continue
+		}
+		f, err := numberToFloat(context, tracer, arg)
+		if err != nil {
+			return err
+		}
+		values[i] = f.Value
+	}
+	beg, end, unit := values[0], values[1], values[2]
+	if limit == NIL {
+		end = math.Inf(1)
+		if unit < 0 {
+			end = math.Inf(-1)
+		}
+	}
+	var n float64
+	if math.IsInf(unit, 0) {
+		if (unit > 0 && beg <= end) || (unit < 0 && beg >= end) {
+			n = 1
+		}
+	} else {
+		n = (end - beg) / unit
+		rounding := math.Min((math.Abs(beg)+math.Abs(end)+math.Abs(end-beg))/math.Abs(unit)*0x1p-52, 0.5)
+		if n >= 0 {
+			n = math.Floor(n+rounding) + 1
+		} else {
+			n = 0
+		}
+	}
+	for i := float64(0); i < n; i++ {
+		d := i*unit + beg
+		if (unit >= 0 && end < d) || (unit < 0 && d < end) {
+			d = end
+		}
+		if _, err := fn.Call(context, tracer, NewFloat(d)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+var objectClass = newClass(
+	"Object",
+	objectMethods,
+	nil,
+	func(c RubyClassObject, args ...RubyObject) (RubyObject, error) {
+		return &Object{class: c}, nil
+	},
+)
+
+func init() {
+	CLASSES.Set("Object", objectClass)
+}
+
+// An Object is an instance of a user defined class
+type Object struct {
+	class     RubyClass
+	singleton *eigenclass // singleton methods and extending modules, if any
+	instanceVariables
+}
+
+// Inspect returns the class name, the object address and the instance
+// variables of the object, e.g. #<Foo:0xc000010000 @bar=1>
+func (o *Object) Inspect() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "#<%s:%p", o.class.Name(), o)
+	for i, name := range o.names {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		fmt.Fprintf(&out, " %s=%s", name, o.values[name].Inspect())
+	}
+	out.WriteString(">")
+	return out.String()
+}
+func (o *Object) Class() RubyClass {
+	if o.singleton != nil {
+		return o.singleton
+	}
+	return o.class
+}
+func (o *Object) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%p", o)))
+	return HashKey(h.Sum64())
+}
+
+var (
+	_ RubyObject = &Object{}
+)
+
+var objectMethods = map[string]RubyMethod{
+	"initialize": withArity(0, newMethod(objectInitialize)),
+}
+
+func objectInitialize(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return NIL, nil
+}
+
+// instanceVariables holds the instance variables of an object in the order
+// they were first assigned
+type instanceVariables struct {
+	names  []string
+	values map[string]RubyObject
+}
+
+func (iv *instanceVariables) instanceVariableGet(name string) (RubyObject, bool) {
+	value, ok := iv.values[name]
+	return value, ok
+}
+
+func (iv *instanceVariables) instanceVariableSet(name string, value RubyObject) {
+	if iv.values == nil {
+		iv.values = make(map[string]RubyObject)
+	}
+	if _, ok := iv.values[name]; !ok {
+		iv.names = append(iv.names, name)
+	}
+	iv.values[name] = value
+}
+
+type instanceVariableHolder interface {
+	instanceVariableGet(name string) (RubyObject, bool)
+	instanceVariableSet(name string, value RubyObject)
+}
+
+// InstanceVariableGet returns the instance variable name of obj. Variables
+// which were never assigned are nil.
+func InstanceVariableGet(obj RubyObject, name string) RubyObject {
+	if holder, ok := obj.(instanceVariableHolder); ok {
+		if value, ok := holder.instanceVariableGet(name); ok {
+			return value
+		}
+	}
+	return NIL
+}
+
+// InstanceVariableSet assigns value to the instance variable name of obj. Only
+// user defined objects, classes and exceptions can hold instance variables.
+func InstanceVariableSet(obj RubyObject, name string, value RubyObject) error {
+	holder, ok := obj.(instanceVariableHolder)
+	if !ok {
+		return NewRuntimeError("can't modify frozen %s: %s", obj.Class().Name(), obj.Inspect())
+	}
+	holder.instanceVariableSet(name, value)
+	return nil
+}
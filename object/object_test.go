@@ -0,0 +1,115 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestInstanceVariables(t *testing.T) {
+	obj := &Object{class: objectClass}
+
+	utils.AssertEqual(t, InstanceVariableGet(obj, "@foo"), RubyObject(NIL))
+
+	err := InstanceVariableSet(obj, "@foo", NewInteger(1))
+	utils.AssertNoError(t, err)
+	err = InstanceVariableSet(obj, "@bar", NewInteger(2))
+	utils.AssertNoError(t, err)
+	err = InstanceVariableSet(obj, "@foo", NewInteger(3))
+	utils.AssertNoError(t, err)
+
+	utils.AssertEqualCmpAny(t, InstanceVariableGet(obj, "@foo"), NewInteger(3), CompareRubyObjectsForTests)
+	utils.AssertEqualCmp(t, obj.names, []string{"@foo", "@bar"}, utils.CompareArrays)
+
+	t.Run("not a holder", func(t *testing.T) {
+		err := InstanceVariableSet(NewInteger(1), "@foo", NIL)
+
+		utils.AssertError(t, err, NewRuntimeError("can't modify frozen Integer: 1"))
+	})
+}
+
+func TestOpenClass(t *testing.T) {
+	env := NewEnvironment()
+
+	foo, err := OpenClass(env, "Foo", nil)
+	utils.AssertNoError(t, err)
+	utils.AssertEqual(t, superClassOf(foo), RubyClass(objectClass))
+
+	bar, err := OpenClass(env, "Bar", foo)
+	utils.AssertNoError(t, err)
+	utils.AssertEqual(t, superClassOf(bar), RubyClass(foo))
+
+	t.Run("reopen", func(t *testing.T) {
+		reopened, err := OpenClass(env, "Bar", nil)
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, reopened, bar)
+
+		reopened, err = OpenClass(env, "Bar", foo)
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, reopened, bar)
+	})
+	t.Run("superclass mismatch", func(t *testing.T) {
+		_, err := OpenClass(env, "Bar", objectClass)
+
+		utils.AssertError(t, err, NewTypeError("superclass mismatch for class Bar"))
+	})
+	t.Run("not a class", func(t *testing.T) {
+		env.Set("Baz", NewInteger(1))
+		_, err := OpenClass(env, "Baz", nil)
+
+		utils.AssertError(t, err, NewTypeError("Baz is not a class"))
+	})
+	t.Run("superclass not a class", func(t *testing.T) {
+		_, err := OpenClass(env, "Qux", NewInteger(1))
+
+		utils.AssertError(t, err, NewTypeError("superclass must be a Class"))
+	})
+}
+
+func TestClassNewInstance(t *testing.T) {
+	env := NewEnvironment()
+	foo, err := OpenClass(env, "Foo", nil)
+	utils.AssertNoError(t, err)
+
+	context := &callContext{receiver: foo, env: env}
+	names, err := moduleAttrAccessor(context, nil, NewSymbol("bar"))
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, names, NewArray(NewSymbol("bar"), NewSymbol("bar=")), CompareRubyObjectsForTests)
+
+	result, err := classNewInstance(context, nil)
+	utils.AssertNoError(t, err)
+
+	instance, ok := result.(*Object)
+	utils.Assert(t, ok, "Expected *Object, got %T", result)
+	utils.AssertEqual(t, instance.Class(), RubyClass(foo))
+
+	_, err = Send(&callContext{receiver: instance, env: env}, "bar=", nil, NewInteger(5))
+	utils.AssertNoError(t, err)
+
+	value, err := Send(&callContext{receiver: instance, env: env}, "bar", nil)
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, value, NewInteger(5), CompareRubyObjectsForTests)
+
+	t.Run("initialize arity", func(t *testing.T) {
+		_, err := classNewInstance(context, nil, NewInteger(1))
+
+		utils.AssertError(t, err, NewWrongNumberOfArgumentsError(0, 1))
+	})
+}
+
+func TestRespondToInheritedMethod(t *testing.T) {
+	env := NewEnvironment()
+	foo, err := OpenClass(env, "Foo", nil)
+	utils.AssertNoError(t, err)
+	bar, err := OpenClass(env, "Bar", foo)
+	utils.AssertNoError(t, err)
+
+	foo.(*class).addMethod("hello", withArity(0, newMethod(objectInitialize)))
+
+	instance, err := bar.New()
+	utils.AssertNoError(t, err)
+
+	utils.Assert(t, RespondTo(instance, "hello"), "Expected instance to respond to hello")
+	utils.Assert(t, RespondTo(instance, "initialize"), "Expected instance to respond to initialize")
+	utils.Assert(t, !RespondTo(instance, "goodbye"), "Expected instance not to respond to goodbye")
+}
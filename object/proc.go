@@ -0,0 +1,283 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+var procClass RubyClassObject = newClass(
+	"Proc",
+	procMethods,
+	procClassMethods,
+	notInstantiatable,
+)
+
+func init() {
+	CLASSES.Set("Proc", procClass)
+}
+
+// NewProc returns a Proc calling function
+func NewProc(function *Function) *Proc {
+	return &Proc{Function: function}
+}
+
+// NewLambda returns a Proc calling function with the semantics of a lambda,
+// i.e. a strict arity and return and break leaving the proc itself
+func NewLambda(function *Function) *Proc {
+	return &Proc{Function: function.asLambda()}
+}
+
+// NewBuiltinProc returns a Proc calling the Go function call, with the
+// semantics of a block taking any number of arguments
+func NewBuiltinProc(call func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error)) *Proc {
+	return &Proc{call: call, arity: -1}
+}
+
+// A Proc is a block or lambda turned into an object. Procs created by curry
+// or composition have no function of their own but call other procs.
+type Proc struct {
+	Function *Function
+	call     func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error)
+	lambda   bool
+	arity    int
+}
+
+func (p *Proc) Inspect() string {
+	if p.IsLambda() {
+		return fmt.Sprintf("#<Proc:%p (lambda)>", p)
+	}
+	return fmt.Sprintf("#<Proc:%p>", p)
+}
+func (p *Proc) Class() RubyClass { return procClass }
+func (p *Proc) HashKey() HashKey {
+	h := fnv.New64a()
+	if p.Function != nil {
+		h.Write([]byte(fmt.Sprintf("%p", p.Function)))
+	} else {
+		h.Write([]byte(fmt.Sprintf("%p", p)))
+	}
+	return HashKey(h.Sum64())
+}
+
+// IsLambda returns true if p has the semantics of a lambda
+func (p *Proc) IsLambda() bool {
+	if p.Function != nil {
+		return p.Function.IsLambda()
+	}
+	return p.lambda
+}
+
+// Arity returns the number of arguments p takes, or its one's complement if
+// the number of arguments is variable
+func (p *Proc) Arity() int {
+	if p.Function != nil {
+		return functionParameters(p.Function.Parameters).arity()
+	}
+	return p.arity
+}
+
+// Call implements the RubyMethod interface. It calls the function of p with
+// args.
+func (p *Proc) Call(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if p.call != nil {
+		return p.call(context, tracer, args...)
+	}
+	return p.Function.Call(context, tracer, args...)
+}
+
+var (
+	_ RubyObject = &Proc{}
+	_ RubyMethod = &Proc{}
+)
+
+// blockMethod returns the block a builtin method receives as its argument,
+// either the name of a block literal or a proc passed with &
+func blockMethod(arg RubyObject) (RubyMethod, bool) {
+	switch arg := arg.(type) {
+	case *Proc:
+		return arg, true
+	case *Symbol:
+		return FUNCS_STORE.GetMethod(arg.Value)
+	default:
+		return nil, false
+	}
+}
+
+// blockArgument splits the arguments of the builtin iterator called name into
+// its arguments and the block passed last
+func blockArgument(name string, args []RubyObject) ([]RubyObject, RubyMethod, error) {
+	if len(args) == 0 {
+		return nil, nil, NewArgumentError("%s requires a block", name)
+	}
+	fn, ok := blockMethod(args[len(args)-1])
+	if !ok {
+		return nil, nil, NewArgumentError("%s requires a block", name)
+	}
+	return args[:len(args)-1], fn, nil
+}
+
+var procClassMethods = map[string]RubyMethod{
+	"new": newMethod(procNew),
+}
+
+var procMethods = map[string]RubyMethod{
+	"call":       newMethod(procCall),
+	"[]":         newMethod(procCall),
+	"===":        newMethod(procCall),
+	"yield":      newMethod(procCall),
+	"arity":      withArity(0, newMethod(procArity)),
+	"parameters": withArity(0, newMethod(procParameters)),
+	"lambda?":    withArity(0, newMethod(procIsLambda)),
+	"curry":      newMethod(procCurry),
+	">>":         withArity(1, newMethod(procComposeRight)),
+	"<<":         withArity(1, newMethod(procComposeLeft)),
+	"to_proc":    withArity(0, newMethod(procToProc)),
+}
+
+func procNew(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	block := blockOf(context)
+	if block == nil {
+		return nil, NewArgumentError("tried to create Proc object without a block")
+	}
+	return block, nil
+}
+
+func procCall(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	proc, _ := context.Receiver().(*Proc)
+	return proc.Call(context, tracer, withoutBlockArgument(args, blockOf(context))...)
+}
+
+func procToProc(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return context.Receiver(), nil
+}
+
+func procArity(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	proc, _ := context.Receiver().(*Proc)
+	return NewInteger(int64(proc.Arity())), nil
+}
+
+func procParameters(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	proc, _ := context.Receiver().(*Proc)
+	if proc.Function == nil {
+		return NewArray(NewArray(NewSymbol("rest"))), nil
+	}
+	return functionParameters(proc.Function.Parameters).describe(proc.IsLambda()), nil
+}
+
+func procIsLambda(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	proc, _ := context.Receiver().(*Proc)
+	if proc.IsLambda() {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func procCurry(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	proc, _ := context.Receiver().(*Proc)
+	arity := proc.Arity()
+	switch len(args) {
+	case 0:
+		if arity < 0 {
+			arity = -arity - 1
+		}
+	case 1:
+		n, ok := args[0].(*Integer)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(n, args[0])
+		}
+		if proc.IsLambda() && (arity >= 0 && int(n.Value) != arity || arity < 0 && int(n.Value) < -arity-1) {
+			return nil, NewWrongNumberOfArgumentsError(arity, int(n.Value))
+		}
+		arity = int(n.Value)
+	default:
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	return curry(proc, arity, nil), nil
+}
+
+// curry returns a proc collecting arguments for proc until there are arity
+// of them, prepended by the ones collected so far
+func curry(proc *Proc, arity int, collected []RubyObject) *Proc {
+	return &Proc{
+		lambda: proc.IsLambda(),
+		arity:  -1,
+		call: func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+			args = append(collected[:len(collected):len(collected)], args...)
+			if len(args) >= arity {
+				return proc.Call(context, tracer, args...)
+			}
+			return curry(proc, arity, args), nil
+		},
+	}
+}
+
+func procComposeRight(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	proc, _ := context.Receiver().(*Proc)
+	return compose(proc.IsLambda(), proc, args[0])
+}
+
+func procComposeLeft(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	proc, _ := context.Receiver().(*Proc)
+	return compose(proc.IsLambda(), args[0], proc)
+}
+
+// compose returns a proc passing its arguments to first and the result on
+// to second. Both have to respond to call.
+func compose(lambda bool, first, second RubyObject) (RubyObject, error) {
+	for _, callable := range []RubyObject{first, second} {
+		if !RespondTo(callable, "call") {
+			return nil, NewTypeError("callable object is expected")
+		}
+	}
+	arity := -1
+	switch first := first.(type) {
+	case *Proc:
+		arity = first.Arity()
+	case *Method:
+		arity = first.Arity()
+	}
+	return &Proc{
+		lambda: lambda,
+		arity:  arity,
+		call: func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+			context = withoutBlock(context)
+			result, err := Send(withReceiver(context, first), "call", tracer, args...)
+			if err != nil {
+				return nil, err
+			}
+			return Send(withReceiver(context, second), "call", tracer, result)
+		},
+	}, nil
+}
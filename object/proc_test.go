@@ -0,0 +1,165 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestProcArity(t *testing.T) {
+	tests := []struct {
+		params []*FunctionParameter
+		arity  int
+	}{
+		{nil, 0},
+		{[]*FunctionParameter{{Name: "x"}}, 1},
+		{[]*FunctionParameter{{Name: "x"}, {Name: "y"}}, 2},
+		{[]*FunctionParameter{{Name: "x"}, {Name: "y", Default: NIL}}, -2},
+		{[]*FunctionParameter{{Name: "x", Default: NIL}}, -1},
+		{[]*FunctionParameter{{Name: "x"}, {Name: "rest", Splat: true}}, -2},
+		{[]*FunctionParameter{{Name: "x"}, {Name: "blk", Block: true}}, 1},
+		{[]*FunctionParameter{{Name: "x"}, {Name: "k", Keyword: true, Default: NIL}}, -2},
+		{[]*FunctionParameter{{Name: "x"}, {Name: "k", Keyword: true}, {Name: "o", Keyword: true, Default: NIL}}, 2},
+		{[]*FunctionParameter{{Name: "opts", DoubleSplat: true}}, -1},
+	}
+
+	for _, tt := range tests {
+		proc := NewProc(&Function{Name: "__block_abc", Parameters: tt.params})
+		context := &callContext{receiver: proc}
+
+		result, err := procArity(context, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(int64(tt.arity)), CompareRubyObjectsForTests)
+	}
+}
+
+func TestProcParameters(t *testing.T) {
+	params := []*FunctionParameter{
+		{Name: "x"},
+		{Name: "y", Default: NIL},
+		{Name: "z", Splat: true},
+		{Name: "k", Keyword: true},
+		{Name: "o", Keyword: true, Default: NIL},
+		{Name: "r", DoubleSplat: true},
+		{Name: "b", Block: true},
+	}
+	describe := func(kinds ...string) *Array {
+		described := NewArray()
+		for i, kind := range kinds {
+			described.Elements = append(described.Elements, NewArray(NewSymbol(kind), NewSymbol(params[i].Name)))
+		}
+		return described
+	}
+
+	t.Run("proc", func(t *testing.T) {
+		context := &callContext{receiver: NewProc(&Function{Name: "__block_abc", Parameters: params})}
+
+		result, err := procParameters(context, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, describe("opt", "opt", "rest", "keyreq", "key", "keyrest", "block"), CompareRubyObjectsForTests)
+	})
+	t.Run("lambda", func(t *testing.T) {
+		context := &callContext{receiver: NewLambda(&Function{Name: "__block_abc", Parameters: params})}
+
+		result, err := procParameters(context, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, describe("req", "opt", "rest", "keyreq", "key", "keyrest", "block"), CompareRubyObjectsForTests)
+	})
+}
+
+func TestProcIsLambda(t *testing.T) {
+	block := &Function{Name: "__block_abc"}
+
+	tests := []struct {
+		proc   *Proc
+		lambda bool
+	}{
+		{NewProc(block), false},
+		{NewLambda(block), true},
+		{NewProc(&Function{Name: "__lambda_abc"}), true},
+	}
+
+	for _, tt := range tests {
+		context := &callContext{receiver: tt.proc}
+
+		result, err := procIsLambda(context, nil)
+
+		utils.AssertNoError(t, err)
+		boolean, _ := SymbolToBool(result)
+		utils.AssertEqual(t, boolean, tt.lambda)
+	}
+
+	utils.AssertEqual(t, block.Name, "__block_abc")
+}
+
+// testProc returns a lambda calling fn with the integer values of its
+// arguments
+func testProc(arity int, fn func(args ...int64) int64) *Proc {
+	return &Proc{
+		lambda: true,
+		arity:  arity,
+		call: func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+			values := make([]int64, len(args))
+			for i, arg := range args {
+				values[i] = arg.(*Integer).Value
+			}
+			return NewInteger(fn(values...)), nil
+		},
+	}
+}
+
+func TestProcCurry(t *testing.T) {
+	sum := testProc(3, func(args ...int64) int64 { return args[0] + args[1] + args[2] })
+
+	t.Run("collects arguments up to the arity", func(t *testing.T) {
+		curried, err := procCurry(&callContext{receiver: sum}, nil)
+		utils.AssertNoError(t, err)
+
+		result, err := procCall(&callContext{receiver: curried}, nil, NewInteger(1))
+		utils.AssertNoError(t, err)
+		_, ok := result.(*Proc)
+		utils.Assert(t, ok, "Expected Proc, got %T", result)
+
+		result, err = procCall(&callContext{receiver: result}, nil, NewInteger(2), NewInteger(3))
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(6), CompareRubyObjectsForTests)
+	})
+	t.Run("lambda with a different arity", func(t *testing.T) {
+		_, err := procCurry(&callContext{receiver: sum}, nil, NewInteger(2))
+
+		utils.AssertError(t, err, NewWrongNumberOfArgumentsError(3, 2))
+	})
+}
+
+func TestProcComposition(t *testing.T) {
+	inc := testProc(1, func(args ...int64) int64 { return args[0] + 1 })
+	double := testProc(1, func(args ...int64) int64 { return args[0] * 2 })
+
+	t.Run(">>", func(t *testing.T) {
+		composed, err := procComposeRight(&callContext{receiver: inc}, nil, double)
+		utils.AssertNoError(t, err)
+
+		result, err := procCall(&callContext{receiver: composed}, nil, NewInteger(3))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(8), CompareRubyObjectsForTests)
+	})
+	t.Run("<<", func(t *testing.T) {
+		composed, err := procComposeLeft(&callContext{receiver: inc}, nil, double)
+		utils.AssertNoError(t, err)
+
+		result, err := procCall(&callContext{receiver: composed}, nil, NewInteger(3))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(7), CompareRubyObjectsForTests)
+	})
+	t.Run("not callable", func(t *testing.T) {
+		_, err := procComposeRight(&callContext{receiver: inc}, nil, NewInteger(3))
+
+		utils.AssertError(t, err, NewTypeError("callable object is expected"))
+	})
+}
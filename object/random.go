@@ -0,0 +1,334 @@
+package object
+
+import (
+	"crypto/rand"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"sort"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+var randomClass RubyClassObject = newClass(
+	"Random",
+	randomMethods,
+	randomClassMethods,
+	notInstantiatable,
+)
+
+func init() {
+	CLASSES.Set("Random", randomClass)
+}
+
+// NewRandom returns a Random generator seeded with seed. Generators with the
+// same seed produce the same sequence of numbers.
+func NewRandom(seed *Integer) *Random {
+	return &Random{seed: seed, mt: newMersenneTwister(seed.BigInt())}
+}
+
+// Random represents a seedable pseudo random number generator in Ruby
+type Random struct {
+	seed *Integer
+	mt   *mersenneTwister
+}
+
+// Seed returns the seed the generator was created with
+func (r *Random) Seed() *Integer { return r.seed }
+
+// Inspect returns the generator's address
+func (r *Random) Inspect() string { return fmt.Sprintf("#<Random:%p>", r) }
+
+// Class returns randomClass
+func (r *Random) Class() RubyClass { return randomClass }
+
+// HashKey returns a hash key identifying the generator
+func (r *Random) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(r.Inspect()))
+	return HashKey(h.Sum64())
+}
+
+// defaultRandomKey is the name under which the root environment holds the
+// generator of Kernel#rand, Random.rand and of the methods which take a
+// `random:` keyword. It is no valid identifier, so Ruby code cannot reach it.
+const defaultRandomKey = "#random"
+
+// defaultGenerator returns the default generator of the interpreter context
+// runs in. It is seeded on first use.
+func defaultGenerator(context CallContext) *Random {
+	env := context.Env()
+	if env == nil {
+		return NewRandom(newSeed())
+	}
+	if random, ok := env.Get(defaultRandomKey); ok {
+		return random.(*Random)
+	}
+	random := NewRandom(newSeed())
+	env.SetGlobal(defaultRandomKey, random)
+	return random
+}
+
+// newSeed returns a seed of 128 bits from the operating system
+func newSeed() *Integer {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return NewBigInteger(new(big.Int).SetBytes(buf))
+}
+
+var randomClassMethods = map[string]RubyMethod{
+	"new":      newMethod(randomNew),
+	"rand":     newMethod(randomClassRand),
+	"srand":    newMethod(bottomSrand),
+	"new_seed": withArity(0, newMethod(randomNewSeed)),
+}
+
+var randomMethods = map[string]RubyMethod{
+	"rand":  newMethod(randomRand),
+	"bytes": withArity(1, newMethod(randomBytes)),
+	"seed":  withArity(0, newMethod(randomSeed)),
+}
+
+// randomSeedArgument converts a seed to an Integer, truncating Floats
+func randomSeedArgument(arg RubyObject) (*Integer, error) {
+	switch arg := arg.(type) {
+	case *Integer:
+		return arg, nil
+	case *Float:
+		return floatToInteger(arg.Value)
+	default:
+		return nil, NewImplicitConversionTypeError(NewInteger(0), arg)
+	}
+}
+
+func randomNew(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	switch len(args) {
+	case 0:
+		return NewRandom(newSeed()), nil
+	case 1:
+		seed, err := randomSeedArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewRandom(seed), nil
+	default:
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+}
+
+func randomNewSeed(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return newSeed(), nil
+}
+
+func randomClassRand(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return randomValue(defaultGenerator(context), args)
+}
+
+func randomRand(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	random, _ := context.Receiver().(*Random)
+	return randomValue(random, args)
+}
+
+// randomValue implements Random#rand. Without an argument it returns a float
+// in [0, 1), for an Integer or a Float max a number in [0, max), and for a
+// Range one of its members. Unlike Kernel#rand it rejects any argument which
+// leaves nothing to choose from.
+func randomValue(random *Random, args []RubyObject) (RubyObject, error) {
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	if len(args) == 0 {
+		return NewFloat(random.mt.float64()), nil
+	}
+	switch max := args[0].(type) {
+	case *Integer:
+		if max.Sign() > 0 {
+			return randomInteger(random, max), nil
+		}
+	case *Float:
+		if max.Value > 0 {
+			return NewFloat(random.mt.float64() * max.Value), nil
+		}
+		if max.Value == 0 {
+			return NewFloat(random.mt.float64()), nil
+		}
+	case *Range:
+		if value, ok := randomRangeMember(random, max); ok {
+			return value, nil
+		}
+	}
+	return nil, NewArgumentError("invalid argument - %s", args[0].Inspect())
+}
+
+// randomInteger returns an integer in [0, max) for a positive max
+func randomInteger(random *Random, max *Integer) *Integer {
+	limit := subIntegers(max, NewInteger(1)).BigInt()
+	if limit.IsUint64() {
+		return NewBigInteger(new(big.Int).SetUint64(random.mt.limited(limit.Uint64())))
+	}
+	return NewBigInteger(random.mt.limitedBig(limit))
+}
+
+// randomRangeMember returns a random member of rng. It reports false if the
+// range is empty.
+func randomRangeMember(random *Random, rng *Range) (RubyObject, bool) {
+	size := subIntegers(NewInteger(rng.Right), NewInteger(rng.Left))
+	if rng.Inclusive {
+		size = addIntegers(size, NewInteger(1))
+	}
+	if size.Sign() <= 0 {
+		return nil, false
+	}
+	return addIntegers(NewInteger(rng.Left), randomInteger(random, size)), true
+}
+
+func randomBytes(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	random, _ := context.Receiver().(*Random)
+	n, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(NewInteger(0), args[0])
+	}
+	if n.Sign() < 0 {
+		return nil, NewArgumentError("negative string size (or size too big)")
+	}
+	return NewString(string(random.mt.bytes(int(n.Value)))), nil
+}
+
+func randomSeed(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	random, _ := context.Receiver().(*Random)
+	return random.seed, nil
+}
+
+// bottomRand implements Kernel#rand. It returns a float in [0, 1) unless max
+// is given. A Range yields one of its members, or nil if it is empty. Any
+// other max is truncated to an Integer and its absolute value is the
+// exclusive upper bound, with zero again meaning a float.
+func bottomRand(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	random := defaultGenerator(context)
+	if len(args) == 0 || args[0] == NIL {
+		return NewFloat(random.mt.float64()), nil
+	}
+	if rng, ok := args[0].(*Range); ok {
+		if value, ok := randomRangeMember(random, rng); ok {
+			return value, nil
+		}
+		return NIL, nil
+	}
+	max, err := randomSeedArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if max.Sign() == 0 {
+		return NewFloat(random.mt.float64()), nil
+	}
+	if max.Sign() < 0 {
+		max = max.Negate()
+	}
+	return randomInteger(random, max), nil
+}
+
+// bottomSrand implements Kernel#srand. It reseeds the generator of
+// Kernel#rand, with a new random seed if none is given, and returns the
+// previous seed.
+func bottomSrand(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	seed := newSeed()
+	if len(args) == 1 {
+		var err error
+		seed, err = randomSeedArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	previous := defaultGenerator(context).seed
+	if env := context.Env(); env != nil {
+		env.SetGlobal(defaultRandomKey, NewRandom(seed))
+	}
+	return previous, nil
+}
+
+// randomKeyword splits the `random:` keyword off the arguments of a method.
+// The generator defaults to the one of Kernel#rand.
+func randomKeyword(context CallContext, args []RubyObject) ([]RubyObject, RubyObject, error) {
+	if len(args) == 0 {
+		return args, defaultGenerator(context), nil
+	}
+	options, ok := args[len(args)-1].(*Hash)
+	if !ok || !options.keywords {
+		return args, defaultGenerator(context), nil
+	}
+	key := NewSymbol("random")
+	var unknown []RubyObject
+	for hashKey, pair := range options.Map {
+		if hashKey != key.HashKey() {
+			unknown = append(unknown, pair.Key)
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i].Inspect() < unknown[j].Inspect() })
+		return nil, nil, NewUnknownKeywordError(unknown...)
+	}
+	generator, ok := options.Get(key)
+	if !ok {
+		generator = defaultGenerator(context)
+	}
+	return args[:len(args)-1], generator, nil
+}
+
+// randomIndex returns an integer in [0, max) drawn from generator. Objects
+// other than Random are sent rand(max), which has to return an Integer in
+// that interval.
+func randomIndex(context CallContext, tracer trace.Tracer, generator RubyObject, max int) (int, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if random, ok := generator.(*Random); ok {
+		return int(random.mt.limited(uint64(max - 1))), nil
+	}
+	value, err := Send(withReceiver(context, generator), "rand", tracer, NewInteger(int64(max)))
+	if err != nil {
+		return 0, err
+	}
+	index, ok := value.(*Integer)
+	if !ok {
+		return 0, NewImplicitConversionTypeError(NewInteger(0), value)
+	}
+	if index.Sign() < 0 {
+		return 0, NewRangeError("random number too small %s", index.Inspect())
+	}
+	if compareIntegers(index, NewInteger(int64(max))) >= 0 {
+		return 0, NewRangeError("random number too big %s", index.Inspect())
+	}
+	return int(index.Value), nil
+}
@@ -0,0 +1,83 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func TestMersenneTwister(t *testing.T) {
+	t.Run("single word seed", func(t *testing.T) {
+		mt := newMersenneTwister(big.NewInt(42))
+
+		utils.AssertEqual(t, mt.uint32(), uint32(1608637542))
+		utils.AssertEqual(t, mt.uint32(), uint32(3421126067))
+	})
+	t.Run("multi word seed", func(t *testing.T) {
+		seed := new(big.Int).Lsh(big.NewInt(1), 100)
+
+		utils.AssertEqual(t, newMersenneTwister(seed).float64(), 0.7586581712996778)
+	})
+	t.Run("negative seed", func(t *testing.T) {
+		utils.AssertEqual(t, newMersenneTwister(big.NewInt(-42)).float64(), newMersenneTwister(big.NewInt(42)).float64())
+	})
+}
+
+func TestRandomRand(t *testing.T) {
+	tests := []struct {
+		name      string
+		seed      int64
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{"float", 42, nil, NewFloat(0.3745401188473625), nil},
+		{"float", 1234, nil, NewFloat(0.1915194503788923), nil},
+		{"integer", 42, []RubyObject{NewInteger(100)}, NewInteger(51), nil},
+		{"range", 42, []RubyObject{&Range{Left: 1, Right: 6, Inclusive: true}}, NewInteger(4), nil},
+		{"float max", 42, []RubyObject{NewFloat(2)}, NewFloat(0.749080237694725), nil},
+		{"zero", 42, []RubyObject{NewInteger(0)}, nil, NewArgumentError("invalid argument - 0")},
+		{"negative", 42, []RubyObject{NewInteger(-3)}, nil, NewArgumentError("invalid argument - -3")},
+		{"empty range", 42, []RubyObject{&Range{Left: 5, Right: 1, Inclusive: true}}, nil, NewArgumentError("invalid argument - 5..1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := &callContext{receiver: NewRandom(NewInteger(tt.seed))}
+
+			result, err := randomRand(context, nil, tt.arguments...)
+
+			utils.AssertError(t, err, tt.err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+}
+
+func TestRandomBytes(t *testing.T) {
+	context := &callContext{receiver: NewRandom(NewInteger(42))}
+
+	result, err := randomBytes(context, nil, NewInteger(5))
+
+	utils.AssertNoError(t, err)
+	// 1608637542 and 3421126067, least significant byte first
+	utils.AssertEqual(t, result.(*String).Value, "\x66\xdc\xe1\x5f\xb3")
+}
+
+func TestSampleIndices(t *testing.T) {
+	tests := []struct {
+		draws   []int
+		indices []int
+	}{
+		{[]int{3}, []int{3}},
+		{[]int{3, 3}, []int{3, 4}},
+		{[]int{3, 2}, []int{3, 2}},
+		{[]int{0, 0, 0}, []int{0, 1, 2}},
+		{[]int{2, 1, 1}, []int{2, 1, 3}},
+		{[]int{1, 1, 1, 0}, []int{1, 2, 3, 0}},
+	}
+
+	for _, tt := range tests {
+		utils.AssertEqualArrays(t, sampleIndices(tt.draws), tt.indices)
+	}
+}
@@ -55,6 +55,8 @@ var rangeMethods = map[string]RubyMethod{
 	"find_all": withArity(1, newMethod(rangeFindAll)),
 	"all?":     newMethod(rangeAll),
 	"size":     newMethod(rangeSize),
+	"include?": withArity(1, newMethod(rangeInclude)),
+	"===":      withArity(1, newMethod(rangeInclude)),
 }
 
 // Actually create an array of integers from the range
@@ -75,16 +77,10 @@ func rangeFindAll(context CallContext, tracer trace.Tracer, args ...RubyObject)
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	rng, _ := context.Receiver().(*Range)
-	proc, ok := args[0].(*Symbol)
+	fn, ok := blockMethod(args[0])
 	if !ok {
 		return nil, NewArgumentError("(2) range find_all requires a block")
 	}
-	// self, _ := context.Env().Get( "funcs")
-	// self_class := self.Class()
-	fn, ok := FUNCS_STORE.Class().GetMethod(proc.Value)
-	if !ok {
-		return nil, NewNoMethodError(FUNCS_STORE, proc.Value)
-	}
 	// evaluate the range
 	result := NewArray()
 	for _, elem := range rng.ToArray().Elements {
@@ -115,14 +111,10 @@ func rangeAll(context CallContext, tracer trace.Tracer, args ...RubyObject) (Rub
 		return nil, NewArgumentError("all? requires a block")
 	}
 	block := args[0]
-	proc, ok := block.(*Symbol)
+	fn, ok := blockMethod(block)
 	if !ok {
 		return nil, NewArgumentError("all? requires a block")
 	}
-	fn, ok := FUNCS_STORE.GetMethod(proc.Value)
-	if !ok {
-		return nil, NewNoMethodError(FUNCS_STORE, proc.Value)
-	}
 	for _, elem := range rng.ToArray().Elements {
 		ret, err := fn.Call(context, tracer, elem)
 		if err != nil {
@@ -150,3 +142,27 @@ func rangeSize(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 	}
 	return NewInteger(size), nil
 }
+
+// rangeInclude implements Range#include? and Range#===. Only numbers can be
+// covered by a range.
+func rangeInclude(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	rng, _ := context.Receiver().(*Range)
+	var below, above bool
+	switch arg := args[0].(type) {
+	case *Integer:
+		below = arg.Value < rng.Left
+		above = arg.Value > rng.Right || (!rng.Inclusive && arg.Value == rng.Right)
+	case *Float:
+		below = arg.Value < float64(rng.Left)
+		above = arg.Value > float64(rng.Right) || (!rng.Inclusive && arg.Value == float64(rng.Right))
+	default:
+		return FALSE, nil
+	}
+	if below || above {
+		return FALSE, nil
+	}
+	return TRUE, nil
+}
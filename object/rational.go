@@ -0,0 +1,553 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+var rationalClass RubyClassObject = newSubclass(
+	numericClass, "Rational", rationalMethods, nil, notInstantiatable,
+)
+
+func init() {
+	CLASSES.Set("Rational", rationalClass)
+}
+
+// NewRational returns a new Rational with the given value
+func NewRational(value *big.Rat) *Rational {
+	return &Rational{Value: value}
+}
+
+// Rational represents a fraction in Ruby, e.g. `Rational(1, 3)` or `3r`. Its
+// value is always in lowest terms, with a positive denominator.
+type Rational struct {
+	Value *big.Rat
+}
+
+// Inspect returns the fraction in parentheses, e.g. `(1/3)`
+func (r *Rational) Inspect() string { return "(" + r.Value.String() + ")" }
+
+// Class returns rationalClass
+func (r *Rational) Class() RubyClass { return rationalClass }
+
+func (r *Rational) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(r.Inspect()))
+	return HashKey(h.Sum64())
+}
+
+// Float64 returns the float nearest to the value of r
+func (r *Rational) Float64() float64 {
+	value, _ := r.Value.Float64()
+	return value
+}
+
+var (
+	_ RubyObject = &Rational{}
+)
+
+var rationalMethods = map[string]RubyMethod{
+	"+":           withArity(1, newMethod(rationalAdd)),
+	"-":           withArity(1, newMethod(rationalSub)),
+	"*":           withArity(1, newMethod(rationalMul)),
+	"/":           withArity(1, newMethod(rationalDiv)),
+	"quo":         withArity(1, newMethod(rationalDiv)),
+	"**":          withArity(1, newMethod(rationalPow)),
+	"%":           withArity(1, newMethod(rationalModulo)),
+	"modulo":      withArity(1, newMethod(rationalModulo)),
+	"divmod":      withArity(1, newMethod(rationalDivmod)),
+	"<":           withArity(1, newMethod(rationalLt)),
+	">":           withArity(1, newMethod(rationalGt)),
+	"<=":          withArity(1, newMethod(rationalLte)),
+	">=":          withArity(1, newMethod(rationalGte)),
+	"<=>":         withArity(1, newMethod(rationalSpaceship)),
+	"-@":          withArity(0, newMethod(rationalNegate)),
+	"abs":         withArity(0, newMethod(rationalAbs)),
+	"numerator":   withArity(0, newMethod(rationalNumerator)),
+	"denominator": withArity(0, newMethod(rationalDenominator)),
+	"coerce":      withArity(1, newMethod(rationalCoerce)),
+	"to_r":        withArity(0, newMethod(rationalToR)),
+	"to_i":        withArity(0, newMethod(rationalTruncate)),
+	"truncate":    withArity(0, newMethod(rationalTruncate)),
+	"floor":       withArity(0, newMethod(rationalFloor)),
+	"ceil":        withArity(0, newMethod(rationalCeil)),
+	"round":       withArity(0, newMethod(rationalRound)),
+	"to_f":        withArity(0, newMethod(rationalToF)),
+	"to_s":        withArity(0, newMethod(rationalToS)),
+	"rationalize": newMethod(rationalRationalize),
+}
+
+// exactValue returns the value of the Integer or Rational obj
+func exactValue(obj RubyObject) (*big.Rat, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return new(big.Rat).SetInt(obj.BigInt()), true
+	case *Rational:
+		return obj.Value, true
+	default:
+		return nil, false
+	}
+}
+
+// toRational converts obj to the exact value of a Rational, as done by
+// Kernel#Rational. Floats keep their binary value, e.g. 0.5 is 1/2, and
+// strings are parsed, e.g. "1/3" or "0.75".
+func toRational(obj RubyObject) (*big.Rat, error) {
+	if value, ok := exactValue(obj); ok {
+		return value, nil
+	}
+	switch obj := obj.(type) {
+	case *Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return nil, NewFloatDomainError("%s", formatFloat(obj.Value))
+		}
+		return new(big.Rat).SetFloat64(obj.Value), nil
+	case *String:
+		value, ok := new(big.Rat).SetString(strings.ReplaceAll(strings.TrimSpace(obj.Value), "_", ""))
+		if !ok {
+			return nil, NewArgumentError("invalid value for convert(): %q", obj.Value)
+		}
+		return value, nil
+	default:
+		return nil, NewTypeError(fmt.Sprintf("can't convert %s into Rational", obj.Class().(RubyObject).Inspect()))
+	}
+}
+
+// floorRational returns the largest integer not greater than x
+func floorRational(x *big.Rat) *Integer {
+	// the denominator is positive, so Euclidean division rounds down
+	return NewBigInteger(new(big.Int).Div(x.Num(), x.Denom()))
+}
+
+// ceilRational returns the smallest integer not less than x
+func ceilRational(x *big.Rat) *Integer {
+	return floorRational(new(big.Rat).Neg(x)).Negate()
+}
+
+// simplestRational returns the rational with the smallest denominator within
+// the interval from a up to b, where a < b, by their continued fractions
+func simplestRational(a, b *big.Rat) *big.Rat {
+	p0, p1 := big.NewInt(0), big.NewInt(1)
+	q0, q1 := big.NewInt(1), big.NewInt(0)
+	var c *big.Int
+	for {
+		c = ceilRational(a).BigInt()
+		if new(big.Rat).SetInt(c).Cmp(b) < 0 {
+			break
+		}
+		k := new(big.Int).Sub(c, big.NewInt(1))
+		p2 := new(big.Int).Add(new(big.Int).Mul(k, p1), p0)
+		q2 := new(big.Int).Add(new(big.Int).Mul(k, q1), q0)
+		kr := new(big.Rat).SetInt(k)
+		t := new(big.Rat).Inv(new(big.Rat).Sub(b, kr))
+		b = new(big.Rat).Inv(new(big.Rat).Sub(a, kr))
+		a = t
+		p0, p1, q0, q1 = p1, p2, q1, q2
+	}
+	p := new(big.Int).Add(new(big.Int).Mul(c, p1), p0)
+	q := new(big.Int).Add(new(big.Int).Mul(c, q1), q0)
+	return new(big.Rat).SetFrac(p, q)
+}
+
+// rationalize returns the simplest rational within e of the non-negative x,
+// or x itself if e is zero
+func rationalize(x, e *big.Rat) *big.Rat {
+	if e.Sign() == 0 {
+		return x
+	}
+	return simplestRational(new(big.Rat).Sub(x, e), new(big.Rat).Add(x, e))
+}
+
+// powRational returns x raised to the Integer exp, e.g. `Rational(2, 3) ** -2`
+// is `(9/4)`
+func powRational(x *big.Rat, exp *Integer) (*Rational, error) {
+	num, den := NewBigInteger(new(big.Int).Set(x.Num())), NewBigInteger(new(big.Int).Set(x.Denom()))
+	if exp.Sign() < 0 {
+		if num.Sign() == 0 {
+			return nil, NewZeroDivisionError()
+		}
+		num, den, exp = den, num, exp.Negate()
+	}
+	num, err := powIntegerBounded(num, exp)
+	if err != nil {
+		return nil, err
+	}
+	den, err = powIntegerBounded(den, exp)
+	if err != nil {
+		return nil, err
+	}
+	return NewRational(new(big.Rat).SetFrac(num.BigInt(), den.BigInt())), nil
+}
+
+// rationalArithmetic applies an arithmetic operator to the receiver and arg,
+// exactly if arg is an Integer or a Rational and approximately if it is a
+// Float. Any other arg is coerced.
+func rationalArithmetic(
+	context CallContext,
+	tracer trace.Tracer,
+	op string,
+	arg RubyObject,
+	exact func(z, x, y *big.Rat) *big.Rat,
+	approximate func(x, y float64) float64,
+) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	if value, ok := exactValue(arg); ok {
+		return NewRational(exact(new(big.Rat), r.Value, value)), nil
+	}
+	if f, ok := arg.(*Float); ok {
+		return NewFloat(approximate(r.Float64(), f.Value)), nil
+	}
+	return coerceBinop(context, tracer, op, arg)
+}
+
+func rationalAdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return rationalArithmetic(context, tracer, "+", args[0], (*big.Rat).Add, func(x, y float64) float64 { return x + y })
+}
+
+func rationalSub(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return rationalArithmetic(context, tracer, "-", args[0], (*big.Rat).Sub, func(x, y float64) float64 { return x - y })
+}
+
+func rationalMul(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return rationalArithmetic(context, tracer, "*", args[0], (*big.Rat).Mul, func(x, y float64) float64 { return x * y })
+}
+
+func rationalDiv(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if value, ok := exactValue(args[0]); ok && value.Sign() == 0 {
+		return nil, NewZeroDivisionError()
+	}
+	return rationalArithmetic(context, tracer, "/", args[0], (*big.Rat).Quo, func(x, y float64) float64 { return x / y })
+}
+
+// rationalPow raises the receiver to the argument, which keeps it exact for
+// Integer exponents and approximates it otherwise, e.g. `Rational(1, 4) **
+// 0.5` is 0.5
+func rationalPow(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	switch arg := args[0].(type) {
+	case *Integer:
+		return powRational(r.Value, arg)
+	case *Rational:
+		if arg.Value.IsInt() {
+			return powRational(r.Value, NewBigInteger(new(big.Int).Set(arg.Value.Num())))
+		}
+		return powFloat(r.Float64(), arg.Float64()), nil
+	case *Float:
+		return powFloat(r.Float64(), arg.Value), nil
+	default:
+		return coerceBinop(context, tracer, "**", args[0])
+	}
+}
+
+// rationalDivmod returns the quotient of the floor division of the receiver
+// by arg, and the remainder, which has the sign of arg
+func rationalDivmod(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	divisor, ok := exactValue(args[0])
+	if !ok {
+		if _, ok := args[0].(*Float); ok {
+			return Send(withReceiver(context, NewFloat(r.Float64())), "divmod", tracer, args[0])
+		}
+		return coerceBinop(context, tracer, "divmod", args[0])
+	}
+	if divisor.Sign() == 0 {
+		return nil, NewZeroDivisionError()
+	}
+	quotient := floorRational(new(big.Rat).Quo(r.Value, divisor))
+	product := new(big.Rat).Mul(divisor, new(big.Rat).SetInt(quotient.BigInt()))
+	return NewArray(quotient, NewRational(product.Sub(r.Value, product))), nil
+}
+
+func rationalModulo(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	if _, ok := exactValue(args[0]); !ok {
+		if _, ok := args[0].(*Float); ok {
+			return Send(withReceiver(context, NewFloat(r.Float64())), "%", tracer, args[0])
+		}
+		return coerceBinop(context, tracer, "%", args[0])
+	}
+	result, err := rationalDivmod(context, tracer, args...)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Array).Elements[1], nil
+}
+
+// rationalCompare returns -1, 0 or 1 depending on whether the receiver is less
+// than, equal to or greater than the Integer, Float or Rational arg. ok is
+// false if arg is of any other class or NaN.
+func rationalCompare(r *Rational, arg RubyObject) (result int, ok bool) {
+	if value, ok := exactValue(arg); ok {
+		return r.Value.Cmp(value), true
+	}
+	f, isFloat := arg.(*Float)
+	if !isFloat || math.IsNaN(f.Value) {
+		return 0, false
+	}
+	switch x := r.Float64(); {
+	case x < f.Value:
+		return -1, true
+	case x > f.Value:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// rationalRelop applies the comparison operator op, which holds if accept
+// does for the result of <=>
+func rationalRelop(context CallContext, tracer trace.Tracer, op string, arg RubyObject, accept func(int) bool) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	result, ok := rationalCompare(r, arg)
+	if !ok {
+		if f, isFloat := arg.(*Float); isFloat && math.IsNaN(f.Value) {
+			return FALSE, nil
+		}
+		return coerceRelop(context, tracer, op, arg)
+	}
+	if accept(result) {
+		return TRUE, nil
+	}
+	return FALSE, nil
+}
+
+func rationalLt(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return rationalRelop(context, tracer, "<", args[0], func(c int) bool { return c < 0 })
+}
+
+func rationalGt(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return rationalRelop(context, tracer, ">", args[0], func(c int) bool { return c > 0 })
+}
+
+func rationalLte(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return rationalRelop(context, tracer, "<=", args[0], func(c int) bool { return c <= 0 })
+}
+
+func rationalGte(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return rationalRelop(context, tracer, ">=", args[0], func(c int) bool { return c >= 0 })
+}
+
+func rationalSpaceship(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	result, ok := rationalCompare(r, args[0])
+	if !ok {
+		if _, isFloat := args[0].(*Float); isFloat {
+			return NIL, nil
+		}
+		return coerceCmp(context, tracer, args[0])
+	}
+	return NewInteger(int64(result)), nil
+}
+
+func rationalNegate(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return NewRational(new(big.Rat).Neg(r.Value)), nil
+}
+
+func rationalAbs(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return NewRational(new(big.Rat).Abs(r.Value)), nil
+}
+
+func rationalNumerator(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return NewBigInteger(new(big.Int).Set(r.Value.Num())), nil
+}
+
+func rationalDenominator(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return NewBigInteger(new(big.Int).Set(r.Value.Denom())), nil
+}
+
+// rationalCoerce converts Integers to Rationals and the receiver to a Float
+// for Floats
+func rationalCoerce(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	switch arg := args[0].(type) {
+	case *Integer, *Rational:
+		value, _ := exactValue(arg)
+		return NewArray(NewRational(value), r), nil
+	case *Float:
+		return NewArray(arg, NewFloat(r.Float64())), nil
+	case *Complex:
+		return NewArray(arg, NewComplex(r, NewInteger(0))), nil
+	default:
+		return nil, NewTypeError(fmt.Sprintf("%s can't be coerced into Rational", arg.Class().(RubyObject).Inspect()))
+	}
+}
+
+func rationalToR(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return context.Receiver(), nil
+}
+
+// rationalTruncate returns the receiver rounded towards zero
+func rationalTruncate(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return NewBigInteger(new(big.Int).Quo(r.Value.Num(), r.Value.Denom())), nil
+}
+
+func rationalFloor(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return floorRational(r.Value), nil
+}
+
+func rationalCeil(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return ceilRational(r.Value), nil
+}
+
+// rationalRound returns the receiver rounded to the nearest integer, and
+// halves away from zero
+func rationalRound(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	half := big.NewRat(1, 2)
+	rounded := floorRational(new(big.Rat).Add(new(big.Rat).Abs(r.Value), half))
+	if r.Value.Sign() < 0 {
+		return rounded.Negate(), nil
+	}
+	return rounded, nil
+}
+
+func rationalToF(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return NewFloat(r.Float64()), nil
+}
+
+func rationalToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	return NewString(r.Value.String()), nil
+}
+
+// rationalRationalize returns the simplest rational within the optional
+// argument of the receiver, e.g. `Rational(333, 1000).rationalize(0.01)` is
+// `(1/3)`
+func rationalRationalize(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	r := context.Receiver().(*Rational)
+	if len(args) > 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	if len(args) == 0 {
+		return r, nil
+	}
+	e, err := toRational(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value := rationalize(new(big.Rat).Abs(r.Value), new(big.Rat).Abs(e))
+	if r.Value.Sign() < 0 {
+		value = new(big.Rat).Neg(value)
+	}
+	return NewRational(value), nil
+}
+
+// bottomRational implements Kernel#Rational, which returns the exact
+// quotient of its arguments, e.g. `Rational(1, 3)` or `Rational("0.75")`
+func bottomRational(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	num, err := toRational(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		return NewRational(new(big.Rat).Set(num)), nil
+	}
+	den, err := toRational(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if den.Sign() == 0 {
+		return nil, NewZeroDivisionError()
+	}
+	return NewRational(new(big.Rat).Quo(num, den)), nil
+}
@@ -0,0 +1,119 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func rational(a, b int64) *Rational {
+	return NewRational(big.NewRat(a, b))
+}
+
+func TestRationalArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   func(CallContext, trace.Tracer, ...RubyObject) (RubyObject, error)
+		receiver RubyObject
+		argument RubyObject
+		result   RubyObject
+	}{
+		{"+", rationalAdd, rational(1, 2), rational(1, 3), rational(5, 6)},
+		{"+ Integer", rationalAdd, rational(1, 2), NewInteger(1), rational(3, 2)},
+		{"+ Float", rationalAdd, rational(1, 2), NewFloat(0.25), NewFloat(0.75)},
+		{"-", rationalSub, rational(1, 2), rational(1, 2), rational(0, 1)},
+		{"*", rationalMul, rational(2, 3), NewInteger(3), rational(2, 1)},
+		{"/", rationalDiv, rational(1, 2), NewInteger(-2), rational(-1, 4)},
+		{"**", rationalPow, rational(2, 3), NewInteger(-2), rational(9, 4)},
+		{"%", rationalModulo, rational(-7, 2), NewInteger(2), rational(1, 2)},
+		{"Integer +", integerAdd, NewInteger(1), rational(1, 2), rational(3, 2)},
+		{"Integer quo", integerQuo, NewInteger(1), NewInteger(3), rational(1, 3)},
+		{"Integer ** negative", integerPow, NewInteger(2), NewInteger(-3), rational(1, 8)},
+		{"Float +", floatAdd, NewFloat(0.5), rational(1, 4), NewFloat(0.75)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(&callContext{receiver: tt.receiver}, nil, tt.argument)
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+		})
+	}
+	t.Run("by zero", func(t *testing.T) {
+		_, err := rationalDiv(&callContext{receiver: rational(1, 2)}, nil, NewInteger(0))
+		utils.AssertError(t, err, NewZeroDivisionError())
+
+		_, err = rationalPow(&callContext{receiver: rational(0, 1)}, nil, NewInteger(-1))
+		utils.AssertError(t, err, NewZeroDivisionError())
+	})
+}
+
+func TestRationalize(t *testing.T) {
+	tests := []struct {
+		receiver  RubyObject
+		arguments []RubyObject
+		result    RubyObject
+	}{
+		{NewFloat(0.333), nil, rational(333, 1000)},
+		{NewFloat(0.1), nil, rational(1, 10)},
+		{NewFloat(-0.75), nil, rational(-3, 4)},
+		{NewFloat(0.333), []RubyObject{rational(1, 100)}, rational(1, 3)},
+		{NewFloat(3.14159), []RubyObject{NewFloat(0.001)}, rational(201, 64)},
+	}
+
+	for _, tt := range tests {
+		result, err := floatRationalize(&callContext{receiver: tt.receiver}, nil, tt.arguments...)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+
+	t.Run("Rational", func(t *testing.T) {
+		result, err := rationalRationalize(&callContext{receiver: rational(333, 1000)}, nil, rational(1, 100))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, rational(1, 3), CompareRubyObjectsForTests)
+	})
+}
+
+func TestRationalCoerce(t *testing.T) {
+	tests := []struct {
+		argument RubyObject
+		result   RubyObject
+		err      error
+	}{
+		{NewInteger(2), NewArray(rational(2, 1), rational(1, 2)), nil},
+		{NewFloat(1.5), NewArray(NewFloat(1.5), NewFloat(0.5)), nil},
+		{NewString("1"), nil, NewTypeError("String can't be coerced into Rational")},
+	}
+
+	for _, tt := range tests {
+		result, err := rationalCoerce(&callContext{receiver: rational(1, 2)}, nil, tt.argument)
+
+		utils.AssertError(t, err, tt.err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
+
+func TestBottomRational(t *testing.T) {
+	tests := []struct {
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{[]RubyObject{NewInteger(2), NewInteger(-4)}, rational(-1, 2), nil},
+		{[]RubyObject{NewFloat(0.5)}, rational(1, 2), nil},
+		{[]RubyObject{NewString("3/4")}, rational(3, 4), nil},
+		{[]RubyObject{NewInteger(1), NewInteger(0)}, nil, NewZeroDivisionError()},
+	}
+
+	for _, tt := range tests {
+		result, err := bottomRational(&callContext{receiver: NIL}, nil, tt.arguments...)
+
+		utils.AssertError(t, err, tt.err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
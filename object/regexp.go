@@ -0,0 +1,599 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	"github.com/MarcinKonowalczyk/goruby/trace"
+)
+
+// The option flags of a Regexp, as returned by Regexp#options
+const (
+	REGEXP_IGNORECASE = 1
+	REGEXP_EXTENDED   = 2
+	REGEXP_MULTILINE  = 4
+)
+
+var regexpClass RubyClassObject = newClass(
+	"Regexp",
+	regexpMethods,
+	regexpClassMethods,
+	notInstantiatable,
+)
+
+func init() {
+	CLASSES.Set("Regexp", regexpClass)
+	constants := regexpClass.(Environment)
+	constants.Set("IGNORECASE", NewInteger(REGEXP_IGNORECASE))
+	constants.Set("EXTENDED", NewInteger(REGEXP_EXTENDED))
+	constants.Set("MULTILINE", NewInteger(REGEXP_MULTILINE))
+}
+
+// NewRegexp returns a Regexp for the Ruby regular expression source. It
+// returns a RegexpError if source is invalid or uses a construct which has no
+// equivalent in Go's RE2 syntax, e.g. backreferences or lookarounds.
+func NewRegexp(source string, options int) (*Regexp, error) {
+	translated, err := translateRegexp(source, options)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(translated)
+	if err != nil {
+		return nil, NewRegexpError("%s: /%s/", strings.TrimPrefix(err.Error(), "error parsing regexp: "), source)
+	}
+	return &Regexp{Source: source, Options: options, re: re}, nil
+}
+
+// RegexpOptions returns the option flags for the option letters of a regex
+// literal, e.g. `im`. Letters without a meaning in goruby, like the encoding
+// options, are ignored.
+func RegexpOptions(letters string) int {
+	options := 0
+	for _, letter := range letters {
+		switch letter {
+		case 'i':
+			options |= REGEXP_IGNORECASE
+		case 'x':
+			options |= REGEXP_EXTENDED
+		case 'm':
+			options |= REGEXP_MULTILINE
+		}
+	}
+	return options
+}
+
+// A Regexp is a compiled regular expression
+type Regexp struct {
+	Source  string
+	Options int
+	re      *regexp.Regexp
+}
+
+func (r *Regexp) Inspect() string {
+	var out strings.Builder
+	out.WriteString("/")
+	escaped := false
+	for _, c := range r.Source {
+		if c == '/' && !escaped {
+			out.WriteRune('\\')
+		}
+		escaped = c == '\\' && !escaped
+		out.WriteRune(c)
+	}
+	out.WriteString("/")
+	out.WriteString(r.optionLetters(true))
+	return out.String()
+}
+
+func (r *Regexp) Class() RubyClass { return regexpClass }
+
+func (r *Regexp) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%d/%s", r.Options, r.Source)))
+	return HashKey(h.Sum64())
+}
+
+// optionLetters returns the letters of the options set on r, in the order
+// `mix`. If set is false the letters of the options not set are returned.
+func (r *Regexp) optionLetters(set bool) string {
+	var letters strings.Builder
+	for _, option := range []struct {
+		flag   int
+		letter byte
+	}{{REGEXP_MULTILINE, 'm'}, {REGEXP_IGNORECASE, 'i'}, {REGEXP_EXTENDED, 'x'}} {
+		if (r.Options&option.flag != 0) == set {
+			letters.WriteByte(option.letter)
+		}
+	}
+	return letters.String()
+}
+
+// String returns r as it is embedded into other regular expressions, e.g.
+// `(?i-mx:abc)`
+func (r *Regexp) String() string {
+	disabled := r.optionLetters(false)
+	if disabled != "" {
+		disabled = "-" + disabled
+	}
+	return fmt.Sprintf("(?%s%s:%s)", r.optionLetters(true), disabled, r.Source)
+}
+
+// Match returns the first match of r within str starting at the byte offset
+// pos, or nil if there is none
+func (r *Regexp) Match(str string, pos int) *MatchData {
+	if pos < 0 || pos > len(str) {
+		return nil
+	}
+	indices := r.re.FindStringSubmatchIndex(str[pos:])
+	if indices == nil {
+		return nil
+	}
+	for i := range indices {
+		if indices[i] >= 0 {
+			indices[i] += pos
+		}
+	}
+	return &MatchData{Regexp: r, String: str, indices: indices}
+}
+
+// MatchAll returns all successive, non-overlapping matches of r within str
+func (r *Regexp) MatchAll(str string) []*MatchData {
+	var matches []*MatchData
+	for _, indices := range r.re.FindAllStringSubmatchIndex(str, -1) {
+		matches = append(matches, &MatchData{Regexp: r, String: str, indices: indices})
+	}
+	return matches
+}
+
+var (
+	_ RubyObject = &Regexp{}
+)
+
+// translateRegexp rewrites the Ruby regular expression source into the
+// syntax of Go's regexp package. Anchors always match at line boundaries in
+// Ruby, and the `m` option of Ruby is the `s` flag of Go.
+func translateRegexp(source string, options int) (string, error) {
+	flags := "m"
+	if options&REGEXP_IGNORECASE != 0 {
+		flags += "i"
+	}
+	if options&REGEXP_MULTILINE != 0 {
+		flags += "s"
+	}
+	unsupported := func(construct string) error {
+		return NewRegexpError("%s are not supported: /%s/", construct, source)
+	}
+
+	var out strings.Builder
+	out.WriteString("(?" + flags + ")")
+	extended := options&REGEXP_EXTENDED != 0
+	inClass := false
+	runes := []rune(source)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		next := func(offset int) rune {
+			if i+offset < len(runes) {
+				return runes[i+offset]
+			}
+			return 0
+		}
+		switch {
+		case c == '\\':
+			i++
+			escaped := next(0)
+			switch {
+			case escaped >= '1' && escaped <= '9' && !inClass:
+				return "", unsupported("backreferences")
+			case escaped == 'k' && (next(1) == '<' || next(1) == '\''):
+				return "", unsupported("backreferences")
+			case escaped == 'g' && (next(1) == '<' || next(1) == '\''):
+				return "", unsupported("subexpression calls")
+			case escaped == 'G':
+				return "", unsupported("\\G anchors")
+			case escaped == 'h' && inClass:
+				out.WriteString("0-9a-fA-F")
+			case escaped == 'h':
+				out.WriteString("[0-9a-fA-F]")
+			case escaped == 'H' && !inClass:
+				out.WriteString("[^0-9a-fA-F]")
+			case escaped == 'e':
+				out.WriteString(`\x1B`)
+			case escaped == '/':
+				out.WriteRune('/')
+			case escaped == ' ':
+				out.WriteString(`\x20`)
+			case escaped == 0:
+				return "", NewRegexpError("too short escape sequence: /%s/", source)
+			default:
+				out.WriteRune('\\')
+				out.WriteRune(escaped)
+			}
+		case inClass:
+			if c == '[' && next(1) == ':' {
+				// a POSIX bracket expression, e.g. `[[:alpha:]]`
+				end := strings.Index(string(runes[i:]), ":]")
+				if end >= 0 {
+					bracket := string(runes[i:])[:end+2]
+					out.WriteString(bracket)
+					i += len([]rune(bracket)) - 1
+					continue
+				}
+			}
+			if c == ']' {
+				inClass = false
+			}
+			out.WriteRune(c)
+		case c == '[':
+			inClass = true
+			out.WriteRune(c)
+			// a leading `]` or `^]` is literal
+			if next(1) == '^' {
+				i++
+				out.WriteRune('^')
+			}
+			if next(1) == ']' {
+				i++
+				out.WriteString(`\]`)
+			}
+		case c == '(' && next(1) == '?':
+			switch {
+			case next(2) == '=' || next(2) == '!':
+				return "", unsupported("lookahead assertions")
+			case next(2) == '<' && (next(3) == '=' || next(3) == '!'):
+				return "", unsupported("lookbehind assertions")
+			case next(2) == '>':
+				return "", unsupported("atomic groups")
+			case next(2) == '#':
+				// a comment group
+				for i < len(runes) && runes[i] != ')' {
+					i++
+				}
+			case next(2) == '\'':
+				end := strings.IndexRune(string(runes[i+3:]), '\'')
+				if end < 0 {
+					return "", NewRegexpError("invalid group name: /%s/", source)
+				}
+				name := string(runes[i+3:])[:end]
+				out.WriteString("(?P<" + name + ">")
+				i += 3 + len([]rune(name))
+			case next(2) == '<' || next(2) == ':' || next(2) == 'P':
+				out.WriteString("(?")
+				i++
+			default:
+				// inline options, e.g. `(?i)` or `(?m-x:...)`
+				out.WriteString("(?")
+				i += 2
+				for ; i < len(runes) && runes[i] != ')' && runes[i] != ':'; i++ {
+					switch runes[i] {
+					case 'm':
+						out.WriteRune('s')
+					case 'x':
+						// extended mode is resolved here rather than by Go
+					default:
+						out.WriteRune(runes[i])
+					}
+				}
+				if i < len(runes) {
+					out.WriteRune(runes[i])
+				}
+			}
+		case (c == '+' || c == '*' || c == '?' || c == '}') && next(1) == '+':
+			return "", unsupported("possessive quantifiers")
+		case extended && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			// insignificant whitespace
+		case extended && c == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		default:
+			out.WriteRune(c)
+		}
+	}
+	return out.String(), nil
+}
+
+var regexpClassMethods = map[string]RubyMethod{
+	"new":        newMethod(regexpNew),
+	"compile":    newMethod(regexpNew),
+	"escape":     withArity(1, newMethod(regexpEscape)),
+	"quote":      withArity(1, newMethod(regexpEscape)),
+	"union":      newMethod(regexpUnion),
+	"last_match": newMethod(regexpLastMatch),
+}
+
+var regexpMethods = map[string]RubyMethod{
+	"match":   newMethod(regexpMatch),
+	"match?":  newMethod(regexpIsMatch),
+	"=~":      withArity(1, newMethod(regexpMatchOperator)),
+	"===":     withArity(1, newMethod(regexpCaseEqual)),
+	"source":  withArity(0, newMethod(regexpSource)),
+	"options": withArity(0, newMethod(regexpOptions)),
+	"names":   withArity(0, newMethod(regexpNames)),
+	"to_s":    withArity(0, newMethod(regexpToS)),
+	"inspect": withArity(0, newMethod(regexpInspect)),
+}
+
+// regexpOf returns the Regexp for the pattern obj. Strings match literally.
+func regexpOf(obj RubyObject) (*Regexp, error) {
+	switch obj := obj.(type) {
+	case *Regexp:
+		return obj, nil
+	case *String:
+		return NewRegexp(regexp.QuoteMeta(obj.Value), 0)
+	default:
+		return nil, NewWrongArgumentTypeError(regexpClass, obj)
+	}
+}
+
+// lastMatchVariable is the name of `$~`. Unlike other globals it is local to
+// a method and shared with the blocks within it, so every method environment
+// holds one of its own.
+const lastMatchVariable = "$~"
+
+// setLastMatch sets `$~` to match, which is either a MatchData or nil, within
+// the method the match is made in
+func setLastMatch(context CallContext, match RubyObject) {
+	if env := context.Env(); env != nil {
+		holdingEnvironment(env, lastMatchVariable).Set(lastMatchVariable, match)
+	}
+}
+
+// lastMatchOf returns match as a RubyObject, i.e. nil if match is nil
+func lastMatchOf(match *MatchData) RubyObject {
+	if match == nil {
+		return NIL
+	}
+	return match
+}
+
+func regexpNew(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	if re, ok := args[0].(*Regexp); ok {
+		return re, nil
+	}
+	source, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(source, args[0])
+	}
+	options := 0
+	if len(args) == 2 {
+		switch opt := args[1].(type) {
+		case *Integer:
+			options = int(opt.Value)
+		case *String:
+			options = RegexpOptions(opt.Value)
+		default:
+			if opt != NIL && opt != FALSE {
+				options = REGEXP_IGNORECASE
+			}
+		}
+	}
+	return NewRegexp(source.Value, options)
+}
+
+func regexpEscape(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	var str string
+	switch arg := args[0].(type) {
+	case *String:
+		str = arg.Value
+	case *Symbol:
+		str = arg.Value
+	default:
+		return nil, NewImplicitConversionTypeError(NewString(""), args[0])
+	}
+	escaped := regexp.QuoteMeta(str)
+	escaped = strings.NewReplacer("\n", `\n`, "\t", `\t`, "\r", `\r`, " ", `\ `, "-", `\-`).Replace(escaped)
+	return NewString(escaped), nil
+}
+
+func regexpUnion(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) == 1 {
+		if arr, ok := args[0].(*Array); ok {
+			args = arr.Elements
+		}
+	}
+	if len(args) == 1 {
+		if re, ok := args[0].(*Regexp); ok {
+			return re, nil
+		}
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case *Regexp:
+			parts[i] = arg.String()
+		case *String:
+			parts[i] = regexp.QuoteMeta(arg.Value)
+		default:
+			return nil, NewImplicitConversionTypeError(NewString(""), arg)
+		}
+	}
+	if len(parts) == 0 {
+		// matches nothing, which RE2 cannot express as `(?!)`
+		return &Regexp{Source: "(?!)", re: regexp.MustCompile(`[^\x00-\x{10FFFF}]`)}, nil
+	}
+	return NewRegexp(strings.Join(parts, "|"), 0)
+}
+
+func regexpLastMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	var match RubyObject = NIL
+	if env := context.Env(); env != nil {
+		if last, ok := env.Get(lastMatchVariable); ok {
+			match = last
+		}
+	}
+	if len(args) == 0 || match == NIL {
+		return match, nil
+	}
+	return Send(withReceiver(context, match), "[]", tracer, args[0])
+}
+
+// matchArguments returns the string and the byte offset a Regexp is matched
+// against, as passed to Regexp#match and Regexp#match?. ok is false if the
+// string is nil.
+func matchArguments(args []RubyObject) (str string, pos int, ok bool, err error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", 0, false, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	switch arg := args[0].(type) {
+	case *String:
+		str = arg.Value
+	case *Symbol:
+		if arg == NIL {
+			return "", 0, false, nil
+		}
+		str = arg.Value
+	default:
+		return "", 0, false, NewImplicitConversionTypeError(NewString(""), args[0])
+	}
+	if len(args) == 2 {
+		offset, isInt := args[1].(*Integer)
+		if !isInt {
+			return "", 0, false, NewImplicitConversionTypeError(NewInteger(0), args[1])
+		}
+		pos = byteOffset(str, int(offset.Value))
+	}
+	return str, pos, true, nil
+}
+
+func regexpMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	block := blockOf(context)
+	str, pos, ok, err := matchArguments(withoutBlockArgument(args, block))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		setLastMatch(context, NIL)
+		return NIL, nil
+	}
+	match := lastMatchOf(re.Match(str, pos))
+	setLastMatch(context, match)
+	if block != nil && match != NIL {
+		return block.Call(context, tracer, match)
+	}
+	return match, nil
+}
+
+func regexpIsMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	str, pos, ok, err := matchArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || re.Match(str, pos) == nil {
+		return FALSE, nil
+	}
+	return TRUE, nil
+}
+
+func regexpMatchOperator(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	str, _, ok, err := matchArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		setLastMatch(context, NIL)
+		return NIL, nil
+	}
+	match := re.Match(str, 0)
+	setLastMatch(context, lastMatchOf(match))
+	if match == nil {
+		return NIL, nil
+	}
+	return NewInteger(int64(match.Begin(0))), nil
+}
+
+func regexpCaseEqual(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	var str string
+	switch arg := args[0].(type) {
+	case *String:
+		str = arg.Value
+	case *Symbol:
+		str = arg.Value
+	default:
+		return FALSE, nil
+	}
+	match := re.Match(str, 0)
+	setLastMatch(context, lastMatchOf(match))
+	if match == nil {
+		return FALSE, nil
+	}
+	return TRUE, nil
+}
+
+func regexpSource(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	return NewString(re.Source), nil
+}
+
+func regexpOptions(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	return NewInteger(int64(re.Options)), nil
+}
+
+func regexpNames(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	names := NewArray()
+	for _, name := range re.re.SubexpNames() {
+		if name != "" {
+			names.Elements = append(names.Elements, NewString(name))
+		}
+	}
+	return names, nil
+}
+
+func regexpToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	return NewString(re.String()), nil
+}
+
+func regexpInspect(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	re, _ := context.Receiver().(*Regexp)
+	return NewString(re.Inspect()), nil
+}
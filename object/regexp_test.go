@@ -0,0 +1,182 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/MarcinKonowalczyk/goruby/utils"
+)
+
+func mustRegexp(t *testing.T, source string, options int) *Regexp {
+	t.Helper()
+	re, err := NewRegexp(source, options)
+	utils.AssertNoError(t, err)
+	return re
+}
+
+func TestTranslateRegexp(t *testing.T) {
+	tests := []struct {
+		source     string
+		options    int
+		translated string
+	}{
+		{`a+b`, 0, `(?m)a+b`},
+		{`a.b`, REGEXP_MULTILINE | REGEXP_IGNORECASE, `(?mis)a.b`},
+		{`\h+\H`, 0, `(?m)[0-9a-fA-F]+[^0-9a-fA-F]`},
+		{`[\h_]`, 0, `(?m)[0-9a-fA-F_]`},
+		{`a\/b`, 0, `(?m)a/b`},
+		{`\e`, 0, `(?m)\x1B`},
+		{`a(?#comment)b`, 0, `(?m)ab`},
+		{`(?<year>\d+)`, 0, `(?m)(?<year>\d+)`},
+		{`(?'year'\d+)`, 0, `(?m)(?P<year>\d+)`},
+		{`(?m:a.b)`, 0, `(?m)(?s:a.b)`},
+		{`[[:alpha:] ]`, 0, `(?m)[[:alpha:] ]`},
+		{`[]a]`, 0, `(?m)[\]a]`},
+		{"a b # comment\n c", REGEXP_EXTENDED, `(?m)abc`},
+		{`a\ b [ ]`, REGEXP_EXTENDED, `(?m)a\x20b[ ]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			translated, err := translateRegexp(tt.source, tt.options)
+
+			utils.AssertNoError(t, err)
+			utils.AssertEqual(t, translated, tt.translated)
+		})
+	}
+}
+
+func TestTranslateRegexpUnsupported(t *testing.T) {
+	tests := []struct {
+		source    string
+		construct string
+	}{
+		{`(a)\1`, "backreferences"},
+		{`(?<a>x)\k<a>`, "backreferences"},
+		{`a(?=b)`, "lookahead assertions"},
+		{`a(?!b)`, "lookahead assertions"},
+		{`(?<=a)b`, "lookbehind assertions"},
+		{`(?<!a)b`, "lookbehind assertions"},
+		{`(?>a)`, "atomic groups"},
+		{`a++`, "possessive quantifiers"},
+		{`\Ga`, `\G anchors`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := NewRegexp(tt.source, 0)
+
+			utils.AssertError(t, err, NewRegexpError("%s are not supported: /%s/", tt.construct, tt.source))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewRegexp(`a(b`, 0)
+
+		utils.AssertError(t, err, NewRegexpError("missing closing ): `(?m)a(b`: /a(b/"))
+	})
+}
+
+func TestRegexpInspect(t *testing.T) {
+	tests := []struct {
+		re      *Regexp
+		inspect string
+		toS     string
+	}{
+		{mustRegexp(t, "a+", 0), "/a+/", "(?-mix:a+)"},
+		{mustRegexp(t, "a/b", REGEXP_IGNORECASE), `/a\/b/i`, "(?i-mx:a/b)"},
+		{mustRegexp(t, `a\/b`, 0), `/a\/b/`, `(?-mix:a\/b)`},
+		{mustRegexp(t, "a", REGEXP_MULTILINE|REGEXP_IGNORECASE|REGEXP_EXTENDED), "/a/mix", "(?mix:a)"},
+	}
+
+	for _, tt := range tests {
+		utils.AssertEqual(t, tt.re.Inspect(), tt.inspect)
+		utils.AssertEqual(t, tt.re.String(), tt.toS)
+	}
+}
+
+func TestRegexpOptions(t *testing.T) {
+	utils.AssertEqual(t, RegexpOptions(""), 0)
+	utils.AssertEqual(t, RegexpOptions("i"), REGEXP_IGNORECASE)
+	utils.AssertEqual(t, RegexpOptions("mix"), REGEXP_MULTILINE|REGEXP_IGNORECASE|REGEXP_EXTENDED)
+	utils.AssertEqual(t, RegexpOptions("on"), 0)
+}
+
+func TestRegexpMatch(t *testing.T) {
+	re := mustRegexp(t, `(\d+)`, 0)
+
+	t.Run("match", func(t *testing.T) {
+		result, err := regexpMatch(&callContext{receiver: re}, nil, NewString("ab 12 34"))
+
+		utils.AssertNoError(t, err)
+		match, ok := result.(*MatchData)
+		utils.Assert(t, ok, "Expected MatchData, got %T", result)
+		utils.AssertEqual(t, match.Group(1), "12")
+	})
+	t.Run("match with position", func(t *testing.T) {
+		result, err := regexpMatch(&callContext{receiver: re}, nil, NewString("ab 12 34"), NewInteger(5))
+
+		utils.AssertNoError(t, err)
+		match, ok := result.(*MatchData)
+		utils.Assert(t, ok, "Expected MatchData, got %T", result)
+		utils.AssertEqual(t, match.Group(1), "34")
+	})
+	t.Run("no match", func(t *testing.T) {
+		result, err := regexpMatch(&callContext{receiver: re}, nil, NewString("ab"))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, result, NIL)
+	})
+	t.Run("=~", func(t *testing.T) {
+		result, err := regexpMatchOperator(&callContext{receiver: re}, nil, NewString("äb 12"))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(3), CompareRubyObjectsForTests)
+	})
+	t.Run("sets the last match", func(t *testing.T) {
+		env := NewMainEnvironment()
+		context := NewCallContext(env, re)
+
+		_, err := regexpMatch(context, nil, NewString("ab 12"))
+		utils.AssertNoError(t, err)
+
+		last, ok := env.Get("$~")
+		utils.Assert(t, ok, "Expected $~ to be set")
+		utils.AssertEqual(t, last.(*MatchData).Group(0), "12")
+	})
+}
+
+func TestRegexpEscape(t *testing.T) {
+	result, err := regexpEscape(&callContext{receiver: regexpClass}, nil, NewString("a.b*c d-e\n"))
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewString(`a\.b\*c\ d\-e\n`), CompareRubyObjectsForTests)
+
+	re := mustRegexp(t, result.(*String).Value, 0)
+	utils.AssertNotEqual(t, re.Match("a.b*c d-e\n", 0), (*MatchData)(nil))
+}
+
+func TestRegexpUnion(t *testing.T) {
+	tests := []struct {
+		args   []RubyObject
+		source string
+	}{
+		{[]RubyObject{NewString("a.b"), mustRegexp(t, "c", REGEXP_IGNORECASE)}, `a\.b|(?i-mx:c)`},
+		{[]RubyObject{NewArray(NewString("a"), NewString("b"))}, `a|b`},
+		{[]RubyObject{mustRegexp(t, "c", REGEXP_IGNORECASE)}, "c"},
+		{nil, "(?!)"},
+	}
+
+	for _, tt := range tests {
+		result, err := regexpUnion(&callContext{receiver: regexpClass}, nil, tt.args...)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, result.(*Regexp).Source, tt.source)
+	}
+
+	t.Run("union matches nothing", func(t *testing.T) {
+		result, err := regexpUnion(&callContext{receiver: regexpClass}, nil)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqual(t, result.(*Regexp).Match("", 0), (*MatchData)(nil))
+	})
+}
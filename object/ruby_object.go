@@ -1,10 +1,12 @@
 package object
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/MarcinKonowalczyk/goruby/ast"
 	"github.com/MarcinKonowalczyk/goruby/trace"
+	"github.com/pkg/errors"
 )
 
 type inspectable interface {
@@ -66,6 +68,44 @@ var (
 	_ RubyObject = &BreakValue{}
 )
 
+// NextValue represents a wrapper object for a next statement. It is no
+// real Ruby object and only used within the interpreter evaluation
+type NextValue struct {
+	Value RubyObject
+}
+
+func (nv *NextValue) Inspect() string  { return nv.Value.Inspect() }
+func (nv *NextValue) Class() RubyClass { return nv.Value.Class() }
+func (nv *NextValue) HashKey() HashKey { return nv.Value.HashKey() }
+
+var (
+	_ RubyObject = &NextValue{}
+)
+
+// RetryValue represents a wrapper object for a retry statement. It is no
+// real Ruby object and only used within the interpreter evaluation
+type RetryValue struct{}
+
+func (rv *RetryValue) Inspect() string  { return "retry" }
+func (rv *RetryValue) Class() RubyClass { return nil }
+func (rv *RetryValue) HashKey() HashKey { return HashKey(0) }
+
+var (
+	_ RubyObject = &RetryValue{}
+)
+
+// RedoValue represents a wrapper object for a redo statement. It is no
+// real Ruby object and only used within the interpreter evaluation
+type RedoValue struct{}
+
+func (rv *RedoValue) Inspect() string  { return "redo" }
+func (rv *RedoValue) Class() RubyClass { return nil }
+func (rv *RedoValue) HashKey() HashKey { return HashKey(0) }
+
+var (
+	_ RubyObject = &RedoValue{}
+)
+
 // FunctionParameters represents a list of function parameters.
 type functionParameters []*FunctionParameter
 
@@ -79,23 +119,198 @@ func (f functionParameters) defaultParamCount() int {
 	return count
 }
 
-func (f functionParameters) separateDefaultParams() ([]*FunctionParameter, []*FunctionParameter) {
-	mandatory, defaults := make([]*FunctionParameter, 0), make([]*FunctionParameter, 0)
+// withoutBlock splits off the explicit block parameter, if any
+func (f functionParameters) withoutBlock() (functionParameters, *FunctionParameter) {
+	if len(f) != 0 && f[len(f)-1].Block {
+		return f[:len(f)-1], f[len(f)-1]
+	}
+	return f, nil
+}
+
+// withoutKeywords splits off the keyword parameters, including a double splat
+func (f functionParameters) withoutKeywords() (functionParameters, functionParameters) {
+	var positional, keywords functionParameters
 	for _, p := range f {
-		if p.Default != nil {
-			defaults = append(defaults, p)
+		if p.Keyword || p.DoubleSplat {
+			keywords = append(keywords, p)
+			continue
+		}
+		positional = append(positional, p)
+	}
+	return positional, keywords
+}
+
+// requireKeywords returns true if any of the parameters is a required keyword
+func (f functionParameters) requireKeywords() bool {
+	for _, p := range f {
+		if p.Keyword && p.Default == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// bindKeywords takes the keyword arguments off args and binds them to the
+// keyword parameters. They are passed as a trailing keyword hash, which is
+// left to the positional parameters if they would lack an argument otherwise.
+func (f functionParameters) bindKeywords(args []RubyObject, positional functionParameters) ([]RubyObject, map[string]RubyObject, error) {
+	mandatory := 0
+	for _, p := range positional {
+		if p.Default == nil && !p.Splat {
+			mandatory++
+		}
+	}
+	given := &Hash{}
+	if len(args) > mandatory {
+		if hash, ok := args[len(args)-1].(*Hash); ok && hash.keywords {
+			given, args = hash, args[:len(args)-1]
+		}
+	}
+	unknown := &Hash{}
+	for _, pair := range given.Map {
+		unknown.Set(pair.Key, pair.Value)
+	}
+	params := make(map[string]RubyObject)
+	var missing []RubyObject
+	var rest *FunctionParameter
+	for _, p := range f {
+		if p.DoubleSplat {
+			rest = p
+			continue
+		}
+		key := NewSymbol(p.Name)
+		if value, ok := given.Get(key); ok {
+			params[p.Name] = value
+			delete(unknown.Map, key.HashKey())
+			continue
+		}
+		if p.Default == nil {
+			missing = append(missing, key)
+			continue
+		}
+		params[p.Name] = p.Default
+	}
+	if len(missing) != 0 {
+		return nil, nil, NewMissingKeywordError(missing...)
+	}
+	if rest != nil {
+		params[rest.Name] = unknown
+		return args, params, nil
+	}
+	if len(unknown.Map) != 0 {
+		keys := make([]RubyObject, 0, len(unknown.Map))
+		for _, pair := range unknown.Map {
+			keys = append(keys, pair.Key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Inspect() < keys[j].Inspect() })
+		return nil, nil, NewUnknownKeywordError(keys...)
+	}
+	return args, params, nil
+}
+
+// positionalArguments returns args with a trailing keyword hash which no
+// keyword parameter took turned into an ordinary hash, so that passing it on
+// from the parameter it is bound to passes a positional argument.
+func positionalArguments(args []RubyObject) []RubyObject {
+	if len(args) == 0 {
+		return args
+	}
+	if hash, ok := args[len(args)-1].(*Hash); ok && hash.keywords {
+		args = append(args[:len(args)-1:len(args)-1], &Hash{Map: hash.Map})
+	}
+	return args
+}
+
+// blockArguments adapts args to the parameters of a block the way Ruby does
+// for procs: a single array is spread over several parameters, missing
+// arguments are nil and surplus ones are dropped.
+func (f functionParameters) blockArguments(args []RubyObject) []RubyObject {
+	if len(f) > 1 && len(args) == 1 {
+		if arr, ok := args[0].(*Array); ok {
+			args = arr.Elements
+		}
+	}
+	for _, p := range f {
+		if p.Splat {
+			return args
+		}
+	}
+	if len(args) > len(f) {
+		return args[:len(f)]
+	}
+	for mandatory := len(f) - f.defaultParamCount(); len(args) < mandatory; {
+		args = append(args, NIL)
+	}
+	return args
+}
+
+// arity returns the number of mandatory parameters, or its one's complement
+// if optional or splat parameters make the number of arguments variable.
+// Keywords count as one more parameter, which is mandatory if any of them is.
+func (f functionParameters) arity() int {
+	params, _ := f.withoutBlock()
+	params, keywords := params.withoutKeywords()
+	required, variable := 0, false
+	for _, p := range params {
+		if p.Default != nil || p.Splat {
+			variable = true
+			continue
+		}
+		required++
+	}
+	if len(keywords) != 0 {
+		if keywords.requireKeywords() {
+			required++
 		} else {
-			mandatory = append(mandatory, p)
+			variable = true
+		}
+	}
+	if variable {
+		return -required - 1
+	}
+	return required
+}
+
+// describe returns the parameters as pairs of their kind and name, as
+// returned by Proc#parameters. The mandatory parameters of procs are reported
+// as optional since procs don't enforce them.
+func (f functionParameters) describe(lambda bool) *Array {
+	described := NewArray()
+	for _, p := range f {
+		kind := "req"
+		switch {
+		case p.Block:
+			kind = "block"
+		case p.Splat:
+			kind = "rest"
+		case p.DoubleSplat:
+			kind = "keyrest"
+		case p.Keyword && p.Default == nil:
+			kind = "keyreq"
+		case p.Keyword:
+			kind = "key"
+		case p.Default != nil || !lambda:
+			kind = "opt"
+		}
+		if len(p.Destructure) != 0 {
+			// destructuring parameters have no name
+			described.Elements = append(described.Elements, NewArray(NewSymbol(kind)))
+			continue
 		}
+		described.Elements = append(described.Elements, NewArray(NewSymbol(kind), NewSymbol(p.Name)))
 	}
-	return mandatory, defaults
+	return described
 }
 
 // FunctionParameter represents a parameter within a function
 type FunctionParameter struct {
-	Name    string
-	Default RubyObject
-	Splat   bool
+	Name        string
+	Default     RubyObject
+	Splat       bool
+	Block       bool
+	Keyword     bool                 // a keyword parameter, required if it has no default
+	DoubleSplat bool                 // collects the keywords not taken by any other parameter
+	Destructure []*FunctionParameter // nested parameters of a destructuring parameter, e.g. `(k, v)`
 }
 
 func (f *FunctionParameter) String() string {
@@ -103,7 +318,28 @@ func (f *FunctionParameter) String() string {
 	if f.Splat {
 		out.WriteString("*")
 	}
+	if f.DoubleSplat {
+		out.WriteString("**")
+	}
+	if f.Block {
+		out.WriteString("&")
+	}
 	out.WriteString(f.Name)
+	if len(f.Destructure) != 0 {
+		nested := make([]string, len(f.Destructure))
+		for i, param := range f.Destructure {
+			nested[i] = param.String()
+		}
+		out.WriteString("(" + strings.Join(nested, ", ") + ")")
+	}
+	if f.Keyword {
+		out.WriteString(":")
+		if f.Default != nil {
+			out.WriteString(" ")
+			out.WriteString(f.Default.Inspect())
+		}
+		return out.String()
+	}
 	if f.Default != nil {
 		out.WriteString(" = ")
 		out.WriteString(f.Default.Inspect())
@@ -117,6 +353,106 @@ type Function struct {
 	Parameters []*FunctionParameter
 	Body       *ast.BlockStatement
 	Env        Environment
+	Scope      string    // label of the frame an anonymous function was created in
+	Home       *Frame    // frame of the method a block was created in, left by a return within the block
+	Owner      RubyClass // class the method is defined in, used to resolve super
+}
+
+// IsAnonymous returns true for blocks and lambdas
+func (f *Function) IsAnonymous() bool {
+	return strings.HasPrefix(f.Name, "__")
+}
+
+// IsLambda returns true for lambdas. Unlike blocks they handle break and
+// return themselves.
+func (f *Function) IsLambda() bool {
+	return strings.HasPrefix(f.Name, "__lambda_")
+}
+
+// asLambda returns a copy of the block f which behaves like a lambda, as
+// created by `lambda { ... }`
+func (f *Function) asLambda() *Function {
+	if !f.IsAnonymous() || f.IsLambda() {
+		return f
+	}
+	lambda := *f
+	lambda.Name = "__lambda_" + strings.TrimPrefix(f.Name, "__block_")
+	return &lambda
+}
+
+// pushFrame records the invocation of f on the call stack of context, if it
+// keeps one. It returns the new frame and a function popping it again.
+func (f *Function) pushFrame(context CallContext) (*Frame, func()) {
+	stack := callStackOf(context)
+	if stack == nil {
+		return nil, func() {}
+	}
+	if f.IsAnonymous() {
+		return stack.Push(blockLabel(f.Scope), ""), stack.Pop
+	}
+	var frame *Frame
+	receiver := context.Receiver()
+	switch {
+	case receiver == FUNCS_STORE || receiver.Class() == nil:
+		frame = stack.Push(f.Name, "")
+	case isClassObject(receiver):
+		// class methods are labelled e.g. 'Foo.create'
+		frame = stack.Push(receiver.(RubyClass).Name()+"."+f.Name, "")
+	default:
+		frame = stack.Push(f.Name, receiver.Class().Name())
+	}
+	frame.Function = f
+	return frame, stack.Pop
+}
+
+// enclose returns the environment f.Body is evaluated in. Methods see their
+// receiver as self and start without a last match, whereas blocks share both
+// with the scope they were created in.
+func (f *Function) enclose(context CallContext) Environment {
+	env := NewEnclosedEnvironment(f.Env)
+	if !f.IsAnonymous() {
+		env.Set("self", context.Receiver())
+		env.Set(lastMatchVariable, NIL)
+	}
+	return env
+}
+
+// parameterValues returns the current values of the parameters of f within
+// env, as passed on by a bare super. Keywords are passed on as a trailing
+// hash.
+func (f *Function) parameterValues(env Environment) []RubyObject {
+	var values []RubyObject
+	params, _ := functionParameters(f.Parameters).withoutBlock()
+	params, keywords := params.withoutKeywords()
+	for _, param := range params {
+		value, ok := env.Get(param.Name)
+		if !ok {
+			continue
+		}
+		if arr, isArray := value.(*Array); isArray && param.Splat {
+			values = append(values, arr.Elements...)
+			continue
+		}
+		values = append(values, value)
+	}
+	if len(keywords) == 0 {
+		return values
+	}
+	hash := &Hash{keywords: true}
+	for _, param := range keywords {
+		value, ok := env.Get(param.Name)
+		if !ok {
+			continue
+		}
+		if rest, isHash := value.(*Hash); isHash && param.DoubleSplat {
+			for _, pair := range rest.Map {
+				hash.Set(pair.Key, pair.Value)
+			}
+			continue
+		}
+		hash.Set(NewSymbol(param.Name), value)
+	}
+	return append(values, hash)
 }
 
 // String returns the function literal
@@ -145,74 +481,213 @@ func (f *Function) Call(context CallContext, tracer trace.Tracer, args ...RubyOb
 		tracer.Message(f.Name)
 		tracer.Message(f.String())
 	}
-	// TODO: Handle tail splats
-	if len(f.Parameters) == 1 && f.Parameters[0].Splat {
-		// Only one splat parameter.
-		args_arr := NewArray(args...)
-		extendedEnv := NewEnclosedEnvironment(f.Env)
-		extendedEnv.Set(f.Parameters[0].Name, args_arr)
-		evaluated, err := context.Eval(f.Body, extendedEnv)
-		if err != nil {
-			return nil, err
+	parameters, blockParam := functionParameters(f.Parameters).withoutBlock()
+	parameters, keywords := parameters.withoutKeywords()
+	var block *Proc
+	if f.IsAnonymous() {
+		// blocks and lambdas yield to the block of the method they were
+		// created in
+		if f.Home != nil {
+			block = f.Home.Block
 		}
-		return f.unwrapReturnValue(evaluated), nil
-
 	} else {
-		// normal evaluation
-		defaultParams := functionParameters(f.Parameters).defaultParamCount()
-		if len(args) < len(f.Parameters)-defaultParams || len(args) > len(f.Parameters) {
-			return nil, NewWrongNumberOfArgumentsError(len(f.Parameters), len(args))
-		}
-		params, err := f.populateParameters(args)
+		block = blockOf(context)
+		args = withoutBlockArgument(args, block)
+	}
+	frame, pop := f.pushFrame(context)
+	defer pop()
+	if frame != nil {
+		frame.Block = block
+	}
+	var keywordParams map[string]RubyObject
+	if len(keywords) != 0 {
+		var err error
+		args, keywordParams, err = keywords.bindKeywords(args, parameters)
 		if err != nil {
 			return nil, err
 		}
-		extendedEnv := NewEnclosedEnvironment(f.Env)
-		for k, v := range params {
-			extendedEnv.Set(k, v)
-		}
-		evaluated, err := context.Eval(f.Body, extendedEnv)
-		if err != nil {
+	}
+	args = positionalArguments(args)
+	if f.IsAnonymous() && !f.IsLambda() {
+		args = parameters.blockArguments(args)
+	}
+	values, err := parameters.positionalValues(args, f.IsAnonymous() && !f.IsLambda())
+	if err != nil {
+		return nil, err
+	}
+	extendedEnv := f.enclose(context)
+	for i, param := range parameters {
+		if err := bindParameter(context, tracer, extendedEnv, param, values[i]); err != nil {
 			return nil, err
 		}
-		return f.unwrapReturnValue(evaluated), nil
 	}
+	for k, v := range keywordParams {
+		extendedEnv.Set(k, v)
+	}
+	f.setBlockParameter(extendedEnv, blockParam, block)
+	return f.evalBody(context, frame, extendedEnv)
 }
 
-func (f *Function) populateParameters(args []RubyObject) (map[string]RubyObject, error) {
-	if len(args) > len(f.Parameters) {
-		return nil, NewWrongNumberOfArgumentsError(len(f.Parameters), len(args))
+// positionalValues spreads args over the positional parameters f and returns
+// the value of each. The mandatory parameters before and after a splat come
+// first, optional ones take the arguments left over in order, and the splat
+// collects the rest, just like a multiple assignment does. Unless loose is
+// set, as it is for procs, the number of arguments has to fit the parameters.
+func (f functionParameters) positionalValues(args []RubyObject, loose bool) ([]RubyObject, error) {
+	mandatory, optional, splat := 0, 0, false
+	for _, p := range f {
+		switch {
+		case p.Splat:
+			splat = true
+		case p.Default != nil:
+			optional++
+		default:
+			mandatory++
+		}
 	}
-	params := make(map[string]RubyObject)
-
-	mandatory, defaults := functionParameters(f.Parameters).separateDefaultParams()
+	if !loose && len(args) < mandatory {
+		return nil, NewWrongNumberOfArgumentsError(mandatory, len(args))
+	}
+	if !loose && !splat && len(args) > mandatory+optional {
+		return nil, NewWrongNumberOfArgumentsError(mandatory+optional, len(args))
+	}
+	// optional parameters beyond the arguments left over keep their default
+	filled := min(optional, max(len(args)-mandatory, 0))
+	values := make([]RubyObject, len(f))
+	var bound []int
+	splatIndex := -1
+	for i, p := range f {
+		if p.Default != nil && !p.Splat {
+			if filled == 0 {
+				values[i] = p.Default
+				continue
+			}
+			filled--
+		}
+		if p.Splat {
+			splatIndex = len(bound)
+		}
+		bound = append(bound, i)
+	}
+	for i, value := range Distribute(args, len(bound), splatIndex) {
+		values[bound[i]] = value
+	}
+	return values, nil
+}
 
-	if len(args) < len(mandatory)-len(defaults) || len(args) > len(f.Parameters) {
-		return nil, NewWrongNumberOfArgumentsError(len(f.Parameters), len(args))
+// bindParameter sets param to value within env. A destructuring parameter,
+// as in `|(k, v), i|`, spreads value over its nested parameters the way a
+// multiple assignment does.
+func bindParameter(context CallContext, tracer trace.Tracer, env Environment, param *FunctionParameter, value RubyObject) error {
+	if len(param.Destructure) == 0 {
+		env.Set(param.Name, value)
+		return nil
 	}
+	values, err := ImplicitArray(context, tracer, value)
+	if err != nil {
+		return err
+	}
+	splat := -1
+	for i, nested := range param.Destructure {
+		if nested.Splat {
+			splat = i
+		}
+	}
+	for i, value := range Distribute(values, len(param.Destructure), splat) {
+		if err := bindParameter(context, tracer, env, param.Destructure[i], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if len(args) == len(f.Parameters) {
-		for paramIdx, param := range f.Parameters {
-			params[param.Name] = args[paramIdx]
+// withoutBlockArgument removes block from the end of args. Builtin methods
+// receive the block of a call as their last argument, either the name of a
+// block literal or a proc passed with &, whereas methods defined in Ruby
+// access it through yield or an explicit block parameter.
+func withoutBlockArgument(args []RubyObject, block *Proc) []RubyObject {
+	if block == nil || len(args) == 0 {
+		return args
+	}
+	switch last := args[len(args)-1].(type) {
+	case *Proc:
+		if last == block {
+			return args[:len(args)-1]
+		}
+	case *Symbol:
+		if block.Function != nil && last.Value == block.Function.Name {
+			return args[:len(args)-1]
 		}
-		return params, nil
 	}
+	return args
+}
 
-	parameters := append(mandatory, defaults...)
+// setBlockParameter binds the block passed to a method to its explicit block
+// parameter, or to nil if no block was given. Blocks themselves never receive
+// one.
+func (f *Function) setBlockParameter(env Environment, param *FunctionParameter, block *Proc) {
+	if param == nil {
+		return
+	}
+	if block == nil || f.IsAnonymous() {
+		env.Set(param.Name, NIL)
+		return
+	}
+	env.Set(param.Name, block)
+}
 
-	for paramIdx, param := range parameters {
-		if paramIdx >= len(args) {
-			params[param.Name] = param.Default
+// evalBody evaluates the body of f within env and resolves the control flow
+// leaving it. Within a block, next and redo end or restart the invocation,
+// whereas break and return unwind the stack up to the method call the block
+// is attached to and the method the block was created in respectively.
+// Lambdas return on both. A method returns on a return of its own or one
+// unwinding from a block created within its frame.
+func (f *Function) evalBody(context CallContext, frame *Frame, env Environment) (RubyObject, error) {
+	for {
+		evaluated, err := context.Eval(f.Body, env)
+		if err != nil {
+			if ret, ok := errors.Cause(err).(*ReturnError); ok && frame != nil && ret.Frame == frame {
+				return ret.Value, nil
+			}
+			return nil, err
+		}
+		block := f.IsAnonymous() && !f.IsLambda()
+		switch evaluated := evaluated.(type) {
+		case *RedoValue:
 			continue
+		case *NextValue:
+			return evaluated.Value, nil
+		case *BreakValue:
+			if block {
+				return nil, &BreakError{Function: f, Value: evaluated.Value}
+			}
+			return evaluated.Value, nil
+		case *ReturnValue:
+			if block {
+				return nil, &ReturnError{Frame: f.Home, Value: evaluated.Value}
+			}
+			return evaluated.Value, nil
 		}
-		params[param.Name] = args[paramIdx]
+		return evaluated, nil
 	}
-	return params, nil
 }
 
-func (f *Function) unwrapReturnValue(obj RubyObject) RubyObject {
-	if returnValue, ok := obj.(*ReturnValue); ok {
-		return returnValue.Value
-	}
-	return obj
+// A BreakError unwinds the stack from a break within a block up to the method
+// call the block is attached to, which returns Value. It is no Ruby exception
+// and cannot be rescued.
+type BreakError struct {
+	Function *Function
+	Value    RubyObject
 }
+
+func (e *BreakError) Error() string { return "break from proc-closure" }
+
+// A ReturnError unwinds the stack from a return within a block up to the
+// method the block was created in, which returns Value. It is no Ruby
+// exception and cannot be rescued.
+type ReturnError struct {
+	Frame *Frame
+	Value RubyObject
+}
+
+func (e *ReturnError) Error() string { return "unexpected return" }
@@ -201,4 +201,150 @@ func TestFunctionCall(t *testing.T) {
 			utils.AssertNoError(t, err)
 		})
 	})
+	t.Run("passes the block of the call to an explicit block parameter", func(t *testing.T) {
+		var evalEnv Environment
+		block := NewProc(&Function{Name: "__block_test"})
+		context := &testBlockContext{
+			callContext: &callContext{
+				env: NewMainEnvironment(),
+				eval: func(node ast.Node, env Environment) (RubyObject, error) {
+					evalEnv = env
+					return nil, nil
+				},
+			},
+			block: block,
+		}
+
+		function := &Function{
+			Name: "foo",
+			Parameters: []*FunctionParameter{
+				{Name: "x"},
+				{Name: "blk", Block: true},
+			},
+		}
+
+		// the block symbol passed on to builtins is dropped
+		_, err := function.Call(context, nil, NewInteger(1), NewSymbol(block.Function.Name))
+		utils.AssertNoError(t, err)
+
+		actual, ok := evalEnv.Get("blk")
+		utils.Assert(t, ok, "Expected block parameter %q to be in Eval env", "blk")
+		proc, ok := actual.(*Proc)
+		utils.Assert(t, ok, "Expected block parameter to be a *Proc, got %T", actual)
+		utils.Assert(t, proc == block, "Expected the block Proc itself")
+	})
+	t.Run("binds keyword arguments", func(t *testing.T) {
+		function := &Function{
+			Name: "foo",
+			Parameters: []*FunctionParameter{
+				{Name: "x"},
+				{Name: "opt", Keyword: true, Default: NewInteger(2)},
+				{Name: "req", Keyword: true},
+				{Name: "rest", DoubleSplat: true},
+			},
+		}
+		call := func(args ...RubyObject) (Environment, error) {
+			var evalEnv Environment
+			context := &callContext{
+				env: NewMainEnvironment(),
+				eval: func(node ast.Node, env Environment) (RubyObject, error) {
+					evalEnv = env
+					return nil, nil
+				},
+			}
+			_, err := function.Call(context, nil, args...)
+			return evalEnv, err
+		}
+		keywords := func(pairs ...RubyObject) *Hash {
+			hash := &Hash{}
+			for i := 0; i < len(pairs); i += 2 {
+				hash.Set(pairs[i], pairs[i+1])
+			}
+			return KeywordArguments(hash)
+		}
+
+		env, err := call(NewInteger(1), keywords(NewSymbol("req"), NewInteger(3), NewSymbol("other"), NewInteger(4)))
+		utils.AssertNoError(t, err)
+
+		expected := map[string]RubyObject{
+			"x":   NewInteger(1),
+			"opt": NewInteger(2),
+			"req": NewInteger(3),
+		}
+		for name, value := range expected {
+			actual, ok := env.Get(name)
+			utils.Assert(t, ok, "Expected parameter %q to be in Eval env", name)
+			utils.AssertEqualCmpAny(t, actual, value, CompareRubyObjectsForTests)
+		}
+		rest, _ := env.Get("rest")
+		utils.AssertEqual(t, len(rest.(*Hash).Map), 1)
+		other, _ := rest.(*Hash).Get(NewSymbol("other"))
+		utils.AssertEqualCmpAny(t, other, NewInteger(4), CompareRubyObjectsForTests)
+
+		_, err = call(NewInteger(1), keywords(NewSymbol("opt"), NewInteger(3)))
+		utils.AssertError(t, err, NewMissingKeywordError(NewSymbol("req")))
+
+		_, err = call(keywords(NewSymbol("req"), NewInteger(3)))
+		utils.AssertError(t, err, NewMissingKeywordError(NewSymbol("req")))
+	})
+	t.Run("rejects unknown keywords", func(t *testing.T) {
+		function := &Function{
+			Name:       "foo",
+			Parameters: []*FunctionParameter{{Name: "opt", Keyword: true, Default: NIL}},
+		}
+		context := &callContext{
+			env:  NewMainEnvironment(),
+			eval: func(node ast.Node, env Environment) (RubyObject, error) { return nil, nil },
+		}
+		keywords := KeywordArguments(&Hash{})
+		keywords.Set(NewSymbol("b"), NIL)
+		keywords.Set(NewSymbol("a"), NIL)
+
+		_, err := function.Call(context, nil, keywords)
+
+		utils.AssertError(t, err, NewUnknownKeywordError(NewSymbol("a"), NewSymbol("b")))
+	})
+	t.Run("adapts the arguments of a block to its parameters", func(t *testing.T) {
+		var evalEnv Environment
+		context := &callContext{
+			env: NewMainEnvironment(),
+			eval: func(node ast.Node, env Environment) (RubyObject, error) {
+				evalEnv = env
+				return nil, nil
+			},
+		}
+
+		block := &Function{
+			Name: "__block_test",
+			Parameters: []*FunctionParameter{
+				{Name: "a"},
+				{Name: "b"},
+			},
+		}
+
+		tests := []struct {
+			args []RubyObject
+			a, b RubyObject
+		}{
+			{[]RubyObject{NewArray(NewInteger(1), NewInteger(2))}, NewInteger(1), NewInteger(2)},
+			{[]RubyObject{NewInteger(1)}, NewInteger(1), NIL},
+			{[]RubyObject{NewInteger(1), NewInteger(2), NewInteger(3)}, NewInteger(1), NewInteger(2)},
+		}
+		for _, tt := range tests {
+			_, err := block.Call(context, nil, tt.args...)
+			utils.AssertNoError(t, err)
+
+			a, _ := evalEnv.Get("a")
+			utils.AssertEqualCmpAny(t, tt.a, a, CompareRubyObjectsForTests)
+			b, _ := evalEnv.Get("b")
+			utils.AssertEqualCmpAny(t, tt.b, b, CompareRubyObjectsForTests)
+		}
+	})
 }
+
+type testBlockContext struct {
+	*callContext
+	block *Proc
+}
+
+func (c *testBlockContext) Block() *Proc { return c.block }
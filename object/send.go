@@ -13,26 +13,77 @@ func Send(context CallContext, method string, tracer trace.Tracer, args ...RubyO
 		tracer.Message(method)
 	}
 	receiver := context.Receiver()
-	class := receiver.Class()
-
-	// search for the method in the ancestry tree
-	for class != nil {
-		fn, ok := class.GetMethod(method)
-		if !ok {
-			if class == bottomClass {
-				// no method and we are at the top of the ancestry tree
-				break
-			}
-			class = bottomClass
-			continue
+	fn, ok := lookupMethod(receiver.Class(), method)
+	if !ok {
+		return nil, NewNoMethodError(receiver, method)
+	}
+	return fn.Call(context, tracer, args...)
+}
+
+// lookupMethod searches the method name in class and its ancestors. The
+// methods of Bottom are available to every object.
+func lookupMethod(class RubyClass, name string) (RubyMethod, bool) {
+	for _, c := range ancestorsOf(class) {
+		if fn, ok := c.GetMethod(name); ok {
+			return fn, true
 		}
+	}
+	return bottomClass.GetMethod(name)
+}
 
-		return fn.Call(context, tracer, args...)
+// lookupSuperMethod searches the method name in the ancestors of class which
+// follow owner, i.e. the class or module defining the method calling super
+func lookupSuperMethod(class, owner RubyClass, name string) (RubyMethod, bool) {
+	ancestors := ancestorsOf(class)
+	for i, c := range ancestors {
+		if c != owner {
+			continue
+		}
+		for _, c := range ancestors[i+1:] {
+			if fn, ok := c.GetMethod(name); ok {
+				return fn, true
+			}
+		}
+		return bottomClass.GetMethod(name)
 	}
+	return lookupMethod(superClassOf(owner), name)
+}
 
-	// fmt.Printf("receiver: %v(%T)\n", receiver, receiver)
-	// fmt.Printf("method: %v(%T)\n", method, method)
-	return nil, NewNoMethodError(receiver, method)
+// RespondTo returns true if obj has a method called name
+func RespondTo(obj RubyObject, name string) bool {
+	_, ok := lookupMethod(obj.Class(), name)
+	return ok
+}
+
+// Super calls the implementation of the currently executing method which the
+// ancestors of the receiver following its owner provide. With implicitArgs, as for a bare `super`,
+// the current values of the parameters of the executing method are passed on
+// instead of args.
+func Super(context CallContext, tracer trace.Tracer, implicitArgs bool, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	var function *Function
+	if stack := callStackOf(context); stack != nil {
+		if frame := stack.MethodFrame(); frame != nil {
+			function = frame.Function
+		}
+	}
+	if function == nil {
+		return nil, NewRuntimeError("super called outside of method")
+	}
+	if implicitArgs {
+		args = function.parameterValues(context.Env())
+	}
+	receiver := context.Receiver()
+	if function.Owner == nil {
+		return nil, NewNoSuperclassMethodError(receiver, function.Name)
+	}
+	fn, ok := lookupSuperMethod(receiver.Class(), function.Owner, function.Name)
+	if !ok {
+		return nil, NewNoSuperclassMethodError(receiver, function.Name)
+	}
+	return fn.Call(context, tracer, args...)
 }
 
 func newEigenclass(wrappedClass RubyClass) *eigenclass {
@@ -45,9 +96,15 @@ func newEigenclass(wrappedClass RubyClass) *eigenclass {
 type eigenclass struct {
 	methods      SettableMethodSet
 	wrappedClass RubyClass
+	parent       *eigenclass // class methods of the super class, if any
+	attached     *class      // the class or module, for metaclasses
+	includes     []*class    // modules extending the object, the most recent first
 }
 
 func (e *eigenclass) Inspect() string {
+	if e.attached != nil {
+		return e.attached.kind().Name()
+	}
 	if e.wrappedClass == nil_class {
 		return "(eigenclass of nil)"
 	}
@@ -62,13 +119,19 @@ func (e *eigenclass) Class() RubyClass {
 }
 func (e *eigenclass) Methods() MethodSet { return e.methods }
 func (e *eigenclass) GetMethod(name string) (RubyMethod, bool) {
-	if method, ok := e.methods.Get(name); ok {
-		return method, true
-	}
-	return nil, false
+	return e.methods.Get(name)
 }
 
+// SuperClass returns the class methods of the super class for metaclasses,
+// ending in the instance methods of Class or Module, and the class of the
+// extended object otherwise
 func (e *eigenclass) SuperClass() RubyClass {
+	if e.parent != nil {
+		return e.parent
+	}
+	if e.attached != nil {
+		return e.attached.kind()
+	}
 	if e.wrappedClass != nil {
 		return e.wrappedClass
 	}
@@ -77,7 +140,12 @@ func (e *eigenclass) SuperClass() RubyClass {
 func (e *eigenclass) New(args ...RubyObject) (RubyObject, error) {
 	return e.wrappedClass.New(args...)
 }
-func (e *eigenclass) Name() string { return e.wrappedClass.Name() }
+func (e *eigenclass) Name() string {
+	if e.attached != nil {
+		return e.attached.kind().Name()
+	}
+	return e.wrappedClass.Name()
+}
 func (e *eigenclass) addMethod(name string, method RubyMethod) {
 	e.methods.Set(name, method)
 }
@@ -100,6 +168,7 @@ var (
 type extendedObject struct {
 	RubyObject
 	eigenclass *eigenclass
+	instanceVariables
 }
 
 func newExtendedObject(object RubyObject) *extendedObject {
@@ -153,6 +222,9 @@ func AddMethod(context RubyObject, methodName string, method *Function) (RubyObj
 	if !is_extendable {
 		extended = newExtendedObject(context)
 	}
+	if class, ok := extended.(RubyClass); ok {
+		method.Owner = class
+	}
 	extended.addMethod(methodName, method)
 	return extended, !is_extendable
 }
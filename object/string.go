@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/MarcinKonowalczyk/goruby/trace"
 )
@@ -82,7 +83,14 @@ var stringClassMethods = map[string]RubyMethod{}
 var stringMethods = map[string]RubyMethod{
 	"to_s":   withArity(0, newMethod(stringToS)),
 	"+":      withArity(1, newMethod(stringAdd)),
-	"gsub":   withArity(2, newMethod(stringGsub)),
+	"<<":     withArity(1, newMethod(stringAppend)),
+	"gsub":   newMethod(stringGsub),
+	"sub":    newMethod(stringSub),
+	"=~":     withArity(1, newMethod(stringMatchOperator)),
+	"match":  newMethod(stringMatch),
+	"match?": newMethod(stringIsMatch),
+	"scan":   newMethod(stringScan),
+	"split":  newMethod(stringSplit),
 	"length": withArity(0, newMethod(stringLength)),
 	"size":   withArity(0, newMethod(stringLength)),
 	"lines":  withArity(0, newMethod(stringLines)),
@@ -115,30 +123,305 @@ func stringAdd(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 	return NewString(s.Value + add.Value), nil
 }
 
+// stringAppend appends a String, or the character of an Integer codepoint, to
+// the receiver in place
+func stringAppend(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	s := context.Receiver().(*String)
+	switch arg := args[0].(type) {
+	case *String:
+		s.Value += arg.Value
+	case *Integer:
+		if arg.Value < 0 || arg.Value > utf8.MaxRune || !utf8.ValidRune(rune(arg.Value)) {
+			return nil, NewArgumentError("invalid codepoint %d", arg.Value)
+		}
+		s.Value += string(rune(arg.Value))
+	default:
+		return nil, NewImplicitConversionTypeError(s, args[0])
+	}
+	return s, nil
+}
+
 func stringGsub(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return substitute(context, tracer, true, args...)
+}
+
+func stringSub(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	return substitute(context, tracer, false, args...)
+}
+
+// substitute implements String#sub and String#gsub. The replacement is either
+// a string, which may reference the groups of the match, a hash mapping the
+// matched text to its replacement or the result of the block.
+func substitute(context CallContext, tracer trace.Tracer, global bool, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	s := context.Receiver().(*String)
-	pattern, ok := args[0].(*String)
-	if !ok {
-		return nil, NewImplicitConversionTypeError(pattern, args[0])
+	block := blockOf(context)
+	args = withoutBlockArgument(args, block)
+	if len(args) == 0 || len(args) > 2 || (len(args) == 1 && block == nil) {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
 	}
-	replacement, ok := args[1].(*String)
-	if !ok {
-		return nil, NewImplicitConversionTypeError(replacement, args[1])
+	re, err := regexpOf(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*MatchData
+	if global {
+		matches = re.MatchAll(s.Value)
+	} else if match := re.Match(s.Value, 0); match != nil {
+		matches = append(matches, match)
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, match := range matches {
+		setLastMatch(context, match)
+		var replacement string
+		switch {
+		case len(args) == 1:
+			value, err := block.Call(context, tracer, NewString(match.Group(0)))
+			if err != nil {
+				return nil, err
+			}
+			if replacement, err = stringify(value); err != nil {
+				return nil, err
+			}
+		default:
+			switch with := args[1].(type) {
+			case *String:
+				replacement = expandReplacement(with.Value, match)
+			case *Hash:
+				value, ok := with.Get(NewString(match.Group(0)))
+				if ok {
+					if replacement, err = stringify(value); err != nil {
+						return nil, err
+					}
+				}
+			default:
+				return nil, NewImplicitConversionTypeError(NewString(""), args[1])
+			}
+		}
+		out.WriteString(s.Value[last:match.indices[0]])
+		out.WriteString(replacement)
+		last = match.indices[1]
 	}
+	if len(matches) == 0 {
+		setLastMatch(context, NIL)
+	}
+	out.WriteString(s.Value[last:])
+	return NewString(out.String()), nil
+}
+
+// expandReplacement returns the replacement string template with its
+// references to the groups of match expanded, i.e. `\0` and `\&` for the
+// whole match, `\1` to `\9` and `\k<name>` for the groups and `\\` for a
+// backslash
+func expandReplacement(template string, match *MatchData) string {
+	var out strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '\\' || i+1 == len(template) {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		switch ref := template[i]; {
+		case ref >= '0' && ref <= '9':
+			out.WriteString(match.Group(int(ref - '0')))
+		case ref == '&':
+			out.WriteString(match.Group(0))
+		case ref == '`':
+			out.WriteString(match.PreMatch())
+		case ref == '\'':
+			out.WriteString(match.PostMatch())
+		case ref == '\\':
+			out.WriteByte('\\')
+		case ref == 'k' && strings.HasPrefix(template[i+1:], "<") && strings.Contains(template[i+1:], ">"):
+			end := strings.Index(template[i+1:], ">")
+			name := template[i+2 : i+1+end]
+			if index, err := match.groupIndex(NewString(name)); err == nil {
+				out.WriteString(match.Group(index))
+			}
+			i += end + 1
+		default:
+			out.WriteByte('\\')
+			out.WriteByte(ref)
+		}
+	}
+	return out.String()
+}
+
+func stringMatchOperator(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if _, ok := args[0].(*String); ok {
+		return nil, NewTypeError("wrong argument type String (expected Regexp)")
+	}
+	return Send(withReceiver(context, args[0]), "=~", tracer, context.Receiver())
+}
+
+// patternOf returns the Regexp for the pattern argument of String#match and
+// String#match?, which compile strings as regular expressions
+func patternOf(obj RubyObject) (*Regexp, error) {
+	if str, ok := obj.(*String); ok {
+		return NewRegexp(str.Value, 0)
+	}
+	return regexpOf(obj)
+}
 
-	// Perform the gsub operation
-	re, err := regexp.Compile(pattern.Value)
+func stringMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	block := blockOf(context)
+	args = withoutBlockArgument(args, block)
+	if len(args) == 0 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	re, err := patternOf(args[0])
 	if err != nil {
-		return nil, NewTypeError(fmt.Sprintf("Invalid regex pattern: %s", err))
+		return nil, err
+	}
+	matchArgs := append([]RubyObject{context.Receiver()}, args[1:]...)
+	if block != nil {
+		matchArgs = append(matchArgs, block)
 	}
+	return regexpMatch(withReceiver(context, re), tracer, matchArgs...)
+}
 
-	result := re.ReplaceAllString(s.Value, replacement.Value)
+func stringIsMatch(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	re, err := patternOf(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return regexpIsMatch(withReceiver(context, re), tracer, append([]RubyObject{context.Receiver()}, args[1:]...)...)
+}
 
-	// Return the modified string
-	return NewString(result), nil
+func stringScan(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	s := context.Receiver().(*String)
+	block := blockOf(context)
+	args = withoutBlockArgument(args, block)
+	if len(args) != 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	re, err := regexpOf(args[0])
+	if err != nil {
+		return nil, err
+	}
+	result := NewArray()
+	var last RubyObject = NIL
+	for _, match := range re.MatchAll(s.Value) {
+		last = match
+		var value RubyObject
+		if match.Size() == 1 {
+			value = NewString(match.Group(0))
+		} else {
+			captures := NewArray()
+			for i := 1; i < match.Size(); i++ {
+				captures.Elements = append(captures.Elements, match.group(i))
+			}
+			value = captures
+		}
+		if block == nil {
+			result.Elements = append(result.Elements, value)
+			continue
+		}
+		setLastMatch(context, match)
+		if _, err := block.Call(context, tracer, value); err != nil {
+			return nil, err
+		}
+	}
+	setLastMatch(context, last)
+	if block != nil {
+		return s, nil
+	}
+	return result, nil
+}
+
+// awkPattern splits strings at runs of whitespace, as `split` does with a
+// single space pattern
+var awkPattern, _ = NewRegexp(`\s+`, 0)
+
+func stringSplit(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	s := context.Receiver().(*String)
+	if len(args) > 2 {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	str := s.Value
+	re := awkPattern
+	if len(args) > 0 && args[0] != NIL {
+		if pattern, ok := args[0].(*String); !ok || pattern.Value != " " {
+			var err error
+			if re, err = regexpOf(args[0]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if re == awkPattern {
+		str = strings.TrimLeft(str, " \t\n\v\f\r")
+	}
+	limit := 0
+	if len(args) == 2 {
+		l, ok := args[1].(*Integer)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(NewInteger(0), args[1])
+		}
+		limit = int(l.Value)
+	}
+
+	result := NewArray()
+	if str == "" {
+		return result, nil
+	}
+	start, splits := 0, 0
+	for _, match := range re.MatchAll(str) {
+		if limit > 0 && splits >= limit-1 {
+			break
+		}
+		begin, end := match.indices[0], match.indices[1]
+		if begin == end && (begin == 0 || begin == len(str)) {
+			continue
+		}
+		result.Elements = append(result.Elements, NewString(str[start:begin]))
+		for i := 1; i < match.Size(); i++ {
+			if match.Matched(i) {
+				result.Elements = append(result.Elements, match.group(i))
+			}
+		}
+		start = end
+		splits++
+	}
+	result.Elements = append(result.Elements, NewString(str[start:]))
+	if limit == 0 {
+		for len(result.Elements) > 0 && result.Elements[len(result.Elements)-1].(*String).Value == "" {
+			result.Elements = result.Elements[:len(result.Elements)-1]
+		}
+	}
+	return result, nil
 }
 
 func stringLength(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -69,6 +69,33 @@ func Test_StringGsub(t *testing.T) {
 			NewString("fzzzzbar"),
 			nil,
 		},
+		{
+			[]RubyObject{NewString("."), NewString("-")},
+			NewString("foobar"),
+			nil,
+		},
+		{
+			[]RubyObject{mustRegexp(t, "[aeiou]", 0), NewString("<\\0>")},
+			NewString("f<o><o>b<a>r"),
+			nil,
+		},
+		{
+			[]RubyObject{mustRegexp(t, "(o+)(?<rest>.*)", 0), NewString("\\k<rest>\\1")},
+			NewString("fbaroo"),
+			nil,
+		},
+		{
+			[]RubyObject{mustRegexp(t, "[ab]", 0), &Hash{Map: map[HashKey]hashPair{
+				NewString("a").HashKey(): {NewString("a"), NewString("A")},
+			}}},
+			NewString("fooAr"),
+			nil,
+		},
+		{
+			[]RubyObject{NewInteger(1), NewString("")},
+			nil,
+			NewWrongArgumentTypeError(regexpClass, NewInteger(1)),
+		},
 	}
 
 	for _, testCase := range tests {
@@ -81,3 +108,115 @@ func Test_StringGsub(t *testing.T) {
 		utils.AssertEqualCmpAny(t, result, testCase.result, CompareRubyObjectsForTests)
 	}
 }
+
+func TestStringSub(t *testing.T) {
+	context := &callContext{receiver: NewString("foobar")}
+
+	result, err := stringSub(context, nil, mustRegexp(t, "o", 0), NewString("0"))
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewString("f0obar"), CompareRubyObjectsForTests)
+}
+
+func TestStringScan(t *testing.T) {
+	tests := []struct {
+		pattern RubyObject
+		result  RubyObject
+	}{
+		{mustRegexp(t, `\d+`, 0), NewArray(NewString("1"), NewString("22"), NewString("333"))},
+		{mustRegexp(t, `([a-z])(\d)`, 0), NewArray(
+			NewArray(NewString("a"), NewString("1")),
+			NewArray(NewString("b"), NewString("2")),
+			NewArray(NewString("c"), NewString("3")),
+		)},
+		{NewString("2"), NewArray(NewString("2"), NewString("2"))},
+		{mustRegexp(t, "x", 0), NewArray()},
+	}
+
+	for _, tt := range tests {
+		context := &callContext{receiver: NewString("a1b22c333")}
+
+		result, err := stringScan(context, nil, tt.pattern)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
+
+func TestStringSplit(t *testing.T) {
+	strings := func(values ...string) *Array {
+		arr := NewArray()
+		for _, value := range values {
+			arr.Elements = append(arr.Elements, NewString(value))
+		}
+		return arr
+	}
+	tests := []struct {
+		str    string
+		args   []RubyObject
+		result RubyObject
+	}{
+		{"  a b\tc  ", nil, strings("a", "b", "c")},
+		{"a b", []RubyObject{NewString(" ")}, strings("a", "b")},
+		{"a,b,,c,,", []RubyObject{NewString(",")}, strings("a", "b", "", "c")},
+		{"a,b,,c,,", []RubyObject{NewString(","), NewInteger(-1)}, strings("a", "b", "", "c", "", "")},
+		{"a-b-c", []RubyObject{NewString("-"), NewInteger(2)}, strings("a", "b-c")},
+		{"abc", []RubyObject{NewString("")}, strings("a", "b", "c")},
+		{"a, b,c", []RubyObject{mustRegexp(t, `,\s*`, 0)}, strings("a", "b", "c")},
+		{"a1b2c", []RubyObject{mustRegexp(t, `(\d)`, 0)}, strings("a", "1", "b", "2", "c")},
+		{"", []RubyObject{NewString(",")}, strings()},
+	}
+
+	for _, tt := range tests {
+		context := &callContext{receiver: NewString(tt.str)}
+
+		result, err := stringSplit(context, nil, tt.args...)
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, tt.result, CompareRubyObjectsForTests)
+	}
+}
+
+func TestStringMatchOperator(t *testing.T) {
+	t.Run("regexp", func(t *testing.T) {
+		context := &callContext{receiver: NewString("foobar")}
+
+		result, err := stringMatchOperator(context, nil, mustRegexp(t, "b", 0))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(3), CompareRubyObjectsForTests)
+	})
+	t.Run("string", func(t *testing.T) {
+		context := &callContext{receiver: NewString("foobar")}
+
+		_, err := stringMatchOperator(context, nil, NewString("b"))
+
+		utils.AssertError(t, err, NewTypeError("wrong argument type String (expected Regexp)"))
+	})
+}
+
+func TestStringAppend(t *testing.T) {
+	tests := []struct {
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{[]RubyObject{NewString(" bar")}, NewString("foo bar"), nil},
+		{[]RubyObject{NewInteger(0x1F600)}, NewString("foo😀"), nil},
+		{[]RubyObject{NewInteger(-1)}, nil, NewArgumentError("invalid codepoint -1")},
+		{[]RubyObject{NewSymbol("a")}, nil, NewImplicitConversionTypeError(NewString(""), NewSymbol("a"))},
+	}
+
+	for _, testCase := range tests {
+		receiver := NewString("foo")
+		context := &callContext{receiver: receiver}
+
+		result, err := stringAppend(context, nil, testCase.arguments...)
+
+		utils.AssertError(t, err, testCase.err)
+		utils.AssertEqualCmpAny(t, result, testCase.result, CompareRubyObjectsForTests)
+		if testCase.err == nil {
+			utils.AssertEqual(t, result, RubyObject(receiver))
+		}
+	}
+}
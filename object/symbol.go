@@ -83,9 +83,10 @@ var (
 var symbolClassMethods = map[string]RubyMethod{}
 
 var symbolMethods = map[string]RubyMethod{
-	"to_s": withArity(0, newMethod(symbolToS)),
-	"to_i": withArity(0, newMethod(symbolToI)),
-	"size": withArity(0, newMethod(symbolSize)),
+	"to_s":    withArity(0, newMethod(symbolToS)),
+	"to_i":    withArity(0, newMethod(symbolToI)),
+	"size":    withArity(0, newMethod(symbolSize)),
+	"to_proc": withArity(0, newMethod(symbolToProc)),
 }
 
 func symbolToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
@@ -93,11 +94,34 @@ func symbolToS(context CallContext, tracer trace.Tracer, args ...RubyObject) (Ru
 		defer tracer.Un(tracer.Trace(trace.Here()))
 	}
 	if sym, ok := context.Receiver().(*Symbol); ok {
+		if sym == NIL {
+			// nil.to_s is the empty string
+			return NewString(""), nil
+		}
 		return NewString(sym.Value), nil
 	}
 	return nil, nil
 }
 
+// symbolToProc returns a lambda sending the symbol to its first argument with
+// the remaining ones, as used in `list.map(&:to_s)`
+func symbolToProc(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+	if tracer != nil {
+		defer tracer.Un(tracer.Trace(trace.Here()))
+	}
+	sym, _ := context.Receiver().(*Symbol)
+	return &Proc{
+		lambda: true,
+		arity:  -2,
+		call: func(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
+			if len(args) == 0 {
+				return nil, NewArgumentError("no receiver given")
+			}
+			return Send(withoutBlock(withReceiver(context, args[0])), sym.Value, tracer, args[1:]...)
+		},
+	}, nil
+}
+
 func symbolSize(context CallContext, tracer trace.Tracer, args ...RubyObject) (RubyObject, error) {
 	if tracer != nil {
 		defer tracer.Un(tracer.Trace(trace.Here()))
@@ -31,6 +31,33 @@ func TestSymbolToS(t *testing.T) {
 	utils.AssertEqualCmpAny(t, result, expected, CompareRubyObjectsForTests)
 }
 
+func TestNilToS(t *testing.T) {
+	result, err := symbolToS(&callContext{receiver: NIL}, nil)
+
+	utils.AssertNoError(t, err)
+	utils.AssertEqualCmpAny(t, result, NewString(""), CompareRubyObjectsForTests)
+}
+
+func TestSymbolToProc(t *testing.T) {
+	result, err := symbolToProc(&callContext{receiver: NewSymbol("+")}, nil)
+	utils.AssertNoError(t, err)
+	proc, ok := result.(*Proc)
+	utils.Assert(t, ok, "Expected Proc, got %T", result)
+	utils.Assert(t, proc.IsLambda(), "Expected a lambda")
+
+	t.Run("sends the symbol to the first argument", func(t *testing.T) {
+		result, err := proc.Call(&callContext{receiver: NIL}, nil, NewInteger(2), NewInteger(3))
+
+		utils.AssertNoError(t, err)
+		utils.AssertEqualCmpAny(t, result, NewInteger(5), CompareRubyObjectsForTests)
+	})
+	t.Run("without arguments", func(t *testing.T) {
+		_, err := proc.Call(&callContext{receiver: NIL}, nil)
+
+		utils.AssertError(t, err, NewArgumentError("no receiver given"))
+	})
+}
+
 func TestSymbolToBool(t *testing.T) {
 	t.Run("true object", func(t *testing.T) {
 		val, ok := SymbolToBool(TRUE)
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	gotoken "go/token"
+	"math/big"
 	"strconv"
 	"strings"
 
@@ -20,87 +21,142 @@ import (
 const (
 	_ int = iota
 	precLowest
-	precBlockBraces // { |x| }
-	precIfUnless    // modifier-if, modifier-unless
-	precAssignment  // x = 5
-	precTernary     // ?, :
-	precRange       // .., ...
-	precLogicalOr   // ||
-	precLogicalAnd  // &&
-	precEquals      // ==, !=, <=>
-	precLessGreater // >, <, >=, <=
-	precOr          // |
-	precAnd         // &
-	precShift       // <<
-	precSum         // + or -
-	precProduct     // *, /, %
-	precPower       // **
-	precPrefix      // -X or !X
-	precSplat       // x = [*y, 1]
-	precCallArg     // func x
-	precCall        // foo.myFunction(X)
-	precIndex       // array[index]
-	precSymbol      // :Symbol
+	precPatternMatch // expr in pattern
+	precBlockBraces  // { |x| }
+	precIfUnless     // modifier-if, modifier-unless, modifier-while, modifier-until
+	precAssignment   // x = 5
+	precTernary      // ?, :
+	precRange        // .., ...
+	precLogicalOr    // ||
+	precLogicalAnd   // &&
+	precEquals       // ==, !=, <=>
+	precLessGreater  // >, <, >=, <=
+	precOr           // |
+	precAnd          // &
+	precShift        // << >>
+	precSum          // + or -
+	precProduct      // *, /, %
+	precPower        // **
+	precPrefix       // -X or !X
+	precSplat        // x = [*y, 1]
+	precCallArg      // func x
+	precCall         // foo.myFunction(X)
+	precIndex        // array[index]
+	precSymbol       // :Symbol
 	precHighest
 )
 
 var precedences = map[token.Type]int{
-	token.IF:         precIfUnless,
-	token.UNLESS:     precIfUnless,
-	token.EQ:         precEquals,
-	token.NOTEQ:      precEquals,
-	token.SPACESHIP:  precEquals,
-	token.LSHIFT:     precShift,
-	token.QMARK:      precTernary,
-	token.COLON:      precTernary,
-	token.LT:         precLessGreater,
-	token.GT:         precLessGreater,
-	token.LTE:        precLessGreater,
-	token.GTE:        precLessGreater,
-	token.PLUS:       precSum,
-	token.MINUS:      precSum,
-	token.SLASH:      precProduct,
-	token.ASTERISK:   precProduct,
-	token.POW:        precPower,
-	token.MODULO:     precProduct,
-	token.ASSIGN:     precAssignment,
-	token.ADDASSIGN:  precAssignment,
-	token.SUBASSIGN:  precAssignment,
-	token.MULASSIGN:  precAssignment,
-	token.DIVASSIGN:  precAssignment,
-	token.MODASSIGN:  precAssignment,
-	token.LPAREN:     precCall,
-	token.DOT:        precCall,
-	token.DDOT:       precRange,
-	token.DDDOT:      precRange,
-	token.IDENT:      precCallArg,
-	token.INT:        precCallArg,
-	token.STRING:     precCallArg,
-	token.SLBRACKET:  precCallArg,
-	token.LBRACKET:   precIndex,
-	token.LBRACE:     precBlockBraces,
-	token.SYMBOL:     precSymbol,
-	token.COMMA:      precAssignment,
-	token.THEN:       precHighest,
-	token.NEWLINE:    precHighest,
-	token.PIPE:       precOr,
-	token.AND:        precAnd,
-	token.LOGICALOR:  precLogicalOr,
-	token.LOGICALAND: precLogicalAnd,
+	token.IF:           precIfUnless,
+	token.UNLESS:       precIfUnless,
+	token.RESCUE:       precIfUnless,
+	token.WHILE:        precIfUnless,
+	token.UNTIL:        precIfUnless,
+	token.IN:           precPatternMatch,
+	token.EQ:           precEquals,
+	token.CASEEQ:       precEquals,
+	token.NOTEQ:        precEquals,
+	token.SPACESHIP:    precEquals,
+	token.MATCH:        precEquals,
+	token.NOTMATCH:     precEquals,
+	token.LSHIFT:       precShift,
+	token.RSHIFT:       precShift,
+	token.QMARK:        precTernary,
+	token.COLON:        precTernary,
+	token.LT:           precLessGreater,
+	token.GT:           precLessGreater,
+	token.LTE:          precLessGreater,
+	token.GTE:          precLessGreater,
+	token.PLUS:         precSum,
+	token.MINUS:        precSum,
+	token.SLASH:        precProduct,
+	token.ASTERISK:     precProduct,
+	token.POW:          precPower,
+	token.MODULO:       precProduct,
+	token.ASSIGN:       precAssignment,
+	token.ADDASSIGN:    precAssignment,
+	token.SUBASSIGN:    precAssignment,
+	token.MULASSIGN:    precAssignment,
+	token.DIVASSIGN:    precAssignment,
+	token.MODASSIGN:    precAssignment,
+	token.POWASSIGN:    precAssignment,
+	token.ANDASSIGN:    precAssignment,
+	token.ORASSIGN:     precAssignment,
+	token.XORASSIGN:    precAssignment,
+	token.LSHIFTASSIGN: precAssignment,
+	token.RSHIFTASSIGN: precAssignment,
+	token.LPAREN:       precCall,
+	token.DOT:          precCall,
+	token.SCOPE:        precCall,
+	token.DDOT:         precRange,
+	token.DDDOT:        precRange,
+	token.IDENT:        precCallArg,
+	token.INT:          precCallArg,
+	token.FLOAT:        precCallArg,
+	token.RATIONAL:     precCallArg,
+	token.IMAGINARY:    precCallArg,
+	token.STRING:       precCallArg,
+	token.BACKTICK:     precCallArg,
+	token.REGEX:        precCallArg,
+	token.WORDS:        precCallArg,
+	token.SYMBOLS:      precCallArg,
+	token.SLBRACKET:    precCallArg,
+	token.SSCOPE:       precCallArg,
+	token.SELF:         precCallArg,
+	token.SAND:         precCallArg,
+	token.TILDE:        precCallArg,
+	token.LAMBDAROCKET: precCallArg,
+	token.LBRACKET:     precIndex,
+	token.LBRACE:       precBlockBraces,
+	token.SYMBOL:       precSymbol,
+	token.COMMA:        precAssignment,
+	token.THEN:         precHighest,
+	token.NEWLINE:      precHighest,
+	token.PIPE:         precOr,
+	token.CARET:        precOr,
+	token.AND:          precAnd,
+	token.LOGICALOR:    precLogicalOr,
+	token.LOGICALAND:   precLogicalAnd,
+
+	token.LOGICALANDASSIGN: precAssignment,
+	token.LOGICALORASSIGN:  precAssignment,
 }
 
 var tokensNotPossibleInCallArgs = []token.Type{
 	token.ASSIGN,
+	token.ADDASSIGN,
+	token.SUBASSIGN,
+	token.MULASSIGN,
+	token.DIVASSIGN,
+	token.MODASSIGN,
+	token.POWASSIGN,
+	token.ANDASSIGN,
+	token.ORASSIGN,
+	token.XORASSIGN,
+	token.LSHIFTASSIGN,
+	token.RSHIFTASSIGN,
+	token.LOGICALANDASSIGN,
+	token.LOGICALORASSIGN,
 	token.LT,
 	token.LTE,
 	token.GT,
 	token.GTE,
 	token.SPACESHIP,
 	token.LSHIFT,
+	token.RSHIFT,
 	token.EQ,
+	token.CASEEQ,
 	token.NOTEQ,
+	token.MATCH,
+	token.NOTMATCH,
 	token.IF,
 	token.UNLESS,
+	token.WHILE,
+	token.UNTIL,
+	token.RESCUE,
+	token.THEN,
+	token.IN,
+	token.HASHROCKET,
 	token.COLON,
 	token.RBRACKET,
 	token.COMMA,
@@ -120,8 +176,15 @@ type parser struct {
 
 	tracer trace.Tracer
 
-	pos       gotoken.Pos
-	lastLine  string
+	pos      gotoken.Pos
+	lastLine string
+
+	rescueDepth int // number of enclosing rescue clauses, retry is valid only within one
+	jumpDepth   int // number of enclosing loops and blocks, break, next and redo are valid only within one
+	strayJumps  []strayJump
+	doBlocked   int // number of enclosing call arguments without parens and loop headers, a do block binds to the outermost of them
+	argLists    int // number of enclosing argument lists, within which the braces of a trailing hash may be left out
+
 	curToken  token.Token
 	peekToken token.Token
 
@@ -131,6 +194,7 @@ type parser struct {
 
 func (p *parser) init(fset *gotoken.FileSet, filename string, src []byte, trace_parse bool) {
 	p.file = fset.AddFile(filename, -1, len(src))
+	p.file.SetLinesForContent(src)
 
 	p.l = lexer.New(string(src))
 	p.errors = []error{}
@@ -142,22 +206,43 @@ func (p *parser) init(fset *gotoken.FileSet, filename string, src []byte, trace_
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.RATIONAL, p.parseRationalLiteral)
+	p.registerPrefix(token.IMAGINARY, p.parseImaginaryLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.UNLESS, p.parseIfExpression)
 	p.registerPrefix(token.LOOP, p.parseLoopExpression)
+	p.registerPrefix(token.WHILE, p.parseWhileExpression)
+	p.registerPrefix(token.UNTIL, p.parseWhileExpression)
+	p.registerPrefix(token.FOR, p.parseForExpression)
+	p.registerPrefix(token.BEGIN, p.parseBeginExpression)
+	p.registerPrefix(token.CASE, p.parseCaseExpression)
 	p.registerPrefix(token.DEF, p.parseFunctionLiteral)
+	p.registerPrefix(token.CLASS, p.parseClassExpression)
+	p.registerPrefix(token.MODULE, p.parseModuleExpression)
+	p.registerPrefix(token.SCOPE, p.parseTopLevelConstant)
+	p.registerPrefix(token.SSCOPE, p.parseTopLevelConstant)
+	p.registerPrefix(token.SELF, p.parseSelf)
+	p.registerPrefix(token.SUPER, p.parseSuper)
+	p.registerPrefix(token.YIELD, p.parseYield)
 	p.registerPrefix(token.SYMBOL, p.parseSymbolLiteral)
+	p.registerPrefix(token.BACKTICK, p.parseCommandLiteral)
+	p.registerPrefix(token.REGEX, p.parseRegexLiteral)
+	p.registerPrefix(token.WORDS, p.parseWordsLiteral)
+	p.registerPrefix(token.SYMBOLS, p.parseWordsLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.SLBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.NIL, p.parseNilLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHash)
 	p.registerPrefix(token.LAMBDAROCKET, p.parseLambdaLiteral)
+	p.registerPrefix(token.AND, p.parseBlockPass)
+	p.registerPrefix(token.SAND, p.parseBlockPass)
 	p.registerPrefix(token.ASTERISK, p.parseSplat)
 
 	p.infixParseFns = make(map[token.Type]infixParseFn)
@@ -169,7 +254,9 @@ func (p *parser) init(fset *gotoken.FileSet, filename string, src []byte, trace_
 	p.registerInfix(token.MODULO, p.parseInfixExpression)
 	p.registerInfix(token.AND, p.parseInfixExpression)
 	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
+	p.registerInfix(token.CASEEQ, p.parseInfixExpression)
 	p.registerInfix(token.NOTEQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
@@ -179,6 +266,9 @@ func (p *parser) init(fset *gotoken.FileSet, filename string, src []byte, trace_
 	p.registerInfix(token.LOGICALAND, p.parseInfixExpression)
 	p.registerInfix(token.SPACESHIP, p.parseInfixExpression)
 	p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.RSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.MATCH, p.parseInfixExpression)
+	p.registerInfix(token.NOTMATCH, p.parseInfixExpression)
 	p.registerInfix(token.DDOT, p.parseRangeLiteral)
 	p.registerInfix(token.DDDOT, p.parseRangeLiteral)
 	p.registerInfix(token.ASSIGN, p.parseAssignment)
@@ -187,19 +277,44 @@ func (p *parser) init(fset *gotoken.FileSet, filename string, src []byte, trace_
 	p.registerInfix(token.MULASSIGN, p.parseAssignmentOperator)
 	p.registerInfix(token.DIVASSIGN, p.parseAssignmentOperator)
 	p.registerInfix(token.MODASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.POWASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.ANDASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.ORASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.XORASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.LSHIFTASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.RSHIFTASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.LOGICALANDASSIGN, p.parseAssignmentOperator)
+	p.registerInfix(token.LOGICALORASSIGN, p.parseAssignmentOperator)
 	p.registerInfix(token.IF, p.parseModifierConditionalExpression)
 	p.registerInfix(token.UNLESS, p.parseModifierConditionalExpression)
+	p.registerInfix(token.WHILE, p.parseModifierLoopExpression)
+	p.registerInfix(token.UNTIL, p.parseModifierLoopExpression)
+	p.registerInfix(token.IN, p.parseMatchPredicate)
+	p.registerInfix(token.RESCUE, p.parseRescueModifier)
 	p.registerInfix(token.QMARK, p.parseTernaryIfExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpressionWithParens)
 	p.registerInfix(token.IDENT, p.parseCallArgument)
 	p.registerInfix(token.INT, p.parseCallArgument)
+	p.registerInfix(token.FLOAT, p.parseCallArgument)
+	p.registerInfix(token.RATIONAL, p.parseCallArgument)
+	p.registerInfix(token.IMAGINARY, p.parseCallArgument)
 	p.registerInfix(token.STRING, p.parseCallArgument)
 	p.registerInfix(token.SYMBOL, p.parseCallArgument)
+	p.registerInfix(token.BACKTICK, p.parseCallArgument)
+	p.registerInfix(token.REGEX, p.parseCallArgument)
+	p.registerInfix(token.WORDS, p.parseCallArgument)
+	p.registerInfix(token.SYMBOLS, p.parseCallArgument)
+	p.registerInfix(token.TILDE, p.parseCallArgument)
 	p.registerInfix(token.LBRACE, p.parseCallBlock)
 	p.registerInfix(token.DOT, p.parseMethodCall)
+	p.registerInfix(token.SCOPE, p.parseScopedConstant)
 	p.registerInfix(token.COMMA, p.parseExpressions)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.SLBRACKET, p.parseCallArgument)
+	p.registerInfix(token.SSCOPE, p.parseCallArgument)
+	p.registerInfix(token.SELF, p.parseCallArgument)
+	p.registerInfix(token.SAND, p.parseCallArgument)
+	p.registerInfix(token.LAMBDAROCKET, p.parseCallArgument)
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
@@ -244,10 +359,9 @@ func (p *parser) nextToken() {
 		// }
 	}
 	p.curToken = p.peekToken
-	p.pos = gotoken.Pos(p.curToken.Pos)
+	p.pos = p.tokenPos(p.curToken)
 	p.lastLine += p.curToken.Literal
 	if p.curToken.Type == token.NEWLINE {
-		p.file.AddLine(int(p.pos))
 		p.lastLine = ""
 	}
 	if p.l.HasNext() {
@@ -257,12 +371,86 @@ func (p *parser) nextToken() {
 	}
 }
 
+// tokenPos translates the offset of tok into a position within p.file. Tokens
+// without an offset (like the synthetic EOF) are placed at the end of the file.
+func (p *parser) tokenPos(tok token.Token) gotoken.Pos {
+	offset := tok.Pos
+	if offset < 0 || offset > p.file.Size() {
+		offset = p.file.Size()
+	}
+	return p.file.Pos(offset)
+}
+
+// endPos returns the position immediately after the current token
+func (p *parser) endPos() gotoken.Pos {
+	end := p.pos + gotoken.Pos(len(p.curToken.Literal))
+	if limit := gotoken.Pos(p.file.Base() + p.file.Size()); end > limit {
+		return limit
+	}
+	return end
+}
+
+// spanner is implemented by all ast nodes which embed an ast.Span
+type spanner interface {
+	ast.Node
+	SetSpan(start, end gotoken.Pos)
+}
+
+// setSpan records that node starts at start and ends with the current token.
+// If the node already has a position (e.g. it was returned unchanged by an
+// infix parse function) only its end is extended.
+func (p *parser) setSpan(node ast.Node, start gotoken.Pos) {
+	n, ok := node.(spanner)
+	if !ok {
+		return
+	}
+	end := p.endPos()
+	if n.Pos().IsValid() {
+		start = n.Pos()
+		if n.End() > end {
+			end = n.End()
+		}
+	}
+	n.SetSpan(start, end)
+}
+
+// fillSpans gives the nodes synthesized by the parser (like the blocks wrapping
+// the branches of a ternary) the range covered by their descendants.
+func fillSpans(root ast.Node) {
+	ast.Inspect(root, func(node ast.Node) {
+		n, ok := node.(spanner)
+		if !ok || n.Pos().IsValid() {
+			return
+		}
+		var start, end gotoken.Pos
+		ast.Inspect(n, func(child ast.Node) {
+			if child == nil || child == n || !child.Pos().IsValid() {
+				return
+			}
+			if !start.IsValid() || child.Pos() < start {
+				start = child.Pos()
+			}
+			if child.End() > end {
+				end = child.End()
+			}
+		})
+		if start.IsValid() {
+			n.SetSpan(start, end)
+		}
+	})
+}
+
 func (p *parser) parseIdentifier() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 		p.tracer.Message(p.curToken.Literal)
 	}
-	return &ast.Identifier{Value: p.curToken.Literal}
+	ident := &ast.Identifier{Value: p.curToken.Literal}
+	// a block binds to the closest method name, as in `foo bar { |x| x }`
+	if p.acceptBlock() {
+		return p.parseCallBlock(ident)
+	}
+	return ident
 }
 
 // Errors returns all errors which happened during the parsing of the input.
@@ -291,6 +479,10 @@ func (p *parser) unexpectedTokenError(actual token.Type, description string, exp
 
 func (p *parser) noPrefixParseFnError(t token.Type) {
 	msg := fmt.Sprintf("no prefix parse function for type %s found", t)
+	if t == token.ILLEGAL {
+		// the error of the lexer, e.g. a malformed number
+		msg = p.curToken.Literal
+	}
 	epos := p.file.Position(p.pos)
 	if epos.Filename != "" || epos.IsValid() {
 		msg = epos.String() + ": " + msg
@@ -305,6 +497,7 @@ func (p *parser) noPrefixParseFnError(t token.Type) {
 func (p *parser) ParseProgram() (*ast.Program, error) {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
+	program.SetSpan(p.file.Pos(0), p.file.Pos(p.file.Size()))
 	for !p.currentIs(token.EOF) {
 		if p.currentIs(token.NEWLINE) {
 			// Early exit
@@ -319,9 +512,12 @@ func (p *parser) ParseProgram() (*ast.Program, error) {
 		p.nextToken()
 	}
 	// fmt.Println("Last token:", p.curToken)
+	p.reportStrayJumps()
+	p.checkBlockPasses(program)
 	if len(p.errors) != 0 {
 		return program, NewErrors("Parsing errors", p.errors...)
 	}
+	fillSpans(program)
 	// fmt.Println("Program:", program)
 	return program, nil
 }
@@ -350,6 +546,12 @@ func (p *parser) parseStatement() ast.Statement {
 		return p.parseComment()
 	case token.BREAK:
 		return p.parseBreakStatement()
+	case token.NEXT:
+		return p.parseNextStatement()
+	case token.RETRY:
+		return p.parseRetryStatement()
+	case token.REDO:
+		return p.parseRedoStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -360,40 +562,11 @@ func (p *parser) parseReturnStatement() *ast.ReturnStatement {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
 	stmt := &ast.ReturnStatement{}
-	p.nextToken()
-
-	if p.currentIs(token.NEWLINE, token.SEMICOLON) {
-		p.nextToken()
-		return stmt
-	}
-
-	stmt.ReturnValue = p.parseExpression(precLowest)
-	if list, ok := stmt.ReturnValue.(ast.ExpressionList); ok {
-		stmt.ReturnValue = &ast.ArrayLiteral{Elements: list}
-	}
-
-	if p.peekIs(token.NEWLINE, token.SEMICOLON) {
+	start := p.pos
+	stmt.ReturnValue, stmt.Condition, stmt.Unless = p.parseJumpArguments()
+	stmt.SetSpan(start, p.endPos())
+	if p.peekIs(token.SEMICOLON, token.NEWLINE) {
 		p.nextToken()
-		return stmt
-	}
-	if p.currentIs(token.NEWLINE, token.SEMICOLON) {
-		return stmt
-	}
-
-	if !p.peekIs(token.COMMA) {
-		p.unexpectedTokenError(p.peekToken.Type, "", token.COMMA)
-		return nil
-	}
-
-	arr := &ast.ArrayLiteral{Elements: []ast.Expression{stmt.ReturnValue}}
-	for p.peekIs(token.COMMA) {
-		p.consume(token.COMMA)
-		arr.Elements = append(arr.Elements, p.parseExpression(precLowest))
-	}
-	stmt.ReturnValue = arr
-
-	if !p.accept(token.NEWLINE, token.SEMICOLON) {
-		return nil
 	}
 	return stmt
 }
@@ -403,7 +576,12 @@ func (p *parser) parseExpressionStatement() *ast.ExpressionStatement {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
 	stmt := &ast.ExpressionStatement{}
+	start := p.pos
 	stmt.Expression = p.parseExpression(precLowest)
+	if stmt.Expression != nil && p.peekIs(token.HASHROCKET) {
+		stmt.Expression = p.parseRightwardAssignment(stmt.Expression, start)
+	}
+	p.setSpan(stmt, start)
 	if p.peekIs(token.SEMICOLON, token.NEWLINE) {
 		p.nextToken()
 	}
@@ -419,7 +597,11 @@ func (p *parser) parseExpression(precedence int) ast.Expression {
 		p.noPrefixParseFnError(p.curToken.Type)
 		return nil
 	}
+	start := p.pos
 	leftExp := prefix()
+	if leftExp != nil {
+		p.setSpan(leftExp, start)
+	}
 	for precedence < precedenceForToken(p.peekToken.Type) {
 		if leftExp == nil {
 			return nil // fail early and stop parsing
@@ -433,6 +615,9 @@ func (p *parser) parseExpression(precedence int) ast.Expression {
 		}
 		p.nextToken()
 		leftExp = infix(leftExp)
+		if leftExp != nil {
+			p.setSpan(leftExp, start)
+		}
 	}
 	return leftExp
 }
@@ -443,6 +628,7 @@ func (p *parser) parseComment() ast.Statement {
 	}
 	comment := &ast.Comment{}
 	comment.Value = p.curToken.Literal
+	comment.SetSpan(p.pos, p.endPos())
 	if !p.peekIs(token.NEWLINE, token.EOF) {
 		epos := p.file.Position(p.pos)
 		p.Error(fmt.Errorf("%s: Expected newline or eof after comment", epos.String()))
@@ -484,19 +670,72 @@ func (p *parser) parseLambdaLiteral() ast.Expression {
 	if p.peekIs(token.LPAREN) {
 		proc.Parameters = p.parseFunctionParameters(token.LPAREN, token.RPAREN)
 	}
-	if !p.accept(token.LBRACE) {
+	if !p.accept(token.LBRACE, token.DO) {
 		return nil
 	}
-	proc.Body = p.parseBlockStatement(token.RBRACE)
+	endToken := token.RBRACE
+	if p.currentIs(token.DO) {
+		endToken = token.END
+	}
+	p.jumpDepth++
+	proc.Body = p.parseBlockStatement(endToken)
+	p.jumpDepth--
 	if proc.Body == nil {
 		return nil
 	}
-	if !p.accept(token.RBRACE) {
+	if !p.accept(endToken) {
 		return nil
 	}
 	return proc
 }
 
+// parseBlockPass parses an argument passed as the block of a call, as in
+// `list.each(&handler)`
+func (p *parser) parseBlockPass() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	pass := &ast.BlockPass{}
+	start := p.pos
+	p.nextToken()
+	pass.Value = p.parseExpression(precSplat)
+	if pass.Value == nil {
+		return nil
+	}
+	pass.SetSpan(start, p.endPos())
+	return pass
+}
+
+// checkBlockPasses reports block arguments which are not the last argument of
+// a method call, or given along with a block literal
+func (p *parser) checkBlockPasses(program *ast.Program) {
+	valid := make(map[*ast.BlockPass]bool)
+	var passes []*ast.BlockPass
+	ast.Inspect(program, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.ContextCallExpression:
+			if len(n.Arguments) == 0 {
+				return
+			}
+			if pass, ok := n.Arguments[len(n.Arguments)-1].(*ast.BlockPass); ok {
+				if n.Block != nil {
+					epos := p.file.Position(pass.Pos())
+					p.Error(fmt.Errorf("%s: both block arg and actual block given", epos.String()))
+				}
+				valid[pass] = true
+			}
+		case *ast.BlockPass:
+			passes = append(passes, n)
+		}
+	})
+	for _, pass := range passes {
+		if !valid[pass] {
+			epos := p.file.Position(pass.Pos())
+			p.Error(fmt.Errorf("%s: block argument should not be given", epos.String()))
+		}
+	}
+}
+
 func (p *parser) parseSplat() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -526,12 +765,15 @@ func (p *parser) parseExpressions(left ast.Expression) ast.Expression {
 	}
 	p.nextToken()
 	elements := []ast.Expression{left}
-	next := p.parseExpression(precAssignment)
-	elements = append(elements, next)
+	next := p.parseArgument(precAssignment)
+	elements = appendArgument(elements, next)
 	for p.peekIs(token.COMMA) {
 		p.consume(token.COMMA)
-		next = p.parseExpression(precAssignment)
-		elements = append(elements, next)
+		next = p.parseArgument(precAssignment)
+		elements = appendArgument(elements, next)
+	}
+	if len(elements) == 1 {
+		return elements[0]
 	}
 	return ast.ExpressionList(elements)
 }
@@ -551,27 +793,41 @@ func (p *parser) parseAssignmentOperator(left ast.Expression) ast.Expression {
 		return nil
 	}
 
-	newInf := &ast.InfixExpression{
-		Left:     left,
-		Operator: op,
-	}
-	assign := &ast.Assignment{
-		Left: left,
+	var expr ast.Expression
+	var right *ast.Expression
+	if op == infix.LOGICALOR || op == infix.LOGICALAND {
+		// `x ||= y` is `x || x = y`, which assigns only when needed
+		assign := &ast.LogicalAssignment{Left: left, Operator: op}
+		expr = assign
+		right = &assign.Right
+	} else {
+		newInf := &ast.InfixExpression{Left: left, Operator: op}
+		expr = &ast.Assignment{Left: left, Right: newInf}
+		right = &newInf.Right
 	}
 	p.nextToken()
-	newInf.Right = p.parseExpression(precLowest)
-	assign.Right = newInf
-	return assign
+	*right = p.parseExpression(precLowest)
+	if loop, ok := (*right).(*ast.LoopExpression); ok && loop.Modifier {
+		*right = hoistModifierLoop(loop, expr)
+		return loop
+	}
+	return expr
 }
 
 func (p *parser) parseAssignment(left ast.Expression) ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
-	switch left.(type) {
+	switch left := left.(type) {
 	case *ast.Identifier:
 	case *ast.IndexExpression:
 	case ast.ExpressionList:
+	case *ast.ContextCallExpression:
+		// attribute assignment, e.g. `foo.bar = 5`
+		if left.Context == nil || len(left.Arguments) != 0 || left.Block != nil {
+			p.unexpectedTokenError(p.curToken.Type, "", token.EOF)
+			return nil
+		}
 	default:
 		p.unexpectedTokenError(p.curToken.Type, "", token.EOF)
 		return nil
@@ -582,6 +838,10 @@ func (p *parser) parseAssignment(left ast.Expression) ast.Expression {
 	}
 	p.nextToken()
 	expr := p.parseExpression(precLowest)
+	if loop, ok := expr.(*ast.LoopExpression); ok && loop.Modifier {
+		assign.Right = hoistModifierLoop(loop, assign)
+		return loop
+	}
 	right, ok := expr.(*ast.ConditionalExpression)
 	if !ok {
 		assign.Right = expr
@@ -636,6 +896,19 @@ func (p *parser) parseAssignment(left ast.Expression) ast.Expression {
 	return cond
 }
 
+// hoistModifierLoop turns the modifier loop swallowed by the right hand side
+// of an assignment, as in `x += 1 while x < 10`, into a loop around the
+// assignment. It returns the original right hand side.
+func hoistModifierLoop(loop *ast.LoopExpression, assign ast.Expression) ast.Expression {
+	body := loop.Block.Statements[0].(*ast.ExpressionStatement)
+	right := body.Expression
+	body.Expression = assign
+	// only a bare begin block runs before the condition is checked
+	loop.DoWhile = false
+	loop.Span = ast.Span{}
+	return right
+}
+
 func (p *parser) parseNilLiteral() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -643,54 +916,195 @@ func (p *parser) parseNilLiteral() ast.Expression {
 	return &ast.SymbolLiteral{Value: "nil"}
 }
 
-var integerLiteralReplacer = strings.NewReplacer("_", "")
+var integerLiteralReplacer = strings.NewReplacer("_", "", "0d", "", "0D", "")
 
 func (p *parser) parseIntegerLiteral() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
-	// p.tracer.Message("hello sailor!")
-	lit := &ast.IntegerLiteral{}
-	value, err := strconv.ParseInt(integerLiteralReplacer.Replace(p.curToken.Literal), 0, 64)
+	return p.integerLiteral(p.curToken.Literal)
+}
+
+// integerLiteral parses literal, which is a decimal integer or has a base
+// prefix, e.g. `0x1F`
+func (p *parser) integerLiteral(literal string) ast.Expression {
+	digits := integerLiteralReplacer.Replace(literal)
+	value, err := strconv.ParseInt(digits, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if value, ok := new(big.Int).SetString(digits, 0); ok {
+			return &ast.IntegerLiteral{Big: value}
+		}
+	}
 	if err != nil {
-		p.Error(fmt.Errorf("could not parse %q as integer", p.curToken.Literal))
+		p.Error(fmt.Errorf("could not parse %q as integer", literal))
 		return nil
 	}
-	lit.Value = value
-	return lit
+	return &ast.IntegerLiteral{Value: value}
 }
 
 func (p *parser) parseFloatLiteral() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
-	lit := &ast.FloatLiteral{}
-	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	return p.floatLiteral(p.curToken.Literal)
+}
+
+func (p *parser) floatLiteral(literal string) ast.Expression {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(literal, "_", ""), 64)
 	if err != nil {
-		p.Error(fmt.Errorf("could not parse %q as float", p.curToken.Literal))
+		p.Error(fmt.Errorf("could not parse %q as float", literal))
+		return nil
+	}
+	return &ast.FloatLiteral{Value: value}
+}
+
+// parseRationalLiteral parses a rational number, e.g. `3r` or `1.5r`, whose
+// value is exact rather than that of a float
+func (p *parser) parseRationalLiteral() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	return p.rationalLiteral(p.curToken.Literal)
+}
+
+func (p *parser) rationalLiteral(literal string) ast.Expression {
+	number := strings.TrimSuffix(literal, "r")
+	if !strings.Contains(number, ".") {
+		integer, ok := p.integerLiteral(number).(*ast.IntegerLiteral)
+		if !ok {
+			return nil
+		}
+		if integer.Big != nil {
+			return &ast.RationalLiteral{Value: new(big.Rat).SetInt(integer.Big)}
+		}
+		return &ast.RationalLiteral{Value: new(big.Rat).SetInt64(integer.Value)}
+	}
+	value, ok := new(big.Rat).SetString(strings.ReplaceAll(number, "_", ""))
+	if !ok {
+		p.Error(fmt.Errorf("could not parse %q as rational", literal))
 		return nil
 	}
-	lit.Value = value
-	return lit
+	return &ast.RationalLiteral{Value: value}
+}
 
+// parseImaginaryLiteral parses an imaginary number, e.g. `2i`, `1.5i` or
+// `3ri`
+func (p *parser) parseImaginaryLiteral() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	number := strings.TrimSuffix(p.curToken.Literal, "i")
+	var value ast.Expression
+	switch {
+	case strings.HasSuffix(number, "r"):
+		value = p.rationalLiteral(number)
+	case !strings.HasPrefix(strings.ToLower(number), "0x") && strings.ContainsAny(number, ".eE"):
+		value = p.floatLiteral(number)
+	default:
+		value = p.integerLiteral(number)
+	}
+	if value == nil {
+		return nil
+	}
+	p.setSpan(value, p.pos)
+	return &ast.ImaginaryLiteral{Value: value}
 }
+
 func (p *parser) parseStringLiteral() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
-	return &ast.StringLiteral{
-		Value: p.curToken.Literal}
+	literal := &ast.StringLiteral{Value: p.curToken.Literal}
+	if !p.peekIs(token.INTERPOLATION) {
+		return literal
+	}
+	literal.SetSpan(p.pos, p.endPos())
+	str := &ast.InterpolatedString{Literals: []*ast.StringLiteral{literal}}
+	for p.peekIs(token.INTERPOLATION) {
+		p.nextToken()
+		embedded := p.parseBlockStatement(token.RBRACE)
+		if !p.accept(token.RBRACE) || !p.accept(token.STRING) {
+			return nil
+		}
+		literal := &ast.StringLiteral{Value: p.curToken.Literal}
+		literal.SetSpan(p.pos, p.endPos())
+		str.Embedded = append(str.Embedded, embedded)
+		str.Literals = append(str.Literals, literal)
+	}
+	return str
 }
 
 func (p *parser) parseSymbolLiteral() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
+	if (p.curToken.Literal == ":" || strings.HasPrefix(p.curToken.Literal, "%s")) && p.peekIs(token.STRING) {
+		// a quoted symbol, e.g. `:"foo bar"`, `:"foo#{bar}"` or `%s(foo)`
+		p.nextToken()
+		switch str := p.parseStringLiteral().(type) {
+		case *ast.StringLiteral:
+			return &ast.SymbolLiteral{Value: str.Value}
+		case *ast.InterpolatedString:
+			str.Symbol = true
+			return str
+		default:
+			return nil
+		}
+	}
 	return &ast.SymbolLiteral{
 		Value: strings.TrimPrefix(p.curToken.Literal, ":"),
 	}
 }
 
+// parseRegexLiteral parses a regex literal, e.g. `/a+#{b}/i` or `%r{a+}i`
+func (p *parser) parseRegexLiteral() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	start := p.pos
+	regex := &ast.RegexLiteral{}
+	if !p.accept(token.STRING) {
+		return nil
+	}
+	switch pattern := p.parseStringLiteral().(type) {
+	case *ast.StringLiteral:
+		pattern.SetSpan(p.pos, p.endPos())
+		regex.Pattern = &ast.InterpolatedString{Literals: []*ast.StringLiteral{pattern}}
+		regex.Pattern.SetSpan(p.pos, p.endPos())
+	case *ast.InterpolatedString:
+		regex.Pattern = pattern
+	default:
+		return nil
+	}
+	if !p.accept(token.REGEXEND) {
+		return nil
+	}
+	regex.Options = p.curToken.Literal
+	p.setSpan(regex, start)
+	return regex
+}
+
+// parseCommandLiteral parses a backtick command, e.g. “ `ls #{dir}` “,
+// which is a call to Kernel#`
+func (p *parser) parseCommandLiteral() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	if !p.accept(token.STRING) {
+		return nil
+	}
+	start := p.pos
+	command := p.parseStringLiteral()
+	if command == nil {
+		return nil
+	}
+	p.setSpan(command, start)
+	return &ast.ContextCallExpression{
+		Function:  "`",
+		Arguments: []ast.Expression{command},
+	}
+}
+
 func (p *parser) parseArrayLiteral() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -702,6 +1116,28 @@ func (p *parser) parseArrayLiteral() ast.Expression {
 	return array
 }
 
+// parseWordsLiteral parses an array of strings or symbols, e.g. `%w[a b]` or
+// `%i[a b]`
+func (p *parser) parseWordsLiteral() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	start := p.pos
+	symbols := p.currentIs(token.SYMBOLS)
+	array := &ast.ArrayLiteral{}
+	for p.peekIs(token.STRING) {
+		p.nextToken()
+		var element ast.Expression = &ast.StringLiteral{Value: p.curToken.Literal}
+		if symbols {
+			element = &ast.SymbolLiteral{Value: p.curToken.Literal}
+		}
+		p.setSpan(element, p.pos)
+		array.Elements = append(array.Elements, element)
+	}
+	p.setSpan(array, start)
+	return array
+}
+
 func (p *parser) parseBoolean() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -746,11 +1182,9 @@ func (p *parser) parseHash() ast.Expression {
 	}
 
 	// parse the first key-value pair
-	k, v, ok := p.parseKeyValue()
-	if !ok {
+	if !p.parseHashElement(hash) {
 		return nil
 	}
-	hash.Map[k] = v
 	p.nextToken() // move past the end of the key-value pair
 	p.consumeNewlineOrComment()
 
@@ -760,11 +1194,9 @@ func (p *parser) parseHash() ast.Expression {
 		if p.currentIs(token.RBRACE) {
 			break
 		}
-		k, v, ok := p.parseKeyValue()
-		if !ok {
+		if !p.parseHashElement(hash) {
 			return nil
 		}
-		hash.Map[k] = v
 		p.nextToken() // move past the end of the key-value pair
 		p.consumeNewlineOrComment()
 	}
@@ -781,6 +1213,47 @@ func (p *parser) parseHash() ast.Expression {
 	return hash
 }
 
+// parseHashElement parses a key-value pair of a hash into hash. The key is
+// either followed by `=>` or given as a label, as in `{key: 1}` or
+// `{"key": 1}`, which makes it a symbol. A double splat, as in `{**opts}`,
+// merges another hash into hash.
+func (p *parser) parseHashElement(hash *ast.HashLiteral) bool {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	switch {
+	case p.currentIs(token.POW):
+		p.nextToken()
+		splat := p.parseExpression(precSplat)
+		if splat == nil {
+			return false
+		}
+		hash.Splats = append(hash.Splats, splat)
+	case p.isLabel():
+		key := &ast.SymbolLiteral{Value: p.curToken.Literal}
+		key.SetSpan(p.pos, p.endPos())
+		p.consume(token.COLON)
+		value := p.parseExpression(precAssignment)
+		if value == nil {
+			return false
+		}
+		hash.Map[key] = value
+	default:
+		key, value, ok := p.parseKeyValue()
+		if !ok {
+			return false
+		}
+		hash.Map[key] = value
+	}
+	return true
+}
+
+// isLabel reports whether the current token is a `key:` label of a hash or
+// keyword argument
+func (p *parser) isLabel() bool {
+	return p.currentIs(token.IDENT, token.STRING) && p.peekIs(token.COLON)
+}
+
 func (p *parser) parseKeyValue() (ast.Expression, ast.Expression, bool) {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -804,6 +1277,11 @@ func (p *parser) parseBlock() *ast.FunctionLiteral {
 	block := &ast.FunctionLiteral{
 		Name: name,
 	}
+	start := p.pos
+	endToken := token.RBRACE
+	if p.currentIs(token.DO) {
+		endToken = token.END
+	}
 	if p.peekIs(token.PIPE) {
 		block.Parameters = p.parseFunctionParameters(token.PIPE, token.PIPE)
 	}
@@ -812,10 +1290,11 @@ func (p *parser) parseBlock() *ast.FunctionLiteral {
 		p.accept(token.NEWLINE, token.SEMICOLON)
 	}
 
-	endToken := token.RBRACE
-
+	p.jumpDepth++
 	block.Body = p.parseBlockStatement(endToken)
+	p.jumpDepth--
 	p.nextToken()
+	block.SetSpan(start, p.endPos())
 	return block
 }
 
@@ -826,11 +1305,46 @@ func (p *parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Operator: p.curToken.Literal,
 	}
+	if expression.Operator == "-" && p.peekIs(token.INT, token.FLOAT, token.RATIONAL, token.IMAGINARY) && p.tokenPos(p.peekToken) == p.endPos() {
+		// a negative number, e.g. `-2.abs`, except that `-2 ** 2` is
+		// `-(2 ** 2)`
+		p.nextToken()
+		number := p.prefixParseFns[p.curToken.Type]()
+		if number == nil || !p.peekIs(token.POW) {
+			return negate(number)
+		}
+		p.setSpan(number, p.pos)
+		p.nextToken()
+		expression.Right = p.parseInfixExpression(number)
+		return expression
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precPrefix)
 	return expression
 }
 
+// negate negates the number literal number
+func negate(number ast.Expression) ast.Expression {
+	switch number := number.(type) {
+	case *ast.IntegerLiteral:
+		number.Value = -number.Value
+		if number.Big != nil {
+			number.Big.Neg(number.Big)
+			if number.Big.IsInt64() {
+				// the smallest integer, whose magnitude does not fit
+				number.Value, number.Big = number.Big.Int64(), nil
+			}
+		}
+	case *ast.FloatLiteral:
+		number.Value = -number.Value
+	case *ast.RationalLiteral:
+		number.Value.Neg(number.Value)
+	case *ast.ImaginaryLiteral:
+		negate(number.Value)
+	}
+	return number
+}
+
 func (p *parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -1003,9 +1517,11 @@ func (p *parser) parseLoopExpression() ast.Expression {
 	}
 	loop := &ast.LoopExpression{}
 	if p.curToken.Type == token.LOOP {
-		if p.peekIs(token.LBRACE) {
-			p.accept(token.LBRACE)
+		if p.peekIs(token.LBRACE, token.DO) {
+			p.accept(token.LBRACE, token.DO)
+			p.jumpDepth++
 			loop.Block = p.parseBlockStatement(token.RBRACE)
+			p.jumpDepth--
 			p.nextToken()
 		}
 	} else {
@@ -1016,97 +1532,1155 @@ func (p *parser) parseLoopExpression() ast.Expression {
 	return loop
 }
 
-func (p *parser) parseBreakStatement() *ast.BreakStatement {
+func (p *parser) parseWhileExpression() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
-	stmt := &ast.BreakStatement{}
-	if p.peekIs(token.IF) {
-		p.accept(token.IF)
-		p.nextToken()
-		stmt.Condition = p.parseExpression(precLowest)
-	} else if p.peekIs(token.UNLESS) {
-		p.accept(token.UNLESS)
-		p.nextToken()
-		stmt.Condition = p.parseExpression(precLowest)
-		stmt.Unless = true
+	loop := &ast.LoopExpression{Until: p.currentIs(token.UNTIL)}
+	p.nextToken()
+	p.doBlocked++
+	loop.Condition = p.parseExpression(precLowest)
+	p.doBlocked--
+	if loop.Condition == nil {
+		return nil
 	}
-	return stmt
+	if !p.parseLoopBody(loop) {
+		return nil
+	}
+	return loop
 }
 
-func (p *parser) parseFunctionLiteral() ast.Expression {
+func (p *parser) parseForExpression() ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
-	fl := &ast.FunctionLiteral{}
-
-	if !p.peekIs(token.IDENT) && !p.peekToken.Type.IsOperator() {
-		p.unexpectedTokenError(p.peekToken.Type, "", token.IDENT)
-		return nil
+	loop := &ast.LoopExpression{}
+	var variables ast.ExpressionList
+	for {
+		if !p.accept(token.IDENT) {
+			return nil
+		}
+		variable := p.parseIdentifier()
+		p.setSpan(variable, p.pos)
+		variables = append(variables, variable)
+		if !p.peekIs(token.COMMA) {
+			break
+		}
+		p.accept(token.COMMA)
 	}
-
-	if p.peekIs(token.IDENT) {
-		p.accept(token.IDENT)
-	} else {
-		p.nextToken()
+	loop.Variable = variables
+	if len(variables) == 1 {
+		loop.Variable = variables[0]
 	}
-	fl.Name = p.curToken.Literal
-
-	fl.Parameters = p.parseFunctionParameters(token.LPAREN, token.RPAREN)
-
-	if !p.accept(token.NEWLINE, token.SEMICOLON) {
+	if !p.accept(token.IN) {
 		return nil
 	}
-
-	fl.Body = p.parseBlockStatement(token.END)
-	if !p.accept(token.END) {
+	p.nextToken()
+	p.doBlocked++
+	loop.Iterable = p.parseExpression(precLowest)
+	p.doBlocked--
+	if loop.Iterable == nil {
 		return nil
 	}
-
-	// Check for dynamic constant assignment
-	inspect := func(n ast.Node) {
-		// if _, ok := n.(*ast.Splat); ok {
-		// 	// skip walking the splat since we don't want to evaluate it
-		// 	return false
-		// }
-		x, ok := n.(*ast.Assignment)
-		if ok {
-			switch left := x.Left.(type) {
-			case *ast.Identifier:
-				if left.IsConstant() {
-					p.Error(fmt.Errorf("dynamic constant assignment"))
-				}
-			case ast.ExpressionList:
-				for _, expr := range left {
-					if ident, ok := expr.(*ast.Identifier); ok {
-						if ident.IsConstant() {
-							p.Error(fmt.Errorf("dynamic constant assignment"))
-						}
-					}
-				}
-			}
-		}
-		// return true
+	if !p.parseLoopBody(loop) {
+		return nil
 	}
-	ast.Inspect(fl.Body, inspect)
-
-	return fl
+	return loop
 }
 
-func (p *parser) parseFunctionParameters(startToken, endToken token.Type) []*ast.FunctionParameter {
+// parseLoopBody parses the body of a while, until or for loop following its
+// header, up to and including the closing END
+func (p *parser) parseLoopBody(loop *ast.LoopExpression) bool {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
 	}
-	hasDelimiters := false
-	if p.peekIs(startToken) {
-		hasDelimiters = true
-		p.accept(startToken)
+	// the header may be followed by a do
+	do := p.peekIs(token.DO)
+	if do {
+		p.accept(token.DO)
 	}
-
-	identifiers := []*ast.FunctionParameter{}
-
-	if !hasDelimiters && p.peekIs(endToken) {
-		p.unexpectedTokenError(p.peekToken.Type, "", token.NEWLINE, token.SEMICOLON)
+	// there may be a comment here. gobble it up
+	if p.peekIs(token.COMMENT) {
+		p.accept(token.COMMENT)
+	}
+	if !p.peekIs(token.NEWLINE, token.SEMICOLON) && !do {
+		p.unexpectedTokenError(p.peekToken.Type, "could not parse loop", token.NEWLINE, token.SEMICOLON, token.DO)
+		return false
+	}
+	if p.peekIs(token.NEWLINE, token.SEMICOLON) {
+		p.accept(token.NEWLINE, token.SEMICOLON)
+	}
+	p.jumpDepth++
+	loop.Block = p.parseBlockStatement()
+	p.jumpDepth--
+	return p.accept(token.END)
+}
+
+// parseModifierLoopExpression parses `body while cond` and `body until cond`.
+// A begin block as body is evaluated once before the condition is checked.
+func (p *parser) parseModifierLoopExpression(left ast.Expression) ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	loop := &ast.LoopExpression{Until: p.currentIs(token.UNTIL), Modifier: true}
+	_, loop.DoWhile = left.(*ast.BeginExpression)
+	p.adoptStrayJumps(left)
+	p.nextToken()
+	loop.Condition = p.parseExpression(precLowest)
+	loop.Block = &ast.BlockStatement{
+		Statements: []ast.Statement{
+			&ast.ExpressionStatement{Expression: left},
+		},
+	}
+	return loop
+}
+
+func (p *parser) parseBeginExpression() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	if p.peekIs(token.NEWLINE, token.SEMICOLON) {
+		p.accept(token.NEWLINE, token.SEMICOLON)
+	}
+	body := p.parseBlockStatement(token.RESCUE, token.ELSE, token.ENSURE)
+	begin := p.parseRescueClauses(body)
+	if begin == nil {
+		return nil
+	}
+	return begin
+}
+
+// parseRescueClauses parses the rescue, else and ensure clauses following body
+// up to and including the closing END
+func (p *parser) parseRescueClauses(body *ast.BlockStatement) *ast.BeginExpression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	begin := &ast.BeginExpression{Body: body}
+	for p.peekIs(token.RESCUE) {
+		p.accept(token.RESCUE)
+		rescue := p.parseRescueClause()
+		if rescue == nil {
+			return nil
+		}
+		begin.Rescues = append(begin.Rescues, rescue)
+	}
+	if p.peekIs(token.ELSE) {
+		p.accept(token.ELSE)
+		if p.peekIs(token.NEWLINE, token.SEMICOLON) {
+			p.accept(token.NEWLINE, token.SEMICOLON)
+		}
+		begin.Else = p.parseBlockStatement(token.ENSURE)
+	}
+	if p.peekIs(token.ENSURE) {
+		p.accept(token.ENSURE)
+		if p.peekIs(token.NEWLINE, token.SEMICOLON) {
+			p.accept(token.NEWLINE, token.SEMICOLON)
+		}
+		begin.Ensure = p.parseBlockStatement()
+	}
+	if !p.accept(token.END) {
+		return nil
+	}
+	return begin
+}
+
+func (p *parser) parseRescueClause() *ast.RescueClause {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	rescue := &ast.RescueClause{}
+	start := p.pos
+	if !p.peekIs(token.NEWLINE, token.SEMICOLON, token.THEN, token.HASHROCKET) {
+		p.nextToken()
+		// the `=> e` is no hash, even within the arguments of a call
+		argLists := p.argLists
+		p.argLists = 0
+		classes := p.parseExpression(precLowest)
+		p.argLists = argLists
+		if list, ok := classes.(ast.ExpressionList); ok {
+			rescue.ExceptionClasses = list
+		} else if classes != nil {
+			rescue.ExceptionClasses = []ast.Expression{classes}
+		}
+	}
+	if p.peekIs(token.HASHROCKET) {
+		p.accept(token.HASHROCKET)
+		if !p.accept(token.IDENT) {
+			return nil
+		}
+		rescue.Exception = &ast.Identifier{Value: p.curToken.Literal}
+		rescue.Exception.SetSpan(p.pos, p.endPos())
+	}
+	if p.peekIs(token.THEN) {
+		p.accept(token.THEN)
+	} else if !p.accept(token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+	p.rescueDepth++
+	rescue.Body = p.parseBlockStatement(token.RESCUE, token.ELSE, token.ENSURE)
+	p.rescueDepth--
+	rescue.SetSpan(start, max(start, p.endPos()))
+	return rescue
+}
+
+func (p *parser) parseRescueModifier(left ast.Expression) ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	rescue := &ast.RescueClause{}
+	start := p.pos
+	p.nextToken()
+	fallback := p.parseExpression(precIfUnless)
+	if fallback == nil {
+		return nil
+	}
+	rescue.Body = &ast.BlockStatement{
+		Statements: []ast.Statement{&ast.ExpressionStatement{Expression: fallback}},
+	}
+	rescue.SetSpan(start, p.endPos())
+	return &ast.BeginExpression{
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{&ast.ExpressionStatement{Expression: left}},
+		},
+		Rescues: []*ast.RescueClause{rescue},
+	}
+}
+
+func (p *parser) parseCaseExpression() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	var subject ast.Expression
+	if !p.peekIs(token.NEWLINE, token.SEMICOLON, token.WHEN) {
+		p.nextToken()
+		subject = p.parseExpression(precPatternMatch)
+		if subject == nil {
+			return nil
+		}
+	}
+	for p.peekIs(token.NEWLINE, token.SEMICOLON) {
+		p.nextToken()
+	}
+	if subject != nil && p.peekIs(token.IN) {
+		return p.parseCaseMatchExpression(subject)
+	}
+	if !p.peekIs(token.WHEN) {
+		p.unexpectedTokenError(p.peekToken.Type, "", token.WHEN, token.IN)
+		return nil
+	}
+	expression := &ast.CaseExpression{Subject: subject}
+	for p.peekIs(token.WHEN) {
+		p.accept(token.WHEN)
+		when := p.parseWhenClause()
+		if when == nil {
+			return nil
+		}
+		expression.Whens = append(expression.Whens, when)
+	}
+	var ok bool
+	if expression.Else, ok = p.parseCaseElse(); !ok {
+		return nil
+	}
+	return expression
+}
+
+// parseCaseElse parses the optional else clause of a case expression up to
+// and including the closing END. It returns false if END is missing.
+func (p *parser) parseCaseElse() (*ast.BlockStatement, bool) {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	var alternative *ast.BlockStatement
+	if p.peekIs(token.ELSE) {
+		p.accept(token.ELSE)
+		if p.peekIs(token.NEWLINE, token.SEMICOLON) {
+			p.accept(token.NEWLINE, token.SEMICOLON)
+		}
+		alternative = p.parseBlockStatement()
+	}
+	return alternative, p.accept(token.END)
+}
+
+func (p *parser) parseWhenClause() *ast.WhenClause {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	when := &ast.WhenClause{}
+	start := p.pos
+	p.nextToken()
+	values := p.parseExpression(precLowest)
+	if values == nil {
+		return nil
+	}
+	if list, ok := values.(ast.ExpressionList); ok {
+		when.Values = list
+	} else {
+		when.Values = []ast.Expression{values}
+	}
+	if p.peekIs(token.THEN) {
+		p.accept(token.THEN)
+	} else if !p.accept(token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+	when.Body = p.parseBlockStatement(token.WHEN, token.ELSE)
+	when.SetSpan(start, max(start, p.endPos()))
+	return when
+}
+
+// patternTerminators are the tokens which can follow the pattern of an in
+// clause or of a standalone pattern match
+var patternTerminators = []token.Type{
+	token.THEN,
+	token.NEWLINE,
+	token.SEMICOLON,
+	token.IF,
+	token.UNLESS,
+	token.RPAREN,
+	token.EOF,
+}
+
+func (p *parser) parseCaseMatchExpression(subject ast.Expression) ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	expression := &ast.CaseMatchExpression{Subject: subject}
+	for p.peekIs(token.IN) {
+		p.accept(token.IN)
+		in := p.parseInClause()
+		if in == nil {
+			return nil
+		}
+		expression.Ins = append(expression.Ins, in)
+	}
+	var ok bool
+	if expression.Else, ok = p.parseCaseElse(); !ok {
+		return nil
+	}
+	return expression
+}
+
+func (p *parser) parseInClause() *ast.InClause {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	in := &ast.InClause{}
+	start := p.pos
+	p.nextToken()
+	in.Pattern = p.parseTopPattern()
+	if in.Pattern == nil {
+		return nil
+	}
+	if p.peekIs(token.IF, token.UNLESS) {
+		p.nextToken()
+		in.Unless = p.currentIs(token.UNLESS)
+		p.nextToken()
+		in.Guard = p.parseExpression(precLowest)
+		if in.Guard == nil {
+			return nil
+		}
+	}
+	if p.peekIs(token.THEN) {
+		p.accept(token.THEN)
+	} else if !p.accept(token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+	in.Body = p.parseBlockStatement(token.IN, token.ELSE)
+	in.SetSpan(start, max(start, p.endPos()))
+	return in
+}
+
+// parseMatchPredicate parses `value in pattern`
+func (p *parser) parseMatchPredicate(value ast.Expression) ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	match := &ast.MatchExpression{Value: value}
+	p.nextToken()
+	match.Pattern = p.parseTopPattern()
+	if match.Pattern == nil {
+		return nil
+	}
+	return match
+}
+
+// parseRightwardAssignment parses `value => pattern`. Being a statement it
+// is not handled by parseExpression, which would clash with the HASHROCKET
+// of hash literals and rescue clauses.
+func (p *parser) parseRightwardAssignment(value ast.Expression, start gotoken.Pos) ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	match := &ast.MatchExpression{Value: value, Required: true}
+	p.accept(token.HASHROCKET)
+	p.nextToken()
+	match.Pattern = p.parseTopPattern()
+	if match.Pattern == nil {
+		return nil
+	}
+	p.setSpan(match, start)
+	return match
+}
+
+// parseTopPattern parses the pattern of an in clause or of a standalone
+// pattern match, where array and hash patterns may omit their brackets, as
+// in `in first, *rest` or `in name:, age:`
+func (p *parser) parseTopPattern() ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	start := p.pos
+	if p.isHashPatternStart() {
+		hash := p.parseHashPattern(nil, patternTerminators...)
+		if hash == nil {
+			return nil
+		}
+		p.setSpan(hash, start)
+		return hash
+	}
+	first := p.parsePattern()
+	if first == nil {
+		return nil
+	}
+	if _, ok := first.(*ast.SplatPattern); !ok && !p.peekIs(token.COMMA) {
+		return first
+	}
+	elements := []ast.Pattern{first}
+	for p.peekIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		elements = append(elements, element)
+	}
+	array := p.newArrayPattern(nil, elements)
+	if array == nil {
+		return nil
+	}
+	p.setSpan(array, start)
+	return array
+}
+
+// parsePattern parses a pattern with its alternatives and captures, e.g.
+// `Integer | Float => n`
+func (p *parser) parsePattern() ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	start := p.pos
+	pattern := p.parsePrimaryPattern()
+	if pattern == nil {
+		return nil
+	}
+	if p.peekIs(token.PIPE) {
+		alternatives := &ast.AlternativePattern{Alternatives: []ast.Pattern{pattern}}
+		for p.peekIs(token.PIPE) {
+			p.nextToken()
+			p.nextToken()
+			alternative := p.parsePrimaryPattern()
+			if alternative == nil {
+				return nil
+			}
+			alternatives.Alternatives = append(alternatives.Alternatives, alternative)
+		}
+		alternatives.SetSpan(start, p.endPos())
+		pattern = alternatives
+	}
+	for p.peekIs(token.HASHROCKET) {
+		p.nextToken()
+		if !p.accept(token.IDENT) {
+			return nil
+		}
+		capture := &ast.CapturePattern{
+			Pattern: pattern,
+			Name:    &ast.Identifier{Value: p.curToken.Literal},
+		}
+		capture.Name.SetSpan(p.pos, p.endPos())
+		capture.SetSpan(start, p.endPos())
+		pattern = capture
+	}
+	return pattern
+}
+
+func (p *parser) parsePrimaryPattern() ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	start := p.pos
+	var pattern ast.Pattern
+	switch p.curToken.Type {
+	case token.LBRACKET, token.SLBRACKET:
+		pattern = p.parsePatternElements(nil, token.RBRACKET)
+	case token.LBRACE:
+		pattern = p.parsePatternElements(nil, token.RBRACE)
+	case token.LPAREN:
+		p.nextToken()
+		pattern = p.parsePattern()
+		if pattern == nil || !p.accept(token.RPAREN) {
+			return nil
+		}
+		return pattern
+	case token.CARET:
+		pattern = p.parsePinPattern()
+	case token.ASTERISK:
+		splat := &ast.SplatPattern{}
+		if p.peekIs(token.IDENT) {
+			p.nextToken()
+			splat.Name = &ast.Identifier{Value: p.curToken.Literal}
+			splat.Name.SetSpan(p.pos, p.endPos())
+		}
+		pattern = splat
+	case token.IDENT:
+		name := &ast.Identifier{Value: p.curToken.Literal}
+		name.SetSpan(p.pos, p.endPos())
+		if !name.IsConstant() {
+			pattern = &ast.VariablePattern{Name: name}
+			break
+		}
+		pattern = p.parseConstantPattern(name)
+	case token.SCOPE, token.SSCOPE:
+		constant := p.parseTopLevelConstant()
+		if constant == nil {
+			return nil
+		}
+		p.setSpan(constant, start)
+		pattern = p.parseConstantPattern(constant)
+	default:
+		pattern = p.parseValuePattern()
+	}
+	if pattern == nil {
+		return nil
+	}
+	p.setSpan(pattern, start)
+	return pattern
+}
+
+// parseConstantPattern parses the rest of a pattern starting with constant,
+// which is either a plain value pattern or an array or hash pattern like
+// `Point(x, y)`
+func (p *parser) parseConstantPattern(constant ast.Expression) ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	start := constant.Pos()
+	for p.peekIs(token.SCOPE) {
+		p.nextToken()
+		constant = p.parseScopedConstant(constant)
+		if constant == nil {
+			return nil
+		}
+		p.setSpan(constant, start)
+	}
+	switch {
+	case p.peekIs(token.LPAREN):
+		p.nextToken()
+		return p.parsePatternElements(constant, token.RPAREN)
+	case p.peekIs(token.LBRACKET):
+		p.nextToken()
+		return p.parsePatternElements(constant, token.RBRACKET)
+	case p.peekIs(token.DDOT, token.DDDOT):
+		return p.parseRangePattern(constant)
+	}
+	return &ast.ValuePattern{Value: constant}
+}
+
+func (p *parser) parsePinPattern() ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	pin := &ast.PinPattern{}
+	p.nextToken()
+	switch p.curToken.Type {
+	case token.IDENT:
+		name := &ast.Identifier{Value: p.curToken.Literal}
+		name.SetSpan(p.pos, p.endPos())
+		pin.Value = name
+	case token.LPAREN:
+		p.nextToken()
+		pin.Value = p.parseExpression(precLowest)
+		if pin.Value == nil || !p.accept(token.RPAREN) {
+			return nil
+		}
+	default:
+		p.unexpectedTokenError(p.curToken.Type, "", token.IDENT, token.LPAREN)
+		return nil
+	}
+	return pin
+}
+
+// parseValuePattern parses a pattern matching values through ===, like
+// literals and ranges. The value ends before any | separating alternatives.
+func (p *parser) parseValuePattern() ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	value := p.parseExpression(precOr)
+	if value == nil {
+		return nil
+	}
+	if p.peekIs(token.DDOT, token.DDDOT) {
+		return p.parseRangePattern(value)
+	}
+	return &ast.ValuePattern{Value: value}
+}
+
+func (p *parser) parseRangePattern(left ast.Expression) ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	p.nextToken()
+	rng := &ast.RangeLiteral{Left: left, Inclusive: p.currentIs(token.DDOT)}
+	p.nextToken()
+	rng.Right = p.parseExpression(precOr)
+	if rng.Right == nil {
+		return nil
+	}
+	p.setSpan(rng, left.Pos())
+	return &ast.ValuePattern{Value: rng}
+}
+
+// parsePatternElements parses the elements of an array, find or hash pattern
+// from the opening bracket up to and including closing
+func (p *parser) parsePatternElements(constant ast.Expression, closing token.Type) ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	p.nextToken()
+	p.consumeNewlineOrComment()
+	if p.isHashPatternStart() || (closing == token.RBRACE && !p.currentIs(token.RBRACE)) {
+		hash := p.parseHashPattern(constant, closing)
+		if hash == nil {
+			return nil
+		}
+		for p.peekIs(token.NEWLINE) {
+			p.nextToken()
+		}
+		if !p.accept(closing) {
+			return nil
+		}
+		return hash
+	}
+	if closing == token.RBRACE {
+		return &ast.HashPattern{Constant: constant}
+	}
+	var elements []ast.Pattern
+	for !p.currentIs(closing) {
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		elements = append(elements, element)
+		for p.peekIs(token.NEWLINE) {
+			p.nextToken()
+		}
+		if !p.peekIs(token.COMMA) {
+			if !p.accept(closing) {
+				return nil
+			}
+			break
+		}
+		p.nextToken()
+		p.nextToken()
+		p.consumeNewlineOrComment()
+	}
+	return p.newArrayPattern(constant, elements)
+}
+
+// isHashPatternStart reports whether the current token starts a hash
+// pattern, i.e. is a `key:` label or a `**` rest
+func (p *parser) isHashPatternStart() bool {
+	return (p.currentIs(token.IDENT) && p.peekIs(token.COLON)) || p.currentIs(token.POW)
+}
+
+// parseHashPattern parses the pairs of a hash pattern. A key directly
+// followed by a comma or one of the closing tokens has no value pattern.
+func (p *parser) parseHashPattern(constant ast.Expression, closing ...token.Type) ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	hash := &ast.HashPattern{Constant: constant}
+	valueless := append([]token.Type{token.COMMA, token.NEWLINE}, closing...)
+	for {
+		start := p.pos
+		switch {
+		case p.currentIs(token.POW):
+			p.nextToken()
+			if p.currentIs(token.NIL) {
+				hash.NoRest = true
+				break
+			}
+			if !p.currentIs(token.IDENT) {
+				p.unexpectedTokenError(p.curToken.Type, "", token.IDENT, token.NIL)
+				return nil
+			}
+			name := &ast.Identifier{Value: p.curToken.Literal}
+			name.SetSpan(p.pos, p.endPos())
+			hash.Rest = &ast.SplatPattern{Name: name}
+			hash.Rest.SetSpan(start, p.endPos())
+		case p.currentIs(token.IDENT) && p.peekIs(token.COLON):
+			pair := &ast.HashPatternPair{Key: &ast.Identifier{Value: p.curToken.Literal}}
+			pair.Key.SetSpan(p.pos, p.endPos())
+			p.nextToken()
+			if !p.peekIs(valueless...) {
+				p.nextToken()
+				pair.Value = p.parsePattern()
+				if pair.Value == nil {
+					return nil
+				}
+			}
+			pair.SetSpan(start, p.endPos())
+			hash.Pairs = append(hash.Pairs, pair)
+		default:
+			p.unexpectedTokenError(p.curToken.Type, "expected a key of a hash pattern", token.IDENT, token.POW)
+			return nil
+		}
+		if !p.peekIs(token.COMMA) {
+			return hash
+		}
+		p.nextToken()
+		p.nextToken()
+		p.consumeNewlineOrComment()
+	}
+}
+
+// newArrayPattern sorts the elements of an array pattern around its splat.
+// Elements enclosed by two splats, as in `[*, x, *]`, form a FindPattern.
+func (p *parser) newArrayPattern(constant ast.Expression, elements []ast.Pattern) ast.Pattern {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	var splats []int
+	for i, element := range elements {
+		if _, ok := element.(*ast.SplatPattern); ok {
+			splats = append(splats, i)
+		}
+	}
+	switch {
+	case len(splats) == 0:
+		return &ast.ArrayPattern{Constant: constant, Pre: elements}
+	case len(splats) == 1:
+		i := splats[0]
+		return &ast.ArrayPattern{
+			Constant: constant,
+			Pre:      elements[:i],
+			Rest:     elements[i].(*ast.SplatPattern),
+			Post:     elements[i+1:],
+		}
+	case len(splats) == 2 && splats[0] == 0 && splats[1] == len(elements)-1 && len(elements) > 2:
+		return &ast.FindPattern{
+			Constant: constant,
+			Pre:      elements[0].(*ast.SplatPattern),
+			Middle:   elements[1 : len(elements)-1],
+			Post:     elements[len(elements)-1].(*ast.SplatPattern),
+		}
+	}
+	epos := p.file.Position(p.pos)
+	p.Error(fmt.Errorf("%s: unexpected splat in array pattern", epos.String()))
+	return nil
+}
+
+func (p *parser) parseRetryStatement() *ast.RetryStatement {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	if p.rescueDepth == 0 {
+		epos := p.file.Position(p.pos)
+		p.Error(fmt.Errorf("%s: Invalid retry", epos.String()))
+		return nil
+	}
+	stmt := &ast.RetryStatement{}
+	stmt.SetSpan(p.pos, p.endPos())
+	if p.peekIs(token.SEMICOLON, token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *parser) parseClassExpression() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	class := &ast.ClassExpression{}
+	class.Name = p.parseConstantName()
+	if class.Name == nil {
+		return nil
+	}
+
+	if p.peekIs(token.LT) {
+		p.nextToken()
+		p.nextToken()
+		class.SuperClass = p.parseExpression(precLessGreater)
+		if class.SuperClass == nil {
+			return nil
+		}
+	}
+
+	if !p.accept(token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+	class.Body = p.parseBlockStatement()
+	if !p.accept(token.END) {
+		return nil
+	}
+	return class
+}
+
+func (p *parser) parseModuleExpression() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	module := &ast.ModuleExpression{}
+	module.Name = p.parseConstantName()
+	if module.Name == nil {
+		return nil
+	}
+	if !p.accept(token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+	module.Body = p.parseBlockStatement()
+	if !p.accept(token.END) {
+		return nil
+	}
+	return module
+}
+
+// parseConstantName parses the name of a class or module definition
+func (p *parser) parseConstantName() *ast.Identifier {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	if !p.accept(token.IDENT) {
+		return nil
+	}
+	name := &ast.Identifier{Value: p.curToken.Literal}
+	name.SetSpan(p.pos, p.endPos())
+	if !name.IsConstant() {
+		epos := p.file.Position(p.pos)
+		p.Error(fmt.Errorf("%s: class/module name must be CONSTANT", epos.String()))
+		return nil
+	}
+	return name
+}
+
+// parseScopedConstant parses the right hand side of the scope operator. A
+// lower case name, as in Foo::bar, is a method call on the left hand side.
+func (p *parser) parseScopedConstant(scope ast.Expression) ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	if p.peekIs(token.IDENT) && !(&ast.Identifier{Value: p.peekToken.Literal}).IsConstant() {
+		return p.parseMethodCall(scope)
+	}
+	constant := &ast.ScopedConstant{Scope: scope}
+	if !p.accept(token.IDENT) {
+		return nil
+	}
+	constant.Name = &ast.Identifier{Value: p.curToken.Literal}
+	constant.Name.SetSpan(p.pos, p.endPos())
+	return constant
+}
+
+func (p *parser) parseTopLevelConstant() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	constant := &ast.ScopedConstant{}
+	if !p.accept(token.IDENT) {
+		return nil
+	}
+	constant.Name = &ast.Identifier{Value: p.curToken.Literal}
+	constant.Name.SetSpan(p.pos, p.endPos())
+	if !constant.Name.IsConstant() {
+		epos := p.file.Position(p.pos)
+		p.Error(fmt.Errorf("%s: expected a constant after ::", epos.String()))
+		return nil
+	}
+	return constant
+}
+
+func (p *parser) parseSelf() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	return &ast.Self{}
+}
+
+func (p *parser) parseSuper() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	super := &ast.Super{}
+	if p.peekIs(token.LPAREN) {
+		p.accept(token.LPAREN)
+		super.Arguments = p.parseParenthesizedArguments()
+		super.ExplicitArguments = true
+		return super
+	}
+	if p.peekIs(token.SEMICOLON, token.NEWLINE, token.EOF, token.DOT, token.RPAREN, token.END, token.QMARK) {
+		return super
+	}
+	if p.peekToken.Type.IsOperator() {
+		// a binary operation on the result, e.g. `super + 1`
+		return super
+	}
+	if p.peekIs(append(tokensNotPossibleInCallArgs, token.RBRACE)...) {
+		return super
+	}
+	p.nextToken()
+	super.Arguments = p.parseCallArguments()
+	super.ExplicitArguments = true
+	return super
+}
+
+func (p *parser) parseYield() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	yield := &ast.Yield{}
+	if p.peekIs(token.LPAREN) {
+		p.accept(token.LPAREN)
+		yield.Arguments = p.parseParenthesizedArguments()
+		return yield
+	}
+	if p.peekIs(token.SEMICOLON, token.NEWLINE, token.EOF, token.DOT, token.RPAREN, token.END, token.RBRACE, token.QMARK) {
+		return yield
+	}
+	if p.peekToken.Type.IsOperator() {
+		// a binary operation on the result, e.g. `yield + 1`
+		return yield
+	}
+	if p.peekIs(tokensNotPossibleInCallArgs...) {
+		return yield
+	}
+	p.nextToken()
+	p.doBlocked++
+	yield.Arguments = p.parseCallArguments()
+	p.doBlocked--
+	return yield
+}
+
+func (p *parser) parseBreakStatement() *ast.BreakStatement {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	stmt := &ast.BreakStatement{}
+	start := p.pos
+	p.noteJump(start, "break")
+	stmt.Value, stmt.Condition, stmt.Unless = p.parseJumpArguments()
+	stmt.SetSpan(start, p.endPos())
+	if p.peekIs(token.SEMICOLON, token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *parser) parseNextStatement() *ast.NextStatement {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	stmt := &ast.NextStatement{}
+	start := p.pos
+	p.noteJump(start, "next")
+	stmt.Value, stmt.Condition, stmt.Unless = p.parseJumpArguments()
+	stmt.SetSpan(start, p.endPos())
+	if p.peekIs(token.SEMICOLON, token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// parseJumpArguments parses the optional value and the optional modifier
+// condition following a break or next keyword
+func (p *parser) parseJumpArguments() (value, condition ast.Expression, unless bool) {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	if !p.peekIs(token.NEWLINE, token.SEMICOLON, token.EOF, token.END, token.RBRACE, token.COMMENT, token.IF, token.UNLESS) {
+		p.nextToken()
+		value = p.parseExpression(precIfUnless)
+		if list, ok := value.(ast.ExpressionList); ok {
+			value = &ast.ArrayLiteral{Elements: list}
+		}
+	}
+	if p.peekIs(token.IF, token.UNLESS) {
+		p.nextToken()
+		unless = p.currentIs(token.UNLESS)
+		p.nextToken()
+		condition = p.parseExpression(precLowest)
+	}
+	return value, condition, unless
+}
+
+func (p *parser) parseRedoStatement() *ast.RedoStatement {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	stmt := &ast.RedoStatement{}
+	start := p.pos
+	p.noteJump(start, "redo")
+	if p.peekIs(token.IF, token.UNLESS) {
+		p.nextToken()
+		stmt.Unless = p.currentIs(token.UNLESS)
+		p.nextToken()
+		stmt.Condition = p.parseExpression(precLowest)
+	}
+	stmt.SetSpan(start, p.endPos())
+	if p.peekIs(token.SEMICOLON, token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// A strayJump is a break, next or redo statement found outside of any loop or
+// block. It is only valid if it turns out to be part of the body of a
+// modifier loop, as in `begin ... end while cond`.
+type strayJump struct {
+	pos     gotoken.Pos
+	keyword string
+}
+
+// noteJump records the break, next or redo statement at pos unless it is
+// enclosed by a loop or block
+func (p *parser) noteJump(pos gotoken.Pos, keyword string) {
+	if p.jumpDepth == 0 {
+		p.strayJumps = append(p.strayJumps, strayJump{pos: pos, keyword: keyword})
+	}
+}
+
+// adoptStrayJumps forgets the stray jumps within body, which became the body
+// of a modifier loop
+func (p *parser) adoptStrayJumps(body ast.Node) {
+	strays := p.strayJumps[:0]
+	for _, jump := range p.strayJumps {
+		if jump.pos < body.Pos() || jump.pos >= body.End() {
+			strays = append(strays, jump)
+		}
+	}
+	p.strayJumps = strays
+}
+
+// reportStrayJumps reports an error for every stray jump left
+func (p *parser) reportStrayJumps() {
+	for _, jump := range p.strayJumps {
+		epos := p.file.Position(jump.pos)
+		p.Error(fmt.Errorf("%s: Invalid %s", epos.String(), jump.keyword))
+	}
+	p.strayJumps = nil
+}
+
+func (p *parser) parseFunctionLiteral() ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	fl := &ast.FunctionLiteral{}
+
+	if p.peekIs(token.SELF) {
+		p.nextToken()
+		fl.Receiver = &ast.Self{}
+		fl.Receiver.(*ast.Self).SetSpan(p.pos, p.endPos())
+		if !p.accept(token.DOT) {
+			return nil
+		}
+	}
+
+	if !p.peekIs(token.IDENT) && !p.peekToken.Type.IsOperator() {
+		p.unexpectedTokenError(p.peekToken.Type, "", token.IDENT)
+		return nil
+	}
+
+	if p.peekIs(token.IDENT) {
+		p.accept(token.IDENT)
+	} else {
+		p.nextToken()
+	}
+	fl.Name = p.curToken.Literal
+	if p.currentIs(token.IDENT) && p.peekIs(token.ASSIGN) {
+		// setter method, e.g. `def name=(value)`
+		p.nextToken()
+		fl.Name += "="
+	}
+
+	fl.Parameters = p.parseFunctionParameters(token.LPAREN, token.RPAREN)
+
+	if !p.accept(token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+
+	// loops and blocks around the definition are out of reach of a break,
+	// next or redo within the method body
+	outerDepth, outerJumps := p.jumpDepth, p.strayJumps
+	p.jumpDepth, p.strayJumps = 0, nil
+	defer func() { p.jumpDepth, p.strayJumps = outerDepth, outerJumps }()
+
+	fl.Body = p.parseBlockStatement(token.RESCUE, token.ELSE, token.ENSURE)
+	if p.peekIs(token.RESCUE, token.ELSE, token.ENSURE) {
+		// the whole method body is wrapped in an implicit begin
+		start := fl.Body.Pos()
+		begin := p.parseRescueClauses(fl.Body)
+		if begin == nil {
+			return nil
+		}
+		begin.SetSpan(start, p.endPos())
+		fl.Body = &ast.BlockStatement{
+			Statements: []ast.Statement{&ast.ExpressionStatement{Expression: begin}},
+		}
+	} else if !p.accept(token.END) {
+		return nil
+	}
+	p.reportStrayJumps()
+
+	// Check for dynamic constant assignment
+	inspect := func(n ast.Node) {
+		// if _, ok := n.(*ast.Splat); ok {
+		// 	// skip walking the splat since we don't want to evaluate it
+		// 	return false
+		// }
+		var target ast.Expression
+		switch x := n.(type) {
+		case *ast.Assignment:
+			target = x.Left
+		case *ast.LogicalAssignment:
+			target = x.Left
+		}
+		if target != nil {
+			switch left := target.(type) {
+			case *ast.Identifier:
+				if left.IsConstant() {
+					p.Error(fmt.Errorf("dynamic constant assignment"))
+				}
+			case ast.ExpressionList:
+				for _, expr := range left {
+					if ident, ok := expr.(*ast.Identifier); ok {
+						if ident.IsConstant() {
+							p.Error(fmt.Errorf("dynamic constant assignment"))
+						}
+					}
+				}
+			}
+		}
+		// return true
+	}
+	ast.Inspect(fl.Body, inspect)
+
+	return fl
+}
+
+func (p *parser) parseFunctionParameters(startToken, endToken token.Type) []*ast.FunctionParameter {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	hasDelimiters := false
+	if p.peekIs(startToken) {
+		hasDelimiters = true
+		p.accept(startToken)
+	}
+
+	identifiers := []*ast.FunctionParameter{}
+
+	if !hasDelimiters && p.peekIs(endToken) {
+		p.unexpectedTokenError(p.peekToken.Type, "", token.NEWLINE, token.SEMICOLON)
 		return nil
 	}
 
@@ -1119,39 +2693,20 @@ func (p *parser) parseFunctionParameters(startToken, endToken token.Type) []*ast
 		return identifiers
 	}
 
-	got_splat := false
-	if p.peekIs(token.ASTERISK) {
-		got_splat = true
-		p.accept(token.ASTERISK)
-	}
-	p.accept(token.IDENT)
-
-	ident := &ast.FunctionParameter{Name: p.curToken.Literal}
-	if got_splat {
-		ident.Splat = true
-		got_splat = false
-	}
-	if p.peekIs(token.ASSIGN) {
-		p.consume(token.ASSIGN)
-		ident.Default = p.parseExpression(precAssignment)
-	}
+	ident := p.parseFunctionParameter(endToken, precAssignment)
 	identifiers = append(identifiers, ident)
 
 	for p.peekIs(token.COMMA) {
 		p.accept(token.COMMA)
-		if p.peekIs(token.ASTERISK) {
-			got_splat = true
-			p.accept(token.ASTERISK)
-		}
-		p.accept(token.IDENT)
-		ident := &ast.FunctionParameter{Name: p.curToken.Literal}
-		if got_splat {
-			ident.Splat = true
-			got_splat = false
+		if ident.Block {
+			p.Error(fmt.Errorf("%s: block parameter must be the last one", p.file.Position(p.pos).String()))
+			return nil
 		}
-		if p.peekIs(token.ASSIGN) {
-			p.consume(token.ASSIGN)
-			ident.Default = p.parseExpression(precPrefix)
+		keywords := ident.Keyword || ident.DoubleSplat
+		ident = p.parseFunctionParameter(endToken, precPrefix)
+		if keywords && !(ident.Keyword || ident.DoubleSplat || ident.Block) {
+			p.Error(fmt.Errorf("%s: positional parameter after keyword parameters", p.file.Position(p.pos).String()))
+			return nil
 		}
 		identifiers = append(identifiers, ident)
 	}
@@ -1168,6 +2723,46 @@ func (p *parser) parseFunctionParameters(startToken, endToken token.Type) []*ast
 	return identifiers
 }
 
+// parseFunctionParameter parses a single parameter of a method or block, with
+// a default value parsed at precedence. A keyword parameter without a default
+// is followed by a comma or endToken.
+func (p *parser) parseFunctionParameter(endToken token.Type, precedence int) *ast.FunctionParameter {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	ident := &ast.FunctionParameter{}
+	start := p.tokenPos(p.peekToken)
+	if p.peekIs(token.ASTERISK) {
+		ident.Splat = true
+		p.accept(token.ASTERISK)
+	} else if p.peekIs(token.POW) {
+		ident.DoubleSplat = true
+		p.accept(token.POW)
+	} else if p.peekIs(token.AND, token.SAND) {
+		ident.Block = true
+		p.accept(token.AND, token.SAND)
+	} else if p.peekIs(token.LPAREN) {
+		ident.Destructure = p.parseFunctionParameters(token.LPAREN, token.RPAREN)
+		ident.SetSpan(start, p.endPos())
+		return ident
+	}
+	p.accept(token.IDENT)
+	ident.Name = p.curToken.Literal
+	if p.peekIs(token.COLON) && !ident.Splat && !ident.DoubleSplat && !ident.Block {
+		p.accept(token.COLON)
+		ident.Keyword = true
+		if !p.peekIs(token.COMMA, token.NEWLINE, token.SEMICOLON, endToken) {
+			p.nextToken()
+			ident.Default = p.parseExpression(precedence)
+		}
+	} else if p.peekIs(token.ASSIGN) {
+		p.consume(token.ASSIGN)
+		ident.Default = p.parseExpression(precedence)
+	}
+	ident.SetSpan(start, p.endPos())
+	return ident
+}
+
 func (p *parser) parseBlockStatement(t ...token.Type) *ast.BlockStatement {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -1180,6 +2775,10 @@ func (p *parser) parseBlockStatement(t ...token.Type) *ast.BlockStatement {
 	)
 	block := &ast.BlockStatement{}
 	block.Statements = []ast.Statement{}
+	start := p.tokenPos(p.peekToken)
+	blocked, argLists := p.doBlocked, p.argLists
+	p.doBlocked, p.argLists = 0, 0
+	defer func() { p.doBlocked, p.argLists = blocked, argLists }()
 
 	for !p.peekIs(terminatorTokens...) {
 		if p.peekIs(token.EOF) {
@@ -1193,6 +2792,8 @@ func (p *parser) parseBlockStatement(t ...token.Type) *ast.BlockStatement {
 		}
 	}
 
+	// an empty block is a zero-width span in front of its terminator
+	block.SetSpan(start, max(start, p.endPos()))
 	return block
 }
 
@@ -1204,7 +2805,17 @@ func (p *parser) parseMethodCall(context ast.Expression) ast.Expression {
 
 	p.nextToken()
 
-	if !p.currentIs(token.IDENT) && !p.curToken.Type.IsOperator() {
+	// `callable.(args)` is shorthand for `callable.call(args)`
+	if p.currentIs(token.LPAREN) {
+		contextCallExpression.Function = "call"
+		contextCallExpression.Arguments = p.parseParenthesizedArguments()
+		if p.acceptBlock() {
+			contextCallExpression.Block = p.parseBlock()
+		}
+		return contextCallExpression
+	}
+
+	if !p.currentIs(token.IDENT) && !p.curToken.Type.IsOperator() && !p.curToken.Type.IsKeyword() {
 		p.unexpectedTokenError(p.curToken.Type, "", token.IDENT)
 		return nil
 	}
@@ -1215,17 +2826,22 @@ func (p *parser) parseMethodCall(context ast.Expression) ast.Expression {
 	}
 	contextCallExpression.Function = name
 
-	if p.peekIs(token.SEMICOLON, token.NEWLINE, token.EOF, token.DOT, token.RPAREN, token.QMARK) {
+	if p.peekIs(token.SEMICOLON, token.NEWLINE, token.EOF, token.DOT, token.SCOPE, token.RPAREN, token.QMARK) {
 		contextCallExpression.Arguments = []ast.Expression{}
 		return contextCallExpression
 	}
 
 	if p.peekIs(token.LPAREN) {
 		p.accept(token.LPAREN)
-		p.nextToken()
-		contextCallExpression.Arguments = p.parseExpressionList(token.RPAREN)
-		if p.peekIs(token.LBRACE) {
-			p.accept(token.LBRACE)
+		contextCallExpression.Arguments = p.parseParenthesizedArguments()
+		if p.acceptBlock() {
+			contextCallExpression.Block = p.parseBlock()
+		}
+		return contextCallExpression
+	}
+
+	if p.peekIs(token.DO) {
+		if p.acceptBlock() {
 			contextCallExpression.Block = p.parseBlock()
 		}
 		return contextCallExpression
@@ -1237,8 +2853,10 @@ func (p *parser) parseMethodCall(context ast.Expression) ast.Expression {
 
 	p.nextToken()
 
+	p.doBlocked++
 	contextCallExpression.Arguments = p.parseCallArguments(token.LBRACE)
-	if p.currentIs(token.LBRACE) {
+	p.doBlocked--
+	if p.currentIs(token.LBRACE) || p.acceptBlock() {
 		contextCallExpression.Block = p.parseBlock()
 	}
 	return contextCallExpression
@@ -1274,10 +2892,15 @@ func (p *parser) parseContextCallExpression(context ast.Expression) ast.Expressi
 
 	if p.peekIs(token.LPAREN) {
 		p.accept(token.LPAREN)
-		p.nextToken()
-		contextCallExpression.Arguments = p.parseExpressionList(token.RPAREN)
-		if p.peekIs(token.LBRACE) {
-			p.accept(token.LBRACE)
+		contextCallExpression.Arguments = p.parseParenthesizedArguments()
+		if p.acceptBlock() {
+			contextCallExpression.Block = p.parseBlock()
+		}
+		return contextCallExpression
+	}
+
+	if p.peekIs(token.DO) {
+		if p.acceptBlock() {
 			contextCallExpression.Block = p.parseBlock()
 		}
 		return contextCallExpression
@@ -1288,8 +2911,10 @@ func (p *parser) parseContextCallExpression(context ast.Expression) ast.Expressi
 	}
 
 	p.nextToken()
+	p.doBlocked++
 	contextCallExpression.Arguments = p.parseCallArguments(token.LBRACE)
-	if p.currentIs(token.LBRACE) {
+	p.doBlocked--
+	if p.currentIs(token.LBRACE) || p.acceptBlock() {
 		contextCallExpression.Block = p.parseBlock()
 	}
 	return contextCallExpression
@@ -1310,9 +2935,10 @@ func (p *parser) parseCallArgument(function ast.Expression) ast.Expression {
 		return exp
 	}
 
+	p.doBlocked++
 	exp.Arguments = p.parseExpressionList(token.SEMICOLON, token.NEWLINE)
-	if p.peekIs(token.LBRACE) {
-		p.accept(token.LBRACE)
+	p.doBlocked--
+	if p.acceptBlock() {
 		exp.Block = p.parseBlock()
 	}
 	return exp
@@ -1354,15 +2980,39 @@ func (p *parser) parseCallExpressionWithParens(function ast.Expression) ast.Expr
 		return nil
 	}
 	exp := &ast.ContextCallExpression{Function: ident.Value}
-	p.nextToken()
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
-	if p.peekIs(token.LBRACE) {
-		p.accept(token.LBRACE)
+	exp.Arguments = p.parseParenthesizedArguments()
+	if p.acceptBlock() {
 		exp.Block = p.parseBlock()
 	}
 	return exp
 }
 
+// parseParenthesizedArguments parses the arguments of a call from the opening
+// parenthesis up to and including the closing one. A do block within them
+// binds to the innermost call again.
+func (p *parser) parseParenthesizedArguments() []ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	blocked := p.doBlocked
+	p.doBlocked = 0
+	defer func() { p.doBlocked = blocked }()
+	p.nextToken()
+	return p.parseExpressionList(token.RPAREN)
+}
+
+// acceptBlock moves onto the `{` or `do` opening a block attached to the call
+// being parsed, if there is one. Braces bind to the closest method name,
+// whereas a do within call arguments without parens or a loop header is left
+// for the outermost call or the loop.
+func (p *parser) acceptBlock() bool {
+	if p.peekIs(token.LBRACE) || p.peekIs(token.DO) && p.doBlocked == 0 {
+		p.nextToken()
+		return true
+	}
+	return false
+}
+
 func (p *parser) parseCallArguments(end ...token.Type) []ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -1371,12 +3021,14 @@ func (p *parser) parseCallArguments(end ...token.Type) []ast.Expression {
 	if p.currentIs(end...) {
 		return list
 	}
+	p.argLists++
+	defer func() { p.argLists-- }()
 
-	list = append(list, p.parseExpression(precAssignment))
+	list = appendArgument(list, p.parseArgument(precAssignment))
 
 	for p.peekIs(token.COMMA) {
 		p.consume(token.COMMA)
-		list = append(list, p.parseExpression(precAssignment))
+		list = appendArgument(list, p.parseArgument(precAssignment))
 	}
 
 	if p.peekIs(end...) {
@@ -1386,6 +3038,55 @@ func (p *parser) parseCallArguments(end ...token.Type) []ast.Expression {
 	return list
 }
 
+// parseArgument parses an argument of a call. A keyword argument, as in
+// `opt: 1`, `:opt => 1` or `**opts`, is returned as a hash without braces,
+// which appendArgument merges with the preceding ones.
+func (p *parser) parseArgument(precedence int) ast.Expression {
+	if p.tracer != nil {
+		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
+	}
+	start := p.pos
+	hash := &ast.HashLiteral{Map: make(map[ast.Expression]ast.Expression), Braceless: true}
+	if p.isLabel() || p.currentIs(token.POW) {
+		if !p.parseHashElement(hash) {
+			return nil
+		}
+		hash.SetSpan(start, p.endPos())
+		return hash
+	}
+	arg := p.parseExpression(precedence)
+	if arg == nil || p.argLists == 0 || !p.peekIs(token.HASHROCKET) {
+		return arg
+	}
+	p.consume(token.HASHROCKET)
+	value := p.parseExpression(precAssignment)
+	if value == nil {
+		return nil
+	}
+	hash.Map[arg] = value
+	hash.SetSpan(start, p.endPos())
+	return hash
+}
+
+// appendArgument appends arg to the argument list args, merging keyword
+// arguments into a single trailing hash
+func appendArgument(args []ast.Expression, arg ast.Expression) []ast.Expression {
+	hash, ok := arg.(*ast.HashLiteral)
+	if !ok || !hash.Braceless || len(args) == 0 {
+		return append(args, arg)
+	}
+	last, ok := args[len(args)-1].(*ast.HashLiteral)
+	if !ok || !last.Braceless {
+		return append(args, arg)
+	}
+	for key, value := range hash.Map {
+		last.Map[key] = value
+	}
+	last.Splats = append(last.Splats, hash.Splats...)
+	last.SetSpan(last.Pos(), hash.End())
+	return args
+}
+
 func (p *parser) parseExpressionList(end ...token.Type) []ast.Expression {
 	if p.tracer != nil {
 		defer p.tracer.Un(p.tracer.Trace(trace.Here()))
@@ -1394,8 +3095,16 @@ func (p *parser) parseExpressionList(end ...token.Type) []ast.Expression {
 	if p.currentIs(end...) {
 		return list
 	}
+	p.argLists++
+	defer func() { p.argLists-- }()
 
-	next := p.parseExpression(precIfUnless)
+	next := p.parseArgument(precIfUnless)
+	if hash, ok := next.(*ast.HashLiteral); ok && hash.Braceless && p.peekIs(token.COMMA) {
+		// the remaining arguments are left to the comma, as they would be
+		// for any other first argument
+		p.nextToken()
+		next = p.parseExpressions(hash)
+	}
 	if elist, ok := next.(ast.ExpressionList); ok {
 		if p.peekIs(end...) {
 			p.accept(end...)
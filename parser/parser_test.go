@@ -84,6 +84,42 @@ func TestAssignmentOperator(t *testing.T) {
 			leftType:      reflect.TypeOf(&ast.Identifier{}),
 			rightOperator: infix.MINUS,
 		},
+		{
+			name:          "**=",
+			input:         `x **= 3`,
+			leftType:      reflect.TypeOf(&ast.Identifier{}),
+			rightOperator: infix.POW,
+		},
+		{
+			name:          "&=",
+			input:         `x[1] &= 3`,
+			leftType:      reflect.TypeOf(&ast.IndexExpression{}),
+			rightOperator: infix.AND,
+		},
+		{
+			name:          "|=",
+			input:         `x.y |= 3`,
+			leftType:      reflect.TypeOf(&ast.ContextCallExpression{}),
+			rightOperator: infix.PIPE,
+		},
+		{
+			name:          "^=",
+			input:         `@x ^= 3`,
+			leftType:      reflect.TypeOf(&ast.Identifier{}),
+			rightOperator: infix.CARET,
+		},
+		{
+			name:          "<<=",
+			input:         `$x <<= 3`,
+			leftType:      reflect.TypeOf(&ast.Identifier{}),
+			rightOperator: infix.LSHIFT,
+		},
+		{
+			name:          ">>=",
+			input:         `x >>= 3`,
+			leftType:      reflect.TypeOf(&ast.Identifier{}),
+			rightOperator: infix.RSHIFT,
+		},
 	}
 
 	for _, tt := range tests {
@@ -112,6 +148,45 @@ func TestAssignmentOperator(t *testing.T) {
 	}
 }
 
+func TestLogicalAssignmentOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		code     string
+		operator infix.Infix
+	}{
+		{`x ||= 3`, "x ||= 3", infix.LOGICALOR},
+		{`x &&= 3`, "x &&= 3", infix.LOGICALAND},
+		{`x[1] ||= 3`, "x[1] ||= 3", infix.LOGICALOR},
+		{`x.y &&= 3`, "x.y &&= 3", infix.LOGICALAND},
+		{`@x ||= 3`, "@x ||= 3", infix.LOGICALOR},
+		{`$x ||= 3`, "$x ||= 3", infix.LOGICALOR},
+		{`x ||= y ||= 3`, "x ||= (y ||= 3)", infix.LOGICALOR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+
+			utils.AssertEqual(t, len(program.Statements), 1)
+			stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+			utils.Assert(t, ok, "program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+			assign, ok := stmt.Expression.(*ast.LogicalAssignment)
+			utils.Assert(t, ok, "expected %T, got %T", assign, stmt.Expression)
+			utils.AssertEqual(t, assign.Operator, tt.operator)
+			utils.AssertEqual(t, stmt.Expression.Code(), tt.code)
+		})
+	}
+
+	t.Run("modifier loop", func(t *testing.T) {
+		program, err := parseSource(`x ||= 1 while x < 5`)
+		checkParserErrors(t, err)
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		utils.Assert(t, ok, "program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		utils.AssertEqual(t, stmt.Expression.Code(), "x ||= 1 while x < 5")
+	})
+}
+
 func TestVariableExpression(t *testing.T) {
 	t.Run("valid variable expressions", func(t *testing.T) {
 		tests := []struct {
@@ -161,6 +236,14 @@ func TestVariableExpression(t *testing.T) {
 				end`,
 				err: fmt.Errorf("dynamic constant assignment"),
 			},
+			{
+				desc: "const logical assignment",
+				input: `
+				def foo
+					Ten ||= 10
+				end`,
+				err: fmt.Errorf("dynamic constant assignment"),
+			},
 		}
 
 		for _, tt := range tests {
@@ -268,6 +351,15 @@ func TestReturnStatements(t *testing.T) {
 		utils.Assert(t, ok, "stmt not *ast.returnStatement. got=%T", stmt)
 		testLiteralExpression(t, returnStmt.ReturnValue, tt.expectedValue)
 	}
+
+	t.Run("modifier", func(t *testing.T) {
+		program, err := parseSource("def foo\n  [1].each { |x| return x unless x }\n  return if bar\nend")
+		checkParserErrors(t, err)
+		utils.AssertEqual(t, len(program.Statements), 1)
+		code := program.Statements[0].Code()
+		utils.Assert(t, strings.Contains(code, "{return x unless x}"), "Expected a return unless in %q", code)
+		utils.Assert(t, strings.Contains(code, ";return if bar"), "Expected a bare return if in %q", code)
+	})
 }
 
 func TestParseComment(t *testing.T) {
@@ -406,6 +498,81 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	utils.AssertEqual(t, literal.Value, 5)
 }
 
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{"0x1F", "31"},
+		{"0XFF_FF", "65535"},
+		{"0b1010", "10"},
+		{"0o17", "15"},
+		{"017", "15"},
+		{"0_17", "15"},
+		{"0d19", "19"},
+		{"0", "0"},
+		{"1_000.5", "1000.500000"},
+		{"1e3", "1000.000000"},
+		{"1.5E-1", "0.150000"},
+		{"2e+2", "200.000000"},
+		{"3r", "3r"},
+		{"1.25r", "1.25r"},
+		{"0x10r", "16r"},
+		{"2i", "2i"},
+		{"1.5i", "1.500000i"},
+		{"3ri", "3ri"},
+		{"1e2i", "100.000000i"},
+		{"-5", "-5"},
+		{"-1.5", "-1.500000"},
+		{"-0x10", "-16"},
+		{"-2.5r", "-2.5r"},
+		{"-2i", "-2i"},
+		{"-2.abs", "-2.abs"},
+		{"-2 ** 2", "-(2 ** 2)"},
+		{"- 2", "-2"},
+		{"a -1", "a - 1"},
+		{"1 - -1", "1 - -1"},
+		{"[1, -2]", "[1, -2]"},
+		{"1if x", "if x; 1 end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	t.Run("folded values", func(t *testing.T) {
+		program, err := parseSource("-0b11")
+		checkParserErrors(t, err)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		testIntegerLiteral(t, stmt.Expression, -3)
+	})
+
+	t.Run("malformed numbers", func(t *testing.T) {
+		tests := []struct {
+			input string
+			err   string
+		}{
+			{"0b102", "1:1: invalid digit '2' in binary literal"},
+			{"x = 08", "1:5: invalid digit '8' in octal literal"},
+			{"1__0", "trailing '_' in number"},
+			{"10_", "trailing '_' in number"},
+			{"0x", "numeric literal without digits"},
+			{"0x_1", "numeric literal without digits"},
+			{"1e3r", "rational suffix after an exponent"},
+		}
+
+		for _, tt := range tests {
+			_, err := parseSource(tt.input)
+			utils.AssertError(t, err, tt.err)
+		}
+	})
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -413,7 +580,7 @@ func TestParsingPrefixExpressions(t *testing.T) {
 		value    interface{}
 	}{
 		{"!5;", "!", 5},
-		{"-15;", "-", 15},
+		{"- 15;", "-", 15},
 		{"!foobar;", "!", "foobar"},
 		{"-foobar;", "-", "foobar"},
 		{"!true;", "!", true},
@@ -455,6 +622,9 @@ func TestParsingInfixExpressions(t *testing.T) {
 			{"5 == 5;", 5, infix.EQ, 5},
 			{"5 != 5;", 5, infix.NOTEQ, 5},
 			{"5 <=> 5;", 5, infix.SPACESHIP, 5},
+			{"5 === 5;", 5, infix.CASEEQ, 5},
+			{"5 << 5;", 5, infix.LSHIFT, 5},
+			{"5 >> 5;", 5, infix.RSHIFT, 5},
 			{"foobar + barfoo;", "foobar", infix.PLUS, "barfoo"},
 			{"foobar - barfoo;", "foobar", infix.MINUS, "barfoo"},
 			{"foobar * barfoo;", "foobar", infix.ASTERISK, "barfoo"},
@@ -463,6 +633,7 @@ func TestParsingInfixExpressions(t *testing.T) {
 			{"foobar < barfoo;", "foobar", infix.LT, "barfoo"},
 			{"foobar == barfoo;", "foobar", infix.EQ, "barfoo"},
 			{"foobar <=> barfoo;", "foobar", infix.SPACESHIP, "barfoo"},
+			{"foobar === barfoo;", "foobar", infix.CASEEQ, "barfoo"},
 			{"foobar != barfoo;", "foobar", infix.NOTEQ, "barfoo"},
 			{"true == true", true, infix.EQ, true},
 			{"true != false", true, infix.NOTEQ, false},
@@ -593,7 +764,7 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		},
 		{
 			"3 + 4; -5 * 5",
-			"3 + 4; (-5) * 5",
+			"3 + 4; -5 * 5",
 		},
 		{
 			"5 > 4 == 3 < 4",
@@ -613,11 +784,27 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		},
 		{
 			"true | true",
-			":true || :true",
+			":true | :true",
 		},
 		{
 			"true & true",
-			":true && :true",
+			":true & :true",
+		},
+		{
+			"a ^ b | c & d",
+			"(a ^ b) | (c & d)",
+		},
+		{
+			"a | b == c ^ d",
+			"(a | b) == (c ^ d)",
+		},
+		{
+			"~a << 2 + b",
+			"(~a) << (2 + b)",
+		},
+		{
+			"-~a",
+			"-(~a)",
 		},
 		{
 			"3 > 5 == false",
@@ -1296,6 +1483,11 @@ func TestFunctionParameterParsing(t *testing.T) {
 			input:          "def fn(*x); end",
 			expectedParams: []funcParam{{name: "x"}},
 		},
+		{
+			desc:           "keyword params with parens",
+			input:          "def fn(x, y: 2, z:, **rest); end",
+			expectedParams: []funcParam{{name: "x"}, {name: "y", defaultValue: 2}, {name: "z"}, {name: "rest"}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1437,6 +1629,41 @@ func TestCallExpressionParsing(t *testing.T) {
 			hasBlock:    true,
 			blockParams: []string{"x"},
 		},
+		{
+			desc:     "with parens and do block",
+			input:    "add(1, 2) do |x, y| x end;",
+			funcName: "add",
+			arguments: []interface{}{
+				1, 2,
+			},
+			hasBlock:    true,
+			blockParams: []string{"x", "y"},
+		},
+		{
+			desc:     "without parens with do block",
+			input:    "add 1, 2 do |x|\n x\nend;",
+			funcName: "add",
+			arguments: []interface{}{
+				1, 2,
+			},
+			hasBlock:    true,
+			blockParams: []string{"x"},
+		},
+		{
+			desc:        "without parens without args with do block",
+			input:       "add do |x, &blk| x end;",
+			funcName:    "add",
+			hasBlock:    true,
+			blockParams: []string{"x", "&blk"},
+		},
+		{
+			desc:        "method call with do block",
+			input:       "foo.add do |x| x end;",
+			context:     "foo",
+			funcName:    "add",
+			hasBlock:    true,
+			blockParams: []string{"x"},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -1469,151 +1696,440 @@ func TestCallExpressionParsing(t *testing.T) {
 			}
 		})
 	}
-	t.Run("with parens and do block", func(t *testing.T) {
-	})
-	t.Run("without parens with block", func(t *testing.T) {
-	})
-	t.Run("without parens", func(t *testing.T) {
-	})
-	t.Run("without parens without args with block", func(t *testing.T) {
-	})
 }
 
-func TestCallExpressionWithoutParens(t *testing.T) {
+func TestBlockBinding(t *testing.T) {
 	tests := []struct {
-		input         string
-		expectedIdent string
+		desc  string
+		input string
+		// name of the call the block is attached to
+		owner string
 	}{
-		{
-			input:         "puts([1][0])",
-			expectedIdent: "puts",
-		},
-		{
-			input:         "puts [1][0]",
-			expectedIdent: "puts",
-		},
+		{"braces bind to the closest call", "foo bar { |x| x }", "bar"},
+		{"do binds to the outermost call", "foo bar do |x| x end", "foo"},
+		{"do binds to the outermost method call", "foo.baz bar do |x| x end", "baz"},
+		{"do within parens binds to the inner call", "foo(bar do |x| x end)", "bar"},
+		{"do in an assignment", "x = bar do |y| y end", "bar"},
+		{"braces in call arguments", "foo 1, bar { |x| x }", "bar"},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
+		t.Run(tt.desc, func(t *testing.T) {
 			program, err := parseSource(tt.input)
 			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
 
-			stmt := program.Statements[0].(*ast.ExpressionStatement)
-			exp, ok := stmt.Expression.(*ast.ContextCallExpression)
-			utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
-			utils.AssertNotEqual(t, stmt.Expression, nil)
-			utils.AssertEqual(t, stmt.Expression.Code(), "puts([1][0])")
-			utils.AssertEqual(t, exp.Function, tt.expectedIdent)
+			var owners []string
+			ast.Inspect(program, func(n ast.Node) {
+				if call, ok := n.(*ast.ContextCallExpression); ok && call.Block != nil {
+					owners = append(owners, call.Function)
+				}
+			})
+			utils.AssertEqual(t, len(owners), 1)
+			utils.AssertEqual(t, owners[0], tt.owner)
 		})
 	}
+
+	t.Run("do of a loop header", func(t *testing.T) {
+		program, err := parseSource("while foo bar do baz end\nfor x in foo bar do baz end")
+		checkParserErrors(t, err)
+		utils.AssertEqual(t, len(program.Statements), 2)
+		utils.AssertEqual(t, program.Statements[0].Code(), "while foo(bar); baz; end")
+		utils.AssertEqual(t, program.Statements[1].Code(), "for x in foo(bar); baz; end")
+	})
 }
-func TestCallExpressionParameterParsing(t *testing.T) {
+
+func TestYield(t *testing.T) {
 	tests := []struct {
-		input         string
-		expectedIdent string
-		expectedArgs  []string
+		input string
+		code  string
 	}{
-		{
-			input:         "add();",
-			expectedIdent: "add",
-			expectedArgs:  []string{},
-		},
-		{
-			input:         "add(1);",
-			expectedIdent: "add",
-			expectedArgs:  []string{"1"},
-		},
-		{
-			input:         "add(1, 2 * 3, 4 + 5);",
-			expectedIdent: "add",
-			expectedArgs:  []string{"1", "2 * 3", "4 + 5"},
-		},
-		{
-			input:         "add 1;",
-			expectedIdent: "add",
-			expectedArgs:  []string{"1"},
-		},
-		{
-			input:         `add "foo";`,
-			expectedIdent: "add",
-			expectedArgs:  []string{"\"foo\""},
-		},
-		{
-			input:         `add :foo;`,
-			expectedIdent: "add",
-			expectedArgs:  []string{":foo"},
-		},
+		{"yield", "yield"},
+		{"yield 1, x", "yield(1, x)"},
+		{"yield(1) + 2", "(yield(1)) + 2"},
+		{"yield [1, 2]", "yield([1, 2])"},
+		{"yield x if y", "if y; yield(x) end"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
 			program, err := parseSource(tt.input)
 			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+}
 
-			stmt := program.Statements[0].(*ast.ExpressionStatement)
-			exp, ok := stmt.Expression.(*ast.ContextCallExpression)
-			utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
-
-			utils.AssertEqual(t, exp.Function, tt.expectedIdent)
-			utils.AssertEqual(t, len(exp.Arguments), len(tt.expectedArgs))
+func TestProcCall(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{"l.(1, 2)", "l.call(1, 2)"},
+		{"l.()", "l.call"},
+		{"puts ->(x) { x }", "puts(-> (x) {x})"},
+		{"puts ->(x) { x }.(2)", "puts((-> (x) {x}).call(2))"},
+		{"f >> g << h", "(f >> g) << h"},
+	}
 
-			for i, arg := range tt.expectedArgs {
-				utils.AssertEqual(t, exp.Arguments[i].Code(), arg)
-			}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
 		})
 	}
 }
 
-func TestContextCallExpression(t *testing.T) {
-	t.Run("context call with multiple args with parens", func(t *testing.T) {
-		input := "foo.add(1, 2 * 3, 4 + 5);"
+func TestKeywordArguments(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{"foo(x, opt: 1)", `foo(x, ":opt" => "1")`},
+		{"foo x, opt: 1", `foo(x, ":opt" => "1")`},
+		{"foo(:opt => 1)", `foo(":opt" => "1")`},
+		{"foo(\"opt\": 1)", `foo(":opt" => "1")`},
+		{"foo(**opts)", "foo(**opts)"},
+		{"obj.foo 1, opt: 2", `obj.foo(1, ":opt" => "2")`},
+		{"foo(opt: 1, &blk)", `foo(":opt" => "1", &blk)`},
+		{"foo({opt: 1})", `foo({":opt" => "1"})`},
+		{"foo(c ? a : b, opt: 1)", `foo(if c; aelse b end, ":opt" => "1")`},
+		{"-> (x, k:) { x }", "-> (x, k:) {x}"},
+	}
 
-		program, err := parseSource(input)
-		checkParserErrors(t, err)
-		utils.AssertEqual(t, len(program.Statements), 1)
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
 
-		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	t.Run("keyword parameters", func(t *testing.T) {
+		program, err := parseSource("def foo(a, b: 2, c:, **rest, &blk); end")
+		checkParserErrors(t, err)
 
-		exp, ok := stmt.Expression.(*ast.ContextCallExpression)
-		utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
-		testIdentifier(t, exp.Context, "foo")
-		utils.AssertEqual(t, exp.Function, "add")
-		utils.AssertEqual(t, len(exp.Arguments), 3)
-		testLiteralExpression(t, exp.Arguments[0], 1)
-		testInfixExpression(t, exp.Arguments[1], 2, infix.ASTERISK, 3)
-		testInfixExpression(t, exp.Arguments[2], 4, infix.PLUS, 5)
+		function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+		params := make([]string, len(function.Parameters))
+		for i, param := range function.Parameters {
+			params[i] = param.Code()
+		}
+		utils.AssertEqualCmp(t, params, []string{"a", "b: 2", "c:", "**rest", "&blk"}, utils.CompareArrays)
+		utils.Assert(t, function.Parameters[2].Keyword && function.Parameters[2].Default == nil, "Expected a required keyword")
+		utils.Assert(t, function.Parameters[3].DoubleSplat, "Expected a double splat")
 	})
-	t.Run("context call with multiple args with parens and block", func(t *testing.T) {
-		input := "foo.add(1, 2 * 3, 4 + 5) { |x|x.to_s };"
-
-		program, err := parseSource(input)
+	t.Run("keywords are merged into a single hash", func(t *testing.T) {
+		program, err := parseSource("foo(x, a: 1, :b => 2, **opts)")
 		checkParserErrors(t, err)
-		utils.AssertEqual(t, len(program.Statements), 1)
-
-		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
 
-		exp, ok := stmt.Expression.(*ast.ContextCallExpression)
-		utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
-		testIdentifier(t, exp.Context, "foo")
-		utils.AssertEqual(t, exp.Function, "add")
-		utils.AssertEqual(t, len(exp.Arguments), 3)
-		testLiteralExpression(t, exp.Arguments[0], 1)
-		testInfixExpression(t, exp.Arguments[1], 2, infix.ASTERISK, 3)
-		testInfixExpression(t, exp.Arguments[2], 4, infix.PLUS, 5)
-		utils.AssertNotEqual(t, exp.Block, nil)
+		call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ContextCallExpression)
+		utils.AssertEqual(t, len(call.Arguments), 2)
+		hash := utils.AssertType[*ast.HashLiteral](t, call.Arguments[1], "argument not *ast.HashLiteral. got=%T", call.Arguments[1])
+		utils.Assert(t, hash.Braceless, "Expected a hash without braces")
+		utils.AssertEqual(t, len(hash.Map), 2)
+		utils.AssertEqual(t, len(hash.Splats), 1)
 	})
-	t.Run("context call with multiple args no parens", func(t *testing.T) {
-		input := "foo.add 1, 2 * 3, 4 + 5;"
-
-		program, err := parseSource(input)
+	t.Run("rescue with several classes within call arguments", func(t *testing.T) {
+		program, err := parseSource("foo(begin; bar; rescue A, B => e; baz; end)")
 		checkParserErrors(t, err)
-		utils.AssertEqual(t, len(program.Statements), 1)
+		utils.AssertEqual(t, program.Statements[0].Code(), "foo(begin; bar; rescue A, B => e; baz; end)")
+	})
+	t.Run("positional parameter after keyword parameters", func(t *testing.T) {
+		_, err := parseSource("def foo(a: 1, b); end")
+		utils.AssertNotEqual(t, err, nil)
+	})
+}
 
-		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{"m { |(k, v), i| k }", "m -> ((k, v), i) {k}"},
+		{"m { |a, (b, (c, *d))| a }", "m -> (a, (b, (c, *d))) {a}"},
+		{"->((a, b), c) { a }", "-> ((a, b), c) {a}"},
+		{"a, (b, c), *d = 1, [2, 3]", "(a, (b, c), (*d)) = (1, ([2, 3]))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{`"plain"`, `"plain"`},
+		{`"a #{b} c"`, `"a #{b} c"`},
+		{`"#{a; b}"`, `"#{a;b}"`},
+		{`"#{"#{a}"}"`, `"#{"#{a}"}"`},
+		{`puts "a#{b}"`, `puts("a#{b}")`},
+		{`:"foo bar"`, `:foo bar`},
+		{`:"a#{b}"`, `:"a#{b}"`},
+		{"`ls #{dir}`", "`(\"ls #{dir}\")"},
+		{`"a\"b\n\e\x7F#{c}\t"`, `"a\"b\n\e\x7F#{c}\t"`},
+		{`"\#{a} #b \M-a"`, `"\#{a} #b \xE1"`},
+		{`'a\n#{b}'`, `"a\\n\#{b}"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	t.Run("parts", func(t *testing.T) {
+		program, err := parseSource(`"a #{b} c #{d}"`)
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		str := utils.AssertType[*ast.InterpolatedString](t, stmt.Expression, "stmt.Expression is not *ast.InterpolatedString. got=%T", stmt.Expression)
+		utils.AssertEqual(t, len(str.Literals), 3)
+		utils.AssertEqual(t, len(str.Embedded), 2)
+		utils.AssertEqual(t, str.Literals[1].Value, " c ")
+		utils.AssertEqual(t, str.Embedded[1].Code(), "d")
+	})
+}
+
+func TestPercentLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{`%w[a b c]`, `["a", "b", "c"]`},
+		{`%w()`, `[]`},
+		{`%i{a b}`, `[:a, :b]`},
+		{`puts %w[a b], 1`, `puts(["a", "b"], 1)`},
+		{`%w[a b].size`, `(["a", "b"]).size`},
+		{`%q(a #{b})`, `"a \#{b}"`},
+		{`%Q{a #{b}}`, `"a #{b}"`},
+		{`%(a)`, `"a"`},
+		{`%s(a b)`, `:a b`},
+		{`%x(ls #{dir})`, "`(\"ls #{dir}\")"},
+		{`%r[a]i`, `/a/i`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+}
+
+func TestHeredoc(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{"x = <<EOS\na\nEOS\n", `x = "a\n"`},
+		{"x = <<~EOS\n  a #{b}\n    c\nEOS\n", `x = ("a #{b}\n  c\n")`},
+		{"puts(<<-A, <<~B)\na\n  A\n  b\nB\n", `puts("a\n", "b\n")`},
+		{"<<~EOS.lines\n  a\nEOS\n", `"a\n".lines`},
+		{"<<`EOS`\nls\nEOS\n", "`(\"ls\\n\")"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+}
+
+func TestBlockPass(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{"arr.map(&:to_s)", "arr.map(&:to_s)"},
+		{"list.each &handler", "list.each(&handler)"},
+		{"foo(1, &blk)", "foo(1, &blk)"},
+		{"foo(&method(:bar))", "foo(&method(:bar))"},
+		{"a & b", "a & b"},
+		{"foo(a&b)", "foo(a & b)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	errorTests := []struct {
+		input string
+		err   string
+	}{
+		{"foo(&blk) { }", "both block arg and actual block given"},
+		{"foo(&blk, 1)", "block argument should not be given"},
+		{"x = &blk", "block argument should not be given"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseSource(tt.input)
+			utils.AssertNotEqual(t, err, nil)
+			utils.Assert(t, strings.Contains(err.Error(), tt.err), "Expected error %q, got %q", tt.err, err.Error())
+		})
+	}
+}
+
+func TestCallExpressionWithoutParens(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedIdent string
+	}{
+		{
+			input:         "puts([1][0])",
+			expectedIdent: "puts",
+		},
+		{
+			input:         "puts [1][0]",
+			expectedIdent: "puts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			exp, ok := stmt.Expression.(*ast.ContextCallExpression)
+			utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
+			utils.AssertNotEqual(t, stmt.Expression, nil)
+			utils.AssertEqual(t, stmt.Expression.Code(), "puts([1][0])")
+			utils.AssertEqual(t, exp.Function, tt.expectedIdent)
+		})
+	}
+}
+func TestCallExpressionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedIdent string
+		expectedArgs  []string
+	}{
+		{
+			input:         "add();",
+			expectedIdent: "add",
+			expectedArgs:  []string{},
+		},
+		{
+			input:         "add(1);",
+			expectedIdent: "add",
+			expectedArgs:  []string{"1"},
+		},
+		{
+			input:         "add(1, 2 * 3, 4 + 5);",
+			expectedIdent: "add",
+			expectedArgs:  []string{"1", "2 * 3", "4 + 5"},
+		},
+		{
+			input:         "add 1;",
+			expectedIdent: "add",
+			expectedArgs:  []string{"1"},
+		},
+		{
+			input:         `add "foo";`,
+			expectedIdent: "add",
+			expectedArgs:  []string{"\"foo\""},
+		},
+		{
+			input:         `add :foo;`,
+			expectedIdent: "add",
+			expectedArgs:  []string{":foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			exp, ok := stmt.Expression.(*ast.ContextCallExpression)
+			utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
+
+			utils.AssertEqual(t, exp.Function, tt.expectedIdent)
+			utils.AssertEqual(t, len(exp.Arguments), len(tt.expectedArgs))
+
+			for i, arg := range tt.expectedArgs {
+				utils.AssertEqual(t, exp.Arguments[i].Code(), arg)
+			}
+		})
+	}
+}
+
+func TestContextCallExpression(t *testing.T) {
+	t.Run("context call with multiple args with parens", func(t *testing.T) {
+		input := "foo.add(1, 2 * 3, 4 + 5);"
+
+		program, err := parseSource(input)
+		checkParserErrors(t, err)
+		utils.AssertEqual(t, len(program.Statements), 1)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+
+		exp, ok := stmt.Expression.(*ast.ContextCallExpression)
+		utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
+		testIdentifier(t, exp.Context, "foo")
+		utils.AssertEqual(t, exp.Function, "add")
+		utils.AssertEqual(t, len(exp.Arguments), 3)
+		testLiteralExpression(t, exp.Arguments[0], 1)
+		testInfixExpression(t, exp.Arguments[1], 2, infix.ASTERISK, 3)
+		testInfixExpression(t, exp.Arguments[2], 4, infix.PLUS, 5)
+	})
+	t.Run("context call with multiple args with parens and block", func(t *testing.T) {
+		input := "foo.add(1, 2 * 3, 4 + 5) { |x|x.to_s };"
+
+		program, err := parseSource(input)
+		checkParserErrors(t, err)
+		utils.AssertEqual(t, len(program.Statements), 1)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+
+		exp, ok := stmt.Expression.(*ast.ContextCallExpression)
+		utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
+		testIdentifier(t, exp.Context, "foo")
+		utils.AssertEqual(t, exp.Function, "add")
+		utils.AssertEqual(t, len(exp.Arguments), 3)
+		testLiteralExpression(t, exp.Arguments[0], 1)
+		testInfixExpression(t, exp.Arguments[1], 2, infix.ASTERISK, 3)
+		testInfixExpression(t, exp.Arguments[2], 4, infix.PLUS, 5)
+		utils.AssertNotEqual(t, exp.Block, nil)
+	})
+	t.Run("context call with multiple args no parens", func(t *testing.T) {
+		input := "foo.add 1, 2 * 3, 4 + 5;"
+
+		program, err := parseSource(input)
+		checkParserErrors(t, err)
+		utils.AssertEqual(t, len(program.Statements), 1)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
 
 		exp, ok := stmt.Expression.(*ast.ContextCallExpression)
 		utils.Assert(t, ok, "stmt.Expression is not ast.ContextCallExpression. got=%T", stmt.Expression)
@@ -2018,6 +2534,14 @@ func TestParseHash(t *testing.T) {
 			}`,
 			hashMap: map[string]string{"\"foo\"": "42", "\"bar\"": "\"baz\""},
 		},
+		{
+			input:   `{foo: 42, "bar": "baz"}`,
+			hashMap: map[string]string{":foo": "42", ":bar": "\"baz\""},
+		},
+		{
+			input:   `{**opts, foo: 42}`,
+			hashMap: map[string]string{":foo": "42"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2061,31 +2585,608 @@ func TestRangeLiteral(t *testing.T) {
 	}
 }
 
-func TestRegexLiteral(t *testing.T) {
+func TestBeginExpression(t *testing.T) {
 	tests := []struct {
-		input     string
-		expected  string
-		modifiers string
+		name  string
+		input string
+		code  string
 	}{
 		{
-			input:    "/foo/",
-			expected: "foo",
+			"rescue all",
+			`
+			begin
+				foo
+			rescue
+				bar
+			end`,
+			"begin; foo; rescue; bar; end",
+		},
+		{
+			"rescue classes with variable",
+			`
+			begin
+				foo
+			rescue ArgumentError, TypeError => e
+				bar
+			rescue => e
+				retry
+			else
+				baz
+			ensure
+				qux
+			end`,
+			"begin; foo; rescue ArgumentError, TypeError => e; bar; rescue => e; retry; else; baz; ensure; qux; end",
+		},
+		{
+			"ensure only",
+			`
+			begin
+				foo
+			ensure
+				qux
+			end`,
+			"begin; foo; ensure; qux; end",
+		},
+		{
+			"rescue modifier",
+			`foo rescue bar`,
+			"begin; foo; rescue; bar; end",
+		},
+		{
+			"rescue modifier in assignment",
+			`x = foo rescue bar`,
+			"x = (begin; foo; rescue; bar; end)",
+		},
+		{
+			"implicit begin in method body",
+			`
+			def foo
+				bar
+			rescue
+				baz
+			end`,
+			"def foo()\n    begin; bar; rescue; baz; end\nend",
 		},
 	}
 
 	for _, tt := range tests {
-		program, err := parseSource(tt.input)
-		checkParserErrors(t, err)
-
-		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", stmt)
-
-		regexLit, ok := stmt.Expression.(*ast.StringLiteral)
-		utils.Assert(t, ok, "stmt.Expression is not ast.RegexLiteral. got=%T", stmt.Expression)
-		utils.AssertEqual(t, regexLit.Value, tt.expected)
-
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
 	}
-}
+
+	t.Run("retry outside of rescue", func(t *testing.T) {
+		_, err := parseSource("retry")
+		utils.AssertNotEqual(t, err, nil)
+	})
+}
+
+func TestClassExpression(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+	}{
+		{
+			"empty class",
+			`
+			class Foo
+			end`,
+			"class Foo\n    \nend",
+		},
+		{
+			"superclass and instance variables",
+			`
+			class Foo < Bar
+				attr_reader :name
+				def initialize(name)
+					@name = name
+				end
+			end`,
+			"class Foo < Bar\n    attr_reader(:name);def initialize(name)\n        @name = name\n    end\nend",
+		},
+		{
+			"class method and super",
+			`
+			class Foo < Bar
+				def self.create
+					super
+				end
+				def to_s
+					super(1, 2)
+				end
+			end`,
+			"class Foo < Bar\n    def self.create()\n        super\n    end;def to_s()\n        super(1, 2)\n    end\nend",
+		},
+		{
+			"setter method",
+			`
+			def name=(value)
+				@name = value
+			end`,
+			"def name=(value)\n    @name = value\nend",
+		},
+		{
+			"attribute assignment",
+			`self.name = 5`,
+			"self.name = 5",
+		},
+		{
+			"keyword as method name",
+			`foo.class`,
+			"foo.class",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	t.Run("lowercase class name", func(t *testing.T) {
+		_, err := parseSource("class foo\nend")
+		utils.AssertNotEqual(t, err, nil)
+	})
+}
+
+func TestModuleExpression(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+	}{
+		{
+			"module with nested class",
+			`
+			module Foo
+				BAR = 1
+				class Baz
+				end
+			end`,
+			"module Foo\n    BAR = 1;class Baz\n        \n    end\nend",
+		},
+		{
+			"include",
+			`
+			class Foo
+				include Bar
+			end`,
+			"class Foo\n    include(Bar)\nend",
+		},
+		{
+			"extend self",
+			`
+			module Foo
+				extend self
+			end`,
+			"module Foo\n    extend(self)\nend",
+		},
+		{
+			"scoped constant",
+			`Foo::Bar::BAZ`,
+			"Foo::Bar::BAZ",
+		},
+		{
+			"scoped constant method call",
+			`Foo::Bar.new(1)`,
+			"Foo::Bar.new(1)",
+		},
+		{
+			"top level constant",
+			`::Foo`,
+			"::Foo",
+		},
+		{
+			"method call with scope operator",
+			`Foo::bar`,
+			"Foo.bar",
+		},
+		{
+			"scoped constant as argument",
+			`puts Foo::Bar`,
+			"puts(Foo::Bar)",
+		},
+		{
+			"top level constant as argument",
+			`puts ::Foo::Bar`,
+			"puts(::Foo::Bar)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	t.Run("lowercase module name", func(t *testing.T) {
+		_, err := parseSource("module foo\nend")
+		utils.AssertNotEqual(t, err, nil)
+	})
+}
+
+func TestCaseExpression(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+	}{
+		{
+			"when with then",
+			`
+			case x
+			when 1, 2 then foo
+			when String then bar
+			end`,
+			"case x; when 1, 2; foo; when String; bar; end",
+		},
+		{
+			"when on separate lines with else",
+			`
+			case x
+			when 1..5
+				foo
+			else
+				bar
+			end`,
+			"case x; when 1 .. 5; foo; else; bar; end",
+		},
+		{
+			"without subject",
+			`
+			case
+			when x > 1 then foo
+			end`,
+			"case; when x > 1; foo; end",
+		},
+		{
+			"single line",
+			`case x when Foo.new then 1 else 2 end`,
+			"case x; when Foo.new; 1; else; 2; end",
+		},
+		{
+			"in assignment",
+			`y = case x; when 1; foo; end`,
+			"y = (case x; when 1; foo; end)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	t.Run("case without when", func(t *testing.T) {
+		_, err := parseSource("case x\nelse\n  foo\nend")
+		utils.AssertNotEqual(t, err, nil)
+	})
+}
+
+func TestLoopExpression(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+	}{
+		{
+			"while",
+			`
+			while x < 10
+				x += 1
+			end`,
+			"while x < 10; x = (x + 1); end",
+		},
+		{
+			"until with comment",
+			`
+			until x > 10 # count
+				foo
+			end`,
+			"until x > 10; foo; end",
+		},
+		{
+			"modifier while",
+			`foo while x`,
+			"foo while x",
+		},
+		{
+			"modifier until around an assignment",
+			`x += 1 until x == 5`,
+			"x = (x + 1) until x == 5",
+		},
+		{
+			"begin end while",
+			`
+			begin
+				foo
+			end while x`,
+			"begin; foo; end while x",
+		},
+		{
+			"for",
+			`
+			for x in [1, 2]
+				next if x == 1
+				break x * 2
+			end`,
+			"for x in [1, 2]; next if x == 1;break x * 2; end",
+		},
+		{
+			"for with multiple variables",
+			`for a, b in pairs; foo a; end`,
+			"for a, b in pairs; foo(a); end",
+		},
+		{
+			"loop with break",
+			`loop { break }`,
+			"loop {break}",
+		},
+		{
+			"break with value unless",
+			`loop { break :done unless x }`,
+			"loop {break :done unless x}",
+		},
+		{
+			"while in assignment",
+			`y = while x; break 1; end`,
+			"y = (while x; break 1; end)",
+		},
+		{
+			"redo if",
+			`while x; redo if y; end`,
+			"while x; redo if y; end",
+		},
+		{
+			"break in begin end while",
+			`begin; break if y; end while x`,
+			"begin; break if y; end while x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	t.Run("modifier forms", func(t *testing.T) {
+		program, err := parseSource("begin\n  foo\nend until x\nbar while x")
+		checkParserErrors(t, err)
+		utils.AssertEqual(t, len(program.Statements), 2)
+
+		loop, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.LoopExpression)
+		utils.Assert(t, ok, "Expected *ast.LoopExpression, got %T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+		utils.Assert(t, loop.Until && loop.Modifier && loop.DoWhile, "Expected begin/end until loop, got %#v", loop)
+
+		loop, ok = program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.LoopExpression)
+		utils.Assert(t, ok, "Expected *ast.LoopExpression, got %T", program.Statements[1].(*ast.ExpressionStatement).Expression)
+		utils.Assert(t, !loop.Until && loop.Modifier && !loop.DoWhile, "Expected while modifier, got %#v", loop)
+	})
+	t.Run("for without in", func(t *testing.T) {
+		_, err := parseSource("for x [1, 2]\nend")
+		utils.AssertNotEqual(t, err, nil)
+	})
+	t.Run("while without end", func(t *testing.T) {
+		_, err := parseSource("while x\n  foo\n")
+		utils.AssertNotEqual(t, err, nil)
+	})
+	t.Run("jump outside of a loop or block", func(t *testing.T) {
+		tests := []struct {
+			input string
+			err   string
+		}{
+			{"break", "Invalid break"},
+			{"foo\nnext 1", "Invalid next"},
+			{"def foo\n  redo\nend", "Invalid redo"},
+			{"while x\n  def foo\n    break\n  end\nend", "Invalid break"},
+		}
+		for _, tt := range tests {
+			_, err := parseSource(tt.input)
+			utils.AssertNotEqual(t, err, nil)
+			utils.Assert(t, strings.Contains(err.Error(), tt.err), "Expected error %q, got %q", tt.err, err.Error())
+		}
+	})
+}
+
+func TestPatternMatching(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+	}{
+		{
+			"values and alternatives",
+			`
+			case x
+			in 1 | :a then foo
+			in 1..5
+				bar
+			in nil
+			else
+				baz
+			end`,
+			"case x; in 1 | :a; foo; in 1 .. 5; bar; in :nil; ; else; baz; end",
+		},
+		{
+			"array patterns",
+			`
+			case x
+			in [Integer => n, *rest] then foo
+			in first, *, last then bar
+			in [*, 1, y, *post] then baz
+			end`,
+			"case x; in [Integer => n, *rest]; foo; in [first, *, last]; bar; in [*, 1, y, *post]; baz; end",
+		},
+		{
+			"hash patterns",
+			`
+			case x
+			in {name: String => name, age:, **rest} then foo
+			in name:, **nil then bar
+			in {} then baz
+			end`,
+			"case x; in {name: String => name, age:, **rest}; foo; in {name:, **nil}; bar; in {}; baz; end",
+		},
+		{
+			"constant patterns",
+			`
+			case x
+			in Point(x:, y: 0) then foo
+			in Foo::Bar[a, b] then bar
+			end`,
+			"case x; in Point(x:, y: 0); foo; in Foo::Bar(a, b); bar; end",
+		},
+		{
+			"guards and pins",
+			`
+			case x
+			in ^y if y > 1 then foo
+			in ^(y + 1) unless z then bar
+			end`,
+			"case x; in ^y if y > 1; foo; in ^(y + 1) unless z; bar; end",
+		},
+		{
+			"multiline array and hash patterns",
+			`
+			case x
+			in [
+				a,
+				b
+			] then foo
+			in {
+				a:
+			} then bar
+			end`,
+			"case x; in [a, b]; foo; in {a:}; bar; end",
+		},
+		{
+			"in",
+			`y = x in [Integer, *]`,
+			"y = (x in [Integer, *])",
+		},
+		{
+			"rightward assignment",
+			`x => {a: [b, *]}`,
+			"x => {a: [b, *]}",
+		},
+		{
+			"rightward assignment of method call",
+			`foo.bar => [a, b]`,
+			"foo.bar => [a, b]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseSource(tt.input)
+			checkParserErrors(t, err)
+			utils.AssertEqual(t, len(program.Statements), 1)
+			utils.AssertEqual(t, program.Statements[0].Code(), tt.code)
+		})
+	}
+
+	errorTests := []struct {
+		name  string
+		input string
+	}{
+		{"two splats", "case x\nin [*a, b, *c, d]\nend"},
+		{"value in hash pattern", "case x\nin {1}\nend"},
+		{"pin without variable", "case x\nin ^1\nend"},
+		{"in without subject", "case\nin 1\nend"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSource(tt.input)
+			utils.AssertNotEqual(t, err, nil)
+		})
+	}
+}
+
+func TestRegexLiteral(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		modifiers string
+	}{
+		{
+			input:    "/foo/",
+			expected: "/foo/",
+		},
+		{
+			input:     "/fo+o/im",
+			expected:  "/fo+o/im",
+			modifiers: "im",
+		},
+		{
+			input:    "/a#{b}c/",
+			expected: "/a#{b}c/",
+		},
+		{
+			input:     "%r{a/b}x",
+			expected:  "/a/b/x",
+			modifiers: "x",
+		},
+		{
+			input:    "x = /foo/",
+			expected: "/foo/",
+		},
+	}
+
+	for _, tt := range tests {
+		program, err := parseSource(tt.input)
+		checkParserErrors(t, err)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		utils.Assert(t, ok, "stmt is not ast.ExpressionStatement. got=%T", stmt)
+
+		expression := stmt.Expression
+		if assignment, ok := expression.(*ast.Assignment); ok {
+			expression = assignment.Right
+		}
+		regexLit, ok := expression.(*ast.RegexLiteral)
+		utils.Assert(t, ok, "stmt.Expression is not ast.RegexLiteral. got=%T", expression)
+		utils.AssertEqual(t, regexLit.Code(), tt.expected)
+		utils.AssertEqual(t, regexLit.Options, tt.modifiers)
+	}
+}
+
+func TestMatchOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x =~ /a/", "x =~ (/a/)"},
+		{"x !~ /a/", "x !~ (/a/)"},
+		{"/a/ =~ x", "(/a/) =~ x"},
+		{"a / b / c", "(a / b) / c"},
+		{"x.scan /a/", "x.scan(/a/)"},
+	}
+
+	for _, tt := range tests {
+		program, err := parseSource(tt.input)
+		checkParserErrors(t, err)
+
+		utils.AssertEqual(t, program.Code(), tt.expected)
+	}
+}
 
 func TestArraySplat(t *testing.T) {
 	type Expected struct {
@@ -2160,6 +3261,55 @@ func TestArraySplat(t *testing.T) {
 	}
 }
 
+func TestNodePositions(t *testing.T) {
+	input := "x = 5\ndef foo(a, b = 2)\n  a + bar(b)\nend\nfoo 1, 2 { |y| y }\n"
+
+	fset := gotoken.NewFileSet()
+	program, err := p.ParseFile(fset, "test.rb", input)
+	checkParserErrors(t, err)
+
+	position := func(pos gotoken.Pos) string {
+		return fset.Position(pos).String()
+	}
+
+	t.Run("every node has a position", func(t *testing.T) {
+		ast.Inspect(program, func(n ast.Node) {
+			if n == nil {
+				return
+			}
+			utils.Assert(t, n.Pos().IsValid(), "expected %T (%q) to have a position", n, n.Code())
+			utils.Assert(t, n.End() >= n.Pos(), "expected %T (%q) to end after its start", n, n.Code())
+		})
+	})
+
+	t.Run("positions resolve to lines and columns", func(t *testing.T) {
+		tests := []struct {
+			node  ast.Node
+			start string
+			end   string
+		}{
+			{program.Statements[0], "test.rb:1:1", "test.rb:1:6"},
+			{program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.Assignment).Right, "test.rb:1:5", "test.rb:1:6"},
+			{program.Statements[1].(*ast.ExpressionStatement).Expression, "test.rb:2:1", "test.rb:4:4"},
+		}
+		for _, tt := range tests {
+			utils.AssertEqual(t, position(tt.node.Pos()), tt.start)
+			utils.AssertEqual(t, position(tt.node.End()), tt.end)
+		}
+
+		fn := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+		utils.AssertEqual(t, position(fn.Parameters[1].Pos()), "test.rb:2:12")
+		body := fn.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+		utils.AssertEqual(t, position(body.Pos()), "test.rb:3:3")
+		utils.AssertEqual(t, position(body.Right.Pos()), "test.rb:3:7")
+
+		call := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.ContextCallExpression)
+		utils.AssertEqual(t, position(call.Pos()), "test.rb:5:1")
+		utils.AssertEqual(t, position(call.Arguments[1].Pos()), "test.rb:5:8")
+		utils.AssertEqual(t, position(call.Block.Pos()), "test.rb:5:10")
+	})
+}
+
 func TestParsePyraRb(t *testing.T) {
 	// t.Skip("Not implemented yet")
 	filename := "../pyra.rb"
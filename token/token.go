@@ -16,23 +16,34 @@ const (
 	IDENT
 	INT
 	FLOAT
+	RATIONAL  // 3r or 1.5r
+	IMAGINARY // 2i, 1.5i or 3ri
 	STRING
 	literal_end
 
 	// Operators
 	operator_beg
 	operator_assign_beg
-	ASSIGN    // =
-	ADDASSIGN // +=
-	SUBASSIGN // -=
-	MULASSIGN // *=
-	DIVASSIGN // /=
-	MODASSIGN // %=
+	ASSIGN           // =
+	ADDASSIGN        // +=
+	SUBASSIGN        // -=
+	MULASSIGN        // *=
+	DIVASSIGN        // /=
+	MODASSIGN        // %=
+	POWASSIGN        // **=
+	ANDASSIGN        // &=
+	ORASSIGN         // |=
+	XORASSIGN        // ^=
+	LSHIFTASSIGN     // <<=
+	RSHIFTASSIGN     // >>=
+	LOGICALANDASSIGN // &&=
+	LOGICALORASSIGN  // ||=
 	operator_assign_end
 
 	PLUS       // +
 	MINUS      // -
 	BANG       // !
+	TILDE      // ~
 	ASTERISK   // *
 	POW        // **
 	SLASH      // /
@@ -41,15 +52,20 @@ const (
 	LOGICALAND // &&
 	PIPE       // |
 	LOGICALOR  // ||
+	CARET      // ^
 
 	LT        // <
 	LTE       // <=
 	GT        // >
 	GTE       // >=
 	EQ        // ==
+	CASEEQ    // ===
 	NOTEQ     // !=
 	SPACESHIP // <=>
 	LSHIFT    // <<
+	RSHIFT    // >>
+	MATCH     // =~
+	NOTMATCH  // !~
 	operator_end
 
 	HASHROCKET   // =>
@@ -66,6 +82,8 @@ const (
 	DDOT      // ..
 	DDDOT     // ...
 	COLON     // :
+	SCOPE     // ::
+	SSCOPE    // _::
 	LPAREN    // (
 	RPAREN    // )
 	LBRACE    // {
@@ -73,9 +91,16 @@ const (
 	LBRACKET  // [
 	SLBRACKET // _[
 	RBRACKET  // ]
+	SAND      // _&
 
-	QMARK  // ?
-	SYMBOL // : ...
+	QMARK         // ?
+	SYMBOL        // : ...
+	INTERPOLATION // #{ within a string
+	BACKTICK      // `
+	REGEX         // / or %r{ opening a regex literal
+	REGEXEND      // / or } closing a regex literal, with its options
+	WORDS         // %w[ opening an array of strings
+	SYMBOLS       // %i[ opening an array of symbols
 
 	// Keywords
 	keyword_beg
@@ -90,9 +115,26 @@ const (
 	FALSE
 	RETURN
 	NIL
-	// WHILE
+	WHILE
+	UNTIL
+	FOR
 	LOOP
 	BREAK
+	NEXT
+	REDO
+	BEGIN
+	RESCUE
+	ENSURE
+	RETRY
+	CLASS
+	SELF
+	SUPER
+	MODULE
+	CASE
+	WHEN
+	IN
+	DO
+	YIELD
 	keyword_end
 	types_end
 )
@@ -133,21 +175,32 @@ var type_strings = [...]string{
 	ILLEGAL: "ILLEGAL",
 	EOF:     "EOF",
 
-	IDENT:  "IDENT",
-	INT:    "INT",
-	FLOAT:  "FLOAT",
-	STRING: "STRING",
-
-	ASSIGN:    "ASSIGN",
-	ADDASSIGN: "ADDASSIGN",
-	SUBASSIGN: "SUBASSIGN",
-	MULASSIGN: "MULASSIGN",
-	DIVASSIGN: "DIVASSIGN",
-	MODASSIGN: "MODASSIGN",
+	IDENT:     "IDENT",
+	INT:       "INT",
+	FLOAT:     "FLOAT",
+	RATIONAL:  "RATIONAL",
+	IMAGINARY: "IMAGINARY",
+	STRING:    "STRING",
+
+	ASSIGN:           "ASSIGN",
+	ADDASSIGN:        "ADDASSIGN",
+	SUBASSIGN:        "SUBASSIGN",
+	MULASSIGN:        "MULASSIGN",
+	DIVASSIGN:        "DIVASSIGN",
+	MODASSIGN:        "MODASSIGN",
+	POWASSIGN:        "POWASSIGN",
+	ANDASSIGN:        "ANDASSIGN",
+	ORASSIGN:         "ORASSIGN",
+	XORASSIGN:        "XORASSIGN",
+	LSHIFTASSIGN:     "LSHIFTASSIGN",
+	RSHIFTASSIGN:     "RSHIFTASSIGN",
+	LOGICALANDASSIGN: "LOGICALANDASSIGN",
+	LOGICALORASSIGN:  "LOGICALORASSIGN",
 
 	PLUS:       "PLUS",
 	MINUS:      "MINUS",
 	BANG:       "BANG",
+	TILDE:      "TILDE",
 	ASTERISK:   "ASTERISK",
 	POW:        "POW",
 	SLASH:      "SLASH",
@@ -155,15 +208,20 @@ var type_strings = [...]string{
 	AND:        "AND",
 	LOGICALAND: "LOGICALAND",
 	LOGICALOR:  "LOGICALOR",
+	CARET:      "CARET",
 
 	LT:        "LT",
 	LTE:       "LTE",
 	GT:        "GT",
 	GTE:       "GTE",
 	EQ:        "EQ",
+	CASEEQ:    "CASEEQ",
 	NOTEQ:     "NOTEQ",
 	SPACESHIP: "SPACESHIP",
 	LSHIFT:    "LSHIFT",
+	RSHIFT:    "RSHIFT",
+	MATCH:     "MATCH",
+	NOTMATCH:  "NOTMATCH",
 
 	NEWLINE:   "NEWLINE",
 	COMMA:     "COMMA",
@@ -174,6 +232,8 @@ var type_strings = [...]string{
 	DDOT:      "DDOT",
 	DDDOT:     "DDDOT",
 	COLON:     "COLON",
+	SCOPE:     "SCOPE",
+	SSCOPE:    "SSCOPE",
 	LPAREN:    "LPAREN",
 	RPAREN:    "RPAREN",
 	LBRACE:    "LBRACE",
@@ -181,13 +241,20 @@ var type_strings = [...]string{
 	LBRACKET:  "LBRACKET",
 	SLBRACKET: "SLBRACKET",
 	RBRACKET:  "RBRACKET",
+	SAND:      "SAND",
 	PIPE:      "PIPE",
 
 	HASHROCKET:   "HASHROCKET",
 	LAMBDAROCKET: "LAMBDAROCKET",
 
-	QMARK:  "QMARK",
-	SYMBOL: "SYMBOL",
+	QMARK:         "QMARK",
+	SYMBOL:        "SYMBOL",
+	INTERPOLATION: "INTERPOLATION",
+	BACKTICK:      "BACKTICK",
+	REGEX:         "REGEX",
+	REGEXEND:      "REGEXEND",
+	WORDS:         "WORDS",
+	SYMBOLS:       "SYMBOLS",
 
 	DEF:    "DEF",
 	END:    "END",
@@ -200,29 +267,58 @@ var type_strings = [...]string{
 	FALSE:  "FALSE",
 	RETURN: "RETURN",
 	NIL:    "NIL",
+	WHILE:  "WHILE",
+	UNTIL:  "UNTIL",
+	FOR:    "FOR",
 	LOOP:   "LOOP",
 	BREAK:  "BREAK",
+	NEXT:   "NEXT",
+	REDO:   "REDO",
+	BEGIN:  "BEGIN",
+	RESCUE: "RESCUE",
+	ENSURE: "ENSURE",
+	RETRY:  "RETRY",
+	CLASS:  "CLASS",
+	SELF:   "SELF",
+	SUPER:  "SUPER",
+	MODULE: "MODULE",
+	CASE:   "CASE",
+	WHEN:   "WHEN",
+	IN:     "IN",
+	DO:     "DO",
+	YIELD:  "YIELD",
 }
 
 var type_reprs = [...]string{
 	ILLEGAL: "ILLEGAL",
 	EOF:     "EOF",
 
-	IDENT:  "IDENT",
-	INT:    "INT",
-	FLOAT:  "FLOAT",
-	STRING: "STRING",
-
-	ASSIGN:    "=",
-	ADDASSIGN: "+=",
-	SUBASSIGN: "-=",
-	MULASSIGN: "*=",
-	DIVASSIGN: "/=",
-	MODASSIGN: "%=",
+	IDENT:     "IDENT",
+	INT:       "INT",
+	FLOAT:     "FLOAT",
+	RATIONAL:  "RATIONAL",
+	IMAGINARY: "IMAGINARY",
+	STRING:    "STRING",
+
+	ASSIGN:           "=",
+	ADDASSIGN:        "+=",
+	SUBASSIGN:        "-=",
+	MULASSIGN:        "*=",
+	DIVASSIGN:        "/=",
+	MODASSIGN:        "%=",
+	POWASSIGN:        "**=",
+	ANDASSIGN:        "&=",
+	ORASSIGN:         "|=",
+	XORASSIGN:        "^=",
+	LSHIFTASSIGN:     "<<=",
+	RSHIFTASSIGN:     ">>=",
+	LOGICALANDASSIGN: "&&=",
+	LOGICALORASSIGN:  "||=",
 
 	PLUS:       "+",
 	MINUS:      "-",
 	BANG:       "!",
+	TILDE:      "~",
 	ASTERISK:   "*",
 	POW:        "**",
 	SLASH:      "/",
@@ -230,15 +326,20 @@ var type_reprs = [...]string{
 	AND:        "&",
 	LOGICALAND: "&&",
 	LOGICALOR:  "||",
+	CARET:      "^",
 
 	LT:        "<",
 	LTE:       "<=",
 	GT:        ">",
 	GTE:       ">=",
 	EQ:        "==",
+	CASEEQ:    "===",
 	NOTEQ:     "!=",
 	SPACESHIP: "<=>",
 	LSHIFT:    "<<",
+	RSHIFT:    ">>",
+	MATCH:     "=~",
+	NOTMATCH:  "!~",
 
 	NEWLINE:   "\\n",
 	COMMA:     ",",
@@ -249,6 +350,8 @@ var type_reprs = [...]string{
 	DDOT:      "..",
 	DDDOT:     "...",
 	COLON:     ":",
+	SCOPE:     "::",
+	SSCOPE:    "_::",
 	LPAREN:    "(",
 	RPAREN:    ")",
 	LBRACE:    "{",
@@ -256,13 +359,20 @@ var type_reprs = [...]string{
 	LBRACKET:  "[",
 	SLBRACKET: "_[",
 	RBRACKET:  "]",
+	SAND:      "_&",
 	PIPE:      "|",
 
 	HASHROCKET:   "=>",
 	LAMBDAROCKET: "->",
 
-	QMARK:  "?",
-	SYMBOL: ":",
+	QMARK:         "?",
+	SYMBOL:        ":",
+	INTERPOLATION: "#{",
+	BACKTICK:      "`",
+	REGEX:         "/",
+	REGEXEND:      "/imx",
+	WORDS:         "%w",
+	SYMBOLS:       "%i",
 
 	DEF:    "def",
 	END:    "end",
@@ -275,9 +385,26 @@ var type_reprs = [...]string{
 	FALSE:  "false",
 	RETURN: "return",
 	NIL:    "nil",
-	// WHILE:  "while", // to remove?
-	LOOP:  "loop",
-	BREAK: "break",
+	WHILE:  "while",
+	UNTIL:  "until",
+	FOR:    "for",
+	LOOP:   "loop",
+	BREAK:  "break",
+	NEXT:   "next",
+	REDO:   "redo",
+	BEGIN:  "begin",
+	RESCUE: "rescue",
+	ENSURE: "ensure",
+	RETRY:  "retry",
+	CLASS:  "class",
+	SELF:   "self",
+	SUPER:  "super",
+	MODULE: "module",
+	CASE:   "case",
+	WHEN:   "when",
+	IN:     "in",
+	DO:     "do",
+	YIELD:  "yield",
 }
 
 // String returns the string corresponding to the token tok.
@@ -336,9 +463,6 @@ func LookupIdent(ident string) Type {
 	if tok, ok := keywords[ident]; ok {
 		return tok
 	}
-	if ident == "class" {
-		return COMMENT
-	}
 	return IDENT
 }
 
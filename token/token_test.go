@@ -43,6 +43,8 @@ func TestTypeSting(t *testing.T) {
 		{tk: IDENT, str: "IDENT", repr: "IDENT"},
 		{tk: INT, str: "INT", repr: "INT"},
 		{tk: FLOAT, str: "FLOAT", repr: "FLOAT"},
+		{tk: RATIONAL, str: "RATIONAL", repr: "RATIONAL"},
+		{tk: IMAGINARY, str: "IMAGINARY", repr: "IMAGINARY"},
 		{tk: STRING, str: "STRING", repr: "STRING"},
 		//
 		{tk: ASSIGN, str: "ASSIGN", repr: "="},
@@ -51,10 +53,19 @@ func TestTypeSting(t *testing.T) {
 		{tk: MULASSIGN, str: "MULASSIGN", repr: "*="},
 		{tk: DIVASSIGN, str: "DIVASSIGN", repr: "/="},
 		{tk: MODASSIGN, str: "MODASSIGN", repr: "%="},
+		{tk: POWASSIGN, str: "POWASSIGN", repr: "**="},
+		{tk: ANDASSIGN, str: "ANDASSIGN", repr: "&="},
+		{tk: ORASSIGN, str: "ORASSIGN", repr: "|="},
+		{tk: XORASSIGN, str: "XORASSIGN", repr: "^="},
+		{tk: LSHIFTASSIGN, str: "LSHIFTASSIGN", repr: "<<="},
+		{tk: RSHIFTASSIGN, str: "RSHIFTASSIGN", repr: ">>="},
+		{tk: LOGICALANDASSIGN, str: "LOGICALANDASSIGN", repr: "&&="},
+		{tk: LOGICALORASSIGN, str: "LOGICALORASSIGN", repr: "||="},
 		//
 		{tk: PLUS, str: "PLUS", repr: "+"},
 		{tk: MINUS, str: "MINUS", repr: "-"},
 		{tk: BANG, str: "BANG", repr: "!"},
+		{tk: TILDE, str: "TILDE", repr: "~"},
 		{tk: ASTERISK, str: "ASTERISK", repr: "*"},
 		{tk: SLASH, str: "SLASH", repr: "/"},
 		{tk: MODULO, str: "MODULO", repr: "%"},
@@ -62,15 +73,20 @@ func TestTypeSting(t *testing.T) {
 		{tk: LOGICALAND, str: "LOGICALAND", repr: "&&"},
 		{tk: PIPE, str: "PIPE", repr: "|"},
 		{tk: LOGICALOR, str: "LOGICALOR", repr: "||"},
+		{tk: CARET, str: "CARET", repr: "^"},
 		//
 		{tk: LT, str: "LT", repr: "<"},
 		{tk: LTE, str: "LTE", repr: "<="},
 		{tk: GT, str: "GT", repr: ">"},
 		{tk: GTE, str: "GTE", repr: ">="},
 		{tk: EQ, str: "EQ", repr: "=="},
+		{tk: CASEEQ, str: "CASEEQ", repr: "==="},
 		{tk: NOTEQ, str: "NOTEQ", repr: "!="},
 		{tk: SPACESHIP, str: "SPACESHIP", repr: "<=>"},
 		{tk: LSHIFT, str: "LSHIFT", repr: "<<"},
+		{tk: RSHIFT, str: "RSHIFT", repr: ">>"},
+		{tk: MATCH, str: "MATCH", repr: "=~"},
+		{tk: NOTMATCH, str: "NOTMATCH", repr: "!~"},
 		//
 		{tk: HASHROCKET, str: "HASHROCKET", repr: "=>"},
 		{tk: LAMBDAROCKET, str: "LAMBDAROCKET", repr: "->"},
@@ -84,6 +100,8 @@ func TestTypeSting(t *testing.T) {
 		{tk: DDOT, str: "DDOT", repr: ".."},
 		{tk: DDDOT, str: "DDDOT", repr: "..."},
 		{tk: COLON, str: "COLON", repr: ":"},
+		{tk: SCOPE, str: "SCOPE", repr: "::"},
+		{tk: SSCOPE, str: "SSCOPE", repr: "_::"},
 		{tk: LPAREN, str: "LPAREN", repr: "("},
 		{tk: RPAREN, str: "RPAREN", repr: ")"},
 		{tk: LBRACE, str: "LBRACE", repr: "{"},
@@ -91,9 +109,16 @@ func TestTypeSting(t *testing.T) {
 		{tk: LBRACKET, str: "LBRACKET", repr: "["},
 		{tk: SLBRACKET, str: "SLBRACKET", repr: "_["},
 		{tk: RBRACKET, str: "RBRACKET", repr: "]"},
+		{tk: SAND, str: "SAND", repr: "_&"},
 		//
 		{tk: QMARK, str: "QMARK", repr: "?"},
 		{tk: SYMBOL, str: "SYMBOL", repr: ":"},
+		{tk: INTERPOLATION, str: "INTERPOLATION", repr: "#{"},
+		{tk: BACKTICK, str: "BACKTICK", repr: "`"},
+		{tk: REGEX, str: "REGEX", repr: "/"},
+		{tk: REGEXEND, str: "REGEXEND", repr: "/imx"},
+		{tk: WORDS, str: "WORDS", repr: "%w"},
+		{tk: SYMBOLS, str: "SYMBOLS", repr: "%i"},
 		{tk: POW, str: "POW", repr: "**"},
 		//
 		{tk: DEF, str: "DEF", repr: "def"},
@@ -107,9 +132,26 @@ func TestTypeSting(t *testing.T) {
 		{tk: FALSE, str: "FALSE", repr: "false"},
 		{tk: RETURN, str: "RETURN", repr: "return"},
 		{tk: NIL, str: "NIL", repr: "nil"},
-		// {tk: WHILE, str: "WHILE", repr: "while"},
+		{tk: WHILE, str: "WHILE", repr: "while"},
+		{tk: UNTIL, str: "UNTIL", repr: "until"},
+		{tk: FOR, str: "FOR", repr: "for"},
 		{tk: LOOP, str: "LOOP", repr: "loop"},
 		{tk: BREAK, str: "BREAK", repr: "break"},
+		{tk: NEXT, str: "NEXT", repr: "next"},
+		{tk: REDO, str: "REDO", repr: "redo"},
+		{tk: BEGIN, str: "BEGIN", repr: "begin"},
+		{tk: RESCUE, str: "RESCUE", repr: "rescue"},
+		{tk: ENSURE, str: "ENSURE", repr: "ensure"},
+		{tk: RETRY, str: "RETRY", repr: "retry"},
+		{tk: CLASS, str: "CLASS", repr: "class"},
+		{tk: SELF, str: "SELF", repr: "self"},
+		{tk: SUPER, str: "SUPER", repr: "super"},
+		{tk: MODULE, str: "MODULE", repr: "module"},
+		{tk: CASE, str: "CASE", repr: "case"},
+		{tk: WHEN, str: "WHEN", repr: "when"},
+		{tk: IN, str: "IN", repr: "in"},
+		{tk: DO, str: "DO", repr: "do"},
+		{tk: YIELD, str: "YIELD", repr: "yield"},
 	}
 
 	seen := make(map[Type]bool)
@@ -44,6 +44,9 @@ type block_lifting_transformer struct {
 	name_changes map[string]string // map of old name to new name
 	// pass number. this is a two-pass transformation.
 	pass int
+	// depth of the class and module bodies we are in. methods defined in
+	// them belong to the class or module, so they are never lifted.
+	class_depth int
 }
 
 func (f *block_lifting_transformer) PreTransform(node ast.Node) ast.Node {
@@ -60,6 +63,9 @@ func (f *block_lifting_transformer) PreTransform(node ast.Node) ast.Node {
 		}
 		f.call_stack = append(f.call_stack, &call{name: node.Name, parameters: parameter_names})
 		return node
+	case *ast.ClassExpression, *ast.ModuleExpression:
+		f.class_depth++
+		return node
 	default:
 		fmt.Printf("# TRANSFORMER walking %T\n", node)
 	}
@@ -78,9 +84,11 @@ func (f *block_lifting_transformer) PostTransform(node ast.Node) ast.Node {
 			f.call_stack = f.call_stack[:len(f.call_stack)-1]
 		}
 		// call the post-transform function
-		if f.pass == 0 {
+		if f.pass == 0 && f.class_depth == 0 {
 			return f.transformFunctionLiteralPass0(node)
 		}
+	case *ast.ClassExpression, *ast.ModuleExpression:
+		f.class_depth--
 	case *ast.ContextCallExpression:
 		if f.pass == 1 {
 			return f.transformContextCallExpressionPass1(node)